@@ -0,0 +1,183 @@
+// Command capture-history saves workflow histories from a Temporal server as JSON, for the
+// replay tests in internal/temporal/testdata/replay.
+//
+// Capture one workflow:
+//
+//	go run ./cmd/capture-history -workflow-id birthday-test-<userId>-<unix>
+//
+// Or the newest workflows matching a visibility query:
+//
+//	go run ./cmd/capture-history -query "WorkflowType = 'BirthdayTestWorkflow' AND ExecutionStatus = 'Completed'" -limit 5
+//
+// It connects with TEMPORAL_ADDRESS and TEMPORAL_NAMESPACE, like the worker. Histories carry
+// the workflows' inputs and results, contact names and email addresses included: replace them
+// with example data before committing a history.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"cardprocessor-go/internal/config"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/joho/godotenv"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+)
+
+// unsafeFileChars are replaced in workflow IDs to make file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func main() {
+	workflowID := flag.String("workflow-id", "", "ID of the workflow to capture")
+	runID := flag.String("run-id", "", "run of the workflow to capture; the latest when empty")
+	query := flag.String("query", "", "visibility query selecting the workflows to capture, instead of -workflow-id")
+	limit := flag.Int("limit", 10, "most workflows to capture with -query")
+	outDir := flag.String("out", filepath.Join("internal", "temporal", "testdata", "replay"), "directory to write the histories to")
+	name := flag.String("name", "", "file name for a -workflow-id history, without .json; defaults to the workflow ID")
+	flag.Parse()
+
+	if (*workflowID == "") == (*query == "") {
+		fmt.Fprintln(os.Stderr, "capture-history: set one of -workflow-id or -query")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+	cfg := config.Load()
+
+	c, err := client.Dial(client.Options{
+		HostPort:  cfg.TemporalAddress,
+		Namespace: cfg.TemporalNamespace,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to Temporal at %s: %v", cfg.TemporalAddress, err)
+	}
+	defer c.Close()
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		log.Fatalf("Failed to create %s: %v", *outDir, err)
+	}
+
+	ctx := context.Background()
+	if *workflowID != "" {
+		fileName := *name
+		if fileName == "" {
+			fileName = *workflowID
+		}
+		if err := captureHistory(ctx, c, *workflowID, *runID, filepath.Join(*outDir, historyFileName(fileName))); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		return
+	}
+
+	executions, err := listWorkflows(ctx, c, cfg.TemporalNamespace, *query, *limit)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	if len(executions) == 0 {
+		log.Printf("ℹ️ No workflows match %q", *query)
+		return
+	}
+	failed := 0
+	for _, execution := range executions {
+		fileName := historyFileName(execution.workflowID + "_" + shortRunID(execution.runID))
+		if err := captureHistory(ctx, c, execution.workflowID, execution.runID, filepath.Join(*outDir, fileName)); err != nil {
+			log.Printf("❌ %v", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// execution is a workflow run found by a visibility query
+type execution struct {
+	workflowID string
+	runID      string
+}
+
+// listWorkflows returns up to limit workflow runs matching query, newest first
+func listWorkflows(ctx context.Context, c client.Client, namespace, query string, limit int) ([]execution, error) {
+	var executions []execution
+	var nextPageToken []byte
+	for {
+		resp, err := c.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			Namespace:     namespace,
+			Query:         query,
+			NextPageToken: nextPageToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workflows: %w", err)
+		}
+		for _, info := range resp.GetExecutions() {
+			executions = append(executions, execution{
+				workflowID: info.GetExecution().GetWorkflowId(),
+				runID:      info.GetExecution().GetRunId(),
+			})
+			if len(executions) >= limit {
+				return executions, nil
+			}
+		}
+		nextPageToken = resp.GetNextPageToken()
+		if len(nextPageToken) == 0 {
+			return executions, nil
+		}
+	}
+}
+
+// captureHistory writes the full event history of a workflow run to path
+func captureHistory(ctx context.Context, c client.Client, workflowID, runID, path string) error {
+	history := &historypb.History{}
+	iter := c.GetWorkflowHistory(ctx, workflowID, runID, false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("failed to fetch history of %s: %w", workflowID, err)
+		}
+		history.Events = append(history.Events, event)
+	}
+	if len(history.Events) == 0 {
+		return fmt.Errorf("workflow %s has no history", workflowID)
+	}
+
+	var buf bytes.Buffer
+	marshaler := jsonpb.Marshaler{Indent: "  "}
+	if err := marshaler.Marshal(&buf, history); err != nil {
+		return fmt.Errorf("failed to encode history of %s: %w", workflowID, err)
+	}
+	buf.WriteString("\n")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write history of %s: %w", workflowID, err)
+	}
+
+	log.Printf("✅ Captured %d events of %s to %s", len(history.Events), workflowID, path)
+	return nil
+}
+
+// historyFileName makes a file name for a history out of a workflow ID
+func historyFileName(name string) string {
+	name = strings.TrimSuffix(name, ".json")
+	return strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_") + ".json"
+}
+
+// shortRunID keeps file names of several runs of one workflow apart
+func shortRunID(runID string) string {
+	if len(runID) > 8 {
+		return runID[:8]
+	}
+	return runID
+}
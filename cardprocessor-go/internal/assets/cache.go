@@ -0,0 +1,42 @@
+package assets
+
+// boundedCache maps keys to values, dropping the oldest entry once it holds max entries. It
+// isn't safe for concurrent use; Service guards its caches with its mutex.
+type boundedCache[V any] struct {
+	max     int
+	entries map[string]V
+	order   []string // keys, oldest first
+}
+
+func newBoundedCache[V any](max int) *boundedCache[V] {
+	return &boundedCache[V]{max: max, entries: make(map[string]V, max)}
+}
+
+func (c *boundedCache[V]) get(key string) (V, bool) {
+	value, ok := c.entries[key]
+	return value, ok
+}
+
+func (c *boundedCache[V]) put(key string, value V) {
+	if _, ok := c.entries[key]; !ok {
+		if len(c.order) >= c.max {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = value
+}
+
+func (c *boundedCache[V]) remove(key string) {
+	if _, ok := c.entries[key]; !ok {
+		return
+	}
+	delete(c.entries, key)
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package assets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cardprocessor-go/internal/i18n"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// cardRendererVersion is part of every template version; bump it when the drawing code changes
+const cardRendererVersion = "1"
+
+// Card images are rendered at 2x the 600px card width
+const (
+	cardImageWidth  = 1200
+	cardImageHeight = 600
+	cardTextMargin  = 80
+	cardLogoMaxSize = 120
+)
+
+// CardImageRequest describes a personalized card image
+type CardImageRequest struct {
+	TemplateID     string
+	BackgroundURL  string
+	LogoURL        string
+	PrimaryColor   string
+	SecondaryColor string
+	ContactID      string
+	Name           string
+	Age            int // 0 when the contact's birth year is unknown
+	Year           int
+	Language       string // greeting language; empty means English
+}
+
+// TemplateVersion fingerprints everything that affects the image apart from the contact,
+// so changing a tenant's template, background, logo or colors produces new images
+func (r CardImageRequest) TemplateVersion() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		cardRendererVersion, r.TemplateID, r.BackgroundURL, r.LogoURL, r.PrimaryColor, r.SecondaryColor,
+	}, "|")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Key returns the storage key for the rendered image, unique per (template version, contact, year, language)
+func (r CardImageRequest) Key() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		r.TemplateVersion(), r.ContactID, r.Name, strconv.Itoa(r.Age), strconv.Itoa(r.Year), i18n.ResolveLanguage(r.Language),
+	}, "|")))
+	return "card-" + hex.EncodeToString(sum[:]) + ".png"
+}
+
+var (
+	cardFontsOnce sync.Once
+	cardFontBold  *opentype.Font
+	cardFontBody  *opentype.Font
+	cardFontsErr  error
+)
+
+// loadCardFonts parses the bundled Go fonts once
+func loadCardFonts() error {
+	cardFontsOnce.Do(func() {
+		if cardFontBold, cardFontsErr = opentype.Parse(gobold.TTF); cardFontsErr != nil {
+			return
+		}
+		cardFontBody, cardFontsErr = opentype.Parse(goregular.TTF)
+	})
+	return cardFontsErr
+}
+
+// RenderCardImage returns the URL of the personalized card image for req, rendering and
+// storing it on first use. Later calls for the same contact, year and template hit the cache.
+func (s *Service) RenderCardImage(ctx context.Context, req CardImageRequest) (string, error) {
+	key := req.Key()
+	exists, err := s.storage.Exists(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return s.URLFor(key), nil
+	}
+
+	data, err := s.drawCardImage(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	if err := s.storage.Put(ctx, key, "image/png", data); err != nil {
+		return "", err
+	}
+	return s.URLFor(key), nil
+}
+
+func (s *Service) drawCardImage(ctx context.Context, req CardImageRequest) ([]byte, error) {
+	if err := loadCardFonts(); err != nil {
+		return nil, fmt.Errorf("failed to load card fonts: %w", err)
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, cardImageWidth, cardImageHeight))
+
+	// Background: the template image scaled to cover, or a gradient of the theme colors
+	drawGradient(canvas, parseHexColor(req.PrimaryColor, color.RGBA{0x66, 0x7e, 0xea, 0xff}), parseHexColor(req.SecondaryColor, color.RGBA{0x76, 0x4b, 0xa2, 0xff}))
+	if req.BackgroundURL != "" {
+		if background, err := s.loadImage(ctx, req.BackgroundURL); err == nil {
+			drawCover(canvas, background)
+		} else {
+			fmt.Printf("⚠️ [RenderCardImage] Background unavailable, using gradient: %v\n", err)
+		}
+	}
+
+	// Darken the lower part so the text stays readable on any background
+	drawBottomShade(canvas, cardImageHeight*45/100)
+
+	if req.LogoURL != "" {
+		if logo, err := s.loadImage(ctx, req.LogoURL); err == nil {
+			drawLogo(canvas, logo)
+		} else {
+			fmt.Printf("⚠️ [RenderCardImage] Logo unavailable, skipping: %v\n", err)
+		}
+	}
+
+	text := i18n.GetCardText(req.Language)
+	greeting := text.ImageGreeting
+	if req.Age > 0 {
+		greeting = fmt.Sprintf(text.ImageGreetingAge, i18n.Ordinal(req.Language, req.Age))
+	}
+	if err := drawCenteredText(canvas, cardFontBody, greeting, 56, 0, cardImageHeight-150); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = text.ImageFallbackName
+	}
+	if err := drawCenteredText(canvas, cardFontBold, name+"!", 104, 48, cardImageHeight-50); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, canvas); err != nil {
+		return nil, fmt.Errorf("failed to encode card image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// loadImage decodes an image from this service's storage or from an external URL
+func (s *Service) loadImage(ctx context.Context, rawURL string) (image.Image, error) {
+	var data []byte
+	var err error
+	if s.isOwnURL(rawURL) {
+		data, _, err = s.storage.Get(ctx, strings.TrimPrefix(rawURL, s.publicURL+"/assets/"))
+	} else {
+		data, err = s.fetch(ctx, rawURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxSourcePixels {
+		return nil, fmt.Errorf("image dimensions %dx%d are not allowed", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	return img, nil
+}
+
+// drawGradient fills dst with a diagonal gradient from one color to another
+func drawGradient(dst *image.RGBA, from, to color.RGBA) {
+	bounds := dst.Bounds()
+	span := bounds.Dx() + bounds.Dy()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			t := float64(x+y) / float64(span)
+			dst.SetRGBA(x, y, color.RGBA{
+				R: lerp(from.R, to.R, t),
+				G: lerp(from.G, to.G, t),
+				B: lerp(from.B, to.B, t),
+				A: 0xff,
+			})
+		}
+	}
+}
+
+// drawCover scales src to cover dst, cropping the overflow around the center
+func drawCover(dst *image.RGBA, src image.Image) {
+	bounds := src.Bounds()
+	dstW, dstH := dst.Bounds().Dx(), dst.Bounds().Dy()
+
+	crop := bounds
+	if bounds.Dx()*dstH > bounds.Dy()*dstW {
+		width := bounds.Dy() * dstW / dstH
+		crop.Min.X += (bounds.Dx() - width) / 2
+		crop.Max.X = crop.Min.X + width
+	} else {
+		height := bounds.Dx() * dstH / dstW
+		crop.Min.Y += (bounds.Dy() - height) / 2
+		crop.Max.Y = crop.Min.Y + height
+	}
+
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Over, nil)
+}
+
+// drawBottomShade blends a transparent-to-dark gradient over the bottom height pixels
+func drawBottomShade(dst *image.RGBA, height int) {
+	bounds := dst.Bounds()
+	top := bounds.Max.Y - height
+	for y := top; y < bounds.Max.Y; y++ {
+		alpha := uint8(170 * (y - top) / height)
+		shade := image.NewUniform(color.RGBA{A: alpha})
+		draw.Draw(dst, image.Rect(bounds.Min.X, y, bounds.Max.X, y+1), shade, image.Point{}, draw.Over)
+	}
+}
+
+// drawLogo places the logo in the top-left corner, scaled down to fit cardLogoMaxSize
+func drawLogo(dst *image.RGBA, logo image.Image) {
+	bounds := logo.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if height > cardLogoMaxSize {
+		width = width * cardLogoMaxSize / height
+		height = cardLogoMaxSize
+	}
+	if width > cardLogoMaxSize*3 {
+		height = height * cardLogoMaxSize * 3 / width
+		width = cardLogoMaxSize * 3
+	}
+	if width < 1 || height < 1 {
+		return
+	}
+
+	target := image.Rect(48, 48, 48+width, 48+height)
+	draw.CatmullRom.Scale(dst, target, logo, bounds, draw.Over, nil)
+}
+
+// drawCenteredText draws text centered horizontally with its baseline at y, shrinking the
+// font from size towards minSize (when set) until it fits between the margins
+func drawCenteredText(dst *image.RGBA, f *opentype.Font, text string, size, minSize float64, y int) error {
+	maxWidth := fixed.I(dst.Bounds().Dx() - 2*cardTextMargin)
+
+	var face font.Face
+	var width fixed.Int26_6
+	for {
+		var err error
+		face, err = opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return fmt.Errorf("failed to create font face: %w", err)
+		}
+		width = font.MeasureString(face, text)
+		if width <= maxWidth || minSize == 0 || size <= minSize {
+			break
+		}
+		face.Close()
+		size -= 4
+	}
+	defer face.Close()
+
+	x := (fixed.I(dst.Bounds().Dx()) - width) / 2
+	if x < fixed.I(cardTextMargin) && minSize > 0 {
+		x = fixed.I(cardTextMargin)
+	}
+
+	// Soft shadow first, then the text itself
+	shadow := &font.Drawer{Dst: dst, Src: image.NewUniform(color.RGBA{A: 0x99}), Face: face, Dot: fixed.Point26_6{X: x + fixed.I(3), Y: fixed.I(y + 3)}}
+	shadow.DrawString(text)
+	drawer := &font.Drawer{Dst: dst, Src: image.White, Face: face, Dot: fixed.Point26_6{X: x, Y: fixed.I(y)}}
+	drawer.DrawString(text)
+	return nil
+}
+
+// parseHexColor parses #rgb or #rrggbb, returning fallback for anything else
+func parseHexColor(value string, fallback color.RGBA) color.RGBA {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if len(value) == 3 {
+		value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+	}
+	if len(value) != 6 {
+		return fallback
+	}
+	n, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return fallback
+	}
+	return color.RGBA{R: uint8(n >> 16), G: uint8(n >> 8), B: uint8(n), A: 0xff}
+}
+
+func lerp(a, b uint8, t float64) uint8 {
+	return uint8(float64(a) + (float64(b)-float64(a))*t)
+}
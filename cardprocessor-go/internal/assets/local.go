@@ -0,0 +1,93 @@
+package assets
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage stores assets as files in a directory
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage creates a local storage rooted at dir, creating it if needed
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("asset directory is required for local storage")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create asset directory: %w", err)
+	}
+	return &LocalStorage{root: dir}, nil
+}
+
+// path resolves a key inside the storage root, rejecting traversal
+func (s *LocalStorage) path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("invalid asset key %q", key)
+	}
+	return filepath.Join(s.root, key), nil
+}
+
+// Put writes the object atomically via a temporary file
+func (s *LocalStorage) Put(ctx context.Context, key, contentType string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.root, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write asset: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close asset file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store asset: %w", err)
+	}
+	return nil
+}
+
+// Get reads the object; the content type is derived from the key's extension
+func (s *LocalStorage) Get(ctx context.Context, key string) ([]byte, string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, "", ErrNotFound
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, "", ErrNotFound
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read asset: %w", err)
+	}
+	return data, mime.TypeByExtension(filepath.Ext(key)), nil
+}
+
+// Exists reports whether the object is present
+func (s *LocalStorage) Exists(ctx context.Context, key string) (bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return false, nil
+	}
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat asset: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,141 @@
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register WebP decoder
+)
+
+// EmailWidths are the widths variants are produced at: the 600px card width and a 2x copy for high-DPI screens
+var EmailWidths = []int{600, 1200}
+
+// DisplayWidth is the variant width used when rewriting images in rendered cards
+const DisplayWidth = 600
+
+// maxSourcePixels guards against decompression bombs
+const maxSourcePixels = 40_000_000
+
+// ErrUnsupportedImage is returned for data that is not a decodable image
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// Variant is a resized, re-encoded copy of a source image
+type Variant struct {
+	Width       int
+	Height      int
+	ContentType string
+	Extension   string
+	Hash        string
+	Data        []byte
+}
+
+// Key returns the content-hash storage key for the variant
+func (v Variant) Key() string {
+	return v.Hash + "." + v.Extension
+}
+
+// ProcessedImage is the result of processing a source image
+type ProcessedImage struct {
+	Format   string // source format: jpeg, png, gif or webp
+	Width    int
+	Height   int
+	Variants []Variant
+}
+
+// Process decodes an image and produces email-safe variants. Images are never upscaled:
+// a source narrower than a target width yields a single variant at its own width.
+// Opaque images are encoded as JPEG, images with transparency as PNG.
+func Process(data []byte) (*ProcessedImage, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxSourcePixels {
+		return nil, fmt.Errorf("image dimensions %dx%d are not allowed", cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	result := &ProcessedImage{Format: format, Width: cfg.Width, Height: cfg.Height}
+	opaque := isOpaque(src)
+
+	seen := make(map[int]bool)
+	for _, width := range EmailWidths {
+		if width > cfg.Width {
+			width = cfg.Width
+		}
+		if seen[width] {
+			continue
+		}
+		seen[width] = true
+
+		variant, err := encodeVariant(src, width, opaque)
+		if err != nil {
+			return nil, err
+		}
+		result.Variants = append(result.Variants, variant)
+	}
+
+	return result, nil
+}
+
+// encodeVariant scales src to width (keeping aspect ratio) and encodes it
+func encodeVariant(src image.Image, width int, opaque bool) (Variant, error) {
+	bounds := src.Bounds()
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	variant := Variant{Width: width, Height: height}
+	if opaque {
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+			return Variant{}, fmt.Errorf("failed to encode JPEG: %w", err)
+		}
+		variant.ContentType = "image/jpeg"
+		variant.Extension = "jpg"
+	} else {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := encoder.Encode(&buf, dst); err != nil {
+			return Variant{}, fmt.Errorf("failed to encode PNG: %w", err)
+		}
+		variant.ContentType = "image/png"
+		variant.Extension = "png"
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	variant.Hash = hex.EncodeToString(sum[:])
+	variant.Data = buf.Bytes()
+	return variant, nil
+}
+
+// isOpaque reports whether the image has no transparent pixels
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
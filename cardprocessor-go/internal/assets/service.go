@@ -0,0 +1,402 @@
+package assets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"cardprocessor-go/internal/config"
+	"cardprocessor-go/internal/models"
+	"cardprocessor-go/internal/repository"
+)
+
+// ErrTooLarge is returned when an upload or fetched image exceeds the size limit
+var ErrTooLarge = errors.New("image exceeds the maximum allowed size")
+
+// proxyFailureTTL is how long a failed proxy fetch is remembered before retrying
+const proxyFailureTTL = 10 * time.Minute
+
+// proxyCacheSize bounds how many proxied URLs, and separately failed fetches, are kept in memory
+const proxyCacheSize = 2000
+
+// prefetchQueueSize is how many images can wait for a background fetch. Images that don't fit
+// are queued again the next time a card using them is rendered.
+const prefetchQueueSize = 100
+
+// maxFetchRedirects is how many redirects an image fetch follows
+const maxFetchRedirects = 3
+
+// Service stores images as email-safe variants and proxies external card images
+type Service struct {
+	storage   Storage
+	repo      *repository.Repository
+	client    *http.Client
+	publicURL string
+	maxBytes  int64
+
+	mu       sync.Mutex
+	proxied  *boundedCache[string]    // source URL -> proxied URL
+	failures *boundedCache[time.Time] // source URL -> time of last failed fetch
+	pending  map[string]bool          // source URLs waiting in queue
+	queue    chan string              // source URLs to fetch in the background
+}
+
+// NewService creates the asset service from configuration
+func NewService(cfg *config.Config, repo *repository.Repository) (*Service, error) {
+	storage, err := NewStorage(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Service{
+		storage:   storage,
+		repo:      repo,
+		client:    newFetchClient(),
+		publicURL: strings.TrimRight(cfg.AssetPublicURL, "/"),
+		maxBytes:  int64(cfg.AssetMaxUploadMB) * 1024 * 1024,
+		proxied:   newBoundedCache[string](proxyCacheSize),
+		failures:  newBoundedCache[time.Time](proxyCacheSize),
+		pending:   make(map[string]bool),
+		queue:     make(chan string, prefetchQueueSize),
+	}
+	go s.fetchQueued()
+	return s, nil
+}
+
+// MaxBytes returns the maximum accepted image size
+func (s *Service) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// URLFor returns the public URL of a stored variant
+func (s *Service) URLFor(key string) string {
+	return s.publicURL + "/assets/" + key
+}
+
+// Upload processes and stores an uploaded image for a tenant
+func (s *Service) Upload(ctx context.Context, tenantID, filename string, data []byte) (*models.ImageAsset, error) {
+	if int64(len(data)) > s.maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	asset, err := s.store(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	asset.TenantID = &tenantID
+	if filename != "" {
+		asset.OriginalFilename = &filename
+	}
+
+	if err := s.repo.CreateImageAsset(ctx, asset); err != nil {
+		return nil, err
+	}
+	s.fillURLs(asset)
+	return asset, nil
+}
+
+// Open returns a stored variant for serving
+func (s *Service) Open(ctx context.Context, key string) ([]byte, string, error) {
+	return s.storage.Get(ctx, key)
+}
+
+// CachedURL returns the self-hosted URL of an external image that has already been proxied,
+// without any I/O, so it is safe to call while rendering. An image not proxied yet is queued
+// for a background fetch and CachedURL reports false. URLs already served by this service are
+// returned unchanged.
+func (s *Service) CachedURL(sourceURL string) (string, bool) {
+	if s.isOwnURL(sourceURL) {
+		return sourceURL, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if proxied, ok := s.proxied.get(sourceURL); ok {
+		return proxied, true
+	}
+	if failedAt, failed := s.failures.get(sourceURL); failed && time.Since(failedAt) < proxyFailureTTL {
+		return "", false
+	}
+	if !s.pending[sourceURL] {
+		select {
+		case s.queue <- sourceURL:
+			s.pending[sourceURL] = true
+		default:
+			// The queue is full; the image is queued again on a later render
+		}
+	}
+	return "", false
+}
+
+// ProxyURL returns a self-hosted URL for an external image, fetching, resizing and
+// storing it on first use. URLs already served by this service are returned unchanged.
+func (s *Service) ProxyURL(ctx context.Context, sourceURL string) (string, error) {
+	if s.isOwnURL(sourceURL) {
+		return sourceURL, nil
+	}
+
+	s.mu.Lock()
+	proxied, ok := s.proxied.get(sourceURL)
+	failedAt, failed := s.failures.get(sourceURL)
+	s.mu.Unlock()
+	if ok {
+		return proxied, nil
+	}
+	if failed && time.Since(failedAt) < proxyFailureTTL {
+		return "", fmt.Errorf("recent proxy fetch failed for %s", sourceURL)
+	}
+
+	proxied, err := s.proxy(ctx, sourceURL)
+	s.mu.Lock()
+	if err != nil {
+		s.failures.put(sourceURL, time.Now())
+	} else {
+		s.proxied.put(sourceURL, proxied)
+		s.failures.remove(sourceURL)
+	}
+	s.mu.Unlock()
+
+	return proxied, err
+}
+
+// fetchQueued proxies the images CachedURL queued, one at a time
+func (s *Service) fetchQueued() {
+	for sourceURL := range s.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if _, err := s.ProxyURL(ctx, sourceURL); err != nil {
+			fmt.Printf("⚠️ [ImageProxy] Failed to proxy %s: %v\n", sourceURL, err)
+		}
+		cancel()
+
+		s.mu.Lock()
+		delete(s.pending, sourceURL)
+		s.mu.Unlock()
+	}
+}
+
+func (s *Service) proxy(ctx context.Context, sourceURL string) (string, error) {
+	asset, err := s.repo.GetImageAssetBySourceURL(ctx, sourceURL)
+	if err != nil {
+		return "", err
+	}
+
+	if asset == nil {
+		data, err := s.fetch(ctx, sourceURL)
+		if err != nil {
+			return "", err
+		}
+		asset, err = s.store(ctx, data)
+		if err != nil {
+			return "", err
+		}
+		asset.SourceURL = &sourceURL
+
+		if err := s.repo.CreateImageAsset(ctx, asset); err != nil {
+			// Another worker may have proxied the same URL concurrently
+			existing, lookupErr := s.repo.GetImageAssetBySourceURL(ctx, sourceURL)
+			if lookupErr != nil || existing == nil {
+				return "", err
+			}
+			asset = existing
+		}
+	}
+
+	variant := displayVariant(asset.Variants)
+	if variant == nil {
+		return "", fmt.Errorf("image asset %s has no variants", asset.ID)
+	}
+	return s.URLFor(variant.StorageKey), nil
+}
+
+// store processes image data and writes every variant to storage
+func (s *Service) store(ctx context.Context, data []byte) (*models.ImageAsset, error) {
+	processed, err := Process(data)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := &models.ImageAsset{
+		OriginalContentType: "image/" + processed.Format,
+		OriginalWidth:       processed.Width,
+		OriginalHeight:      processed.Height,
+	}
+
+	for _, variant := range processed.Variants {
+		key := variant.Key()
+		exists, err := s.storage.Exists(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			if err := s.storage.Put(ctx, key, variant.ContentType, variant.Data); err != nil {
+				return nil, err
+			}
+		}
+
+		asset.Variants = append(asset.Variants, models.ImageAssetVariant{
+			Width:       variant.Width,
+			Height:      variant.Height,
+			ContentType: variant.ContentType,
+			ContentHash: variant.Hash,
+			StorageKey:  key,
+			SizeBytes:   len(variant.Data),
+		})
+	}
+
+	return asset, nil
+}
+
+// fetch downloads an external image, enforcing scheme, host and size limits. The client checks
+// every address it connects to, including after redirects, so a host that resolves to a
+// private address when connecting is refused too.
+func (s *Service) fetch(ctx context.Context, sourceURL string) ([]byte, error) {
+	parsed, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image URL %q", sourceURL)
+	}
+	if err := checkFetchURL(parsed); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create image request: %w", err)
+	}
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image fetch returned status %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("URL did not return an image (content type %q)", contentType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if int64(len(data)) > s.maxBytes {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+func (s *Service) isOwnURL(rawURL string) bool {
+	return s.publicURL != "" && strings.HasPrefix(rawURL, s.publicURL+"/assets/")
+}
+
+func (s *Service) fillURLs(asset *models.ImageAsset) {
+	for i := range asset.Variants {
+		asset.Variants[i].URL = s.URLFor(asset.Variants[i].StorageKey)
+	}
+}
+
+// displayVariant picks the variant closest to the card display width
+func displayVariant(variants []models.ImageAssetVariant) *models.ImageAssetVariant {
+	var best *models.ImageAssetVariant
+	for i := range variants {
+		variant := &variants[i]
+		if best == nil {
+			best = variant
+			continue
+		}
+		if abs(variant.Width-DisplayWidth) < abs(best.Width-DisplayWidth) {
+			best = variant
+		}
+	}
+	return best
+}
+
+// newFetchClient makes the HTTP client for image fetches. It ignores proxy settings, refuses
+// to connect to private addresses and checks each redirect.
+func newFetchClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+				return fmt.Errorf("refusing to fetch image from private address %s", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: 15 * time.Second,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 10 * time.Second,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: checkFetchRedirect,
+	}
+}
+
+// checkFetchRedirect follows up to maxFetchRedirects redirects to public http(s) URLs
+func checkFetchRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxFetchRedirects {
+		return fmt.Errorf("image fetch stopped after %d redirects", len(via))
+	}
+	return checkFetchURL(req.URL)
+}
+
+// checkFetchURL accepts http(s) URLs whose host resolves only to public addresses. A host
+// that can't be resolved is refused.
+func checkFetchURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("invalid image URL %q", u.String())
+	}
+	if isPrivateHost(u.Hostname()) {
+		return fmt.Errorf("refusing to fetch image from private host %q", u.Hostname())
+	}
+	return nil
+}
+
+// isPrivateHost reports whether host is, or resolves to, a loopback, private or link-local
+// address. Hosts that fail to resolve count as private.
+func isPrivateHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil || len(ips) == 0 {
+		return true
+	}
+	for _, ip := range ips {
+		if isPrivateIP(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPrivateIP reports whether ip is an address image fetches must not reach
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,140 @@
+package assets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckFetchURL(t *testing.T) {
+	tests := []struct {
+		rawURL  string
+		wantErr bool
+	}{
+		{"https://93.184.215.14/cake.png", false},
+		{"http://localhost/cake.png", true},
+		{"http://127.0.0.1:8080/cake.png", true},
+		{"http://10.1.2.3/cake.png", true},
+		{"http://192.168.0.10/cake.png", true},
+		{"http://169.254.169.254/latest/meta-data/", true},
+		{"http://[::1]/cake.png", true},
+		{"http://[fe80::1]/cake.png", true},
+		{"http://0.0.0.0/cake.png", true},
+		{"ftp://93.184.215.14/cake.png", true},
+		{"file:///etc/passwd", true},
+		// Hosts that don't resolve are refused rather than let through
+		{"http://image-host.invalid/cake.png", true},
+	}
+	for _, tt := range tests {
+		parsed, err := url.Parse(tt.rawURL)
+		if err != nil {
+			t.Fatalf("url.Parse(%q): %v", tt.rawURL, err)
+		}
+		if err := checkFetchURL(parsed); (err != nil) != tt.wantErr {
+			t.Errorf("checkFetchURL(%q) error = %v, want error %v", tt.rawURL, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFetchClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+	}))
+	defer server.Close()
+
+	// The dialer checks the address it connects to, whatever the URL said
+	resp, err := newFetchClient().Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("fetch client connected to a loopback address")
+	}
+	if !strings.Contains(err.Error(), "private address") {
+		t.Errorf("fetch client error = %v, want a private address refusal", err)
+	}
+}
+
+func TestCheckFetchRedirect(t *testing.T) {
+	public, _ := url.Parse("https://93.184.215.14/cake.png")
+	private, _ := url.Parse("http://169.254.169.254/latest/meta-data/")
+	via := []*http.Request{{URL: public}}
+
+	if err := checkFetchRedirect(&http.Request{URL: public}, via); err != nil {
+		t.Errorf("redirect to a public address refused: %v", err)
+	}
+	if err := checkFetchRedirect(&http.Request{URL: private}, via); err == nil {
+		t.Error("redirect to a link-local address followed")
+	}
+
+	tooMany := make([]*http.Request, maxFetchRedirects)
+	for i := range tooMany {
+		tooMany[i] = &http.Request{URL: public}
+	}
+	if err := checkFetchRedirect(&http.Request{URL: public}, tooMany); err == nil {
+		t.Errorf("redirect followed after %d redirects", maxFetchRedirects)
+	}
+}
+
+func TestCachedURLQueuesMissingImages(t *testing.T) {
+	s := &Service{
+		publicURL: "https://cdn.example.com",
+		proxied:   newBoundedCache[string](10),
+		failures:  newBoundedCache[time.Time](10),
+		pending:   make(map[string]bool),
+		queue:     make(chan string, 1),
+	}
+	s.proxied.put("https://images.example.com/stored.png", "https://cdn.example.com/assets/stored.png")
+	s.failures.put("https://images.example.com/broken.png", time.Now())
+
+	tests := []struct {
+		source string
+		want   string
+		wantOK bool
+	}{
+		{"https://cdn.example.com/assets/own.png", "https://cdn.example.com/assets/own.png", true},
+		{"https://images.example.com/stored.png", "https://cdn.example.com/assets/stored.png", true},
+		{"https://images.example.com/broken.png", "", false},
+		{"https://images.example.com/new.png", "", false},
+		{"https://images.example.com/new.png", "", false},
+		{"https://images.example.com/other.png", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := s.CachedURL(tt.source); got != tt.want || ok != tt.wantOK {
+			t.Errorf("CachedURL(%q) = %q, %v, want %q, %v", tt.source, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	// new.png is queued once; other.png found the queue full and waits for a later render
+	if len(s.queue) != 1 || <-s.queue != "https://images.example.com/new.png" {
+		t.Error("CachedURL didn't queue the missing image exactly once")
+	}
+	if s.pending["https://images.example.com/other.png"] || s.pending["https://images.example.com/broken.png"] {
+		t.Errorf("pending = %v, want only the queued image", s.pending)
+	}
+}
+
+func TestBoundedCacheDropsOldestEntries(t *testing.T) {
+	cache := newBoundedCache[int](2)
+	cache.put("a", 1)
+	cache.put("b", 2)
+	cache.put("a", 3) // updating doesn't make room
+	cache.put("c", 4)
+
+	if _, ok := cache.get("a"); ok {
+		t.Error("oldest entry was kept")
+	}
+	if got, _ := cache.get("b"); got != 2 {
+		t.Errorf("get(b) = %d, want 2", got)
+	}
+	if got, _ := cache.get("c"); got != 4 {
+		t.Errorf("get(c) = %d, want 4", got)
+	}
+
+	cache.remove("b")
+	cache.put("d", 5)
+	if len(cache.entries) != 2 || len(cache.order) != 2 {
+		t.Errorf("cache holds %d entries in %d keys, want 2", len(cache.entries), len(cache.order))
+	}
+}
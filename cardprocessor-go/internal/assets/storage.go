@@ -0,0 +1,37 @@
+package assets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cardprocessor-go/internal/config"
+)
+
+// ErrNotFound is returned when an object does not exist in storage
+var ErrNotFound = errors.New("asset not found")
+
+// Storage stores encoded image variants under content-hash keys
+type Storage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, string, error)
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// NewStorage creates the storage backend selected by ASSET_STORAGE
+func NewStorage(cfg *config.Config) (Storage, error) {
+	switch cfg.AssetStorage {
+	case "", "local":
+		return NewLocalStorage(cfg.AssetLocalDir)
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:  cfg.AssetS3Endpoint,
+			Bucket:    cfg.AssetS3Bucket,
+			Region:    cfg.AssetS3Region,
+			AccessKey: cfg.AssetS3AccessKey,
+			SecretKey: cfg.AssetS3SecretKey,
+		})
+	default:
+		return nil, fmt.Errorf("unknown asset storage backend %q", cfg.AssetStorage)
+	}
+}
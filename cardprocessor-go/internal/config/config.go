@@ -4,6 +4,8 @@ import (
 	"os"
 	"strconv"
 	"strings"
+
+	"cardprocessor-go/internal/tokens"
 )
 
 type Config struct {
@@ -41,13 +43,60 @@ type Config struct {
 	BirthdayMaxRetries    int
 	BirthdayRetryDelay    int // in seconds
 	BirthdayWorkerEnabled bool
-	EnableEmailFallback    bool // Allow direct email sending when Temporal is unavailable
+	EnableEmailFallback   bool // Allow direct email sending when Temporal is unavailable
 
 	// Temporal settings
 	TemporalAddress       string
 	TemporalNamespace     string
 	TemporalTaskQueue     string
 	TemporalWorkerEnabled bool
+	TemporalWorker        TemporalWorkerConfig
+
+	// Send workflow activity policies (timeouts in seconds); sends retry per BirthdayMaxRetries
+	// and BirthdayRetryDelay
+	ActivityPrepareTimeout      int // Timeout of one attempt to sign tokens, render images or build content
+	ActivityPrepareMaxAttempts  int
+	ActivitySendTimeout         int // Timeout of one attempt to send through the providers
+	ActivitySendMaxRetryDelay   int // Longest wait between send retries as the delay backs off
+	ActivityTrackingTimeout     int // Timeout of one attempt to record a status or email_sends row
+	ActivityTrackingMaxAttempts int
+
+	// Image assets
+	AssetStorage      string // "local" or "s3"
+	AssetLocalDir     string
+	AssetPublicURL    string // Base URL used for self-hosted images in emails
+	AssetMaxUploadMB  int
+	AssetProxyEnabled bool // Rewrite external card images to self-hosted copies
+	CardImageEnabled  bool // Allow personalized card images (still opt-in per tenant)
+	AssetS3Endpoint   string
+	AssetS3Bucket     string
+	AssetS3Region     string
+	AssetS3AccessKey  string
+	AssetS3SecretKey  string
+
+	// Localization
+	LocalesDir string // Optional directory of <lang>.json catalogs overriding the embedded ones
+
+	// Public pages
+	AppURL                string          // Public base URL of the app that proxies the contact-facing pages
+	InvitationTokenSecret string          // HMAC key for birthday invitation tokens, kept apart from JWT_SECRET
+	UnsubscribeMailto     string          // Inbound address offered as the mailto: List-Unsubscribe option; empty disables it
+	UnsubscribeTokenKeys  string          // "<id>:<secret>,..." HMAC keys for unsubscribe links; the first signs, all verify
+	UnsubscribeTokenDays  int             // How long a signed unsubscribe link stays valid; 0 never expires
+	UnsubscribeKeyring    *tokens.Keyring // UnsubscribeTokenKeys, parsed once at startup (see LoadUnsubscribeKeyring)
+
+	// Data retention (defaults for tenants without their own settings; 0 keeps data forever)
+	RetentionContentDays     int    // Days to keep the HTML/text of sent emails
+	RetentionWebhookDataDays int    // Days to keep raw webhook payloads on email activity
+	RetentionEventDays       int    // Days before email events are compacted into daily counts
+	RetentionPurgeCron       string // Cron schedule for the retention purge workflow; empty disables it
+
+	// Send rate limits, shared by all activities in the worker; 0 disables a limit
+	ProviderSendsPerMinute int // Sends per minute to each email provider
+	ProviderSendBurst      int
+	TenantSendsPerMinute   int // Sends per minute for each tenant, so one tenant can't starve the rest
+	TenantSendBurst        int
+	DefaultDailySendLimit  int // Daily sends for tenants with no plan or override limit to fall back on
 
 	// Logging
 	LogLevel string
@@ -85,6 +134,14 @@ type CORSConfig struct {
 	AllowCredentials bool
 }
 
+// TemporalWorkerConfig sizes the Temporal worker
+type TemporalWorkerConfig struct {
+	MaxConcurrentActivities    int // Activities running at once
+	MaxConcurrentWorkflowTasks int // Workflow tasks running at once
+	ActivityPollers            int // Goroutines polling the task queue for activity tasks
+	WorkflowPollers            int // Goroutines polling the task queue for workflow tasks
+}
+
 type TemporalConfig struct {
 	Address   string
 	Namespace string
@@ -143,13 +200,63 @@ func Load() *Config {
 		BirthdayMaxRetries:    getEnvAsInt("BIRTHDAY_MAX_RETRIES", 3),
 		BirthdayRetryDelay:    getEnvAsInt("BIRTHDAY_RETRY_DELAY", 300),
 		BirthdayWorkerEnabled: getEnvAsBool("BIRTHDAY_WORKER_ENABLED", true),
-		EnableEmailFallback:    getEnvAsBool("ENABLE_EMAIL_FALLBACK", false),
+		EnableEmailFallback:   getEnvAsBool("ENABLE_EMAIL_FALLBACK", false),
 
 		// Temporal settings
 		TemporalAddress:       getEnv("TEMPORAL_ADDRESS", "localhost:7233"),
 		TemporalNamespace:     getEnv("TEMPORAL_NAMESPACE", "default"),
 		TemporalTaskQueue:     getEnv("TEMPORAL_TASK_QUEUE", "authentik-tasks"),
 		TemporalWorkerEnabled: getEnvAsBool("TEMPORAL_WORKER_ENABLED", true),
+		TemporalWorker: TemporalWorkerConfig{
+			MaxConcurrentActivities:    getEnvAsInt("TEMPORAL_MAX_CONCURRENT_ACTIVITIES", 10),
+			MaxConcurrentWorkflowTasks: getEnvAsInt("TEMPORAL_MAX_CONCURRENT_WORKFLOW_TASKS", 5),
+			ActivityPollers:            getEnvAsInt("TEMPORAL_ACTIVITY_POLLERS", 2),
+			WorkflowPollers:            getEnvAsInt("TEMPORAL_WORKFLOW_POLLERS", 2),
+		},
+
+		// Send workflow activity policies
+		ActivityPrepareTimeout:      getEnvAsInt("ACTIVITY_PREPARE_TIMEOUT", 120),
+		ActivityPrepareMaxAttempts:  getEnvAsInt("ACTIVITY_PREPARE_MAX_ATTEMPTS", 3),
+		ActivitySendTimeout:         getEnvAsInt("ACTIVITY_SEND_TIMEOUT", 60),
+		ActivitySendMaxRetryDelay:   getEnvAsInt("ACTIVITY_SEND_MAX_RETRY_DELAY", 1800),
+		ActivityTrackingTimeout:     getEnvAsInt("ACTIVITY_TRACKING_TIMEOUT", 30),
+		ActivityTrackingMaxAttempts: getEnvAsInt("ACTIVITY_TRACKING_MAX_ATTEMPTS", 5),
+
+		// Image assets
+		AssetStorage:      getEnv("ASSET_STORAGE", "local"),
+		AssetLocalDir:     getEnv("ASSET_LOCAL_DIR", "./data/assets"),
+		AssetPublicURL:    getEnv("ASSET_PUBLIC_URL", "http://localhost:5004"),
+		AssetMaxUploadMB:  getEnvAsInt("ASSET_MAX_UPLOAD_MB", 10),
+		AssetProxyEnabled: getEnvAsBool("ASSET_PROXY_ENABLED", true),
+		CardImageEnabled:  getEnvAsBool("CARD_IMAGE_ENABLED", true),
+		AssetS3Endpoint:   getEnv("ASSET_S3_ENDPOINT", ""),
+		AssetS3Bucket:     getEnv("ASSET_S3_BUCKET", ""),
+		AssetS3Region:     getEnv("ASSET_S3_REGION", "us-east-1"),
+		AssetS3AccessKey:  getEnv("ASSET_S3_ACCESS_KEY", ""),
+		AssetS3SecretKey:  getEnv("ASSET_S3_SECRET_KEY", ""),
+
+		// Localization
+		LocalesDir: getEnv("I18N_LOCALES_DIR", ""),
+
+		// Public pages
+		AppURL:                getEnv("APP_URL", "http://localhost:3502"),
+		InvitationTokenSecret: getEnv("INVITATION_TOKEN_SECRET", ""),
+		UnsubscribeMailto:     getEnv("UNSUBSCRIBE_MAILTO", ""),
+		UnsubscribeTokenKeys:  getEnv("UNSUBSCRIBE_TOKEN_KEYS", ""),
+		UnsubscribeTokenDays:  getEnvAsInt("UNSUBSCRIBE_TOKEN_MAX_AGE_DAYS", 365),
+
+		// Data retention
+		RetentionContentDays:     getEnvAsInt("RETENTION_CONTENT_DAYS", 0),
+		RetentionWebhookDataDays: getEnvAsInt("RETENTION_WEBHOOK_DATA_DAYS", 0),
+		RetentionEventDays:       getEnvAsInt("RETENTION_EVENT_DAYS", 0),
+		RetentionPurgeCron:       getEnv("RETENTION_PURGE_CRON", "0 3 * * *"),
+
+		// Send rate limits
+		ProviderSendsPerMinute: getEnvAsInt("PROVIDER_SENDS_PER_MINUTE", 120),
+		ProviderSendBurst:      getEnvAsInt("PROVIDER_SEND_BURST", 2),
+		TenantSendsPerMinute:   getEnvAsInt("TENANT_SENDS_PER_MINUTE", 60),
+		TenantSendBurst:        getEnvAsInt("TENANT_SEND_BURST", 10),
+		DefaultDailySendLimit:  getEnvAsInt("DEFAULT_DAILY_SEND_LIMIT", 0),
 
 		// Logging
 		LogLevel: getEnv("LOG_LEVEL", "info"),
@@ -206,3 +313,19 @@ func getEnvAsSlice(key string, defaultValue []string) []string {
 	}
 	return defaultValue
 }
+
+// LoadUnsubscribeKeyring parses UNSUBSCRIBE_TOKEN_KEYS into UnsubscribeKeyring. The keys are
+// kept apart from JWT_SECRET, so unsubscribe links can be rotated on their own. When they're
+// not set the keyring stays nil and birthday links keep using stored legacy tokens.
+func (c *Config) LoadUnsubscribeKeyring() error {
+	if strings.TrimSpace(c.UnsubscribeTokenKeys) == "" {
+		c.UnsubscribeKeyring = nil
+		return nil
+	}
+	keyring, err := tokens.ParseKeyring(c.UnsubscribeTokenKeys)
+	if err != nil {
+		return err
+	}
+	c.UnsubscribeKeyring = keyring
+	return nil
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"cardprocessor-go/internal/assets"
+	"cardprocessor-go/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssetHandler handles image uploads and serves self-hosted assets
+type AssetHandler struct {
+	service *assets.Service
+}
+
+// NewAssetHandler creates a new asset handler
+func NewAssetHandler(service *assets.Service) *AssetHandler {
+	return &AssetHandler{service: service}
+}
+
+// UploadAsset stores an uploaded image as email-safe variants
+func (h *AssetHandler) UploadAsset(c *gin.Context) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "An image file is required in the 'file' field",
+		})
+		return
+	}
+	if fileHeader.Size > h.service.MaxBytes() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"success": false,
+			"error":   assets.ErrTooLarge.Error(),
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Failed to read uploaded file",
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.service.MaxBytes()+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Failed to read uploaded file",
+		})
+		return
+	}
+
+	asset, err := h.service.Upload(context.Background(), tenantID, fileHeader.Filename, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, assets.ErrTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+		case errors.Is(err, assets.ErrUnsupportedImage):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{
+				"success": false,
+				"error":   "Unsupported image format. Use JPEG, PNG, GIF or WebP.",
+			})
+		default:
+			fmt.Printf("❌ [500 ERROR] UploadAsset failed\n")
+			fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+			fmt.Printf("   └─ Filename: %s\n", fileHeader.Filename)
+			fmt.Printf("   └─ Size: %d bytes\n", len(data))
+			fmt.Printf("   └─ Error Type: %T\n", err)
+			fmt.Printf("   └─ Error Message: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to store image",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"asset":   asset,
+	})
+}
+
+// ServeAsset serves a stored variant. Keys are content hashes, so responses are immutable.
+func (h *AssetHandler) ServeAsset(c *gin.Context) {
+	key := c.Param("file")
+	if key == "" || strings.ContainsAny(key, `/\`) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	etag := `"` + key + `"`
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	data, contentType, err := h.service.Open(c.Request.Context(), key)
+	if err != nil {
+		if !errors.Is(err, assets.ErrNotFound) {
+			fmt.Printf("❌ [Assets] Failed to read asset %s: %v\n", key, err)
+		}
+		c.Status(http.StatusNotFound)
+		return
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("ETag", etag)
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -3,14 +3,13 @@ package handlers
 import (
 	"bytes"
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"cardprocessor-go/internal/config"
@@ -19,6 +18,7 @@ import (
 	"cardprocessor-go/internal/models"
 	"cardprocessor-go/internal/repository"
 	"cardprocessor-go/internal/temporal"
+	"cardprocessor-go/internal/tokens"
 
 	"github.com/gin-gonic/gin"
 )
@@ -150,6 +150,54 @@ func (h *BirthdayHandler) UpdateBirthdaySettings(c *gin.Context) {
 		}
 	}
 
+	// Validate A/B subject variants if provided
+	if req.SubjectVariants != nil {
+		if _, err := temporal.ParseSubjectVariants(*req.SubjectVariants); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid subject variants: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	// Validate the promotion delay if provided; an empty one goes back to the default
+	var promotionDelay *string
+	if req.PromotionDelay != nil && strings.TrimSpace(*req.PromotionDelay) != "" {
+		delay, err := temporal.ParsePromotionDelay(*req.PromotionDelay)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid promotion delay: " + err.Error(),
+			})
+			return
+		}
+		normalized := delay.String()
+		promotionDelay = &normalized
+	}
+
+	// Validate default language and localized messages if provided
+	defaultLanguage := i18n.DefaultLanguage
+	if req.DefaultLanguage != nil && *req.DefaultLanguage != "" {
+		defaultLanguage = i18n.NormalizeLanguage(*req.DefaultLanguage)
+		if defaultLanguage == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Unsupported default language",
+			})
+			return
+		}
+	}
+	if req.LocalizedMessages != nil {
+		if _, err := temporal.ParseLocalizedMessages(*req.LocalizedMessages); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid localized messages: " + err.Error(),
+			})
+			return
+		}
+	}
+
 	settings := &models.BirthdaySettings{
 		TenantID:              tenantID,
 		Enabled:               *req.Enabled,
@@ -160,6 +208,13 @@ func (h *BirthdayHandler) UpdateBirthdaySettings(c *gin.Context) {
 		SenderName:            getStringValue(req.SenderName),
 		PromotionID:           req.PromotionID,
 		SplitPromotionalEmail: getBoolValue(req.SplitPromotionalEmail),
+		PromotionDelay:        promotionDelay,
+		PersonalizedCardImage: getBoolValue(req.PersonalizedCardImage),
+		SubjectTemplate:       req.SubjectTemplate,
+		PreheaderText:         req.PreheaderText,
+		SubjectVariants:       req.SubjectVariants,
+		DefaultLanguage:       defaultLanguage,
+		LocalizedMessages:     req.LocalizedMessages,
 		UpdatedAt:             time.Now(),
 	}
 
@@ -292,7 +347,20 @@ func (h *BirthdayHandler) UpdateContactBirthday(c *gin.Context) {
 		}
 	}
 
-	_, err = h.repo.UpdateContactBirthday(c.Request.Context(), tenantID, contactID, req.Birthday, req.BirthdayEmailEnabled)
+	// Validate and normalize preferred language if provided (empty clears it)
+	if req.PreferredLanguage != nil && *req.PreferredLanguage != "" {
+		lang := i18n.NormalizeLanguage(*req.PreferredLanguage)
+		if lang == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Unsupported preferred language",
+			})
+			return
+		}
+		req.PreferredLanguage = &lang
+	}
+
+	_, err = h.repo.UpdateContactBirthday(c.Request.Context(), tenantID, contactID, req.Birthday, req.BirthdayEmailEnabled, req.PreferredLanguage, consentEvidence(c, subscriptionSourceAPI, nil))
 	if err != nil {
 		fmt.Printf("❌ [500 ERROR] UpdateContactBirthday failed\n")
 		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
@@ -301,6 +369,7 @@ func (h *BirthdayHandler) UpdateContactBirthday(c *gin.Context) {
 		fmt.Printf("   └─ Error Message: %v\n", err)
 		fmt.Printf("   └─ Birthday: %v\n", req.Birthday)
 		fmt.Printf("   └─ Email Enabled: %v\n", req.BirthdayEmailEnabled)
+		fmt.Printf("   └─ Preferred Language: %v\n", req.PreferredLanguage)
 		fmt.Printf("   └─ Request Path: %s %s\n", c.Request.Method, c.Request.URL.Path)
 		fmt.Printf("   └─ Client IP: %s\n", c.ClientIP())
 		fmt.Printf("   └─ Stack Trace: %+v\n", err)
@@ -346,7 +415,7 @@ func (h *BirthdayHandler) UpdateBulkBirthdayEmailPreference(c *gin.Context) {
 		return
 	}
 
-	err = h.repo.UpdateBulkBirthdayEmailPreference(c.Request.Context(), tenantID, req.ContactIDs, req.BirthdayEmailEnabled)
+	err = h.repo.UpdateBulkBirthdayEmailPreference(c.Request.Context(), tenantID, req.ContactIDs, req.BirthdayEmailEnabled, consentEvidence(c, subscriptionSourceBulkUpdate, nil))
 	if err != nil {
 		fmt.Printf("❌ [500 ERROR] UpdateBulkBirthdayEmailPreference failed\n")
 		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
@@ -372,7 +441,8 @@ func (h *BirthdayHandler) UpdateBulkBirthdayEmailPreference(c *gin.Context) {
 	})
 }
 
-// SendBirthdayInvitation sends a birthday invitation to a contact
+// SendBirthdayInvitation emails a contact a single-use link to the page where they can
+// share their birthday, via BirthdayInvitationWorkflow
 func (h *BirthdayHandler) SendBirthdayInvitation(c *gin.Context) {
 	tenantID, err := middleware.GetTenantID(c)
 	if err != nil {
@@ -383,7 +453,7 @@ func (h *BirthdayHandler) SendBirthdayInvitation(c *gin.Context) {
 		return
 	}
 
-	_, err = middleware.GetUserID(c)
+	userID, err := middleware.GetUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
@@ -401,8 +471,9 @@ func (h *BirthdayHandler) SendBirthdayInvitation(c *gin.Context) {
 		return
 	}
 
+	// The body is optional; the dashboard posts without one
 	var req models.SendBirthdayInvitationRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   "Invalid request body",
@@ -410,9 +481,22 @@ func (h *BirthdayHandler) SendBirthdayInvitation(c *gin.Context) {
 		return
 	}
 
+	ctx := c.Request.Context()
+
 	// Get contact information
-	contact, err := h.repo.GetContactByID(c.Request.Context(), tenantID, contactID)
+	contact, err := h.repo.GetContactByID(ctx, tenantID, contactID)
 	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetContactByID failed (Birthday Invitation)\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch contact",
+		})
+		return
+	}
+	if contact == nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"success": false,
 			"error":   "Contact not found",
@@ -420,13 +504,87 @@ func (h *BirthdayHandler) SendBirthdayInvitation(c *gin.Context) {
 		return
 	}
 
-	// TODO: Implement birthday invitation email sending logic
-	// This would integrate with the email service providers
+	switch {
+	case contact.Email == "":
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Contact email is missing"})
+		return
+	case contact.Status == "unsubscribed" || contact.Status == "bounced" || contact.Status == "suppressed":
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Contact is " + contact.Status})
+		return
+	case getStringValue(contact.Birthday) != "":
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Contact already has a birthday set"})
+		return
+	}
+
+	subscribed, err := h.repo.IsContactSubscribed(ctx, tenantID, contactID, models.TopicInvitations)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] IsContactSubscribed failed (Birthday Invitation)\n")
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to check contact subscriptions",
+		})
+		return
+	}
+	if !subscribed {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": "Contact has unsubscribed from invitations"})
+		return
+	}
+
+	if h.temporalClient == nil || !h.temporalClient.IsConnected() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   "Temporal workflow service is unavailable. Please try again later.",
+		})
+		return
+	}
+
+	tenantName := "Your Company"
+	if company, err := h.repo.GetCompany(ctx, tenantID); err != nil {
+		fmt.Printf("⚠️ [Birthday Invitation] Failed to fetch company: %v\n", err)
+	} else if company != nil {
+		tenantName = company.Name
+	}
+
+	settings, err := h.repo.GetBirthdaySettings(ctx, tenantID)
+	if err != nil {
+		fmt.Printf("⚠️ [Birthday Invitation] Failed to fetch birthday settings: %v\n", err)
+	}
+
+	workflowInput := temporal.BirthdayInvitationWorkflowInput{
+		ContactID:        contact.ID,
+		ContactEmail:     contact.Email,
+		ContactFirstName: getStringValue(contact.FirstName),
+		ContactLastName:  getStringValue(contact.LastName),
+		TenantID:         tenantID,
+		TenantName:       tenantName,
+		UserID:           userID,
+		FromEmail:        h.config.DefaultFromEmail,
+		BaseURL:          strings.TrimRight(h.config.AppURL, "/"),
+		Language:         cardLanguage(req.Language, contact, settings),
+	}
+
+	workflowRun, err := h.temporalClient.StartBirthdayInvitationWorkflow(context.Background(), workflowInput)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] StartBirthdayInvitationWorkflow failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to start birthday invitation workflow: " + err.Error(),
+		})
+		return
+	}
+
+	fmt.Printf("✅ [Birthday Invitation] Workflow started for contact %s: %s\n", contact.ID, workflowRun.GetID())
 
-	// For now, return success
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Birthday invitation sent successfully",
+		"success":       true,
+		"message":       "Birthday invitation sent successfully",
+		"workflowId":    workflowRun.GetID(),
+		"workflowRunId": workflowRun.GetRunID(),
 		"contact": gin.H{
 			"id":        contact.ID,
 			"email":     contact.Email,
@@ -436,6 +594,38 @@ func (h *BirthdayHandler) SendBirthdayInvitation(c *gin.Context) {
 	})
 }
 
+// GetBirthdayInvitationStatus returns the contact's most recent birthday invitation, or null
+// when none was sent
+func (h *BirthdayHandler) GetBirthdayInvitationStatus(c *gin.Context) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return
+	}
+
+	contactID := c.Param("contactId")
+	invitation, err := h.repo.GetLatestBirthdayInvitation(c.Request.Context(), tenantID, contactID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetLatestBirthdayInvitation failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch birthday invitation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"invitation": invitation,
+	})
+}
+
 // SendTestBirthdayCard sends a test birthday card to a user
 func (h *BirthdayHandler) SendTestBirthdayCard(c *gin.Context) {
 	fmt.Printf("🎂 [Birthday Test] Request received from IP: %s\n", c.ClientIP())
@@ -543,6 +733,10 @@ func (h *BirthdayHandler) SendTestBirthdayCard(c *gin.Context) {
 	// Priority: request fields > database settings
 	var promotionID string
 	var splitPromotionalEmail bool
+	var personalizedCardImage bool
+	var subjectTemplate, preheaderText string
+	var subjectVariants []temporal.SubjectVariant
+	var promotionDelay string
 
 	// First, try to get from request
 	if req.PromotionID != nil && *req.PromotionID != "" {
@@ -555,6 +749,27 @@ func (h *BirthdayHandler) SendTestBirthdayCard(c *gin.Context) {
 		fmt.Printf("📧 [Birthday Test] Using split promotional email from request: %v\n", splitPromotionalEmail)
 	}
 
+	if req.PersonalizedCardImage != nil {
+		personalizedCardImage = *req.PersonalizedCardImage
+	}
+
+	if req.SubjectTemplate != nil {
+		subjectTemplate = *req.SubjectTemplate
+	}
+	if req.PreheaderText != nil {
+		preheaderText = *req.PreheaderText
+	}
+	if req.PromotionDelay != nil {
+		if _, err := temporal.ParsePromotionDelay(*req.PromotionDelay); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid promotion delay: " + err.Error(),
+			})
+			return
+		}
+		promotionDelay = *req.PromotionDelay
+	}
+
 	// Fetch birthday settings as fallback
 	birthdaySettings, err := h.repo.GetBirthdaySettings(context.Background(), tenantID)
 	if err != nil {
@@ -570,8 +785,44 @@ func (h *BirthdayHandler) SendTestBirthdayCard(c *gin.Context) {
 			splitPromotionalEmail = birthdaySettings.SplitPromotionalEmail
 			fmt.Printf("📧 [Birthday Test] Using split promotional email from settings: %v\n", splitPromotionalEmail)
 		}
+
+		if req.PersonalizedCardImage == nil {
+			personalizedCardImage = birthdaySettings.PersonalizedCardImage
+		}
+
+		if req.SubjectTemplate == nil && birthdaySettings.SubjectTemplate != nil {
+			subjectTemplate = *birthdaySettings.SubjectTemplate
+		}
+		if req.PreheaderText == nil && birthdaySettings.PreheaderText != nil {
+			preheaderText = *birthdaySettings.PreheaderText
+		}
+		if req.PromotionDelay == nil && birthdaySettings.PromotionDelay != nil {
+			promotionDelay = *birthdaySettings.PromotionDelay
+		}
+
+		// A subject given in the request is tested as-is; otherwise the stored variants are used
+		if req.SubjectTemplate == nil && birthdaySettings.SubjectVariants != nil {
+			subjectVariants, err = temporal.ParseSubjectVariants(*birthdaySettings.SubjectVariants)
+			if err != nil {
+				fmt.Printf("⚠️ [Birthday Test] Ignoring invalid subject variants: %v\n", err)
+				subjectVariants = nil
+			}
+		}
+	}
+
+	// A "next day at" promotion delay is resolved in the tenant's schedule timezone
+	timezone := ""
+	if schedule, err := h.repo.GetBirthdaySchedule(context.Background(), tenantID); err != nil {
+		fmt.Printf("⚠️ [Birthday Test] Failed to fetch birthday schedule: %v\n", err)
+	} else if schedule != nil {
+		timezone = schedule.Timezone
 	}
 
+	// Test cards go to the requesting user, so there is no contact preference to apply
+	language := cardLanguage(req.Language, nil, birthdaySettings)
+	customMessage := localizedCustomMessage(birthdaySettings, language, req.CustomMessage)
+	fmt.Printf("🌐 [Birthday Test] Using card language: %s\n", language)
+
 	// If temporal client is available, use workflow; otherwise, send directly
 	if h.temporalClient != nil && h.temporalClient.IsConnected() {
 		fmt.Printf("🎂 [Birthday Test] Using Temporal workflow\n")
@@ -586,12 +837,19 @@ func (h *BirthdayHandler) SendTestBirthdayCard(c *gin.Context) {
 			TenantName:            tenantName,
 			FromEmail:             h.config.DefaultFromEmail,
 			EmailTemplate:         req.EmailTemplate,
-			CustomMessage:         req.CustomMessage,
+			CustomMessage:         customMessage,
 			CustomThemeData:       customThemeData,
 			SenderName:            req.SenderName,
 			PromotionID:           promotionID,
 			SplitPromotionalEmail: splitPromotionalEmail,
+			PersonalizedCardImage: personalizedCardImage,
+			SubjectTemplate:       subjectTemplate,
+			PreheaderText:         preheaderText,
+			SubjectVariants:       subjectVariants,
+			Language:              language,
 			IsTest:                true,
+			PromotionDelay:        promotionDelay,
+			Timezone:              timezone,
 		}
 
 		fmt.Printf("🎂 [Birthday Test] Workflow input prepared: %+v\n", workflowInput)
@@ -682,6 +940,181 @@ func (h *BirthdayHandler) SendTestBirthdayCard(c *gin.Context) {
 	})
 }
 
+// PreviewBirthdayCard renders a birthday card through the email compatibility pass without sending it
+func (h *BirthdayHandler) PreviewBirthdayCard(c *gin.Context) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return
+	}
+
+	var req models.BirthdayPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	ctx := context.Background()
+
+	// Fall back to stored settings for anything the editor did not send
+	settings, err := h.repo.GetBirthdaySettings(ctx, tenantID)
+	if err != nil {
+		fmt.Printf("⚠️ [Birthday Preview] Failed to fetch birthday settings: %v\n", err)
+	} else if settings != nil {
+		if req.EmailTemplate == "" {
+			req.EmailTemplate = settings.EmailTemplate
+		}
+		if req.CustomMessage == "" {
+			req.CustomMessage = settings.CustomMessage
+		}
+		if req.CustomThemeData == nil && settings.CustomThemeData != nil {
+			req.CustomThemeData = *settings.CustomThemeData
+		}
+		if req.SenderName == "" {
+			req.SenderName = settings.SenderName
+		}
+		if req.PromotionID == nil {
+			req.PromotionID = settings.PromotionID
+		}
+		if req.SubjectTemplate == nil {
+			req.SubjectTemplate = settings.SubjectTemplate
+		}
+		if req.PreheaderText == nil {
+			req.PreheaderText = settings.PreheaderText
+		}
+	}
+
+	brandName := "Your Company"
+	if company, err := h.repo.GetCompany(ctx, tenantID); err != nil {
+		fmt.Printf("⚠️ [Birthday Preview] Failed to fetch company: %v\n", err)
+	} else if company != nil {
+		brandName = company.Name
+	}
+
+	recipientName := strings.TrimSpace(req.RecipientFirstName + " " + req.RecipientLastName)
+	if recipientName == "" {
+		recipientName = "Jane Doe"
+	}
+
+	language := cardLanguage(req.Language, nil, settings)
+
+	params := temporal.TemplateParams{
+		RecipientName:    recipientName,
+		Message:          localizedCustomMessage(settings, language, req.CustomMessage),
+		BrandName:        brandName,
+		CustomThemeData:  temporal.ParseCustomThemeData(req.CustomThemeData),
+		SenderName:       req.SenderName,
+		UnsubscribeToken: "preview",
+		Preheader:        getStringValue(req.PreheaderText),
+		Language:         language,
+		IsTest:           true,
+	}
+
+	if req.PromotionID != nil && *req.PromotionID != "" {
+		promotion, err := h.repo.GetPromotion(ctx, *req.PromotionID, tenantID)
+		if err != nil {
+			fmt.Printf("⚠️ [Birthday Preview] Failed to fetch promotion %s: %v\n", *req.PromotionID, err)
+		} else if promotion != nil {
+			params.PromotionContent = promotion.Content
+			params.PromotionTitle = promotion.Title
+			if promotion.Description != nil {
+				params.PromotionDescription = *promotion.Description
+			}
+		}
+	}
+
+	rendered := temporal.RenderBirthdayEmail(temporal.ParseTemplateId(req.EmailTemplate), params)
+
+	subject := fmt.Sprintf(i18n.GetCardText(language).BirthdaySubject, strings.Fields(recipientName)[0])
+	if custom := temporal.RenderSubject(getStringValue(req.SubjectTemplate), params); custom != "" {
+		subject = custom
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"subject":   subject,
+		"preheader": temporal.RenderSubject(params.Preheader, params),
+		"language":  language,
+		"html":      rendered.HTML,
+		"issues":    rendered.Issues,
+	})
+}
+
+// GetSubjectVariantStats reports open and click rates per A/B subject variant
+func (h *BirthdayHandler) GetSubjectVariantStats(c *gin.Context) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return
+	}
+
+	days := 30
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "days must be between 1 and 365",
+			})
+			return
+		}
+		days = parsed
+	}
+
+	emailTypes := []string{"birthday_card"}
+	if c.Query("includeTests") == "true" {
+		emailTypes = append(emailTypes, "test_card")
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	stats, err := h.repo.GetSubjectVariantStats(c.Request.Context(), tenantID, emailTypes, since)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetSubjectVariantStats failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Error Type: %T\n", err)
+		fmt.Printf("   └─ Error Message: %v\n", err)
+		fmt.Printf("   └─ Request Path: %s %s\n", c.Request.Method, c.Request.URL.Path)
+		fmt.Printf("   └─ Client IP: %s\n", c.ClientIP())
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get subject variant stats",
+		})
+		return
+	}
+
+	// Label results with the currently configured subjects and weights
+	settings, err := h.repo.GetBirthdaySettings(c.Request.Context(), tenantID)
+	if err != nil {
+		fmt.Printf("⚠️ [Subject Stats] Failed to fetch birthday settings: %v\n", err)
+	} else if settings != nil && settings.SubjectVariants != nil {
+		variants, _ := temporal.ParseSubjectVariants(*settings.SubjectVariants)
+		for i := range stats {
+			for _, variant := range variants {
+				if variant.ID == stats[i].Variant {
+					stats[i].Subject = variant.Subject
+					stats[i].Weight = variant.Weight
+				}
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"days":     days,
+		"variants": stats,
+	})
+}
+
 // GenerateBirthdayUnsubscribeToken generates an unsubscribe token for a contact
 func (h *BirthdayHandler) GenerateBirthdayUnsubscribeToken(c *gin.Context) {
 	tenantID, err := middleware.GetTenantID(c)
@@ -729,39 +1162,31 @@ func (h *BirthdayHandler) GenerateBirthdayUnsubscribeToken(c *gin.Context) {
 		return
 	}
 
-	// Generate secure random token
-	tokenBytes := make([]byte, 32)
-	_, err = rand.Read(tokenBytes)
-	if err != nil {
-		fmt.Printf("❌ [500 ERROR] Failed to generate random token\n")
-		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
-		fmt.Printf("   └─ Contact ID: %s\n", contactID)
-		fmt.Printf("   └─ Error Type: %T\n", err)
-		fmt.Printf("   └─ Error Message: %v\n", err)
-		fmt.Printf("   └─ Request Path: %s %s\n", c.Request.Method, c.Request.URL.Path)
-		fmt.Printf("   └─ Client IP: %s\n", c.ClientIP())
-		fmt.Printf("   └─ Stack Trace: %+v\n", err)
-
-		c.JSON(http.StatusInternalServerError, gin.H{
+	scope := c.DefaultQuery("scope", tokens.ScopeBirthday)
+	if !tokens.ValidScope(scope) {
+		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
-			"error":   "Failed to generate token",
+			"error":   "Scope must be birthday, promotions or all",
 		})
 		return
 	}
-	token := hex.EncodeToString(tokenBytes)
 
-	// Create unsubscribe token in database
-	unsubToken, err := h.repo.CreateBirthdayUnsubscribeToken(c.Request.Context(), tenantID, contactID, token)
+	// Sign the token; it is verified on use without being stored. Until UNSUBSCRIBE_TOKEN_KEYS
+	// is set, birthday tokens are stored random tokens as before.
+	var token string
+	if h.config.UnsubscribeKeyring == nil && scope == tokens.ScopeBirthday {
+		token, err = newLegacyUnsubscribeToken(c.Request.Context(), h.repo, tenantID, contactID)
+	} else {
+		token, err = newUnsubscribeToken(h.config, tenantID, contactID, scope)
+	}
 	if err != nil {
-		fmt.Printf("❌ [500 ERROR] CreateBirthdayUnsubscribeToken failed\n")
+		fmt.Printf("❌ [500 ERROR] Failed to sign unsubscribe token\n")
 		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
 		fmt.Printf("   └─ Contact ID: %s\n", contactID)
-		fmt.Printf("   └─ Token Length: %d\n", len(token))
 		fmt.Printf("   └─ Error Type: %T\n", err)
 		fmt.Printf("   └─ Error Message: %v\n", err)
 		fmt.Printf("   └─ Request Path: %s %s\n", c.Request.Method, c.Request.URL.Path)
 		fmt.Printf("   └─ Client IP: %s\n", c.ClientIP())
-		fmt.Printf("   └─ Stack Trace: %+v\n", err)
 
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -772,7 +1197,8 @@ func (h *BirthdayHandler) GenerateBirthdayUnsubscribeToken(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"token":   unsubToken.Token,
+		"token":   token,
+		"scope":   scope,
 		"contact": gin.H{
 			"id":    contact.ID,
 			"email": contact.Email,
@@ -783,12 +1209,8 @@ func (h *BirthdayHandler) GenerateBirthdayUnsubscribeToken(c *gin.Context) {
 // ShowBirthdayUnsubscribePage shows the unsubscribe page
 func (h *BirthdayHandler) ShowBirthdayUnsubscribePage(c *gin.Context) {
 	token := c.Query("token")
-	// Detect language from query param or Accept-Language header
-	lang := c.Query("lang")
-	if lang == "" {
-		acceptLang := c.GetHeader("Accept-Language")
-		lang = i18n.DetectLanguage(acceptLang)
-	}
+	// Detect language from ?lang=, the language cookie or Accept-Language
+	lang := pageLanguage(c)
 	t := i18n.GetTranslations(lang)
 	_ = t // ensure variable is used even if specific branches do not reference it
 
@@ -803,10 +1225,14 @@ func (h *BirthdayHandler) ShowBirthdayUnsubscribePage(c *gin.Context) {
 		return
 	}
 
-	// Get unsubscribe token from database
-	unsubToken, err := h.repo.GetBirthdayUnsubscribeToken(c.Request.Context(), token)
+	// Resolve the signed or legacy unsubscribe token
+	unsubToken, err := resolveUnsubscribeToken(c.Request.Context(), h.repo, h.config, token)
+	if errors.Is(err, tokens.ErrExpired) {
+		renderPreferenceError(c, http.StatusGone, lang, t.TokenExpiredError)
+		return
+	}
 	if err != nil {
-		fmt.Printf("❌ [500 ERROR] GetBirthdayUnsubscribeToken failed (ShowBirthdayUnsubscribePage)\n")
+		fmt.Printf("❌ [500 ERROR] Resolving unsubscribe token failed (ShowBirthdayUnsubscribePage)\n")
 		fmt.Printf("   └─ Token: %s\n", token)
 		fmt.Printf("   └─ Error Type: %T\n", err)
 		fmt.Printf("   └─ Error Message: %v\n", err)
@@ -832,6 +1258,12 @@ func (h *BirthdayHandler) ShowBirthdayUnsubscribePage(c *gin.Context) {
 		return
 	}
 
+	// Links in test sends aren't tied to a contact, so there is nobody to unsubscribe
+	if unsubToken.ContactID == "" {
+		renderPreferenceError(c, http.StatusOK, lang, t.TestSendUnsubscribeNotice)
+		return
+	}
+
 	// Note: Removed automatic success display when token is already used
 	// Users should see the unsubscribe form and be able to resubscribe if needed
 
@@ -859,31 +1291,20 @@ func (h *BirthdayHandler) ShowBirthdayUnsubscribePage(c *gin.Context) {
 		return
 	}
 
+	// The topic checkboxes are optional; the page still works without them
+	subscriptions, err := h.repo.GetContactSubscriptions(c.Request.Context(), unsubToken.TenantID, unsubToken.ContactID)
+	if err != nil {
+		fmt.Printf("⚠️ [Unsubscribe] Failed to load subscriptions for contact %s: %v\n", unsubToken.ContactID, err)
+	}
+
 	// Show unsubscribe form
-	c.HTML(http.StatusOK, "unsubscribe.html", gin.H{
-		"Token":        token,
-		"Email":        contact.Email,
-		"FirstName":    getStringValue(contact.FirstName),
-		"LastName":     getStringValue(contact.LastName),
-		"Lang":         lang,
-		"Translations": t,
-		"TemplateText": i18n.GetTemplateText(lang),
-		"Contact":      contact,
-		"TokenUsed":    unsubToken.Used, // Pass token status to template
-	})
+	c.HTML(http.StatusOK, "unsubscribe.html", unsubscribePageData(contact, subscriptions, unsubToken, lang))
 }
 
 // ProcessBirthdayUnsubscribe processes the unsubscribe request
 func (h *BirthdayHandler) ProcessBirthdayUnsubscribe(c *gin.Context) {
-	// Detect language
-	lang := c.Query("lang")
-	if lang == "" {
-		lang = c.PostForm("lang")
-	}
-	if lang == "" {
-		acceptLang := c.GetHeader("Accept-Language")
-		lang = i18n.DetectLanguage(acceptLang)
-	}
+	// Detect language from ?lang=, the language cookie or Accept-Language
+	lang := pageLanguage(c)
 	t := i18n.GetTranslations(lang)
 
 	var req models.BirthdayUnsubscribeRequest
@@ -909,10 +1330,14 @@ func (h *BirthdayHandler) ProcessBirthdayUnsubscribe(c *gin.Context) {
 		return
 	}
 
-	// Get unsubscribe token from database
-	unsubToken, err := h.repo.GetBirthdayUnsubscribeToken(c.Request.Context(), req.Token)
+	// Resolve the signed or legacy unsubscribe token
+	unsubToken, err := resolveUnsubscribeToken(c.Request.Context(), h.repo, h.config, req.Token)
+	if errors.Is(err, tokens.ErrExpired) {
+		renderPreferenceError(c, http.StatusGone, lang, t.TokenExpiredError)
+		return
+	}
 	if err != nil {
-		fmt.Printf("❌ [500 ERROR] GetBirthdayUnsubscribeToken failed (ProcessBirthdayUnsubscribe)\n")
+		fmt.Printf("❌ [500 ERROR] Resolving unsubscribe token failed (ProcessBirthdayUnsubscribe)\n")
 		fmt.Printf("   └─ Token: %s\n", req.Token)
 		fmt.Printf("   └─ Error Type: %T\n", err)
 		fmt.Printf("   └─ Error Message: %v\n", err)
@@ -942,6 +1367,17 @@ func (h *BirthdayHandler) ProcessBirthdayUnsubscribe(c *gin.Context) {
 		return
 	}
 
+	// Links in test sends aren't tied to a contact, so there is nobody to unsubscribe
+	if unsubToken.ContactID == "" {
+		renderPreferenceError(c, http.StatusOK, lang, t.TestSendUnsubscribeNotice)
+		return
+	}
+
+	if req.Action == unsubscribeActionUpdateTopics {
+		h.saveSubscriptionTopics(c, unsubToken, req, lang)
+		return
+	}
+
 	// If token is already used, treat this as a resubscribe request
 	if unsubToken.Used {
 		// Get contact information for response
@@ -958,7 +1394,7 @@ func (h *BirthdayHandler) ProcessBirthdayUnsubscribe(c *gin.Context) {
 		}
 
 		// Resubscribe the contact
-		err = h.repo.ResubscribeContactToBirthdayEmails(c.Request.Context(), unsubToken.ContactID)
+		err = applyResubscribe(c.Request.Context(), h.repo, unsubToken, consentEvidence(c, subscriptionSourcePage, nil))
 		if err != nil {
 			c.HTML(http.StatusInternalServerError, "unsubscribe_error.html", gin.H{
 				"ErrorTitle":   t.ErrorTitle,
@@ -970,13 +1406,6 @@ func (h *BirthdayHandler) ProcessBirthdayUnsubscribe(c *gin.Context) {
 			return
 		}
 
-		// Reset the token's used status so it can be used for future unsubscribe requests
-		err = h.repo.ResetBirthdayUnsubscribeToken(c.Request.Context(), unsubToken.ID)
-		if err != nil {
-			// Log error but don't fail the request since resubscribe was successful
-			fmt.Printf("Warning: Failed to reset unsubscribe token status: %v\n", err)
-		}
-
 		// Return success response for resubscribe
 		c.HTML(http.StatusOK, "unsubscribe_success.html", gin.H{
 			"Message":        "You have been successfully resubscribed to birthday emails.",
@@ -1015,13 +1444,14 @@ func (h *BirthdayHandler) ProcessBirthdayUnsubscribe(c *gin.Context) {
 		return
 	}
 
-	// Unsubscribe the contact
-	err = h.repo.UnsubscribeContactFromBirthdayEmails(c.Request.Context(), unsubToken.ContactID, req.Reason)
+	// Unsubscribe the contact from everything the token covers
+	err = applyUnsubscribe(c.Request.Context(), h.repo, unsubToken, consentEvidence(c, subscriptionSourcePage, req.Reason))
 	if err != nil {
-		fmt.Printf("❌ [500 ERROR] UnsubscribeContactFromBirthdayEmails failed\n")
+		fmt.Printf("❌ [500 ERROR] Unsubscribe failed\n")
 		fmt.Printf("   └─ Tenant ID: %s\n", unsubToken.TenantID)
 		fmt.Printf("   └─ Contact ID: %s\n", unsubToken.ContactID)
 		fmt.Printf("   └─ Token: %s\n", req.Token)
+		fmt.Printf("   └─ Scope: %s\n", unsubToken.Scope)
 		fmt.Printf("   └─ Reason: %v\n", req.Reason)
 		fmt.Printf("   └─ Error Type: %T\n", err)
 		fmt.Printf("   └─ Error Message: %v\n", err)
@@ -1039,12 +1469,7 @@ func (h *BirthdayHandler) ProcessBirthdayUnsubscribe(c *gin.Context) {
 		return
 	}
 
-	// Mark token as used
-	err = h.repo.MarkBirthdayUnsubscribeTokenUsed(c.Request.Context(), unsubToken.ID)
-	if err != nil {
-		// Log error but don't fail the request since unsubscribe was successful
-		fmt.Printf("Warning: Failed to mark unsubscribe token as used: %v\n", err)
-	}
+	h.cancelPendingPromotions(unsubToken.TenantID, unsubToken.ContactID, scopeTopics(unsubToken.Scope, false))
 
 	// Return success response
 	c.HTML(http.StatusOK, "unsubscribe_success.html", gin.H{
@@ -1069,12 +1494,8 @@ func getStringValue(s *string) string {
 
 // ProcessBirthdayResubscribe processes the resubscribe request
 func (h *BirthdayHandler) ProcessBirthdayResubscribe(c *gin.Context) {
-	// Detect language
-	lang := c.Query("lang")
-	if lang == "" {
-		acceptLang := c.GetHeader("Accept-Language")
-		lang = i18n.DetectLanguage(acceptLang)
-	}
+	// Detect language from ?lang=, the language cookie or Accept-Language
+	lang := pageLanguage(c)
 	t := i18n.GetTranslations(lang)
 
 	token := c.Query("token")
@@ -1089,10 +1510,14 @@ func (h *BirthdayHandler) ProcessBirthdayResubscribe(c *gin.Context) {
 		return
 	}
 
-	// Get unsubscribe token from database
-	unsubToken, err := h.repo.GetBirthdayUnsubscribeToken(c.Request.Context(), token)
+	// Resolve the signed or legacy unsubscribe token
+	unsubToken, err := resolveUnsubscribeToken(c.Request.Context(), h.repo, h.config, token)
+	if errors.Is(err, tokens.ErrExpired) {
+		renderPreferenceError(c, http.StatusGone, lang, t.TokenExpiredError)
+		return
+	}
 	if err != nil {
-		fmt.Printf("❌ [500 ERROR] GetBirthdayUnsubscribeToken failed (ProcessBirthdayResubscribe)\n")
+		fmt.Printf("❌ [500 ERROR] Resolving unsubscribe token failed (ProcessBirthdayResubscribe)\n")
 		fmt.Printf("   └─ Token: %s\n", token)
 		fmt.Printf("   └─ Error Type: %T\n", err)
 		fmt.Printf("   └─ Error Message: %v\n", err)
@@ -1113,6 +1538,12 @@ func (h *BirthdayHandler) ProcessBirthdayResubscribe(c *gin.Context) {
 		return
 	}
 
+	// Links in test sends aren't tied to a contact, so there is nobody to unsubscribe
+	if unsubToken.ContactID == "" {
+		renderPreferenceError(c, http.StatusOK, lang, t.TestSendUnsubscribeNotice)
+		return
+	}
+
 	// Get contact information
 	contact, err := h.repo.GetContactByID(c.Request.Context(), unsubToken.TenantID, unsubToken.ContactID)
 	if err != nil || contact == nil {
@@ -1136,9 +1567,9 @@ func (h *BirthdayHandler) ProcessBirthdayResubscribe(c *gin.Context) {
 	}
 
 	// Resubscribe the contact
-	err = h.repo.ResubscribeContactToBirthdayEmails(c.Request.Context(), unsubToken.ContactID)
+	err = applyResubscribe(c.Request.Context(), h.repo, unsubToken, consentEvidence(c, subscriptionSourceResubscribeLink, nil))
 	if err != nil {
-		fmt.Printf("❌ [500 ERROR] ResubscribeContactToBirthdayEmails failed\n")
+		fmt.Printf("❌ [500 ERROR] Resubscribe failed\n")
 		fmt.Printf("   └─ Tenant ID: %s\n", unsubToken.TenantID)
 		fmt.Printf("   └─ Contact ID: %s\n", unsubToken.ContactID)
 		fmt.Printf("   └─ Token: %s\n", token)
@@ -1154,13 +1585,6 @@ func (h *BirthdayHandler) ProcessBirthdayResubscribe(c *gin.Context) {
 		return
 	}
 
-	// Reset the token's used status so it can be used for future unsubscribe requests
-	err = h.repo.ResetBirthdayUnsubscribeToken(c.Request.Context(), unsubToken.ID)
-	if err != nil {
-		// Log error but don't fail the request since resubscribe was successful
-		fmt.Printf("Warning: Failed to reset unsubscribe token status: %v\n", err)
-	}
-
 	// Return success response
 	c.HTML(http.StatusOK, "unsubscribe_success.html", gin.H{
 		"Message":        "You have been successfully resubscribed to birthday emails.",
@@ -1170,6 +1594,28 @@ func (h *BirthdayHandler) ProcessBirthdayResubscribe(c *gin.Context) {
 	})
 }
 
+// cardLanguage resolves the language a card is rendered in: the requested language, then
+// the contact's preference, then the tenant default, then English
+func cardLanguage(requested *string, contact *models.EmailContact, settings *models.BirthdaySettings) string {
+	candidates := []string{getStringValue(requested)}
+	if contact != nil {
+		candidates = append(candidates, getStringValue(contact.PreferredLanguage))
+	}
+	if settings != nil {
+		candidates = append(candidates, settings.DefaultLanguage)
+	}
+	return i18n.ResolveLanguage(candidates...)
+}
+
+// localizedCustomMessage swaps the tenant's custom message for its variant in language.
+// The message passed in is already in the tenant's default language, so it is kept then.
+func localizedCustomMessage(settings *models.BirthdaySettings, language, message string) string {
+	if settings == nil || settings.LocalizedMessages == nil || language == i18n.ResolveLanguage(settings.DefaultLanguage) {
+		return message
+	}
+	return temporal.LocalizedMessage(*settings.LocalizedMessages, language, message)
+}
+
 // getBoolValue safely gets a bool value from a pointer, defaulting to false if nil
 func getBoolValue(b *bool) bool {
 	if b == nil {
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cardprocessor-go/internal/middleware"
+	"cardprocessor-go/internal/temporal"
+
+	"github.com/gin-gonic/gin"
+)
+
+// birthdayRunsLimit is how many finished runs the run list returns
+const birthdayRunsLimit = 50
+
+// GetBirthdayRuns lists the tenant's most recent finished birthday runs
+func (h *BirthdayHandler) GetBirthdayRuns(c *gin.Context) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return
+	}
+
+	runs, err := h.repo.GetBirthdayRuns(c.Request.Context(), tenantID, birthdayRunsLimit)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetBirthdayRuns failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get birthday runs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"runs":    runs,
+	})
+}
+
+// StartBirthdayRun sends today's birthday cards now instead of waiting for the schedule, using
+// the schedule's timezone and days ahead. Cards already sent for the same birthday are skipped.
+func (h *BirthdayHandler) StartBirthdayRun(c *gin.Context) {
+	schedule, ok := h.birthdayScheduleParams(c)
+	if !ok {
+		return
+	}
+	if !schedule.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Birthday emails are not enabled",
+		})
+		return
+	}
+
+	run, err := h.temporalClient.StartBirthdaySendWorkflow(c.Request.Context(), *schedule)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] StartBirthdaySendWorkflow failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", schedule.TenantID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to start birthday run",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	fmt.Printf("🎂 [Birthday Runs] User %s started birthday run %s (tenant %s)\n", userID, run.GetID(), schedule.TenantID)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":    true,
+		"message":    "Birthday run started",
+		"workflowId": run.GetID(),
+		"runId":      run.GetRunID(),
+	})
+}
+
+// GetBirthdayRun reports a birthday run of this tenant. A finished run is read from its
+// summary row; a run still going is asked for its live progress.
+func (h *BirthdayHandler) GetBirthdayRun(c *gin.Context) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return
+	}
+	workflowID := c.Param("id")
+
+	run, err := h.repo.GetBirthdayRun(c.Request.Context(), tenantID, workflowID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetBirthdayRun failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Workflow ID: %s\n", workflowID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get birthday run",
+		})
+		return
+	}
+	if run != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"run":     run,
+		})
+		return
+	}
+
+	tenantID, workflowID, ok := h.workflowParams(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.temporalClient.DescribeBirthdayRun(c.Request.Context(), tenantID, workflowID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] DescribeBirthdayRun failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Workflow ID: %s\n", workflowID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get birthday run",
+		})
+		return
+	}
+	if progress == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Birthday run not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"run":     progress,
+	})
+}
+
+// PauseBirthdayRun stops a running birthday run from starting more cards. Cards already being
+// sent finish.
+func (h *BirthdayHandler) PauseBirthdayRun(c *gin.Context) {
+	h.signalBirthdayRun(c, true, "Pause requested")
+}
+
+// ResumeBirthdayRun lets a paused birthday run carry on with its remaining cards
+func (h *BirthdayHandler) ResumeBirthdayRun(c *gin.Context) {
+	h.signalBirthdayRun(c, false, "Resume requested")
+}
+
+// signalBirthdayRun pauses or resumes one of the tenant's running birthday runs
+func (h *BirthdayHandler) signalBirthdayRun(c *gin.Context, paused bool, message string) {
+	tenantID, workflowID, ok := h.workflowParams(c)
+	if !ok {
+		return
+	}
+
+	progress, err := h.temporalClient.SignalBirthdayRun(c.Request.Context(), tenantID, workflowID, paused)
+	if errors.Is(err, temporal.ErrWorkflowNotRunning) {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "Birthday run has already finished",
+			"run":     progress,
+		})
+		return
+	}
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] SignalBirthdayRun failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Workflow ID: %s\n", workflowID)
+		fmt.Printf("   └─ Paused: %t\n", paused)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to signal birthday run",
+		})
+		return
+	}
+	if progress == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Birthday run not found",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	fmt.Printf("🎂 [Birthday Runs] User %s set paused=%t on birthday run %s (tenant %s)\n", userID, paused, workflowID, tenantID)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": message,
+		"run":     progress,
+	})
+}
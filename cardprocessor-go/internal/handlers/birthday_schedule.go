@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"cardprocessor-go/internal/middleware"
+	"cardprocessor-go/internal/models"
+	"cardprocessor-go/internal/temporal"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Birthday schedule defaults for tenants without birthday settings
+const (
+	defaultScheduleSendHour = 9
+	defaultScheduleTimezone = "UTC"
+	maxScheduleDaysAhead    = 30
+)
+
+// birthdayScheduleParams reads the tenant and its stored schedule for the schedule endpoints and
+// checks that Temporal is available, writing the error response when not. A tenant without
+// birthday settings gets the default schedule.
+func (h *BirthdayHandler) birthdayScheduleParams(c *gin.Context) (*models.BirthdaySchedule, bool) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return nil, false
+	}
+
+	if h.temporalClient == nil || !h.temporalClient.IsConnected() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   "Temporal workflow service is unavailable. Please try again later.",
+		})
+		return nil, false
+	}
+
+	schedule, err := h.repo.GetBirthdaySchedule(c.Request.Context(), tenantID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetBirthdaySchedule failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get birthday schedule",
+		})
+		return nil, false
+	}
+	if schedule == nil {
+		schedule = &models.BirthdaySchedule{
+			TenantID: tenantID,
+			SendHour: defaultScheduleSendHour,
+			Timezone: defaultScheduleTimezone,
+		}
+	}
+
+	return schedule, true
+}
+
+// applyScheduleRequest copies the fields given in the request onto the schedule, writing a 400
+// response when one is invalid
+func applyScheduleRequest(c *gin.Context, schedule *models.BirthdaySchedule) bool {
+	var req models.BirthdayScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return false
+	}
+
+	if req.SendHour != nil {
+		if *req.SendHour < 0 || *req.SendHour > 23 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "sendHour must be between 0 and 23",
+			})
+			return false
+		}
+		schedule.SendHour = *req.SendHour
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "timezone must be an IANA timezone such as America/New_York",
+			})
+			return false
+		}
+		schedule.Timezone = *req.Timezone
+	}
+	if req.DaysAhead != nil {
+		if *req.DaysAhead < 0 || *req.DaysAhead > maxScheduleDaysAhead {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("daysAhead must be between 0 and %d", maxScheduleDaysAhead),
+			})
+			return false
+		}
+		schedule.DaysAhead = *req.DaysAhead
+	}
+
+	return true
+}
+
+// saveBirthdaySchedule applies the schedule in Temporal and then stores it, so the settings
+// never point at a schedule that doesn't exist, and writes the response
+func (h *BirthdayHandler) saveBirthdaySchedule(c *gin.Context, schedule models.BirthdaySchedule, status int, action string) {
+	scheduleID := temporal.BirthdayScheduleID(schedule.TenantID)
+	schedule.ScheduleID = &scheduleID
+
+	if err := h.temporalClient.SyncBirthdaySchedule(c.Request.Context(), schedule); err != nil {
+		fmt.Printf("❌ [500 ERROR] SyncBirthdaySchedule failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", schedule.TenantID)
+		fmt.Printf("   └─ Error Type: %T\n", err)
+		fmt.Printf("   └─ Error Message: %v\n", err)
+		fmt.Printf("   └─ Request Path: %s %s\n", c.Request.Method, c.Request.URL.Path)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to " + action + " birthday schedule",
+		})
+		return
+	}
+
+	saved, err := h.repo.SaveBirthdaySchedule(c.Request.Context(), schedule)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] SaveBirthdaySchedule failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", schedule.TenantID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save birthday schedule",
+		})
+		return
+	}
+
+	fmt.Printf("🗓️ [Birthday Schedule] Tenant %s: %s schedule %s (%02d:00 %s, %d day(s) ahead, paused: %v)\n",
+		saved.TenantID, action, scheduleID, saved.SendHour, saved.Timezone, saved.DaysAhead, saved.Paused)
+
+	c.JSON(status, gin.H{
+		"success":  true,
+		"schedule": saved,
+	})
+}
+
+// GetBirthdaySchedule returns the tenant's birthday schedule settings and, when it has a
+// schedule, its state in Temporal: whether it is paused, the next send times and recent runs
+func (h *BirthdayHandler) GetBirthdaySchedule(c *gin.Context) {
+	schedule, ok := h.birthdayScheduleParams(c)
+	if !ok {
+		return
+	}
+
+	var info *temporal.BirthdayScheduleInfo
+	if schedule.ScheduleID != nil {
+		var err error
+		info, err = h.temporalClient.DescribeBirthdaySchedule(c.Request.Context(), schedule.TenantID)
+		if err != nil {
+			fmt.Printf("❌ [500 ERROR] DescribeBirthdaySchedule failed\n")
+			fmt.Printf("   └─ Tenant ID: %s\n", schedule.TenantID)
+			fmt.Printf("   └─ Error: %v\n", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to get birthday schedule",
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"schedule": schedule,
+		"temporal": info,
+	})
+}
+
+// CreateBirthdaySchedule starts sending the tenant's birthday cards from a Temporal Schedule at
+// the given hour in its timezone. Fields left out keep their stored value.
+func (h *BirthdayHandler) CreateBirthdaySchedule(c *gin.Context) {
+	schedule, ok := h.birthdayScheduleParams(c)
+	if !ok {
+		return
+	}
+	if schedule.ScheduleID != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "Birthday schedule already exists",
+		})
+		return
+	}
+	if !applyScheduleRequest(c, schedule) {
+		return
+	}
+
+	schedule.Paused = false
+	h.saveBirthdaySchedule(c, *schedule, http.StatusCreated, "create")
+}
+
+// UpdateBirthdaySchedule changes the send hour, timezone or days ahead of the tenant's schedule
+func (h *BirthdayHandler) UpdateBirthdaySchedule(c *gin.Context) {
+	schedule, ok := h.birthdayScheduleParams(c)
+	if !ok {
+		return
+	}
+	if schedule.ScheduleID == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Birthday schedule not found",
+		})
+		return
+	}
+	if !applyScheduleRequest(c, schedule) {
+		return
+	}
+
+	h.saveBirthdaySchedule(c, *schedule, http.StatusOK, "update")
+}
+
+// PauseBirthdaySchedule stops the tenant's schedule sending cards until it is resumed
+func (h *BirthdayHandler) PauseBirthdaySchedule(c *gin.Context) {
+	h.setBirthdaySchedulePaused(c, true)
+}
+
+// ResumeBirthdaySchedule restarts a paused schedule from its next send time; birthdays missed
+// while it was paused are not sent
+func (h *BirthdayHandler) ResumeBirthdaySchedule(c *gin.Context) {
+	h.setBirthdaySchedulePaused(c, false)
+}
+
+func (h *BirthdayHandler) setBirthdaySchedulePaused(c *gin.Context, paused bool) {
+	schedule, ok := h.birthdayScheduleParams(c)
+	if !ok {
+		return
+	}
+	if schedule.ScheduleID == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Birthday schedule not found",
+		})
+		return
+	}
+
+	schedule.Paused = paused
+	action := "resume"
+	if paused {
+		action = "pause"
+	}
+	h.saveBirthdaySchedule(c, *schedule, http.StatusOK, action)
+}
+
+// DeleteBirthdaySchedule removes the tenant's schedule. Its send hour, timezone and days ahead
+// are kept for when a schedule is created again.
+func (h *BirthdayHandler) DeleteBirthdaySchedule(c *gin.Context) {
+	schedule, ok := h.birthdayScheduleParams(c)
+	if !ok {
+		return
+	}
+	if schedule.ScheduleID == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Birthday schedule not found",
+		})
+		return
+	}
+
+	if err := h.temporalClient.DeleteBirthdaySchedule(c.Request.Context(), schedule.TenantID); err != nil {
+		fmt.Printf("❌ [500 ERROR] DeleteBirthdaySchedule failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", schedule.TenantID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to delete birthday schedule",
+		})
+		return
+	}
+
+	schedule.ScheduleID = nil
+	schedule.Paused = false
+	saved, err := h.repo.SaveBirthdaySchedule(c.Request.Context(), *schedule)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] SaveBirthdaySchedule failed (DeleteBirthdaySchedule)\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", schedule.TenantID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to save birthday schedule",
+		})
+		return
+	}
+
+	fmt.Printf("🗓️ [Birthday Schedule] Tenant %s: deleted schedule\n", saved.TenantID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"schedule": saved,
+	})
+}
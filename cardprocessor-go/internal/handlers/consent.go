@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cardprocessor-go/internal/middleware"
+	"cardprocessor-go/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// consentDocumentColumns heads the event table in a consent history document
+var consentDocumentColumns = []string{"Date (UTC)", "Topic", "Action", "Source", "Reason", "Changed by", "Email", "IP address", "User agent", "Consent text"}
+
+// GetContactConsentHistory exports a contact's complete consent history for a GDPR/CASL audit:
+// their current consent state and subscriptions, every consent event oldest first, and the same
+// history laid out as a document for rendering as a PDF. With ?download=true the JSON is sent
+// as a file attachment.
+func (h *BirthdayHandler) GetContactConsentHistory(c *gin.Context) {
+	tenantID, contactID, ok := subscriptionContactParams(c)
+	if !ok {
+		return
+	}
+
+	contact, err := h.repo.GetContactByID(c.Request.Context(), tenantID, contactID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetContactByID failed (GetContactConsentHistory)\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get contact",
+		})
+		return
+	}
+	if contact == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Contact not found",
+		})
+		return
+	}
+
+	events, err := h.repo.GetConsentEvents(c.Request.Context(), tenantID, contactID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetConsentEvents failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get consent history",
+		})
+		return
+	}
+
+	subscriptions, err := h.repo.GetContactSubscriptions(c.Request.Context(), tenantID, contactID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetContactSubscriptions failed (GetContactConsentHistory)\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get contact subscriptions",
+		})
+		return
+	}
+
+	companyName := ""
+	company, err := h.repo.GetCompany(c.Request.Context(), tenantID)
+	if err != nil {
+		fmt.Printf("⚠️ [Consent] Failed to get company for tenant %s: %v\n", tenantID, err)
+	} else if company != nil {
+		companyName = company.Name
+	}
+
+	export := buildConsentHistoryExport(contact, subscriptions, events, companyName, time.Now().UTC())
+
+	if c.Query("download") == "true" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="consent-history-%s.json"`, contactID))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"export":  export,
+	})
+}
+
+// buildConsentHistoryExport assembles the consent history export and its document
+func buildConsentHistoryExport(contact *models.EmailContact, subscriptions []models.ContactSubscription, events []models.ConsentEvent, companyName string, now time.Time) models.ConsentHistoryExport {
+	summary := []models.ConsentDocumentLine{
+		{Label: "Contact", Value: contact.Email},
+		{Label: "Contact ID", Value: contact.ID},
+		{Label: "Name", Value: strings.TrimSpace(getStringValue(contact.FirstName) + " " + getStringValue(contact.LastName))},
+		{Label: "Status", Value: contact.Status},
+	}
+	if companyName != "" {
+		summary = append([]models.ConsentDocumentLine{{Label: "Organization", Value: companyName}}, summary...)
+	}
+
+	// The consent captured when the contact was added predates the event log
+	initial := "No"
+	if contact.ConsentGiven {
+		initial = "Yes"
+	}
+	if contact.ConsentDate != nil {
+		initial += ", " + contact.ConsentDate.UTC().Format(time.RFC3339)
+	}
+	if method := getStringValue(contact.ConsentMethod); method != "" {
+		initial += ", " + method
+	}
+	summary = append(summary, models.ConsentDocumentLine{Label: "Initial consent", Value: initial})
+	if ip := getStringValue(contact.ConsentIPAddress); ip != "" {
+		summary = append(summary, models.ConsentDocumentLine{Label: "Initial consent IP address", Value: ip})
+	}
+	if ua := getStringValue(contact.ConsentUserAgent); ua != "" {
+		summary = append(summary, models.ConsentDocumentLine{Label: "Initial consent user agent", Value: ua})
+	}
+
+	for _, sub := range subscriptions {
+		state := "Unsubscribed"
+		if sub.Subscribed {
+			state = "Subscribed"
+		}
+		summary = append(summary, models.ConsentDocumentLine{Label: "Currently: " + sub.Topic, Value: state})
+	}
+	marketing := "Opted out"
+	if contact.PrefMarketing {
+		marketing = "Opted in"
+	}
+	summary = append(summary,
+		models.ConsentDocumentLine{Label: "Currently: " + models.ConsentTopicMarketing, Value: marketing},
+		models.ConsentDocumentLine{Label: "Consent events", Value: fmt.Sprintf("%d", len(events))},
+		models.ConsentDocumentLine{Label: "Generated", Value: now.Format(time.RFC3339)},
+	)
+
+	rows := make([][]string, 0, len(events))
+	for _, event := range events {
+		changedBy := "Contact"
+		if event.ActorUserID != nil {
+			changedBy = "User " + *event.ActorUserID
+		}
+		rows = append(rows, []string{
+			event.OccurredAt.UTC().Format(time.RFC3339),
+			event.Topic,
+			event.Action,
+			event.Source,
+			getStringValue(event.Reason),
+			changedBy,
+			event.Email,
+			getStringValue(event.IPAddress),
+			getStringValue(event.UserAgent),
+			getStringValue(event.ConsentText),
+		})
+	}
+
+	return models.ConsentHistoryExport{
+		GeneratedAt: now,
+		TenantID:    contact.TenantID,
+		CompanyName: companyName,
+		Contact: models.ConsentHistoryContact{
+			ID:                   contact.ID,
+			Email:                contact.Email,
+			FirstName:            contact.FirstName,
+			LastName:             contact.LastName,
+			Status:               contact.Status,
+			BirthdayEmailEnabled: contact.BirthdayEmailEnabled,
+			PrefMarketing:        contact.PrefMarketing,
+			ConsentGiven:         contact.ConsentGiven,
+			ConsentDate:          contact.ConsentDate,
+			ConsentMethod:        contact.ConsentMethod,
+			ConsentIPAddress:     contact.ConsentIPAddress,
+			ConsentUserAgent:     contact.ConsentUserAgent,
+		},
+		Subscriptions: subscriptions,
+		Events:        events,
+		Document: models.ConsentDocument{
+			Title:   "Consent history for " + contact.Email,
+			Summary: summary,
+			Columns: consentDocumentColumns,
+			Rows:    rows,
+		},
+	}
+}
+
+// consentEvidence collects the evidence for a consent change made by this request: the client's
+// IP address and user agent, and the staff user when the request is authenticated
+func consentEvidence(c *gin.Context, source string, reason *string) models.ConsentEvidence {
+	evidence := models.ConsentEvidence{
+		Source:    source,
+		Reason:    reason,
+		IPAddress: stringPtrOrNil(c.ClientIP()),
+		UserAgent: stringPtrOrNil(c.Request.UserAgent()),
+	}
+	if userID, err := middleware.GetUserID(c); err == nil {
+		evidence.ActorUserID = stringPtrOrNil(userID)
+	}
+	return evidence
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cardprocessor-go/internal/i18n"
+	"cardprocessor-go/internal/models"
+	"cardprocessor-go/internal/repository"
+	"cardprocessor-go/internal/tokens"
+
+	"github.com/gin-gonic/gin"
+)
+
+// earliestBirthdayYear rejects obviously mistyped years on the invitation page
+const earliestBirthdayYear = 1900
+
+// ShowBirthdayInvitationPage shows the page a birthday invitation links to, where the contact
+// enters their birthday. Opening it marks the invitation as opened.
+func (h *BirthdayHandler) ShowBirthdayInvitationPage(c *gin.Context) {
+	lang := pageLanguage(c)
+
+	token := c.Query("token")
+	invitation, ok := h.birthdayInvitation(c, token, lang)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.MarkBirthdayInvitationOpened(c.Request.Context(), invitation.ID); err != nil {
+		fmt.Printf("⚠️ [Birthday Invitation] Failed to mark invitation %s as opened: %v\n", invitation.ID, err)
+	}
+
+	contact, ok := h.invitationContact(c, invitation, lang)
+	if !ok {
+		return
+	}
+
+	c.HTML(http.StatusOK, "birthday_invitation.html", invitationPageData(contact, token, lang, "", ""))
+}
+
+// SubmitBirthdayInvitation saves the birthday the contact entered, opts them in to birthday
+// emails and spends the invitation. The consent checkbox is required.
+func (h *BirthdayHandler) SubmitBirthdayInvitation(c *gin.Context) {
+	lang := pageLanguage(c)
+	t := i18n.GetTranslations(lang)
+	text := i18n.GetTemplateText(lang)
+
+	var req models.BirthdayInvitationSubmission
+	if err := c.ShouldBind(&req); err != nil {
+		renderPreferenceError(c, http.StatusBadRequest, lang, t.InvalidRequestError)
+		return
+	}
+
+	invitation, ok := h.birthdayInvitation(c, req.Token, lang)
+	if !ok {
+		return
+	}
+	contact, ok := h.invitationContact(c, invitation, lang)
+	if !ok {
+		return
+	}
+
+	req.Birthday = strings.TrimSpace(req.Birthday)
+	if !validBirthday(req.Birthday, time.Now()) {
+		c.HTML(http.StatusBadRequest, "birthday_invitation.html", invitationPageData(contact, req.Token, lang, req.Birthday, text.InvalidBirthdayError))
+		return
+	}
+	if !req.Consent {
+		c.HTML(http.StatusBadRequest, "birthday_invitation.html", invitationPageData(contact, req.Token, lang, req.Birthday, text.BirthdayFormConsentRequired))
+		return
+	}
+
+	// The consent wording is stored as shown, since the catalog may change later
+	evidence := consentEvidence(c, subscriptionSourceBirthdayInvitation, nil)
+	evidence.ConsentText = &text.BirthdayFormConsent
+	if err := h.repo.CompleteBirthdayInvitation(c.Request.Context(), invitation, req.Birthday, evidence); err != nil {
+		if errors.Is(err, repository.ErrInvitationUsed) {
+			renderPreferenceError(c, http.StatusGone, lang, text.InvitationUsedError)
+			return
+		}
+		fmt.Printf("❌ [500 ERROR] CompleteBirthdayInvitation failed\n")
+		fmt.Printf("   └─ Invitation ID: %s\n", invitation.ID)
+		fmt.Printf("   └─ Contact ID: %s\n", invitation.ContactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.HTML(http.StatusInternalServerError, "birthday_invitation.html", invitationPageData(contact, req.Token, lang, req.Birthday, text.BirthdayFormSaveError))
+		return
+	}
+
+	activityData, _ := json.Marshal(gin.H{
+		"source":       "birthday_invitation",
+		"invitationId": invitation.ID,
+		"consent":      true,
+		"consentText":  text.BirthdayFormConsent,
+		"language":     lang,
+	})
+	activityDataStr := string(activityData)
+	activity := &models.EmailActivity{
+		TenantID:     invitation.TenantID,
+		ContactID:    invitation.ContactID,
+		ActivityType: "birthday_invitation_completed",
+		ActivityData: &activityDataStr,
+		UserAgent:    stringPtrOrNil(c.Request.UserAgent()),
+		IPAddress:    stringPtrOrNil(c.ClientIP()),
+		OccurredAt:   time.Now(),
+	}
+	if err := h.repo.CreateEmailActivity(activity); err != nil {
+		// The birthday is saved; a missing history entry should not fail the request
+		fmt.Printf("⚠️ [Birthday Invitation] Failed to record invitation activity: %v\n", err)
+	}
+
+	fmt.Printf("✅ [Birthday Invitation] Contact %s shared their birthday\n", invitation.ContactID)
+
+	data := invitationPageData(contact, "", lang, req.Birthday, "")
+	data["Completed"] = true
+	c.HTML(http.StatusOK, "birthday_invitation.html", data)
+}
+
+// birthdayInvitation verifies an invitation token and finds its unused invitation, rendering
+// the error page when it can't
+func (h *BirthdayHandler) birthdayInvitation(c *gin.Context, token, lang string) (*models.BirthdayInvitation, bool) {
+	t := i18n.GetTranslations(lang)
+	text := i18n.GetTemplateText(lang)
+
+	if token == "" {
+		renderPreferenceError(c, http.StatusBadRequest, lang, text.InvitationInvalidError)
+		return nil, false
+	}
+
+	if _, err := tokens.VerifyInvitation(h.config.InvitationTokenSecret, token, time.Now()); err != nil {
+		if errors.Is(err, tokens.ErrExpired) {
+			renderPreferenceError(c, http.StatusGone, lang, text.InvitationExpiredError)
+			return nil, false
+		}
+		renderPreferenceError(c, http.StatusBadRequest, lang, text.InvitationInvalidError)
+		return nil, false
+	}
+
+	invitation, err := h.repo.GetBirthdayInvitationByTokenHash(c.Request.Context(), tokens.Hash(token))
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetBirthdayInvitationByTokenHash failed\n")
+		fmt.Printf("   └─ Error: %v\n", err)
+		fmt.Printf("   └─ Request Path: %s %s\n", c.Request.Method, c.Request.URL.Path)
+		renderPreferenceError(c, http.StatusInternalServerError, lang, t.ProcessingError)
+		return nil, false
+	}
+	if invitation == nil {
+		renderPreferenceError(c, http.StatusNotFound, lang, text.InvitationInvalidError)
+		return nil, false
+	}
+	if invitation.CompletedAt != nil {
+		renderPreferenceError(c, http.StatusGone, lang, text.InvitationUsedError)
+		return nil, false
+	}
+
+	return invitation, true
+}
+
+// invitationContact loads the invitation's contact, rendering the error page when it can't
+func (h *BirthdayHandler) invitationContact(c *gin.Context, invitation *models.BirthdayInvitation, lang string) (*models.EmailContact, bool) {
+	contact, err := h.repo.GetContactByID(c.Request.Context(), invitation.TenantID, invitation.ContactID)
+	if err != nil || contact == nil {
+		if err != nil {
+			fmt.Printf("❌ [500 ERROR] GetContactByID failed (Birthday Invitation)\n")
+			fmt.Printf("   └─ Contact ID: %s\n", invitation.ContactID)
+			fmt.Printf("   └─ Error: %v\n", err)
+		}
+		renderPreferenceError(c, http.StatusNotFound, lang, i18n.GetTranslations(lang).ContactNotFoundError)
+		return nil, false
+	}
+	return contact, true
+}
+
+// validBirthday accepts a YYYY-MM-DD date between earliestBirthdayYear and today
+func validBirthday(value string, now time.Time) bool {
+	birthday, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return false
+	}
+	return birthday.Year() >= earliestBirthdayYear && !birthday.After(now)
+}
+
+// invitationPageData builds the birthday_invitation.html template data
+func invitationPageData(contact *models.EmailContact, token, lang, birthday, errorMessage string) gin.H {
+	return gin.H{
+		"Lang":         lang,
+		"TemplateText": i18n.GetTemplateText(lang),
+		"Token":        token,
+		"Email":        contact.Email,
+		"Birthday":     birthday,
+		"MinBirthday":  fmt.Sprintf("%d-01-01", earliestBirthdayYear),
+		"MaxBirthday":  time.Now().Format("2006-01-02"),
+		"Error":        errorMessage,
+		"Completed":    false,
+	}
+}
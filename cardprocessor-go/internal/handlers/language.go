@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"cardprocessor-go/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// languageParam is the query/form parameter that switches a public page's language
+	languageParam = "lang"
+	// languageCookie remembers an explicit language choice across the public pages
+	languageCookie = "lang"
+	// languageCookieMaxAge keeps the choice for a year
+	languageCookieMaxAge = 365 * 24 * 60 * 60
+)
+
+// pageLanguage resolves the language of the public unsubscribe, resubscribe and preference
+// pages: an explicit ?lang= (or form field) wins and is remembered in a cookie, then the
+// cookie, then Accept-Language negotiation
+func pageLanguage(c *gin.Context) string {
+	// Responses differ per header and cookie, so shared caches must not mix them up
+	c.Header("Vary", "Accept-Language, Cookie")
+
+	requested := c.Query(languageParam)
+	if requested == "" && c.Request.Method == http.MethodPost {
+		requested = c.PostForm(languageParam)
+	}
+	if lang := i18n.NormalizeLanguage(requested); lang != "" {
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(languageCookie, lang, languageCookieMaxAge, "/", "", c.Request.TLS != nil, true)
+		return lang
+	}
+
+	if cookie, err := c.Cookie(languageCookie); err == nil {
+		if lang := i18n.NormalizeLanguage(cookie); lang != "" {
+			return lang
+		}
+	}
+
+	return i18n.DetectLanguage(c.GetHeader("Accept-Language"))
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cardprocessor-go/internal/config"
+	"cardprocessor-go/internal/models"
+	"cardprocessor-go/internal/repository"
+	"cardprocessor-go/internal/tokens"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Unsubscribe methods, recorded as the unsubscribe reason and the subscription change source
+const (
+	unsubscribeMethodOneClick = "one_click"
+	unsubscribeMethodMailto   = "mailto"
+)
+
+// errUnsubscribeTokenNotFound means the token in a one-click or mailto unsubscribe is unknown
+var errUnsubscribeTokenNotFound = errors.New("unsubscribe token not found")
+
+// ProcessOneClickUnsubscribe implements RFC 8058 one-click unsubscribe for the URL in the
+// List-Unsubscribe header. Mailbox providers POST "List-Unsubscribe=One-Click" to it without
+// any user interaction, so it unsubscribes immediately and answers 200 with no body.
+func (h *BirthdayHandler) ProcessOneClickUnsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" || c.PostForm("List-Unsubscribe") != "One-Click" {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	unsubToken, err := unsubscribeByToken(c.Request.Context(), h.repo, h.config, token, unsubscribeMethodOneClick, c.Request.UserAgent(), c.ClientIP())
+	if errors.Is(err, errUnsubscribeTokenNotFound) || errors.Is(err, tokens.ErrExpired) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] One-click unsubscribe failed\n")
+		fmt.Printf("   └─ Error: %v\n", err)
+		fmt.Printf("   └─ Client IP: %s\n", c.ClientIP())
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	if unsubToken != nil {
+		h.cancelPendingPromotions(unsubToken.TenantID, unsubToken.ContactID, scopeTopics(unsubToken.Scope, false))
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// unsubscribeByToken unsubscribes the token's contact from the email in its scope without
+// asking for confirmation. Unlike the unsubscribe page it never toggles back to subscribed, so
+// repeated requests (mailbox providers may retry) leave the contact unsubscribed. It returns
+// the token when the contact was unsubscribed, and nil when there was nothing to change.
+func unsubscribeByToken(ctx context.Context, repo *repository.Repository, cfg *config.Config, token, method, userAgent, ipAddress string) (*models.BirthdayUnsubscribeToken, error) {
+	unsubToken, err := resolveUnsubscribeToken(ctx, repo, cfg, token)
+	if err != nil {
+		return nil, err
+	}
+	if unsubToken == nil {
+		return nil, errUnsubscribeTokenNotFound
+	}
+	// Test sends aren't tied to a contact; accept the request and change nothing
+	if unsubToken.ContactID == "" {
+		return nil, nil
+	}
+
+	subscriptions, err := repo.GetContactSubscriptions(ctx, unsubToken.TenantID, unsubToken.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	if subscriptions == nil {
+		return nil, errUnsubscribeTokenNotFound
+	}
+	if unsubscribedFrom(subscriptions, unsubToken.Scope) {
+		return nil, nil
+	}
+
+	reason := method
+	evidence := models.ConsentEvidence{
+		Source:    method,
+		Reason:    &reason,
+		IPAddress: stringPtrOrNil(ipAddress),
+		UserAgent: stringPtrOrNil(userAgent),
+	}
+	if err := applyUnsubscribe(ctx, repo, unsubToken, evidence); err != nil {
+		return nil, err
+	}
+
+	activityData, _ := json.Marshal(gin.H{
+		"category": unsubToken.Scope,
+		"source":   method,
+	})
+	activityDataStr := string(activityData)
+	activity := &models.EmailActivity{
+		TenantID:     unsubToken.TenantID,
+		ContactID:    unsubToken.ContactID,
+		ActivityType: "unsubscribed",
+		ActivityData: &activityDataStr,
+		UserAgent:    stringPtrOrNil(userAgent),
+		IPAddress:    stringPtrOrNil(ipAddress),
+		OccurredAt:   time.Now(),
+	}
+	if err := repo.CreateEmailActivity(activity); err != nil {
+		fmt.Printf("⚠️ [Unsubscribe] Failed to record %s unsubscribe activity: %v\n", method, err)
+	}
+
+	fmt.Printf("✅ [Unsubscribe] Contact %s unsubscribed from %s emails via %s\n", unsubToken.ContactID, unsubToken.Scope, method)
+	return unsubToken, nil
+}
+
+// unsubscribeTokenFromSubject finds the token in the subject of a mailto: unsubscribe,
+// "unsubscribe <token>", allowing for reply prefixes added by mail clients
+func unsubscribeTokenFromSubject(subject string) string {
+	fields := strings.Fields(subject)
+	for i := 0; i < len(fields)-1; i++ {
+		if strings.EqualFold(fields[i], "unsubscribe") {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
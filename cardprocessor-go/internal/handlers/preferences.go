@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"cardprocessor-go/internal/i18n"
+	"cardprocessor-go/internal/models"
+	"cardprocessor-go/internal/tokens"
+
+	"github.com/gin-gonic/gin"
+)
+
+// preferenceChange is one field changed in the preference center, as recorded in email_activity
+type preferenceChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ShowPreferenceCenter shows the contact's self-service preference page. It accepts the same
+// token as the birthday unsubscribe link and does not consume it.
+func (h *BirthdayHandler) ShowPreferenceCenter(c *gin.Context) {
+	lang := pageLanguage(c)
+
+	token := c.Query("token")
+	if token == "" {
+		renderPreferenceError(c, http.StatusBadRequest, lang, i18n.GetTranslations(lang).TokenMissingError)
+		return
+	}
+
+	contact, ok := h.preferenceContact(c, token, lang)
+	if !ok {
+		return
+	}
+
+	c.HTML(http.StatusOK, "preferences.html", preferencePageData(contact, token, lang, "", ""))
+}
+
+// UpdatePreferenceCenter saves the contact's name, birthday, language and email preferences
+// and records the changes as a "preferences_updated" email activity
+func (h *BirthdayHandler) UpdatePreferenceCenter(c *gin.Context) {
+	lang := pageLanguage(c)
+	t := i18n.GetTranslations(lang)
+	text := i18n.GetTemplateText(lang)
+
+	var req models.ContactPreferencesRequest
+	if err := c.ShouldBind(&req); err != nil {
+		renderPreferenceError(c, http.StatusBadRequest, lang, t.InvalidRequestError)
+		return
+	}
+	if req.Token == "" {
+		renderPreferenceError(c, http.StatusBadRequest, lang, t.TokenRequiredError)
+		return
+	}
+
+	contact, ok := h.preferenceContact(c, req.Token, lang)
+	if !ok {
+		return
+	}
+
+	req.Birthday = strings.TrimSpace(req.Birthday)
+	if req.Birthday != "" {
+		if _, err := time.Parse("2006-01-02", req.Birthday); err != nil {
+			c.HTML(http.StatusBadRequest, "preferences.html", preferencePageData(contact, req.Token, lang, "", text.InvalidBirthdayError))
+			return
+		}
+	}
+	if req.Language != "" {
+		req.Language = i18n.NormalizeLanguage(req.Language)
+		if req.Language == "" {
+			renderPreferenceError(c, http.StatusBadRequest, lang, t.InvalidRequestError)
+			return
+		}
+	}
+
+	changes := preferenceChanges(contact, req)
+	if len(changes) == 0 {
+		c.HTML(http.StatusOK, "preferences.html", preferencePageData(contact, req.Token, lang, text.ProfileUpdatedSuccess, ""))
+		return
+	}
+
+	if err := h.repo.UpdateContactPreferences(c.Request.Context(), contact.TenantID, contact.ID, req, consentEvidence(c, subscriptionSourcePreferenceCenter, nil)); err != nil {
+		fmt.Printf("❌ [500 ERROR] UpdateContactPreferences failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", contact.TenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contact.ID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.HTML(http.StatusInternalServerError, "preferences.html", preferencePageData(contact, req.Token, lang, "", text.ProfileUpdateError))
+		return
+	}
+
+	activityData, _ := json.Marshal(gin.H{
+		"source":  "preference_center",
+		"changes": changes,
+	})
+	activityDataStr := string(activityData)
+	activity := &models.EmailActivity{
+		TenantID:     contact.TenantID,
+		ContactID:    contact.ID,
+		ActivityType: "preferences_updated",
+		ActivityData: &activityDataStr,
+		UserAgent:    stringPtrOrNil(c.Request.UserAgent()),
+		IPAddress:    stringPtrOrNil(c.ClientIP()),
+		OccurredAt:   time.Now(),
+	}
+	if err := h.repo.CreateEmailActivity(activity); err != nil {
+		// The preferences are saved; a missing history entry should not fail the request
+		fmt.Printf("⚠️ [Preferences] Failed to record preference change activity: %v\n", err)
+	}
+
+	fmt.Printf("✅ [Preferences] Contact %s updated %d preference(s)\n", contact.ID, len(changes))
+	if _, changed := changes["promotionalEmails"]; changed {
+		h.cancelPendingPromotions(contact.TenantID, contact.ID, map[string]bool{
+			models.TopicBirthdayPromotion: req.PromotionalEmails,
+		})
+	}
+
+	updated, err := h.repo.GetContactByID(c.Request.Context(), contact.TenantID, contact.ID)
+	if err != nil || updated == nil {
+		fmt.Printf("⚠️ [Preferences] Failed to reload contact after update: %v\n", err)
+		updated = contact
+	}
+
+	// Show the confirmation in the language the contact just chose
+	if _, changed := changes["language"]; changed && req.Language != "" {
+		lang = req.Language
+		text = i18n.GetTemplateText(lang)
+	}
+
+	c.HTML(http.StatusOK, "preferences.html", preferencePageData(updated, req.Token, lang, text.ProfileUpdatedSuccess, ""))
+}
+
+// preferenceContact resolves the token to its contact, rendering the error page when it can't
+func (h *BirthdayHandler) preferenceContact(c *gin.Context, token, lang string) (*models.EmailContact, bool) {
+	t := i18n.GetTranslations(lang)
+
+	unsubToken, err := resolveUnsubscribeToken(c.Request.Context(), h.repo, h.config, token)
+	if errors.Is(err, tokens.ErrExpired) {
+		renderPreferenceError(c, http.StatusGone, lang, t.TokenExpiredError)
+		return nil, false
+	}
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] Resolving unsubscribe token failed (Preferences)\n")
+		fmt.Printf("   └─ Error: %v\n", err)
+		fmt.Printf("   └─ Request Path: %s %s\n", c.Request.Method, c.Request.URL.Path)
+		renderPreferenceError(c, http.StatusInternalServerError, lang, t.ProcessingError)
+		return nil, false
+	}
+	if unsubToken == nil {
+		renderPreferenceError(c, http.StatusNotFound, lang, t.TokenNotFoundError)
+		return nil, false
+	}
+	if unsubToken.ContactID == "" {
+		renderPreferenceError(c, http.StatusOK, lang, t.TestSendUnsubscribeNotice)
+		return nil, false
+	}
+
+	contact, err := h.repo.GetContactByID(c.Request.Context(), unsubToken.TenantID, unsubToken.ContactID)
+	if err != nil || contact == nil {
+		if err != nil {
+			fmt.Printf("❌ [500 ERROR] GetContactByID failed (Preferences)\n")
+			fmt.Printf("   └─ Contact ID: %s\n", unsubToken.ContactID)
+			fmt.Printf("   └─ Error: %v\n", err)
+		}
+		renderPreferenceError(c, http.StatusNotFound, lang, t.ContactNotFoundError)
+		return nil, false
+	}
+
+	return contact, true
+}
+
+// preferenceChanges lists the fields the form changes, keyed by field name
+func preferenceChanges(contact *models.EmailContact, req models.ContactPreferencesRequest) map[string]preferenceChange {
+	changes := make(map[string]preferenceChange)
+	addString := func(field, from, to string) {
+		if from != to {
+			changes[field] = preferenceChange{From: from, To: to}
+		}
+	}
+	addBool := func(field string, from, to bool) {
+		if from != to {
+			changes[field] = preferenceChange{From: from, To: to}
+		}
+	}
+
+	addString("firstName", getStringValue(contact.FirstName), strings.TrimSpace(req.FirstName))
+	addString("lastName", getStringValue(contact.LastName), strings.TrimSpace(req.LastName))
+	addString("birthday", getStringValue(contact.Birthday), req.Birthday)
+	addString("language", getStringValue(contact.PreferredLanguage), req.Language)
+	addBool("birthdayEmails", contact.BirthdayEmailEnabled, req.BirthdayEmails)
+	addBool("promotionalEmails", contact.PrefMarketing, req.PromotionalEmails)
+	return changes
+}
+
+// preferencePageData builds the preferences.html template data
+func preferencePageData(contact *models.EmailContact, token, lang, success, errorMessage string) gin.H {
+	languages := make([]gin.H, 0)
+	for _, code := range i18n.Languages() {
+		languages = append(languages, gin.H{"Code": code, "Name": i18n.LanguageName(code)})
+	}
+
+	return gin.H{
+		"Lang":         lang,
+		"TemplateText": i18n.GetTemplateText(lang),
+		"Token":        token,
+		"Contact":      contact,
+		"FirstName":    getStringValue(contact.FirstName),
+		"LastName":     getStringValue(contact.LastName),
+		"Birthday":     getStringValue(contact.Birthday),
+		"Language":     getStringValue(contact.PreferredLanguage),
+		"Languages":    languages,
+		"Success":      success,
+		"Error":        errorMessage,
+	}
+}
+
+// renderPreferenceError renders the shared error page for the preference center
+func renderPreferenceError(c *gin.Context, status int, lang, message string) {
+	t := i18n.GetTranslations(lang)
+	c.HTML(status, "unsubscribe_error.html", gin.H{
+		"ErrorTitle":   t.ErrorTitle,
+		"ErrorHeading": t.ErrorHeading,
+		"ErrorMessage": message,
+		"Lang":         lang,
+		"TemplateText": i18n.GetTemplateText(lang),
+	})
+}
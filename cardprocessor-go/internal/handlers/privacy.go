@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cardprocessor-go/internal/middleware"
+	"cardprocessor-go/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportContactData answers a GDPR data subject access request with everything held about a
+// contact as JSON. With ?download=true it is sent as a file attachment.
+func (h *BirthdayHandler) ExportContactData(c *gin.Context) {
+	tenantID, contactID, ok := subscriptionContactParams(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.repo.GetContactDataExport(c.Request.Context(), tenantID, contactID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetContactDataExport failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to export contact data",
+		})
+		return
+	}
+	if export == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Contact not found",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	fmt.Printf("📦 [Privacy] User %s exported the data held about contact %s (tenant %s)\n", userID, contactID, tenantID)
+
+	if c.Query("download") == "true" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="contact-data-%s.json"`, contactID))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"export":  export,
+	})
+}
+
+// EraseContact carries out a GDPR erasure request: the contact and their email content are
+// deleted and the records kept for reporting are pseudonymised, and the address is suppressed
+// so it is never mailed again. The request must repeat the contact's email address, since
+// nothing erased can be restored.
+func (h *BirthdayHandler) EraseContact(c *gin.Context) {
+	tenantID, contactID, ok := subscriptionContactParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.EraseContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body: confirmEmail is required",
+		})
+		return
+	}
+
+	contact, err := h.repo.GetContactByID(c.Request.Context(), tenantID, contactID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetContactByID failed (EraseContact)\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get contact",
+		})
+		return
+	}
+	if contact == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Contact not found",
+		})
+		return
+	}
+	if !strings.EqualFold(strings.TrimSpace(req.ConfirmEmail), strings.TrimSpace(contact.Email)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "confirmEmail does not match the contact's email address",
+		})
+		return
+	}
+
+	var requestedBy *string
+	if userID, err := middleware.GetUserID(c); err == nil {
+		requestedBy = stringPtrOrNil(userID)
+	}
+
+	erasure, err := h.repo.EraseContact(c.Request.Context(), tenantID, contactID, req.Reason, requestedBy)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] EraseContact failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error Type: %T\n", err)
+		fmt.Printf("   └─ Error Message: %v\n", err)
+		fmt.Printf("   └─ Request Path: %s %s\n", c.Request.Method, c.Request.URL.Path)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to erase contact",
+		})
+		return
+	}
+	if erasure == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Contact not found",
+		})
+		return
+	}
+
+	fmt.Printf("🗑️ [Privacy] Erased contact %s (tenant %s): %d send(s) pseudonymised, %d content row(s) and %d activity row(s) deleted\n",
+		contactID, tenantID, erasure.EmailSendsPseudonymised, erasure.EmailContentsDeleted, erasure.ActivitiesDeleted)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"erasure": erasure,
+	})
+}
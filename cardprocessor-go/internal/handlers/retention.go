@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cardprocessor-go/internal/middleware"
+	"cardprocessor-go/internal/models"
+	"cardprocessor-go/internal/temporal"
+
+	"github.com/gin-gonic/gin"
+)
+
+// retentionRunsShown is how many recent purge runs the retention settings include
+const retentionRunsShown = 10
+
+// GetRetentionSettings returns the tenant's retention settings, the policy in effect once the
+// configured defaults are filled in, the purge schedule and what the recent purges removed
+func (h *BirthdayHandler) GetRetentionSettings(c *gin.Context) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return
+	}
+
+	settings, err := h.repo.GetRetentionSettings(c.Request.Context(), tenantID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetRetentionSettings failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get retention settings",
+		})
+		return
+	}
+
+	runs, err := h.repo.GetRetentionPurgeRuns(c.Request.Context(), tenantID, retentionRunsShown)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetRetentionPurgeRuns failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get retention purge runs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"settings":      settings,
+		"policy":        models.ResolveRetentionPolicy(tenantID, settings, temporal.RetentionDefaults(h.config)),
+		"purgeSchedule": h.config.RetentionPurgeCron,
+		"recentRuns":    runs,
+	})
+}
+
+// UpdateRetentionSettings replaces the tenant's retention settings. Days left out use the
+// configured default and 0 keeps data forever. They take effect on the next scheduled purge.
+func (h *BirthdayHandler) UpdateRetentionSettings(c *gin.Context) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return
+	}
+
+	var req models.UpdateRetentionSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+
+	if req.ContentAction == "" {
+		req.ContentAction = models.RetentionActionDelete
+	}
+	if req.ContentAction != models.RetentionActionDelete && req.ContentAction != models.RetentionActionTruncate {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "contentAction must be delete or truncate",
+		})
+		return
+	}
+	for name, days := range map[string]*int{"contentDays": req.ContentDays, "webhookDataDays": req.WebhookDataDays, "eventDays": req.EventDays} {
+		if days != nil && *days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   name + " must not be negative",
+			})
+			return
+		}
+	}
+
+	settings, err := h.repo.UpsertRetentionSettings(c.Request.Context(), tenantID, req)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] UpsertRetentionSettings failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Error Type: %T\n", err)
+		fmt.Printf("   └─ Error Message: %v\n", err)
+		fmt.Printf("   └─ Request Path: %s %s\n", c.Request.Method, c.Request.URL.Path)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to update retention settings",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	fmt.Printf("🧹 [Retention] User %s updated the retention settings of tenant %s\n", userID, tenantID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"settings": settings,
+		"policy":   models.ResolveRetentionPolicy(tenantID, settings, temporal.RetentionDefaults(h.config)),
+	})
+}
+
+// GetEmailEventDailyStats returns the daily event counts kept for email events that the
+// retention purge has compacted, covering the last ?days= days (default 90)
+func (h *BirthdayHandler) GetEmailEventDailyStats(c *gin.Context) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return
+	}
+
+	days := 90
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 3650 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "days must be between 1 and 3650",
+			})
+			return
+		}
+		days = parsed
+	}
+
+	stats, err := h.repo.GetEmailEventDailyStats(c.Request.Context(), tenantID, time.Now().UTC().AddDate(0, 0, -days))
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetEmailEventDailyStats failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get email event daily stats",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"stats":   stats,
+	})
+}
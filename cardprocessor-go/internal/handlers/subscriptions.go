@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cardprocessor-go/internal/i18n"
+	"cardprocessor-go/internal/middleware"
+	"cardprocessor-go/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sources recorded with subscription and consent changes, besides the unsubscribe methods
+const (
+	subscriptionSourcePage               = "unsubscribe_page"
+	subscriptionSourceResubscribeLink    = "resubscribe_link"
+	subscriptionSourceAPI                = "api"
+	subscriptionSourceBulkUpdate         = "bulk_update"
+	subscriptionSourcePreferenceCenter   = "preference_center"
+	subscriptionSourceBirthdayInvitation = "birthday_invitation"
+)
+
+// unsubscribeActionUpdateTopics is the unsubscribe form action that saves the topic checkboxes
+const unsubscribeActionUpdateTopics = "update_topics"
+
+// Subscription history page size for the API
+const (
+	defaultSubscriptionHistoryLimit = 50
+	maxSubscriptionHistoryLimit     = 500
+)
+
+// GetContactSubscriptions returns a contact's state for every subscription topic
+func (h *BirthdayHandler) GetContactSubscriptions(c *gin.Context) {
+	tenantID, contactID, ok := subscriptionContactParams(c)
+	if !ok {
+		return
+	}
+
+	subscriptions, err := h.repo.GetContactSubscriptions(c.Request.Context(), tenantID, contactID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetContactSubscriptions failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get contact subscriptions",
+		})
+		return
+	}
+	if subscriptions == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Contact not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"subscriptions": subscriptions,
+	})
+}
+
+// UpdateContactSubscriptions turns subscription topics on or off for a contact on their behalf,
+// e.g. after a phone call. Each change is recorded in the subscription history.
+func (h *BirthdayHandler) UpdateContactSubscriptions(c *gin.Context) {
+	tenantID, contactID, ok := subscriptionContactParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateContactSubscriptionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid request body",
+		})
+		return
+	}
+	for topic := range req.Topics {
+		if !models.ValidSubscriptionTopic(topic) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("Unknown subscription topic %q", topic),
+				"topics":  models.SubscriptionTopics,
+			})
+			return
+		}
+	}
+
+	contact, err := h.repo.GetContactByID(c.Request.Context(), tenantID, contactID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetContactByID failed (UpdateContactSubscriptions)\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get contact",
+		})
+		return
+	}
+	if contact == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Contact not found",
+		})
+		return
+	}
+
+	changed, err := h.repo.SetContactSubscriptions(c.Request.Context(), tenantID, contactID, req.Topics, consentEvidence(c, subscriptionSourceAPI, req.Reason))
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] SetContactSubscriptions failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Topics: %v\n", req.Topics)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to update contact subscriptions",
+		})
+		return
+	}
+
+	subscriptions, err := h.repo.GetContactSubscriptions(c.Request.Context(), tenantID, contactID)
+	if err != nil {
+		fmt.Printf("⚠️ [Subscriptions] Saved, but failed to reload subscriptions for contact %s: %v\n", contactID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"changed":       changed,
+		"subscriptions": subscriptions,
+	})
+}
+
+// GetContactSubscriptionHistory returns a contact's subscription changes, newest first
+func (h *BirthdayHandler) GetContactSubscriptionHistory(c *gin.Context) {
+	tenantID, contactID, ok := subscriptionContactParams(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultSubscriptionHistoryLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > maxSubscriptionHistoryLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   fmt.Sprintf("limit must be between 1 and %d", maxSubscriptionHistoryLimit),
+			})
+			return
+		}
+		limit = n
+	}
+
+	events, err := h.repo.GetContactSubscriptionEvents(c.Request.Context(), tenantID, contactID, limit)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] GetContactSubscriptionEvents failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", contactID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get subscription history",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"events":  events,
+	})
+}
+
+// saveSubscriptionTopics saves the topic checkboxes from the unsubscribe page: checked topics
+// stay subscribed and the rest are unsubscribed
+func (h *BirthdayHandler) saveSubscriptionTopics(c *gin.Context, unsubToken *models.BirthdayUnsubscribeToken, req models.BirthdayUnsubscribeRequest, lang string) {
+	t := i18n.GetTranslations(lang)
+
+	topics := make(map[string]bool)
+	for _, topic := range models.SubscriptionTopics {
+		topics[topic] = false
+	}
+	for _, topic := range req.Topics {
+		if models.ValidSubscriptionTopic(topic) {
+			topics[topic] = true
+		}
+	}
+
+	if _, err := h.repo.SetContactSubscriptions(c.Request.Context(), unsubToken.TenantID, unsubToken.ContactID, topics, consentEvidence(c, subscriptionSourcePage, req.Reason)); err != nil {
+		fmt.Printf("❌ [500 ERROR] SetContactSubscriptions failed (Unsubscribe Page)\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", unsubToken.TenantID)
+		fmt.Printf("   └─ Contact ID: %s\n", unsubToken.ContactID)
+		fmt.Printf("   └─ Topics: %v\n", req.Topics)
+		fmt.Printf("   └─ Error: %v\n", err)
+		renderPreferenceError(c, http.StatusInternalServerError, lang, t.UnsubscribeFailedError)
+		return
+	}
+	h.cancelPendingPromotions(unsubToken.TenantID, unsubToken.ContactID, topics)
+
+	contact, err := h.repo.GetContactByID(c.Request.Context(), unsubToken.TenantID, unsubToken.ContactID)
+	if err != nil || contact == nil {
+		renderPreferenceError(c, http.StatusNotFound, lang, t.ContactNotFoundError)
+		return
+	}
+	subscriptions, err := h.repo.GetContactSubscriptions(c.Request.Context(), unsubToken.TenantID, unsubToken.ContactID)
+	if err != nil {
+		fmt.Printf("⚠️ [Subscriptions] Saved, but failed to reload subscriptions for contact %s: %v\n", unsubToken.ContactID, err)
+	}
+
+	unsubToken.Used = unsubscribedFrom(subscriptions, unsubToken.Scope)
+	data := unsubscribePageData(contact, subscriptions, unsubToken, lang)
+	data["Notice"] = i18n.GetTemplateText(lang).TopicsSaved
+	c.HTML(http.StatusOK, "unsubscribe.html", data)
+}
+
+// unsubscribePageData builds the unsubscribe.html template data
+func unsubscribePageData(contact *models.EmailContact, subscriptions []models.ContactSubscription, unsubToken *models.BirthdayUnsubscribeToken, lang string) gin.H {
+	return gin.H{
+		"Token":         unsubToken.Token,
+		"Email":         contact.Email,
+		"FirstName":     getStringValue(contact.FirstName),
+		"LastName":      getStringValue(contact.LastName),
+		"Lang":          lang,
+		"Translations":  i18n.GetTranslations(lang),
+		"TemplateText":  i18n.GetTemplateText(lang),
+		"Contact":       contact,
+		"TokenUsed":     unsubToken.Used, // Pass token status to template
+		"Subscriptions": subscriptionOptions(subscriptions, lang),
+	}
+}
+
+// subscriptionOptions labels the contact's subscriptions for the unsubscribe page checkboxes
+func subscriptionOptions(subscriptions []models.ContactSubscription, lang string) []gin.H {
+	text := i18n.GetTemplateText(lang)
+	labels := map[string]string{
+		models.TopicBirthdayCard:      text.TopicBirthdayCard,
+		models.TopicBirthdayPromotion: text.TopicBirthdayPromotion,
+		models.TopicAnniversaries:     text.TopicAnniversaries,
+		models.TopicInvitations:       text.TopicInvitations,
+	}
+
+	options := make([]gin.H, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		options = append(options, gin.H{
+			"Topic":      sub.Topic,
+			"Label":      labels[sub.Topic],
+			"Subscribed": sub.Subscribed,
+		})
+	}
+	return options
+}
+
+// subscriptionContactParams reads the tenant and contact ID for the subscription endpoints,
+// answering the request itself when either is missing
+func subscriptionContactParams(c *gin.Context) (string, string, bool) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return "", "", false
+	}
+
+	contactID := c.Param("contactId")
+	if contactID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Contact ID is required",
+		})
+		return "", "", false
+	}
+
+	return tenantID, contactID, true
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"cardprocessor-go/internal/config"
+	"cardprocessor-go/internal/models"
+	"cardprocessor-go/internal/repository"
+	"cardprocessor-go/internal/temporal"
+	"cardprocessor-go/internal/tokens"
+)
+
+// newUnsubscribeToken signs an unsubscribe token with the configured keyring
+func newUnsubscribeToken(cfg *config.Config, tenantID, contactID, scope string) (string, error) {
+	if cfg.UnsubscribeKeyring == nil {
+		return "", errUnsubscribeKeysNotLoaded
+	}
+	return cfg.UnsubscribeKeyring.NewUnsubscribe(tenantID, contactID, scope, time.Now())
+}
+
+// newLegacyUnsubscribeToken issues a random birthday unsubscribe token stored in
+// birthday_unsubscribe_tokens. It's only used while no unsubscribe keys are configured.
+func newLegacyUnsubscribeToken(ctx context.Context, repo *repository.Repository, tenantID, contactID string) (string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to generate unsubscribe token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	if _, err := repo.CreateBirthdayUnsubscribeToken(ctx, tenantID, contactID, token); err != nil {
+		return "", fmt.Errorf("failed to store unsubscribe token: %w", err)
+	}
+	return token, nil
+}
+
+// errUnsubscribeKeysNotLoaded is returned when the unsubscribe keyring wasn't loaded at startup
+var errUnsubscribeKeysNotLoaded = errors.New("unsubscribe token keys are not loaded")
+
+// resolveUnsubscribeToken finds what an unsubscribe link token stands for. Signed tokens are
+// verified without a database lookup; they have no ID and Used reports whether the contact is
+// already unsubscribed from the token's scope. Legacy random tokens are still looked up in
+// birthday_unsubscribe_tokens while mail carrying them is around. It returns nil for unknown
+// or forged tokens and tokens.ErrExpired for signed tokens past UNSUBSCRIBE_TOKEN_MAX_AGE_DAYS.
+func resolveUnsubscribeToken(ctx context.Context, repo *repository.Repository, cfg *config.Config, token string) (*models.BirthdayUnsubscribeToken, error) {
+	if !tokens.IsUnsubscribe(token) {
+		unsubToken, err := repo.GetBirthdayUnsubscribeToken(ctx, token)
+		if unsubToken != nil {
+			unsubToken.Scope = tokens.ScopeBirthday
+		}
+		return unsubToken, err
+	}
+
+	if cfg.UnsubscribeKeyring == nil {
+		return nil, errUnsubscribeKeysNotLoaded
+	}
+	claims, err := cfg.UnsubscribeKeyring.VerifyUnsubscribe(token, time.Duration(cfg.UnsubscribeTokenDays)*24*time.Hour, time.Now())
+	if errors.Is(err, tokens.ErrExpired) {
+		return nil, err
+	}
+	if err != nil {
+		fmt.Printf("⚠️ [Unsubscribe] Rejected signed unsubscribe token: %v\n", err)
+		return nil, nil
+	}
+
+	unsubToken := &models.BirthdayUnsubscribeToken{
+		TenantID:  claims.TenantID,
+		ContactID: claims.ContactID,
+		Token:     token,
+		Scope:     claims.Scope,
+		CreatedAt: claims.IssuedAt,
+	}
+
+	// Test sends have no contact, so there is nothing more to look up
+	if claims.ContactID == "" {
+		return unsubToken, nil
+	}
+
+	subscriptions, err := repo.GetContactSubscriptions(ctx, claims.TenantID, claims.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	if subscriptions == nil {
+		return nil, nil
+	}
+	unsubToken.Used = unsubscribedFrom(subscriptions, claims.Scope)
+	return unsubToken, nil
+}
+
+// scopeTopics sets every subscription topic an unsubscribe scope covers to subscribed
+func scopeTopics(scope string, subscribed bool) map[string]bool {
+	topics := make(map[string]bool)
+	switch scope {
+	case tokens.ScopePromotions:
+		topics[models.TopicBirthdayPromotion] = subscribed
+	case tokens.ScopeAll:
+		for _, topic := range models.SubscriptionTopics {
+			topics[topic] = subscribed
+		}
+	default:
+		topics[models.TopicBirthdayCard] = subscribed
+	}
+	return topics
+}
+
+// unsubscribedFrom reports whether the contact is unsubscribed from every topic in scope
+func unsubscribedFrom(subscriptions []models.ContactSubscription, scope string) bool {
+	topics := scopeTopics(scope, false)
+	for _, sub := range subscriptions {
+		if _, ok := topics[sub.Topic]; ok && sub.Subscribed {
+			return false
+		}
+	}
+	return true
+}
+
+// applyUnsubscribe unsubscribes the token's contact from the topics in its scope, recording
+// the evidence in the subscription history and consent log, and marks a legacy token used
+func applyUnsubscribe(ctx context.Context, repo *repository.Repository, unsubToken *models.BirthdayUnsubscribeToken, evidence models.ConsentEvidence) error {
+	if _, err := repo.SetContactSubscriptions(ctx, unsubToken.TenantID, unsubToken.ContactID, scopeTopics(unsubToken.Scope, false), evidence); err != nil {
+		return err
+	}
+
+	if unsubToken.ID != "" {
+		if err := repo.MarkBirthdayUnsubscribeTokenUsed(ctx, unsubToken.ID); err != nil {
+			// Log error but don't fail the request since unsubscribe was successful
+			fmt.Printf("Warning: Failed to mark unsubscribe token as used: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// applyResubscribe undoes applyUnsubscribe, resetting a legacy token so it can be used again
+func applyResubscribe(ctx context.Context, repo *repository.Repository, unsubToken *models.BirthdayUnsubscribeToken, evidence models.ConsentEvidence) error {
+	if _, err := repo.SetContactSubscriptions(ctx, unsubToken.TenantID, unsubToken.ContactID, scopeTopics(unsubToken.Scope, true), evidence); err != nil {
+		return err
+	}
+
+	if unsubToken.ID != "" {
+		if err := repo.ResetBirthdayUnsubscribeToken(ctx, unsubToken.ID); err != nil {
+			// Log error but don't fail the request since resubscribe was successful
+			fmt.Printf("Warning: Failed to reset unsubscribe token status: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// cancelPromotionsTimeout bounds how long canceling a contact's pending promotions may take
+const cancelPromotionsTimeout = 30 * time.Second
+
+// cancelPendingPromotions calls off the split promotional emails still waiting to go to a
+// contact who was just unsubscribed from promotions by the topic changes. It runs in the
+// background so the unsubscribe doesn't wait on Temporal. Failures are only logged: the
+// send-time subscription check still keeps those emails from going out.
+func (h *BirthdayHandler) cancelPendingPromotions(tenantID, contactID string, topics map[string]bool) {
+	if subscribed, ok := topics[models.TopicBirthdayPromotion]; !ok || subscribed || contactID == "" {
+		return
+	}
+	if h.temporalClient == nil || !h.temporalClient.IsConnected() {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cancelPromotionsTimeout)
+		defer cancel()
+		_, err := h.temporalClient.CancelPendingPromotions(ctx, tenantID, contactID, "unsubscribed")
+		if errors.Is(err, temporal.ErrAdvancedVisibilityRequired) {
+			fmt.Printf("⚠️ [Unsubscribe] Can't look up pending promotions for contact %s without advanced visibility; the send-time subscription check will skip them: %v\n", contactID, err)
+		} else if err != nil {
+			fmt.Printf("⚠️ [Unsubscribe] Failed to cancel pending promotions for contact %s: %v\n", contactID, err)
+		}
+	}()
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
@@ -13,6 +14,7 @@ import (
 	"cardprocessor-go/internal/config"
 	"cardprocessor-go/internal/models"
 	"cardprocessor-go/internal/repository"
+	"cardprocessor-go/internal/tokens"
 
 	"github.com/gin-gonic/gin"
 )
@@ -90,6 +92,12 @@ func (h *WebhookHandler) ResendWebhook(c *gin.Context) {
 		return
 	}
 
+	// Mail sent to the mailto: List-Unsubscribe address arrives as an inbound email
+	if eventType == "email.received" {
+		h.handleInboundUnsubscribe(c, data)
+		return
+	}
+
 	recipient := extractRecipientEmail(data)
 	if recipient == "" {
 		if h.config.Server.Environment == "development" || strings.ToLower(h.config.GinMode) == "debug" {
@@ -211,6 +219,38 @@ func (h *WebhookHandler) ResendWebhook(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"received": true})
 }
 
+// handleInboundUnsubscribe unsubscribes the contact whose token is in the subject of an email
+// sent to the UNSUBSCRIBE_MAILTO address. Other inbound mail is acknowledged and ignored.
+func (h *WebhookHandler) handleInboundUnsubscribe(c *gin.Context, data map[string]interface{}) {
+	mailbox := strings.ToLower(h.config.UnsubscribeMailto)
+	recipient := strings.ToLower(extractRecipientEmail(data))
+	if mailbox == "" || !strings.Contains(recipient, mailbox) {
+		c.JSON(http.StatusOK, gin.H{"received": true, "note": "not an unsubscribe request"})
+		return
+	}
+
+	token := unsubscribeTokenFromSubject(getString(data, "subject", "Subject"))
+	if token == "" {
+		log.Printf("[webhook][resend] inbound unsubscribe without a token from=%s", getString(data, "from", "From"))
+		c.JSON(http.StatusOK, gin.H{"received": true, "note": "no unsubscribe token"})
+		return
+	}
+
+	_, err := unsubscribeByToken(c.Request.Context(), h.repo, h.config, token, unsubscribeMethodMailto, "", "")
+	if errors.Is(err, errUnsubscribeTokenNotFound) || errors.Is(err, tokens.ErrExpired) {
+		c.JSON(http.StatusOK, gin.H{"received": true, "note": "unsubscribe token not found"})
+		return
+	}
+	if err != nil {
+		// Ask Resend to retry the delivery
+		log.Printf("[webhook][resend] inbound unsubscribe failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to unsubscribe"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true, "unsubscribed": true})
+}
+
 func computeHMACSHA256(message, secret []byte) string {
 	h := hmac.New(sha256.New, secret)
 	h.Write(message)
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cardprocessor-go/internal/middleware"
+	"cardprocessor-go/internal/temporal"
+
+	"github.com/gin-gonic/gin"
+)
+
+// workflowParams reads the tenant and workflow ID for the workflow endpoints and checks that
+// Temporal is available, writing the error response when not
+func (h *BirthdayHandler) workflowParams(c *gin.Context) (string, string, bool) {
+	tenantID, err := middleware.GetTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Tenant ID not found",
+		})
+		return "", "", false
+	}
+
+	workflowID := c.Param("id")
+	if workflowID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Workflow ID is required",
+		})
+		return "", "", false
+	}
+
+	if h.temporalClient == nil || !h.temporalClient.IsConnected() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   "Temporal workflow service is unavailable. Please try again later.",
+		})
+		return "", "", false
+	}
+
+	return tenantID, workflowID, true
+}
+
+// GetWorkflowStatus reports on a birthday test card or invitation workflow started by this
+// tenant: its status, the step it is on and, once finished, its result
+func (h *BirthdayHandler) GetWorkflowStatus(c *gin.Context) {
+	tenantID, workflowID, ok := h.workflowParams(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.temporalClient.DescribeSendWorkflow(c.Request.Context(), tenantID, workflowID)
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] DescribeSendWorkflow failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Workflow ID: %s\n", workflowID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to get workflow status",
+		})
+		return
+	}
+	if status == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Workflow not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":  true,
+		"workflow": status,
+	})
+}
+
+// CancelWorkflow cancels a running birthday test card or invitation workflow started by this
+// tenant. Emails not yet sent are dropped; cancellation is asynchronous, so poll the status
+// endpoint to see the workflow close as Canceled.
+func (h *BirthdayHandler) CancelWorkflow(c *gin.Context) {
+	tenantID, workflowID, ok := h.workflowParams(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.temporalClient.CancelSendWorkflow(c.Request.Context(), tenantID, workflowID)
+	if errors.Is(err, temporal.ErrWorkflowNotRunning) {
+		c.JSON(http.StatusConflict, gin.H{
+			"success":  false,
+			"error":    "Workflow has already finished",
+			"workflow": status,
+		})
+		return
+	}
+	if err != nil {
+		fmt.Printf("❌ [500 ERROR] CancelSendWorkflow failed\n")
+		fmt.Printf("   └─ Tenant ID: %s\n", tenantID)
+		fmt.Printf("   └─ Workflow ID: %s\n", workflowID)
+		fmt.Printf("   └─ Error: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to cancel workflow",
+		})
+		return
+	}
+	if status == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Workflow not found",
+		})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	fmt.Printf("🛑 [Workflows] User %s canceled workflow %s at step %q (tenant %s)\n", userID, workflowID, status.CurrentStep, tenantID)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success":  true,
+		"message":  "Cancellation requested",
+		"workflow": status,
+	})
+}
@@ -0,0 +1,74 @@
+package i18n
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Text directions, usable directly as HTML dir attributes
+const (
+	DirectionLTR = "ltr"
+	DirectionRTL = "rtl"
+)
+
+// Unicode directional isolates (UAX #9): FSI takes its direction from the first strong
+// character of the isolated text and PDI ends the isolate
+const (
+	firstStrongIsolate    = "\u2068"
+	popDirectionalIsolate = "\u2069"
+)
+
+// rtlLanguages are base languages written right-to-left by default
+var rtlLanguages = map[string]bool{
+	"ar": true, "he": true, "fa": true, "ur": true, "yi": true,
+	"ps": true, "sd": true, "dv": true, "ckb": true, "ug": true,
+}
+
+// rtlScripts are script subtags that are right-to-left whatever the language, e.g. "az-arab"
+var rtlScripts = map[string]bool{
+	"arab": true, "hebr": true, "thaa": true, "syrc": true, "nkoo": true, "adlm": true,
+}
+
+// Direction returns "rtl" or "ltr" for a language. A catalog's "direction" field wins over
+// the built-in list of right-to-left languages and scripts.
+func Direction(lang string) string {
+	lang = ResolveLanguage(lang)
+	if locale, ok := currentCatalog().locales[lang]; ok && locale.Direction != "" {
+		return locale.Direction
+	}
+
+	subtags := strings.Split(lang, "-")
+	if len(subtags) > 1 && rtlScripts[subtags[1]] {
+		return DirectionRTL
+	}
+	if rtlLanguages[subtags[0]] {
+		return DirectionRTL
+	}
+	return DirectionLTR
+}
+
+// IsRTL reports whether a language is written right-to-left
+func IsRTL(lang string) bool {
+	return Direction(lang) == DirectionRTL
+}
+
+// Isolate wraps a value inserted into lang's text (such as a contact name) in Unicode
+// directional isolates, so a Latin name in Arabic text, or an Arabic name in English text,
+// doesn't reorder the punctuation around it. Values that cannot affect the surrounding
+// text are returned unchanged.
+func Isolate(lang, value string) string {
+	if value == "" || (!IsRTL(lang) && !ContainsRTL(value)) {
+		return value
+	}
+	return firstStrongIsolate + value + popDirectionalIsolate
+}
+
+// ContainsRTL reports whether s has a strong right-to-left character
+func ContainsRTL(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko, unicode.Adlam) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package i18n
+
+import "strings"
+
+// DefaultLanguage is the final fallback when neither the contact nor the tenant has a supported language
+const DefaultLanguage = "en"
+
+// CardText holds the built-in strings used in rendered emails, populated from the locale catalog
+type CardText struct {
+	// Birthday card
+	HappyBirthday     string `msg:"card.happyBirthday"`     // headline without a name
+	HappyBirthdayName string `msg:"card.happyBirthdayName"` // headline with the recipient's name (%s)
+	DefaultMessage    string `msg:"card.defaultMessage"`
+	DefaultSender     string `msg:"card.defaultSender"`
+	BirthdaySubject   string `msg:"card.subject"` // %s is the recipient's first name
+	BestRegards       string `msg:"card.bestRegards"`
+
+	// Personalized card image
+	ImageGreeting     string `msg:"cardImage.greeting"`
+	ImageGreetingAge  string `msg:"cardImage.greetingAge"` // %s is the ordinal age, e.g. "30th"
+	ImageFallbackName string `msg:"cardImage.fallbackName"`
+
+	// Unsubscribe footer
+	UnsubscribePrompt string `msg:"card.unsubscribePrompt"`
+	UnsubscribeLink   string `msg:"card.unsubscribeLink"`
+	ManagePreferences string `msg:"card.managePreferences"`
+
+	// Birthday invitation
+	InvitationSubject       string   `msg:"invitation.subject"`
+	InvitationTitle         string   `msg:"invitation.title"`
+	InvitationHeading       string   `msg:"invitation.heading"`
+	InvitationGreeting      string   `msg:"invitation.greeting"` // %s is the contact's name
+	InvitationIntro         string   `msg:"invitation.intro"`
+	InvitationBenefitsIntro string   `msg:"invitation.benefitsIntro"`
+	InvitationBenefits      []string `msg:"invitation.benefits"`
+	InvitationButton        string   `msg:"invitation.button"`
+	InvitationPrivacy       string   `msg:"invitation.privacy"` // follows the pluralized "invitation.expiry" sentence
+	InvitationFooter        string   `msg:"invitation.footer"`
+	InvitationTextBody      string   `msg:"invitation.textBody"`
+	InvitationClickHere     string   `msg:"invitation.clickHere"`
+	ValuedCustomer          string   `msg:"invitation.valuedCustomer"`
+
+	// Promotional email
+	PromotionSubject     string `msg:"promotion.subject"` // %s is the promotion title
+	PromotionHeading     string `msg:"promotion.heading"`
+	PromotionButton      string `msg:"promotion.button"`
+	PromotionUnsubscribe string `msg:"promotion.unsubscribe"`
+}
+
+// GetCardText returns the email strings for lang, falling back to English
+func GetCardText(lang string) CardText {
+	var text CardText
+	fill(&text, lang)
+	return text
+}
+
+// NormalizeLanguage reduces a language tag such as "es-MX", "FR_ca" or "zh-Hant-TW" to the
+// most specific loaded language, dropping subtags from the end (RFC 4647 lookup) and
+// inferring the script for Chinese regions. It returns "" when the language is not supported.
+func NormalizeLanguage(lang string) string {
+	lang = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+	for lang != "" {
+		if HasLanguage(lang) {
+			return lang
+		}
+		if script, ok := likelyScripts[lang]; ok && HasLanguage(script) {
+			return script
+		}
+		i := strings.LastIndex(lang, "-")
+		if i < 0 {
+			break
+		}
+		lang = lang[:i]
+		// A single-letter subtag introduces an extension and never stands alone
+		if len(lang) > 1 && lang[len(lang)-2] == '-' {
+			lang = lang[:len(lang)-2]
+		}
+	}
+	return ""
+}
+
+// ResolveLanguage returns the first supported language among the candidates, in order
+// (typically contact preference, then tenant default), falling back to English
+func ResolveLanguage(candidates ...string) string {
+	for _, candidate := range candidates {
+		if lang := NormalizeLanguage(candidate); lang != "" {
+			return lang
+		}
+	}
+	return DefaultLanguage
+}
@@ -0,0 +1,346 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Locale catalogs shipped with the binary; LoadCatalog can overlay them from a directory
+//
+//go:embed locales/*.json
+var embeddedLocales embed.FS
+
+// localeCode matches the catalog language codes: a base language with an optional script
+var localeCode = regexp.MustCompile(`^[a-z]{2,3}(-[a-z]{4})?$`)
+
+// ordinalKey holds the ordinal number formats, selected with ordinal rather than cardinal plural rules
+const ordinalKey = "number.ordinal"
+
+// Message is a catalog entry: plain text, a list of texts, or CLDR plural forms
+// keyed by category ("zero", "one", "two", "few", "many", "other")
+type Message struct {
+	Text  string
+	List  []string
+	Forms map[string]string
+}
+
+// UnmarshalJSON accepts a string, an array of strings or an object of plural forms
+func (m *Message) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.Text); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &m.List); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &m.Forms); err != nil {
+		return fmt.Errorf("message must be a string, an array of strings or an object of plural forms")
+	}
+	if _, ok := m.Forms["other"]; !ok {
+		return fmt.Errorf("plural message has no \"other\" form")
+	}
+	return nil
+}
+
+// Locale is one language's catalog
+type Locale struct {
+	Language  string             `json:"language"`
+	Name      string             `json:"name"`
+	Direction string             `json:"direction,omitempty"` // "rtl" or "ltr"; defaults by language
+	Messages  map[string]Message `json:"messages"`
+}
+
+// Catalog holds every loaded locale, keyed by language code ("pt", "zh-hant")
+type Catalog struct {
+	locales map[string]*Locale
+}
+
+var (
+	catalogMu sync.RWMutex
+	catalog   *Catalog
+)
+
+func init() {
+	loaded, err := loadCatalog("")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: embedded locale catalog is invalid: %v", err))
+	}
+	catalog = loaded
+}
+
+// LoadCatalog reloads the embedded locales and overlays every <lang>.json file in dir on
+// top of them. Messages in an override file replace the embedded ones key by key, and files
+// for new languages add those languages. On error the current catalog is kept.
+func LoadCatalog(dir string) error {
+	loaded, err := loadCatalog(dir)
+	if err != nil {
+		return err
+	}
+	catalogMu.Lock()
+	catalog = loaded
+	catalogMu.Unlock()
+	return nil
+}
+
+func loadCatalog(dir string) (*Catalog, error) {
+	c := &Catalog{locales: make(map[string]*Locale)}
+
+	embedded, err := fs.Glob(embeddedLocales, "locales/*.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range embedded {
+		data, err := embeddedLocales.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.merge(path, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if dir != "" {
+		overrides, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range overrides {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read locale file %s: %w", path, err)
+			}
+			if err := c.merge(path, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, ok := c.locales[DefaultLanguage]; !ok {
+		return nil, fmt.Errorf("no %q locale found", DefaultLanguage)
+	}
+	return c, nil
+}
+
+// merge parses a locale file and merges it into the catalog. The language comes from the
+// file's "language" field, or its file name when that is empty.
+func (c *Catalog) merge(path string, data []byte) error {
+	var file Locale
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("invalid locale file %s: %w", path, err)
+	}
+
+	lang := file.Language
+	if lang == "" {
+		lang = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if !localeCode.MatchString(lang) {
+		return fmt.Errorf("invalid locale file %s: language must be a base code such as \"pt\" or a language-script code such as \"zh-hant\"", path)
+	}
+
+	locale, ok := c.locales[lang]
+	if !ok {
+		locale = &Locale{Language: lang, Messages: make(map[string]Message)}
+		c.locales[lang] = locale
+	}
+	if file.Name != "" {
+		locale.Name = file.Name
+	}
+	switch file.Direction {
+	case "":
+	case DirectionLTR, DirectionRTL:
+		locale.Direction = file.Direction
+	default:
+		return fmt.Errorf("invalid locale file %s: direction must be \"ltr\" or \"rtl\"", path)
+	}
+	for key, message := range file.Messages {
+		locale.Messages[key] = message
+	}
+	return nil
+}
+
+func currentCatalog() *Catalog {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	return catalog
+}
+
+// lookup finds key in lang, falling back to English. It also returns the language the
+// message came from, whose plural rules apply to it.
+func (c *Catalog) lookup(lang, key string) (Message, string, bool) {
+	for _, candidate := range []string{lang, DefaultLanguage} {
+		if locale, ok := c.locales[candidate]; ok {
+			if message, ok := locale.Messages[key]; ok {
+				return message, candidate, true
+			}
+		}
+	}
+	return Message{}, "", false
+}
+
+// Languages returns the codes of all loaded locales, sorted
+func Languages() []string {
+	c := currentCatalog()
+	langs := make([]string, 0, len(c.locales))
+	for lang := range c.locales {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// HasLanguage reports whether a locale is loaded for the language code
+func HasLanguage(lang string) bool {
+	_, ok := currentCatalog().locales[lang]
+	return ok
+}
+
+// LanguageName returns the native name of a loaded language, or the code itself
+func LanguageName(lang string) string {
+	if locale, ok := currentCatalog().locales[lang]; ok && locale.Name != "" {
+		return locale.Name
+	}
+	return lang
+}
+
+// T returns the message for key in lang, formatted with args when given.
+// Missing messages fall back to English and then to the key itself.
+func T(lang, key string, args ...interface{}) string {
+	message, _, ok := currentCatalog().lookup(NormalizeLanguage(lang), key)
+	if !ok {
+		return key
+	}
+	text := message.Text
+	if message.Forms != nil {
+		text = message.Forms["other"]
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Plural returns the form of key matching n under lang's CLDR plural rules, formatted
+// with n followed by args
+func Plural(lang, key string, n int, args ...interface{}) string {
+	return formatPlural(lang, key, n, pluralCategory, args)
+}
+
+// Ordinal formats n as an ordinal number in lang, e.g. "30th", "30.º", "30e", "30."
+func Ordinal(lang string, n int) string {
+	return formatPlural(lang, ordinalKey, n, ordinalCategory, nil)
+}
+
+func formatPlural(lang, key string, n int, category func(lang string, n int) string, args []interface{}) string {
+	message, source, ok := currentCatalog().lookup(NormalizeLanguage(lang), key)
+	if !ok {
+		return key
+	}
+	text := message.Text
+	if message.Forms != nil {
+		// A regional tag such as pt-PT keeps its own rule when the message came from its base language
+		ruleLang := source
+		if requested := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-"); baseLanguage(requested) == baseLanguage(source) {
+			ruleLang = requested
+		}
+		var found bool
+		if text, found = message.Forms[category(ruleLang, n)]; !found {
+			text = message.Forms["other"]
+		}
+	}
+	return fmt.Sprintf(text, append([]interface{}{n}, args...)...)
+}
+
+// List returns the list message for key in lang
+func List(lang, key string) []string {
+	message, _, _ := currentCatalog().lookup(NormalizeLanguage(lang), key)
+	if message.List == nil && message.Text != "" {
+		return []string{message.Text}
+	}
+	return message.List
+}
+
+// fill sets every `msg`-tagged string or []string field of the struct dst points to
+func fill(dst interface{}, lang string) {
+	value := reflect.ValueOf(dst).Elem()
+	fields := value.Type()
+	for i := 0; i < fields.NumField(); i++ {
+		key := fields.Field(i).Tag.Get("msg")
+		if key == "" {
+			continue
+		}
+		switch field := value.Field(i); field.Kind() {
+		case reflect.String:
+			field.SetString(T(lang, key))
+		case reflect.Slice:
+			field.Set(reflect.ValueOf(List(lang, key)))
+		}
+	}
+}
+
+// LocaleCompleteness lists what a locale lacks compared to the English catalog
+type LocaleCompleteness struct {
+	Language     string              `json:"language"`
+	Missing      []string            `json:"missing,omitempty"`      // keys present in English only
+	MissingForms map[string][]string `json:"missingForms,omitempty"` // plural categories the locale's rules need
+	Unknown      []string            `json:"unknown,omitempty"`      // keys English does not have (likely typos)
+}
+
+// Complete reports whether nothing is missing
+func (c LocaleCompleteness) Complete() bool {
+	return len(c.Missing) == 0 && len(c.MissingForms) == 0
+}
+
+// CheckCompleteness compares every loaded locale with English and reports missing keys,
+// plural messages without the forms their CLDR rules need, and unknown keys
+func CheckCompleteness() []LocaleCompleteness {
+	c := currentCatalog()
+	base := c.locales[DefaultLanguage]
+
+	var report []LocaleCompleteness
+	for _, lang := range Languages() {
+		locale := c.locales[lang]
+		result := LocaleCompleteness{Language: lang}
+
+		for key, baseMessage := range base.Messages {
+			message, ok := locale.Messages[key]
+			if !ok {
+				result.Missing = append(result.Missing, key)
+				continue
+			}
+			if baseMessage.Forms == nil && message.Forms == nil {
+				continue
+			}
+			categories := cardinalCategories(lang)
+			if key == ordinalKey {
+				categories = ordinalCategories(lang)
+			}
+			for _, category := range categories {
+				if _, ok := message.Forms[category]; !ok {
+					if result.MissingForms == nil {
+						result.MissingForms = make(map[string][]string)
+					}
+					result.MissingForms[key] = append(result.MissingForms[key], category)
+				}
+			}
+		}
+		for key := range locale.Messages {
+			if _, ok := base.Messages[key]; !ok {
+				result.Unknown = append(result.Unknown, key)
+			}
+		}
+
+		sort.Strings(result.Missing)
+		sort.Strings(result.Unknown)
+		report = append(report, result)
+	}
+	return report
+}
@@ -0,0 +1,110 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LanguageRange is one entry of an Accept-Language header
+type LanguageRange struct {
+	Tag     string  // lowercased language range, e.g. "fr-ca", "zh-hant" or "*"
+	Quality float64 // 0 means "not acceptable"
+}
+
+// likelyScripts maps Chinese tags without a script to the one they most likely use, so "zh-TW" finds a
+// "zh-hant" catalog (from the CLDR likely-subtags data)
+var likelyScripts = map[string]string{
+	"zh-tw": "zh-hant",
+	"zh-hk": "zh-hant",
+	"zh-mo": "zh-hant",
+	"zh-cn": "zh-hans",
+	"zh-sg": "zh-hans",
+	"zh-my": "zh-hans",
+	"zh":    "zh-hans",
+}
+
+// ParseAcceptLanguage parses an Accept-Language header (RFC 9110 §12.5.4) into language
+// ranges ordered by descending quality; ranges with equal quality keep their header order.
+// Malformed entries are skipped.
+func ParseAcceptLanguage(header string) []LanguageRange {
+	var ranges []LanguageRange
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" || !validRange(tag) {
+			continue
+		}
+
+		quality := 1.0
+		valid := true
+		for _, param := range fields[1:] {
+			name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+			if !found || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || q < 0 || q > 1 {
+				valid = false
+				break
+			}
+			quality = q
+		}
+		if valid {
+			ranges = append(ranges, LanguageRange{Tag: strings.ReplaceAll(tag, "_", "-"), Quality: quality})
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].Quality > ranges[j].Quality
+	})
+	return ranges
+}
+
+// validRange accepts "*" and tags of 1-8 character alphanumeric subtags
+func validRange(tag string) bool {
+	if tag == "*" {
+		return true
+	}
+	for _, subtag := range strings.FieldsFunc(tag, func(r rune) bool { return r == '-' || r == '_' }) {
+		if len(subtag) > 8 {
+			return false
+		}
+		for _, r := range subtag {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// NegotiateLanguage picks the best loaded language for an Accept-Language header using
+// RFC 4647 lookup: ranges are tried by quality, each falling back from its full tag to
+// shorter prefixes (zh-Hant-TW → zh-hant → zh, pt-BR → pt). Ranges with q=0 exclude a
+// loaded language, and "*" accepts English. It returns "" when nothing acceptable is loaded.
+func NegotiateLanguage(header string) string {
+	ranges := ParseAcceptLanguage(header)
+
+	// q=0 only excludes the exact language, so "fr-CA;q=0" still allows "fr"
+	excluded := make(map[string]bool)
+	for _, r := range ranges {
+		if r.Quality == 0 {
+			excluded[r.Tag] = true
+		}
+	}
+
+	for _, r := range ranges {
+		if r.Quality == 0 {
+			continue
+		}
+		lang := DefaultLanguage
+		if r.Tag != "*" {
+			lang = NormalizeLanguage(r.Tag)
+		}
+		if lang != "" && !excluded[lang] {
+			return lang
+		}
+	}
+	return ""
+}
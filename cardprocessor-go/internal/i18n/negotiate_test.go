@@ -0,0 +1,112 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []LanguageRange
+	}{
+		{"", nil},
+		{"fr", []LanguageRange{{"fr", 1}}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5", []LanguageRange{
+			{"fr-ch", 1}, {"fr", 0.9}, {"en", 0.8}, {"de", 0.7}, {"*", 0.5},
+		}},
+		// Sorted by quality, keeping header order for equal qualities
+		{"de;q=0.5, es, fr;q=0.5, en", []LanguageRange{
+			{"es", 1}, {"en", 1}, {"de", 0.5}, {"fr", 0.5},
+		}},
+		{"pt_BR", []LanguageRange{{"pt-br", 1}}},
+		{"EN-us ; Q=0.3", []LanguageRange{{"en-us", 0.3}}},
+		{"de;q=0", []LanguageRange{{"de", 0}}},
+		// Malformed entries are skipped
+		{"fr;q=2, de;q=abc, es;q=-1, en", []LanguageRange{{"en", 1}}},
+		{"toolongsubtag, en-ü, , en", []LanguageRange{{"en", 1}}},
+		{"es;level=1;q=0.4", []LanguageRange{{"es", 0.4}}},
+	}
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	testCatalog := &Catalog{locales: map[string]*Locale{
+		"en":      {Language: "en"},
+		"de":      {Language: "de"},
+		"fr":      {Language: "fr"},
+		"pt":      {Language: "pt"},
+		"zh-hant": {Language: "zh-hant"},
+	}}
+	catalogMu.Lock()
+	restore := catalog
+	catalog = testCatalog
+	catalogMu.Unlock()
+	defer func() {
+		catalogMu.Lock()
+		catalog = restore
+		catalogMu.Unlock()
+	}()
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"de", "de"},
+		{"it", ""},
+		{"it, de;q=0.8", "de"},
+		{"de;q=0.5, fr;q=0.9", "fr"},
+		{"de;q=0.5, fr", "fr"},
+
+		// Region and script subtags fall back to the base language
+		{"pt-BR", "pt"},
+		{"fr-CA, de;q=0.9", "fr"},
+		{"de-Latn-DE", "de"},
+		{"de-DE-x-private", "de"},
+
+		// Chinese regions find the script they use
+		{"zh-TW", "zh-hant"},
+		{"zh-Hant-HK", "zh-hant"},
+		{"zh-CN", ""},
+
+		// The wildcard accepts English
+		{"*", "en"},
+		{"it, *;q=0.1", "en"},
+		{"it, *;q=0.5, de;q=0.4", "en"},
+
+		// q=0 excludes that language only
+		{"fr;q=0, *", "en"},
+		{"de;q=0, de-AT", ""},
+		{"fr-CA;q=0, fr;q=0.5", "fr"},
+		{"de;q=0", ""},
+	}
+	for _, tt := range tests {
+		if got := NegotiateLanguage(tt.header); got != tt.want {
+			t.Errorf("NegotiateLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"es-MX", "es"},
+		{"FR_ca", "fr"},
+		{" de ", "de"},
+		{"en-u-ca-gregory", "en"},
+		{"xx", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLanguage(tt.lang); got != tt.want {
+			t.Errorf("NormalizeLanguage(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+}
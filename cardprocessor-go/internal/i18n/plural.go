@@ -0,0 +1,191 @@
+package i18n
+
+import "strings"
+
+// CLDR plural rules for integer counts (https://cldr.unicode.org/index/cldr-spec/plural-rules).
+// Only the integer operands matter here, so categories used solely for decimals are omitted.
+
+type pluralRule struct {
+	categories []string // categories the rule can produce, always including "other"
+	category   func(n int) string
+}
+
+var (
+	ruleOther = pluralRule{[]string{"other"}, func(n int) string { return "other" }}
+
+	ruleOneOther = pluralRule{[]string{"one", "other"}, func(n int) string {
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}}
+
+	// Spanish, Italian and Portuguese (Portugal) use "many" for exact millions ("1 millón de días")
+	ruleOneManyOther = pluralRule{[]string{"one", "many", "other"}, func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n != 0 && n%1000000 == 0:
+			return "many"
+		}
+		return "other"
+	}}
+
+	// French and Brazilian Portuguese put 0 in "one" along with 1
+	ruleFrench = pluralRule{[]string{"one", "many", "other"}, func(n int) string {
+		switch {
+		case n == 0 || n == 1:
+			return "one"
+		case n%1000000 == 0:
+			return "many"
+		}
+		return "other"
+	}}
+
+	ruleEastSlavic = pluralRule{[]string{"one", "few", "many", "other"}, func(n int) string {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		}
+		return "many"
+	}}
+
+	rulePolish = pluralRule{[]string{"one", "few", "many", "other"}, func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		}
+		return "many"
+	}}
+
+	ruleCzech = pluralRule{[]string{"one", "few", "other"}, func(n int) string {
+		switch {
+		case n == 1:
+			return "one"
+		case n >= 2 && n <= 4:
+			return "few"
+		}
+		return "other"
+	}}
+
+	ruleHebrew = pluralRule{[]string{"one", "two", "other"}, func(n int) string {
+		switch n {
+		case 1:
+			return "one"
+		case 2:
+			return "two"
+		}
+		return "other"
+	}}
+
+	ruleArabic = pluralRule{[]string{"zero", "one", "two", "few", "many", "other"}, func(n int) string {
+		switch {
+		case n == 0:
+			return "zero"
+		case n == 1:
+			return "one"
+		case n == 2:
+			return "two"
+		case n%100 >= 3 && n%100 <= 10:
+			return "few"
+		case n%100 >= 11:
+			return "many"
+		}
+		return "other"
+	}}
+)
+
+// cardinalRules maps base language codes to their cardinal rule, and language-region codes
+// where the region differs from the base language; unlisted languages use one/other
+var cardinalRules = map[string]pluralRule{
+	"ja": ruleOther, "zh": ruleOther, "ko": ruleOther, "vi": ruleOther, "th": ruleOther, "id": ruleOther,
+	"es": ruleOneManyOther, "it": ruleOneManyOther,
+	"fr": ruleFrench, "pt": ruleFrench, "pt-pt": ruleOneManyOther,
+	"ru": ruleEastSlavic, "uk": ruleEastSlavic,
+	"pl": rulePolish,
+	"cs": ruleCzech, "sk": ruleCzech,
+	"he": ruleHebrew,
+	"ar": ruleArabic,
+}
+
+// ordinalRules maps base language codes to their ordinal rule; unlisted languages use only "other"
+var ordinalRules = map[string]pluralRule{
+	"en": {[]string{"one", "two", "few", "other"}, func(n int) string {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 == 2 && n%100 != 12:
+			return "two"
+		case n%10 == 3 && n%100 != 13:
+			return "few"
+		}
+		return "other"
+	}},
+	"fr": ruleOneOther,
+}
+
+// baseLanguage strips script and region subtags, since plural rules follow the base language
+func baseLanguage(lang string) string {
+	if i := strings.IndexByte(lang, '-'); i >= 0 {
+		return lang[:i]
+	}
+	return lang
+}
+
+// regionalLanguage reduces a tag to its language and region, e.g. "pt-latn-pt" to "pt-pt",
+// or returns "" when it has no region
+func regionalLanguage(lang string) string {
+	subtags := strings.Split(lang, "-")
+	for _, subtag := range subtags[1:] {
+		if len(subtag) == 2 || (len(subtag) == 3 && subtag[0] >= '0' && subtag[0] <= '9') {
+			return subtags[0] + "-" + subtag
+		}
+	}
+	return ""
+}
+
+func cardinalRule(lang string) pluralRule {
+	lang = strings.ReplaceAll(strings.ToLower(lang), "_", "-")
+	if rule, ok := cardinalRules[regionalLanguage(lang)]; ok {
+		return rule
+	}
+	if rule, ok := cardinalRules[baseLanguage(lang)]; ok {
+		return rule
+	}
+	return ruleOneOther
+}
+
+func ordinalRule(lang string) pluralRule {
+	if rule, ok := ordinalRules[baseLanguage(lang)]; ok {
+		return rule
+	}
+	return ruleOther
+}
+
+// pluralCategory returns the CLDR cardinal category of n in lang
+func pluralCategory(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	return cardinalRule(lang).category(n)
+}
+
+// ordinalCategory returns the CLDR ordinal category of n in lang
+func ordinalCategory(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	return ordinalRule(lang).category(n)
+}
+
+func cardinalCategories(lang string) []string {
+	return cardinalRule(lang).categories
+}
+
+func ordinalCategories(lang string) []string {
+	return ordinalRule(lang).categories
+}
@@ -0,0 +1,161 @@
+package i18n
+
+import "testing"
+
+func TestPluralCategory(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 0, "other"},
+		{"en", 1, "one"},
+		{"en", 2, "other"},
+		{"en", -1, "one"},
+		{"de", 1, "one"},
+		{"de", 21, "other"},
+		{"ja", 1, "other"},
+		{"zh-hant", 1, "other"},
+
+		{"es", 0, "other"},
+		{"es", 1, "one"},
+		{"es", 2, "other"},
+		{"es", 1000000, "many"},
+		{"es", 2000000, "many"},
+		{"es", 1000001, "other"},
+		{"it", 1000000, "many"},
+
+		{"fr", 0, "one"},
+		{"fr", 1, "one"},
+		{"fr", 2, "other"},
+		{"fr", 1000000, "many"},
+		{"pt", 0, "one"},
+		{"pt-BR", 0, "one"},
+		{"pt_br", 1, "one"},
+
+		// Portugal doesn't put 0 in "one"
+		{"pt-PT", 0, "other"},
+		{"pt-pt", 1, "one"},
+		{"pt-PT", 2, "other"},
+		{"pt-PT", 1000000, "many"},
+		{"pt-Latn-PT", 0, "other"},
+
+		{"ru", 1, "one"},
+		{"ru", 2, "few"},
+		{"ru", 5, "many"},
+		{"ru", 11, "many"},
+		{"ru", 12, "many"},
+		{"ru", 21, "one"},
+		{"ru", 22, "few"},
+		{"ru", 111, "many"},
+		{"uk", 0, "many"},
+
+		{"pl", 1, "one"},
+		{"pl", 2, "few"},
+		{"pl", 5, "many"},
+		{"pl", 12, "many"},
+		{"pl", 21, "many"},
+		{"pl", 22, "few"},
+
+		{"cs", 1, "one"},
+		{"cs", 3, "few"},
+		{"cs", 5, "other"},
+		{"sk", 22, "other"},
+
+		{"he", 1, "one"},
+		{"he", 2, "two"},
+		{"he", 3, "other"},
+
+		{"ar", 0, "zero"},
+		{"ar", 1, "one"},
+		{"ar", 2, "two"},
+		{"ar", 3, "few"},
+		{"ar", 10, "few"},
+		{"ar", 11, "many"},
+		{"ar", 99, "many"},
+		{"ar", 100, "other"},
+		{"ar", 103, "few"},
+	}
+	for _, tt := range tests {
+		if got := pluralCategory(tt.lang, tt.n); got != tt.want {
+			t.Errorf("pluralCategory(%q, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestOrdinalCategory(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"en", 1, "one"},
+		{"en", 2, "two"},
+		{"en", 3, "few"},
+		{"en", 4, "other"},
+		{"en", 11, "other"},
+		{"en", 12, "other"},
+		{"en", 13, "other"},
+		{"en", 21, "one"},
+		{"en", 22, "two"},
+		{"en", 23, "few"},
+		{"en", 111, "other"},
+		{"en-GB", 1, "one"},
+		{"fr", 1, "one"},
+		{"fr", 2, "other"},
+		{"de", 1, "other"},
+	}
+	for _, tt := range tests {
+		if got := ordinalCategory(tt.lang, tt.n); got != tt.want {
+			t.Errorf("ordinalCategory(%q, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRuleCategoriesIncludeEveryResult(t *testing.T) {
+	for lang, rule := range cardinalRules {
+		allowed := make(map[string]bool)
+		for _, category := range rule.categories {
+			allowed[category] = true
+		}
+		for n := 0; n <= 2000; n++ {
+			if category := rule.category(n); !allowed[category] {
+				t.Errorf("%s: category %q for %d is not in %v", lang, category, n, rule.categories)
+			}
+		}
+	}
+}
+
+func TestPluralUsesRegionalRule(t *testing.T) {
+	testCatalog := &Catalog{locales: map[string]*Locale{
+		DefaultLanguage: {Language: DefaultLanguage, Messages: map[string]Message{}},
+		"pt": {Language: "pt", Messages: map[string]Message{
+			"days": {Forms: map[string]string{"one": "%d dia", "many": "%d de dias", "other": "%d dias"}},
+		}},
+	}}
+	catalogMu.Lock()
+	restore := catalog
+	catalog = testCatalog
+	catalogMu.Unlock()
+	defer func() {
+		catalogMu.Lock()
+		catalog = restore
+		catalogMu.Unlock()
+	}()
+
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"pt", 0, "0 dia"},
+		{"pt-BR", 0, "0 dia"},
+		{"pt-PT", 0, "0 dias"},
+		{"pt-PT", 1, "1 dia"},
+	}
+	for _, tt := range tests {
+		if got := Plural(tt.lang, "days", tt.n); got != tt.want {
+			t.Errorf("Plural(%q, days, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
@@ -22,6 +22,8 @@ type EmailContact struct {
 	BirthdayEmailEnabled       bool       `json:"birthdayEmailEnabled" db:"birthday_email_enabled"`
 	BirthdayUnsubscribeReason  *string    `json:"birthdayUnsubscribeReason" db:"birthday_unsubscribe_reason"`
 	BirthdayUnsubscribedAt     *time.Time `json:"birthdayUnsubscribedAt" db:"birthday_unsubscribed_at"`
+	PreferredLanguage          *string    `json:"preferredLanguage" db:"preferred_language"`
+	PrefMarketing              bool       `json:"prefMarketing" db:"pref_marketing"`
 	ConsentGiven               bool       `json:"consentGiven" db:"consent_given"`
 	ConsentDate                *time.Time `json:"consentDate" db:"consent_date"`
 	ConsentMethod              *string    `json:"consentMethod" db:"consent_method"`
@@ -50,6 +52,13 @@ type BirthdaySettings struct {
 	SenderName      string    `json:"senderName" db:"sender_name"`
 	PromotionID     *string   `json:"promotionId" db:"promotion_id"`
 	SplitPromotionalEmail bool      `json:"splitPromotionalEmail" db:"split_promotional_email"`
+	PromotionDelay        *string   `json:"promotionDelay" db:"promotion_delay"` // wait before a split promotion: "4h" or "next day at HH:MM"; nil is 30s
+	PersonalizedCardImage bool      `json:"personalizedCardImage" db:"personalized_card_image"`
+	SubjectTemplate       *string   `json:"subjectTemplate" db:"subject_template"`
+	PreheaderText         *string   `json:"preheaderText" db:"preheader_text"`
+	SubjectVariants       *string   `json:"subjectVariants" db:"subject_variants"` // JSON array of SubjectVariant
+	DefaultLanguage       string    `json:"defaultLanguage" db:"default_language"`
+	LocalizedMessages     *string   `json:"localizedMessages" db:"localized_messages"` // JSON object of language -> custom message
 	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt       time.Time `json:"updatedAt" db:"updated_at"`
 }
@@ -135,15 +144,39 @@ type BirthdayJob struct {
 	Error     *string    `json:"error,omitempty"`
 }
 
-// BirthdayJobProgress represents the progress of birthday job processing
+// Birthday run statuses
+const (
+	BirthdayRunRunning   = "running"
+	BirthdayRunPaused    = "paused"
+	BirthdayRunCompleted = "completed"
+	BirthdayRunCanceled  = "canceled"
+	BirthdayRunFailed    = "failed"
+)
+
+// Birthday run triggers
+const (
+	BirthdayRunTriggerSchedule = "schedule"
+	BirthdayRunTriggerManual   = "manual"
+)
+
+// BirthdayJobProgress represents the progress of birthday job processing. A running batch
+// workflow reports it live; when the run ends it is stored as the run's summary row.
 type BirthdayJobProgress struct {
-	TenantID       string     `json:"tenantId"`
-	TotalContacts  int        `json:"totalContacts"`
-	ProcessedCount int        `json:"processedCount"`
-	SentCount      int        `json:"sentCount"`
-	FailedCount    int        `json:"failedCount"`
-	StartedAt      time.Time  `json:"startedAt"`
-	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+	ID               string     `json:"id,omitempty"`
+	TenantID         string     `json:"tenantId"`
+	WorkflowID       string     `json:"workflowId"`
+	RunID            string     `json:"runId"`
+	Trigger          string     `json:"trigger"` // schedule, manual
+	Status           string     `json:"status"`  // running, paused, completed, canceled, failed
+	BirthdayDate     string     `json:"birthdayDate,omitempty"`
+	TotalContacts    int        `json:"totalContacts"`
+	ProcessedCount   int        `json:"processedCount"`
+	SentCount        int        `json:"sentCount"`
+	SkippedCount     int        `json:"skippedCount"`     // suppressed or unsubscribed contacts
+	AlreadySentCount int        `json:"alreadySentCount"` // cards an earlier run already sent
+	FailedCount      int        `json:"failedCount"`
+	StartedAt        time.Time  `json:"startedAt"`
+	CompletedAt      *time.Time `json:"completedAt,omitempty"`
 }
 
 // CreateBirthdaySettingsRequest represents the request to create birthday settings
@@ -156,6 +189,13 @@ type CreateBirthdaySettingsRequest struct {
 	SenderName            string  `json:"senderName"`
 	PromotionID           *string `json:"promotionId"`
 	SplitPromotionalEmail *bool   `json:"splitPromotionalEmail,omitempty"`
+	PromotionDelay        *string `json:"promotionDelay,omitempty"`
+	PersonalizedCardImage *bool   `json:"personalizedCardImage,omitempty"`
+	SubjectTemplate       *string `json:"subjectTemplate,omitempty"`
+	PreheaderText         *string `json:"preheaderText,omitempty"`
+	SubjectVariants       *string `json:"subjectVariants,omitempty"`
+	DefaultLanguage       *string `json:"defaultLanguage,omitempty"`
+	LocalizedMessages     *string `json:"localizedMessages,omitempty"`
 }
 
 // UpdateBirthdaySettingsRequest represents the request to update birthday settings
@@ -168,12 +208,52 @@ type UpdateBirthdaySettingsRequest struct {
 	SenderName      *string `json:"senderName,omitempty"`
 	PromotionID     *string `json:"promotionId,omitempty"`
 	SplitPromotionalEmail *bool   `json:"splitPromotionalEmail,omitempty"`
+	PromotionDelay        *string `json:"promotionDelay,omitempty"`
+	PersonalizedCardImage *bool   `json:"personalizedCardImage,omitempty"`
+	SubjectTemplate       *string `json:"subjectTemplate,omitempty"`
+	PreheaderText         *string `json:"preheaderText,omitempty"`
+	SubjectVariants       *string `json:"subjectVariants,omitempty"`
+	DefaultLanguage       *string `json:"defaultLanguage,omitempty"`
+	LocalizedMessages     *string `json:"localizedMessages,omitempty"`
+}
+
+// BirthdaySchedule is when a tenant's Temporal Schedule sends its birthday cards. It is stored
+// alongside the birthday settings; ScheduleID is nil while the tenant has no schedule.
+type BirthdaySchedule struct {
+	TenantID   string  `json:"tenantId" db:"tenant_id"`
+	Enabled    bool    `json:"enabled" db:"enabled"` // Birthday emails are enabled in the settings
+	ScheduleID *string `json:"scheduleId" db:"schedule_id"`
+	SendHour   int     `json:"sendHour" db:"send_hour"`
+	Timezone   string  `json:"timezone" db:"timezone"`
+	DaysAhead  int     `json:"daysAhead" db:"days_ahead"`
+	Paused     bool    `json:"paused" db:"schedule_paused"`
+}
+
+// BirthdayScheduleRequest creates or updates a tenant's birthday schedule; omitted fields keep
+// their current value
+type BirthdayScheduleRequest struct {
+	SendHour  *int    `json:"sendHour,omitempty"`
+	Timezone  *string `json:"timezone,omitempty"`
+	DaysAhead *int    `json:"daysAhead,omitempty"`
 }
 
 // UpdateContactBirthdayRequest represents the request to update contact birthday info
 type UpdateContactBirthdayRequest struct {
 	Birthday             *string `json:"birthday,omitempty"`
 	BirthdayEmailEnabled *bool   `json:"birthdayEmailEnabled,omitempty"`
+	PreferredLanguage    *string `json:"preferredLanguage,omitempty"`
+}
+
+// ContactPreferencesRequest represents the preference center form. The form always carries
+// every field, so unchecked boxes (absent from the form) turn that email type off.
+type ContactPreferencesRequest struct {
+	Token             string `form:"token"`
+	FirstName         string `form:"firstName"`
+	LastName          string `form:"lastName"`
+	Birthday          string `form:"birthday"` // YYYY-MM-DD; empty clears it
+	Language          string `form:"language"` // empty uses the tenant default
+	BirthdayEmails    bool   `form:"birthdayEmails"`
+	PromotionalEmails bool   `form:"promotionalEmails"`
 }
 
 // SendTestBirthdayRequest represents the request to send a test birthday email
@@ -227,9 +307,10 @@ type BulkUpdateBirthdayEmailRequest struct {
 
 // SendBirthdayInvitationRequest represents birthday invitation request
 type SendBirthdayInvitationRequest struct {
-	Email     string `json:"email"`
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
+	Email     string  `json:"email"`
+	FirstName string  `json:"firstName"`
+	LastName  string  `json:"lastName"`
+	Language  *string `json:"language,omitempty"` // Overrides the contact's preferred language
 }
 
 // Promotion represents a promotion in the promotions table
@@ -260,12 +341,336 @@ type BirthdayUnsubscribeToken struct {
 	Used      bool       `json:"used" db:"used"`
 	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
 	UsedAt    *time.Time `json:"usedAt" db:"used_at"`
+	Scope     string     `json:"scope" db:"-"` // birthday, promotions or all; database tokens are always birthday
+}
+
+// Birthday invitation statuses, in the order an invitation normally moves through them
+const (
+	InvitationStatusPending   = "pending"
+	InvitationStatusSent      = "sent"
+	InvitationStatusFailed    = "failed"
+	InvitationStatusOpened    = "opened"
+	InvitationStatusCompleted = "completed"
+)
+
+// BirthdayInvitation represents a birthday invitation sent to a contact
+type BirthdayInvitation struct {
+	ID          string     `json:"id" db:"id"`
+	TenantID    string     `json:"tenantId" db:"tenant_id"`
+	ContactID   string     `json:"contactId" db:"contact_id"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	Status      string     `json:"status" db:"status"`
+	ExpiresAt   time.Time  `json:"expiresAt" db:"expires_at"`
+	SentAt      *time.Time `json:"sentAt" db:"sent_at"`
+	OpenedAt    *time.Time `json:"openedAt" db:"opened_at"`
+	CompletedAt *time.Time `json:"completedAt" db:"completed_at"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+}
+
+// BirthdayInvitationSubmission is the form the contact posts from the invitation page
+type BirthdayInvitationSubmission struct {
+	Token    string `form:"token"`
+	Birthday string `form:"birthday"`
+	Consent  bool   `form:"consent"`
+}
+
+// Subscription topics a contact can opt out of separately
+const (
+	TopicBirthdayCard      = "birthday_card"
+	TopicBirthdayPromotion = "birthday_promotion"
+	TopicAnniversaries     = "anniversaries"
+	TopicInvitations       = "invitations"
+)
+
+// SubscriptionTopics lists the subscription topics in display order
+var SubscriptionTopics = []string{TopicBirthdayCard, TopicBirthdayPromotion, TopicAnniversaries, TopicInvitations}
+
+// ValidSubscriptionTopic reports whether topic is one of SubscriptionTopics
+func ValidSubscriptionTopic(topic string) bool {
+	for _, t := range SubscriptionTopics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// ContactSubscription is a contact's current state for one topic. Birthday cards are kept in
+// email_contacts.birthday_email_enabled; the other topics in contact_subscriptions, where a
+// missing row means subscribed.
+type ContactSubscription struct {
+	Topic      string     `json:"topic" db:"topic"`
+	Subscribed bool       `json:"subscribed" db:"subscribed"`
+	UpdatedAt  *time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// ContactSubscriptionEvent is one change to a contact's topic subscription
+type ContactSubscriptionEvent struct {
+	ID         string    `json:"id" db:"id"`
+	TenantID   string    `json:"tenantId" db:"tenant_id"`
+	ContactID  string    `json:"contactId" db:"contact_id"`
+	Topic      string    `json:"topic" db:"topic"`
+	Subscribed bool      `json:"subscribed" db:"subscribed"`
+	Source     string    `json:"source" db:"source"` // unsubscribe_page, one_click, mailto, api, ...
+	Reason     *string   `json:"reason" db:"reason"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
+
+// UpdateContactSubscriptionsRequest sets topics on or off; topics left out are unchanged
+type UpdateContactSubscriptionsRequest struct {
+	Topics map[string]bool `json:"topics" binding:"required"`
+	Reason *string         `json:"reason,omitempty"`
 }
 
-// BirthdayUnsubscribeRequest represents the request to unsubscribe from birthday emails
+// Consent event actions
+const (
+	ConsentGranted   = "granted"
+	ConsentWithdrawn = "withdrawn"
+)
+
+// ConsentTopicMarketing is the consent log topic for the general marketing preference
+// (email_contacts.pref_marketing), which isn't a subscription topic of its own
+const ConsentTopicMarketing = "marketing"
+
+// ConsentEvidence says who changed a contact's consent and how, for the consent_events log.
+// ActorUserID is set when a staff user made the change on the contact's behalf.
+type ConsentEvidence struct {
+	Source      string
+	Reason      *string
+	ConsentText *string
+	IPAddress   *string
+	UserAgent   *string
+	ActorUserID *string
+}
+
+// ConsentEvent is one entry in the append-only consent audit log
+type ConsentEvent struct {
+	ID          string    `json:"id" db:"id"`
+	TenantID    string    `json:"tenantId" db:"tenant_id"`
+	ContactID   string    `json:"contactId" db:"contact_id"`
+	Email       string    `json:"email" db:"email"` // Address at the time of the change
+	Topic       string    `json:"topic" db:"topic"`
+	Action      string    `json:"action" db:"action"` // granted, withdrawn
+	Source      string    `json:"source" db:"source"`
+	Reason      *string   `json:"reason" db:"reason"`
+	ConsentText *string   `json:"consentText" db:"consent_text"`
+	IPAddress   *string   `json:"ipAddress" db:"ip_address"`
+	UserAgent   *string   `json:"userAgent" db:"user_agent"`
+	ActorUserID *string   `json:"actorUserId" db:"actor_user_id"`
+	OccurredAt  time.Time `json:"occurredAt" db:"occurred_at"`
+}
+
+// ConsentHistoryExport is a contact's complete consent history for an audit. Document holds
+// the same history as display-ready text for rendering as a PDF.
+type ConsentHistoryExport struct {
+	GeneratedAt   time.Time             `json:"generatedAt"`
+	TenantID      string                `json:"tenantId"`
+	CompanyName   string                `json:"companyName,omitempty"`
+	Contact       ConsentHistoryContact `json:"contact"`
+	Subscriptions []ContactSubscription `json:"subscriptions"`
+	Events        []ConsentEvent        `json:"events"`
+	Document      ConsentDocument       `json:"document"`
+}
+
+// ConsentHistoryContact is the contact's current consent state in a ConsentHistoryExport
+type ConsentHistoryContact struct {
+	ID                   string     `json:"id"`
+	Email                string     `json:"email"`
+	FirstName            *string    `json:"firstName"`
+	LastName             *string    `json:"lastName"`
+	Status               string     `json:"status"`
+	BirthdayEmailEnabled bool       `json:"birthdayEmailEnabled"`
+	PrefMarketing        bool       `json:"prefMarketing"`
+	ConsentGiven         bool       `json:"consentGiven"`
+	ConsentDate          *time.Time `json:"consentDate"`
+	ConsentMethod        *string    `json:"consentMethod"`
+	ConsentIPAddress     *string    `json:"consentIpAddress"`
+	ConsentUserAgent     *string    `json:"consentUserAgent"`
+}
+
+// ConsentDocument is a consent history laid out for a printable report: a title, label/value
+// summary lines and a table with one row per event, oldest first
+type ConsentDocument struct {
+	Title   string                `json:"title"`
+	Summary []ConsentDocumentLine `json:"summary"`
+	Columns []string              `json:"columns"`
+	Rows    [][]string            `json:"rows"`
+}
+
+// ConsentDocumentLine is one label/value line in a ConsentDocument summary
+type ConsentDocumentLine struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// Placeholders for the address and subject of sends kept after a contact is erased
+const (
+	ErasedEmailAddress = "erased@erased.invalid"
+	ErasedSubject      = "[erased]"
+)
+
+// ContactDataExport is everything held about a contact, for a data subject access request
+type ContactDataExport struct {
+	GeneratedAt         time.Time                  `json:"generatedAt"`
+	Contact             EmailContact               `json:"contact"`
+	Subscriptions       []ContactSubscription      `json:"subscriptions"`
+	SubscriptionEvents  []ContactSubscriptionEvent `json:"subscriptionEvents"`
+	ConsentEvents       []ConsentEvent             `json:"consentEvents"`
+	Emails              []ContactDataEmail         `json:"emails"`
+	Activities          []EmailActivity            `json:"activities"`
+	UnsubscribeTokens   []BirthdayUnsubscribeToken `json:"unsubscribeTokens"`
+	BirthdayInvitations []BirthdayInvitation       `json:"birthdayInvitations"`
+}
+
+// ContactDataEmail is an email sent to the contact, with its content and tracking events
+type ContactDataEmail struct {
+	EmailSend
+	HTMLContent *string                 `json:"htmlContent"`
+	TextContent *string                 `json:"textContent"`
+	Events      []ContactDataEmailEvent `json:"events"`
+}
+
+// ContactDataEmailEvent is a provider tracking event for an email in a ContactDataExport
+type ContactDataEmailEvent struct {
+	EventType  string    `json:"eventType" db:"event_type"`
+	EventData  *string   `json:"eventData" db:"event_data"`
+	UserAgent  *string   `json:"userAgent" db:"user_agent"`
+	IPAddress  *string   `json:"ipAddress" db:"ip_address"`
+	OccurredAt time.Time `json:"occurredAt" db:"occurred_at"`
+}
+
+// ContactErasure records a GDPR erasure: how much it removed, and the hash of the address
+// that keeps it from being mailed again
+type ContactErasure struct {
+	ID                       string    `json:"id" db:"id"`
+	TenantID                 string    `json:"tenantId" db:"tenant_id"`
+	ContactID                string    `json:"contactId" db:"contact_id"`
+	EmailHash                string    `json:"emailHash" db:"email_hash"`
+	Reason                   *string   `json:"reason" db:"reason"`
+	RequestedByUserID        *string   `json:"requestedByUserId" db:"requested_by_user_id"`
+	EmailsSent               int       `json:"emailsSent" db:"emails_sent"`
+	EmailsOpened             int       `json:"emailsOpened" db:"emails_opened"`
+	EmailSendsPseudonymised  int       `json:"emailSendsPseudonymised" db:"email_sends_pseudonymised"`
+	EmailContentsDeleted     int       `json:"emailContentsDeleted" db:"email_contents_deleted"`
+	EmailEventsScrubbed      int       `json:"emailEventsScrubbed" db:"email_events_scrubbed"`
+	ActivitiesDeleted        int       `json:"activitiesDeleted" db:"activities_deleted"`
+	UnsubscribeTokensDeleted int       `json:"unsubscribeTokensDeleted" db:"unsubscribe_tokens_deleted"`
+	ErasedAt                 time.Time `json:"erasedAt" db:"erased_at"`
+}
+
+// EraseContactRequest confirms an erasure by repeating the contact's email address
+type EraseContactRequest struct {
+	ConfirmEmail string  `json:"confirmEmail" binding:"required"`
+	Reason       *string `json:"reason,omitempty"`
+}
+
+// Retention content actions
+const (
+	RetentionActionDelete   = "delete"   // Delete the stored HTML and text
+	RetentionActionTruncate = "truncate" // Drop the HTML and keep the start of the text
+)
+
+// RetentionSettings is a tenant's stored retention settings. A nil number of days falls back
+// to the configured default; 0 keeps data forever.
+type RetentionSettings struct {
+	TenantID        string     `json:"tenantId" db:"tenant_id"`
+	ContentDays     *int       `json:"contentDays" db:"content_days"`
+	ContentAction   string     `json:"contentAction" db:"content_action"`
+	WebhookDataDays *int       `json:"webhookDataDays" db:"webhook_data_days"`
+	EventDays       *int       `json:"eventDays" db:"event_days"`
+	CreatedAt       *time.Time `json:"createdAt,omitempty" db:"created_at"`
+	UpdatedAt       *time.Time `json:"updatedAt,omitempty" db:"updated_at"`
+}
+
+// UpdateRetentionSettingsRequest replaces a tenant's retention settings; omitted days use the default
+type UpdateRetentionSettingsRequest struct {
+	ContentDays     *int   `json:"contentDays"`
+	ContentAction   string `json:"contentAction"`
+	WebhookDataDays *int   `json:"webhookDataDays"`
+	EventDays       *int   `json:"eventDays"`
+}
+
+// RetentionPolicy is the retention that applies to a tenant once defaults are filled in
+type RetentionPolicy struct {
+	TenantID        string `json:"tenantId"`
+	ContentDays     int    `json:"contentDays"`
+	ContentAction   string `json:"contentAction"`
+	WebhookDataDays int    `json:"webhookDataDays"`
+	EventDays       int    `json:"eventDays"`
+}
+
+// Empty reports whether the policy keeps everything forever
+func (p RetentionPolicy) Empty() bool {
+	return p.ContentDays <= 0 && p.WebhookDataDays <= 0 && p.EventDays <= 0
+}
+
+// ResolveRetentionPolicy fills in a tenant's unset retention settings from the defaults.
+// settings may be nil when the tenant has none.
+func ResolveRetentionPolicy(tenantID string, settings *RetentionSettings, defaults RetentionPolicy) RetentionPolicy {
+	policy := defaults
+	policy.TenantID = tenantID
+	if policy.ContentAction == "" {
+		policy.ContentAction = RetentionActionDelete
+	}
+	if settings == nil {
+		return policy
+	}
+	if settings.ContentDays != nil {
+		policy.ContentDays = *settings.ContentDays
+	}
+	if settings.ContentAction != "" {
+		policy.ContentAction = settings.ContentAction
+	}
+	if settings.WebhookDataDays != nil {
+		policy.WebhookDataDays = *settings.WebhookDataDays
+	}
+	if settings.EventDays != nil {
+		policy.EventDays = *settings.EventDays
+	}
+	return policy
+}
+
+// RetentionPurgeRun reports what one retention purge removed for a tenant
+type RetentionPurgeRun struct {
+	ID                     string    `json:"id" db:"id"`
+	TenantID               string    `json:"tenantId" db:"tenant_id"`
+	RunID                  string    `json:"runId" db:"run_id"`
+	ContentDeleted         int       `json:"contentDeleted" db:"content_deleted"`
+	ContentTruncated       int       `json:"contentTruncated" db:"content_truncated"`
+	WebhookPayloadsCleared int       `json:"webhookPayloadsCleared" db:"webhook_payloads_cleared"`
+	EventsCompacted        int       `json:"eventsCompacted" db:"events_compacted"`
+	RanAt                  time.Time `json:"ranAt" db:"ran_at"`
+}
+
+// EmailEventDailyStat is the number of email events of one type compacted for a day
+type EmailEventDailyStat struct {
+	Day        string `json:"day" db:"day"` // YYYY-MM-DD
+	EmailType  string `json:"emailType" db:"email_type"`
+	EventType  string `json:"eventType" db:"event_type"`
+	EventCount int    `json:"eventCount" db:"event_count"`
+}
+
+// Where a tenant's daily send limit comes from
+const (
+	DailySendLimitOverride = "override" // active tenant_limits row
+	DailySendLimitPlan     = "plan"     // the tenant's subscription plan
+	DailySendLimitFree     = "free"     // the Free plan, for tenants without a subscription
+	DailySendLimitDefault  = "default"  // DEFAULT_DAILY_SEND_LIMIT
+)
+
+// DailySendLimit is how many emails a tenant may send per UTC day
+type DailySendLimit struct {
+	Limit  *int   `json:"limit"` // nil is unlimited
+	Source string `json:"source"`
+}
+
+// BirthdayUnsubscribeRequest represents the request to unsubscribe from birthday emails.
+// Action "update_topics" saves the topic checkboxes instead: Topics lists the checked ones.
 type BirthdayUnsubscribeRequest struct {
-	Token  string  `json:"token" form:"token"`
-	Reason *string `json:"reason,omitempty" form:"reason"`
+	Token  string   `json:"token" form:"token"`
+	Reason *string  `json:"reason,omitempty" form:"reason"`
+	Action string   `json:"action,omitempty" form:"action"`
+	Topics []string `json:"topics,omitempty" form:"topics"`
 }
 
 // BirthdayUnsubscribeResponse represents the response after unsubscribing
@@ -286,6 +691,25 @@ type SendTestBirthdayCardRequest struct {
 	SenderName            string      `json:"senderName"`
 	PromotionID           *string     `json:"promotionId"`
 	SplitPromotionalEmail *bool       `json:"splitPromotionalEmail"`
+	PromotionDelay        *string     `json:"promotionDelay"` // overrides the tenant's promotion delay
+	PersonalizedCardImage *bool       `json:"personalizedCardImage"`
+	SubjectTemplate       *string     `json:"subjectTemplate"`
+	PreheaderText         *string     `json:"preheaderText"`
+	Language              *string     `json:"language"` // overrides the tenant's default language
+}
+
+// BirthdayPreviewRequest represents a request to render a birthday card without sending it
+type BirthdayPreviewRequest struct {
+	EmailTemplate      string      `json:"emailTemplate"`
+	CustomMessage      string      `json:"customMessage"`
+	CustomThemeData    interface{} `json:"customThemeData"`
+	SenderName         string      `json:"senderName"`
+	RecipientFirstName string      `json:"recipientFirstName"`
+	RecipientLastName  string      `json:"recipientLastName"`
+	PromotionID        *string     `json:"promotionId"`
+	SubjectTemplate    *string     `json:"subjectTemplate"`
+	PreheaderText      *string     `json:"preheaderText"`
+	Language           *string     `json:"language"`
 }
 
 // EmailSend represents the core email sending record in the email_sends table
@@ -307,6 +731,7 @@ type EmailSend struct {
 	NewsletterID      *string    `json:"newsletterId" db:"newsletter_id"`
 	CampaignID        *string    `json:"campaignId" db:"campaign_id"`
 	PromotionID       *string    `json:"promotionId" db:"promotion_id"`
+	SubjectVariant    *string    `json:"subjectVariant" db:"subject_variant"`
 	SentAt            time.Time  `json:"sentAt" db:"sent_at"`
 	CreatedAt         time.Time  `json:"createdAt" db:"created_at"`
 	UpdatedAt         time.Time  `json:"updatedAt" db:"updated_at"`
@@ -341,6 +766,18 @@ type EmailSendWithDetails struct {
 	Events  []EmailEvent   `json:"events,omitempty"`
 }
 
+// SubjectVariantStats represents open and click rates for one A/B subject variant
+type SubjectVariantStats struct {
+	Variant   string  `json:"variant"`
+	Subject   string  `json:"subject,omitempty"`
+	Weight    int     `json:"weight,omitempty"`
+	Sends     int     `json:"sends"`
+	Opens     int     `json:"opens"`
+	Clicks    int     `json:"clicks"`
+	OpenRate  float64 `json:"openRate"`
+	ClickRate float64 `json:"clickRate"`
+}
+
 // OutgoingEmail represents an outgoing email record in the outgoing_emails table (DEPRECATED - use EmailSend instead)
 type OutgoingEmail struct {
 	ID                 string     `json:"id" db:"id"`
@@ -388,6 +825,7 @@ type CreateEmailSendRequest struct {
 	NewsletterID      *string `json:"newsletterId,omitempty"`
 	CampaignID        *string `json:"campaignId,omitempty"`
 	PromotionID       *string `json:"promotionId,omitempty"`
+	SubjectVariant    *string `json:"subjectVariant,omitempty"`
 }
 
 // CreateEmailContentRequest represents the request to create email content
@@ -424,6 +862,7 @@ type CreateCompleteEmailRequest struct {
 	NewsletterID      *string `json:"newsletterId,omitempty"`
 	CampaignID        *string `json:"campaignId,omitempty"`
 	PromotionID       *string `json:"promotionId,omitempty"`
+	SubjectVariant    *string `json:"subjectVariant,omitempty"`
 	HTMLContent       *string `json:"htmlContent,omitempty"`
 	TextContent       *string `json:"textContent,omitempty"`
 	Metadata          *string `json:"metadata,omitempty"`
@@ -459,3 +898,30 @@ type CreateOutgoingEmailRequest struct {
 //
 // HTMLContent        *string    `json:"htmlContent" db:"html_content"`
 // TextContent        *string    `json:"textContent" db:"text_content"`
+
+// ImageAsset represents an uploaded or proxied image in the image_assets table
+type ImageAsset struct {
+	ID                  string              `json:"id" db:"id"`
+	TenantID            *string             `json:"tenantId,omitempty" db:"tenant_id"`
+	SourceURL           *string             `json:"sourceUrl,omitempty" db:"source_url"`
+	OriginalFilename    *string             `json:"originalFilename,omitempty" db:"original_filename"`
+	OriginalContentType string              `json:"originalContentType" db:"original_content_type"`
+	OriginalWidth       int                 `json:"originalWidth" db:"original_width"`
+	OriginalHeight      int                 `json:"originalHeight" db:"original_height"`
+	CreatedAt           time.Time           `json:"createdAt" db:"created_at"`
+	Variants            []ImageAssetVariant `json:"variants"`
+}
+
+// ImageAssetVariant represents a resized copy of an image asset in the image_asset_variants table
+type ImageAssetVariant struct {
+	ID          string    `json:"id" db:"id"`
+	AssetID     string    `json:"assetId" db:"asset_id"`
+	Width       int       `json:"width" db:"width"`
+	Height      int       `json:"height" db:"height"`
+	ContentType string    `json:"contentType" db:"content_type"`
+	ContentHash string    `json:"contentHash" db:"content_hash"`
+	StorageKey  string    `json:"storageKey" db:"storage_key"`
+	SizeBytes   int       `json:"sizeBytes" db:"size_bytes"`
+	URL         string    `json:"url" db:"-"`
+	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
+}
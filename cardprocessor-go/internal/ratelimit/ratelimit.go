@@ -0,0 +1,100 @@
+// Package ratelimit provides in-process token buckets shared by everything in the worker
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter holds one token bucket per key, each refilled at the same rate. A Limiter with a
+// rate of zero allows everything.
+type Limiter struct {
+	mu      sync.Mutex
+	rate    float64 // tokens per second
+	burst   float64
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// New returns a limiter allowing perMinute events per key, with up to burst of them at once.
+// A burst below 1 is raised to 1; perMinute <= 0 disables limiting.
+func New(perMinute, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Reserve takes a token from key's bucket and returns 0, or, when the bucket is empty, takes
+// nothing and returns how long until a token is available
+func (l *Limiter) Reserve(key string) time.Duration {
+	if l == nil || l.rate <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(key)
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	wait := (1 - b.tokens) / l.rate
+	return time.Duration(math.Ceil(wait * float64(time.Second)))
+}
+
+// Refund puts back a token taken by Reserve that ended up unused
+func (l *Limiter) Refund(key string) {
+	if l == nil || l.rate <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(key)
+	b.tokens = math.Min(l.burst, b.tokens+1)
+}
+
+// Block empties key's bucket until the given time, for when the other side has said to back off
+func (l *Limiter) Block(key string, until time.Time) {
+	if l == nil || l.rate <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.refill(key)
+	// Set the balance so the next token comes in at until
+	tokens := 1 - until.Sub(l.now()).Seconds()*l.rate
+	if tokens < b.tokens {
+		b.tokens = tokens
+	}
+}
+
+// refill brings key's bucket up to date, creating it full. Callers hold l.mu.
+func (l *Limiter) refill(key string) *bucket {
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+		return b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+	return b
+}
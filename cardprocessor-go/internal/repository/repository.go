@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -28,7 +31,8 @@ func (r *Repository) GetBirthdaySettings(ctx context.Context, tenantID string) (
 	query := `
 		SELECT id, tenant_id, enabled, email_template, segment_filter, 
 		       custom_message, custom_theme_data, sender_name, promotion_id,
-		       split_promotional_email, created_at, updated_at
+		       split_promotional_email, promotion_delay, personalized_card_image, subject_template, preheader_text,
+		       subject_variants, default_language, localized_messages, created_at, updated_at
 		FROM birthday_settings 
 		WHERE tenant_id = $1
 	`
@@ -45,6 +49,13 @@ func (r *Repository) GetBirthdaySettings(ctx context.Context, tenantID string) (
 		&settings.SenderName,
 		&settings.PromotionID,
 		&settings.SplitPromotionalEmail,
+		&settings.PromotionDelay,
+		&settings.PersonalizedCardImage,
+		&settings.SubjectTemplate,
+		&settings.PreheaderText,
+		&settings.SubjectVariants,
+		&settings.DefaultLanguage,
+		&settings.LocalizedMessages,
 		&settings.CreatedAt,
 		&settings.UpdatedAt,
 	)
@@ -59,6 +70,180 @@ func (r *Repository) GetBirthdaySettings(ctx context.Context, tenantID string) (
 	return &settings, nil
 }
 
+// GetBirthdaySchedule retrieves the schedule stored with a tenant's birthday settings, or nil
+// when the tenant has no birthday settings
+func (r *Repository) GetBirthdaySchedule(ctx context.Context, tenantID string) (*models.BirthdaySchedule, error) {
+	query := `
+		SELECT tenant_id, COALESCE(enabled, false), schedule_id, send_hour, timezone, days_ahead, schedule_paused
+		FROM birthday_settings
+		WHERE tenant_id = $1
+	`
+
+	var schedule models.BirthdaySchedule
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&schedule.TenantID,
+		&schedule.Enabled,
+		&schedule.ScheduleID,
+		&schedule.SendHour,
+		&schedule.Timezone,
+		&schedule.DaysAhead,
+		&schedule.Paused,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get birthday schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+// SaveBirthdaySchedule stores a tenant's birthday schedule with its birthday settings, creating
+// the settings with their defaults when the tenant has none
+func (r *Repository) SaveBirthdaySchedule(ctx context.Context, schedule models.BirthdaySchedule) (*models.BirthdaySchedule, error) {
+	query := `
+		INSERT INTO birthday_settings (tenant_id, schedule_id, send_hour, timezone, days_ahead, schedule_paused, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, now(), now())
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			schedule_id = EXCLUDED.schedule_id,
+			send_hour = EXCLUDED.send_hour,
+			timezone = EXCLUDED.timezone,
+			days_ahead = EXCLUDED.days_ahead,
+			schedule_paused = EXCLUDED.schedule_paused,
+			updated_at = now()
+		RETURNING tenant_id, COALESCE(enabled, false), schedule_id, send_hour, timezone, days_ahead, schedule_paused
+	`
+
+	var saved models.BirthdaySchedule
+	err := r.db.QueryRowContext(ctx, query, schedule.TenantID, schedule.ScheduleID, schedule.SendHour,
+		schedule.Timezone, schedule.DaysAhead, schedule.Paused).Scan(
+		&saved.TenantID,
+		&saved.Enabled,
+		&saved.ScheduleID,
+		&saved.SendHour,
+		&saved.Timezone,
+		&saved.DaysAhead,
+		&saved.Paused,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save birthday schedule: %w", err)
+	}
+
+	return &saved, nil
+}
+
+// GetBirthdaySchedules returns the schedules of all tenants that have one
+func (r *Repository) GetBirthdaySchedules(ctx context.Context) ([]models.BirthdaySchedule, error) {
+	query := `
+		SELECT tenant_id, COALESCE(enabled, false), schedule_id, send_hour, timezone, days_ahead, schedule_paused
+		FROM birthday_settings
+		WHERE schedule_id IS NOT NULL
+		ORDER BY tenant_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get birthday schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]models.BirthdaySchedule, 0)
+	for rows.Next() {
+		var schedule models.BirthdaySchedule
+		if err := rows.Scan(&schedule.TenantID, &schedule.Enabled, &schedule.ScheduleID, &schedule.SendHour,
+			&schedule.Timezone, &schedule.DaysAhead, &schedule.Paused); err != nil {
+			return nil, fmt.Errorf("failed to scan birthday schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read birthday schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// GetContactsWithBirthdayOn returns a tenant's active contacts with birthday emails enabled
+// whose birthday (YYYY-MM-DD) falls on one of the given MM-DD days
+func (r *Repository) GetContactsWithBirthdayOn(ctx context.Context, tenantID string, monthDays ...string) ([]models.EmailContact, error) {
+	query := `
+		SELECT id, tenant_id, email, first_name, last_name, status, birthday, birthday_email_enabled, preferred_language
+		FROM email_contacts` + birthdayContactsFilter + `
+		ORDER BY email
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, strings.Join(monthDays, ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get birthday contacts: %w", err)
+	}
+	defer rows.Close()
+
+	contacts := make([]models.EmailContact, 0)
+	for rows.Next() {
+		var contact models.EmailContact
+		if err := rows.Scan(&contact.ID, &contact.TenantID, &contact.Email, &contact.FirstName, &contact.LastName,
+			&contact.Status, &contact.Birthday, &contact.BirthdayEmailEnabled, &contact.PreferredLanguage); err != nil {
+			return nil, fmt.Errorf("failed to scan birthday contact: %w", err)
+		}
+		contacts = append(contacts, contact)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read birthday contacts: %w", err)
+	}
+
+	return contacts, nil
+}
+
+// birthdayContactsFilter selects a tenant's active contacts with birthday emails enabled whose
+// birthday falls on one of a comma-separated list of MM-DD days ($1 tenant, $2 days)
+const birthdayContactsFilter = `
+		WHERE tenant_id = $1
+		  AND birthday IS NOT NULL
+		  AND birthday_email_enabled = true
+		  AND status = 'active'
+		  AND SUBSTRING(birthday, 6) = ANY(string_to_array($2, ','))`
+
+// CountContactsWithBirthdayOn counts the contacts GetContactsWithBirthdayOn would return
+func (r *Repository) CountContactsWithBirthdayOn(ctx context.Context, tenantID string, monthDays ...string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM email_contacts` + birthdayContactsFilter
+	if err := r.db.QueryRowContext(ctx, query, tenantID, strings.Join(monthDays, ",")).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count birthday contacts: %w", err)
+	}
+	return count, nil
+}
+
+// GetContactIDsWithBirthdayOn returns one page of the IDs GetContactsWithBirthdayOn would
+// return: up to limit IDs in ID order, after afterID ("" starts from the first)
+func (r *Repository) GetContactIDsWithBirthdayOn(ctx context.Context, tenantID, afterID string, limit int, monthDays ...string) ([]string, error) {
+	query := `SELECT id FROM email_contacts` + birthdayContactsFilter + `
+		  AND id > $3
+		ORDER BY id
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, strings.Join(monthDays, ","), afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get birthday contact IDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0, limit)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan birthday contact ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read birthday contact IDs: %w", err)
+	}
+
+	return ids, nil
+}
+
 // CreateBirthdaySettings creates new birthday settings for a tenant
 func (r *Repository) CreateBirthdaySettings(tenantID string, req *models.CreateBirthdaySettingsRequest) (*models.BirthdaySettings, error) {
 	id := uuid.New().String()
@@ -69,23 +254,34 @@ func (r *Repository) CreateBirthdaySettings(tenantID string, req *models.CreateB
 	if req.SplitPromotionalEmail != nil {
 		splitEmail = *req.SplitPromotionalEmail
 	}
+	personalizedImage := false
+	if req.PersonalizedCardImage != nil {
+		personalizedImage = *req.PersonalizedCardImage
+	}
+	defaultLanguage := "en"
+	if req.DefaultLanguage != nil && *req.DefaultLanguage != "" {
+		defaultLanguage = *req.DefaultLanguage
+	}
 
 	query := `
 		INSERT INTO birthday_settings (
 			id, tenant_id, enabled, email_template, segment_filter,
 			custom_message, custom_theme_data, sender_name, promotion_id,
-			split_promotional_email, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			split_promotional_email, promotion_delay, personalized_card_image, subject_template, preheader_text,
+			subject_variants, default_language, localized_messages, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 		RETURNING id, tenant_id, enabled, email_template, segment_filter,
 		          custom_message, custom_theme_data, sender_name, promotion_id,
-		          split_promotional_email, created_at, updated_at
+		          split_promotional_email, promotion_delay, personalized_card_image, subject_template, preheader_text,
+		          subject_variants, default_language, localized_messages, created_at, updated_at
 	`
 
 	var settings models.BirthdaySettings
 	err := r.db.QueryRow(query,
 		id, tenantID, req.Enabled, req.EmailTemplate, req.SegmentFilter,
 		req.CustomMessage, req.CustomThemeData, req.SenderName, req.PromotionID,
-		splitEmail, now, now,
+		splitEmail, req.PromotionDelay, personalizedImage, req.SubjectTemplate, req.PreheaderText, req.SubjectVariants,
+		defaultLanguage, req.LocalizedMessages, now, now,
 	).Scan(
 		&settings.ID,
 		&settings.TenantID,
@@ -97,6 +293,13 @@ func (r *Repository) CreateBirthdaySettings(tenantID string, req *models.CreateB
 		&settings.SenderName,
 		&settings.PromotionID,
 		&settings.SplitPromotionalEmail,
+		&settings.PromotionDelay,
+		&settings.PersonalizedCardImage,
+		&settings.SubjectTemplate,
+		&settings.PreheaderText,
+		&settings.SubjectVariants,
+		&settings.DefaultLanguage,
+		&settings.LocalizedMessages,
 		&settings.CreatedAt,
 		&settings.UpdatedAt,
 	)
@@ -119,6 +322,7 @@ func (r *Repository) UpdateBirthdaySettings(ctx context.Context, settings *model
 	if existingSettings == nil {
 		// Create new settings
 		splitEmail := settings.SplitPromotionalEmail
+		personalizedImage := settings.PersonalizedCardImage
 		req := &models.CreateBirthdaySettingsRequest{
 			Enabled:               settings.Enabled,
 			EmailTemplate:         settings.EmailTemplate,
@@ -128,6 +332,13 @@ func (r *Repository) UpdateBirthdaySettings(ctx context.Context, settings *model
 			SenderName:            settings.SenderName,
 			PromotionID:           settings.PromotionID,
 			SplitPromotionalEmail: &splitEmail,
+			PromotionDelay:        settings.PromotionDelay,
+			PersonalizedCardImage: &personalizedImage,
+			SubjectTemplate:       settings.SubjectTemplate,
+			PreheaderText:         settings.PreheaderText,
+			SubjectVariants:       settings.SubjectVariants,
+			DefaultLanguage:       &settings.DefaultLanguage,
+			LocalizedMessages:     settings.LocalizedMessages,
 		}
 		return r.CreateBirthdaySettings(settings.TenantID, req)
 	}
@@ -137,18 +348,23 @@ func (r *Repository) UpdateBirthdaySettings(ctx context.Context, settings *model
 		UPDATE birthday_settings 
 		SET enabled = $1, email_template = $2, segment_filter = $3,
 		    custom_message = $4, custom_theme_data = $5, sender_name = $6,
-		    promotion_id = $7, split_promotional_email = $8, updated_at = $9
-		WHERE tenant_id = $10
+		    promotion_id = $7, split_promotional_email = $8, personalized_card_image = $9,
+		    subject_template = $10, preheader_text = $11, subject_variants = $12,
+		    default_language = $13, localized_messages = $14, promotion_delay = $15, updated_at = $16
+		WHERE tenant_id = $17
 		RETURNING id, tenant_id, enabled, email_template, segment_filter,
 		          custom_message, custom_theme_data, sender_name, promotion_id,
-		          split_promotional_email, created_at, updated_at
+		          split_promotional_email, promotion_delay, personalized_card_image, subject_template, preheader_text,
+		          subject_variants, default_language, localized_messages, created_at, updated_at
 	`
 
 	var updatedSettings models.BirthdaySettings
 	err = r.db.QueryRowContext(ctx, query,
 		settings.Enabled, settings.EmailTemplate, settings.SegmentFilter,
 		settings.CustomMessage, settings.CustomThemeData, settings.SenderName,
-		settings.PromotionID, settings.SplitPromotionalEmail, time.Now(), settings.TenantID,
+		settings.PromotionID, settings.SplitPromotionalEmail, settings.PersonalizedCardImage,
+		settings.SubjectTemplate, settings.PreheaderText, settings.SubjectVariants,
+		settings.DefaultLanguage, settings.LocalizedMessages, settings.PromotionDelay, time.Now(), settings.TenantID,
 	).Scan(
 		&updatedSettings.ID,
 		&updatedSettings.TenantID,
@@ -160,6 +376,13 @@ func (r *Repository) UpdateBirthdaySettings(ctx context.Context, settings *model
 		&updatedSettings.SenderName,
 		&updatedSettings.PromotionID,
 		&updatedSettings.SplitPromotionalEmail,
+		&updatedSettings.PromotionDelay,
+		&updatedSettings.PersonalizedCardImage,
+		&updatedSettings.SubjectTemplate,
+		&updatedSettings.PreheaderText,
+		&updatedSettings.SubjectVariants,
+		&updatedSettings.DefaultLanguage,
+		&updatedSettings.LocalizedMessages,
 		&updatedSettings.CreatedAt,
 		&updatedSettings.UpdatedAt,
 	)
@@ -181,7 +404,7 @@ func (r *Repository) GetContactsWithBirthdays(tenantID string, date time.Time) (
 		       added_date, last_activity, emails_sent, emails_opened,
 		       birthday, birthday_email_enabled, consent_given, consent_date,
 		       consent_method, consent_ip_address, consent_user_agent,
-		       added_by_user_id, created_at, updated_at
+		       added_by_user_id, preferred_language, pref_marketing, created_at, updated_at
 		FROM email_contacts 
 		WHERE tenant_id = $1 
 		  AND birthday_email_enabled = true 
@@ -218,6 +441,8 @@ func (r *Repository) GetContactsWithBirthdays(tenantID string, date time.Time) (
 			&contact.ConsentIPAddress,
 			&contact.ConsentUserAgent,
 			&contact.AddedByUserID,
+			&contact.PreferredLanguage,
+			&contact.PrefMarketing,
 			&contact.CreatedAt,
 			&contact.UpdatedAt,
 		)
@@ -373,8 +598,9 @@ func (r *Repository) GetUpcomingBirthdayContacts(ctx context.Context, tenantID s
 	return contacts, total, nil
 }
 
-// UpdateBulkBirthdayEmailPreference updates birthday email preference for multiple contacts
-func (r *Repository) UpdateBulkBirthdayEmailPreference(ctx context.Context, tenantID string, contactIDs []string, enabled bool) error {
+// UpdateBulkBirthdayEmailPreference updates birthday email preference for multiple contacts,
+// recording each contact whose preference actually changed in the consent log
+func (r *Repository) UpdateBulkBirthdayEmailPreference(ctx context.Context, tenantID string, contactIDs []string, enabled bool, evidence models.ConsentEvidence) error {
 	if len(contactIDs) == 0 {
 		return nil
 	}
@@ -393,13 +619,44 @@ func (r *Repository) UpdateBulkBirthdayEmailPreference(ctx context.Context, tena
 	query := fmt.Sprintf(`
 		UPDATE email_contacts 
 		SET birthday_email_enabled = $2, updated_at = CURRENT_TIMESTAMP
-		WHERE tenant_id = $1 AND id IN (%s)
+		WHERE tenant_id = $1 AND id IN (%s) AND birthday_email_enabled IS DISTINCT FROM $2
+		RETURNING id
 	`, strings.Join(placeholders, ","))
 
-	_, err := r.db.ExecContext(ctx, query, args...)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update bulk birthday email preference: %w", err)
 	}
+	var changed []string
+	for rows.Next() {
+		var contactID string
+		if err := rows.Scan(&contactID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan updated contact: %w", err)
+		}
+		changed = append(changed, contactID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read updated contacts: %w", err)
+	}
+
+	now := time.Now()
+	for _, contactID := range changed {
+		if err := recordSubscriptionChange(ctx, tx, tenantID, contactID, models.TopicBirthdayCard, enabled, evidence, now); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
 	return nil
 }
@@ -411,7 +668,7 @@ func (r *Repository) GetContactByEmail(tenantID, email string) (*models.EmailCon
 		       added_date, last_activity, emails_sent, emails_opened,
 		       birthday, birthday_email_enabled, consent_given, consent_date,
 		       consent_method, consent_ip_address, consent_user_agent,
-		       added_by_user_id, created_at, updated_at
+		       added_by_user_id, preferred_language, pref_marketing, created_at, updated_at
 		FROM email_contacts 
 		WHERE tenant_id = $1 AND email = $2
 	`
@@ -436,6 +693,8 @@ func (r *Repository) GetContactByEmail(tenantID, email string) (*models.EmailCon
 		&contact.ConsentIPAddress,
 		&contact.ConsentUserAgent,
 		&contact.AddedByUserID,
+		&contact.PreferredLanguage,
+		&contact.PrefMarketing,
 		&contact.CreatedAt,
 		&contact.UpdatedAt,
 	)
@@ -458,7 +717,7 @@ func (r *Repository) GetContactByEmailOnly(email string) (*models.EmailContact,
 		       added_date, last_activity, emails_sent, emails_opened,
 		       birthday, birthday_email_enabled, consent_given, consent_date,
 		       consent_method, consent_ip_address, consent_user_agent,
-		       added_by_user_id, created_at, updated_at
+		       added_by_user_id, preferred_language, pref_marketing, created_at, updated_at
 		FROM email_contacts 
 		WHERE email = $1
 		LIMIT 1
@@ -484,6 +743,8 @@ func (r *Repository) GetContactByEmailOnly(email string) (*models.EmailContact,
 		&contact.ConsentIPAddress,
 		&contact.ConsentUserAgent,
 		&contact.AddedByUserID,
+		&contact.PreferredLanguage,
+		&contact.PrefMarketing,
 		&contact.CreatedAt,
 		&contact.UpdatedAt,
 	)
@@ -498,8 +759,9 @@ func (r *Repository) GetContactByEmailOnly(email string) (*models.EmailContact,
 	return &contact, nil
 }
 
-// UpdateContactBirthday updates a contact's birthday information
-func (r *Repository) UpdateContactBirthday(ctx context.Context, tenantID, contactID string, birthday *string, birthdayEmailEnabled *bool) (*models.EmailContact, error) {
+// UpdateContactBirthday updates a contact's birthday information. A change to the birthday
+// email flag is recorded in the consent log with evidence.
+func (r *Repository) UpdateContactBirthday(ctx context.Context, tenantID, contactID string, birthday *string, birthdayEmailEnabled *bool, preferredLanguage *string, evidence models.ConsentEvidence) (*models.EmailContact, error) {
 	setParts := []string{}
 	args := []interface{}{}
 	argIndex := 1
@@ -514,6 +776,12 @@ func (r *Repository) UpdateContactBirthday(ctx context.Context, tenantID, contac
 		args = append(args, *birthdayEmailEnabled)
 		argIndex++
 	}
+	if preferredLanguage != nil {
+		// An empty string clears the preference so the tenant default applies
+		setParts = append(setParts, fmt.Sprintf("preferred_language = NULLIF($%d, '')", argIndex))
+		args = append(args, *preferredLanguage)
+		argIndex++
+	}
 
 	if len(setParts) == 0 {
 		return r.GetContactByID(ctx, tenantID, contactID)
@@ -535,11 +803,28 @@ func (r *Repository) UpdateContactBirthday(ctx context.Context, tenantID, contac
 		          added_date, last_activity, emails_sent, emails_opened,
 		          birthday, birthday_email_enabled, consent_given, consent_date,
 		          consent_method, consent_ip_address, consent_user_agent,
-		          added_by_user_id, created_at, updated_at
-	`, strings.Join(setParts, ", "), argIndex-1, argIndex)
+		          added_by_user_id, preferred_language, pref_marketing, created_at, updated_at
+	`, strings.Join(setParts, ", "), argIndex, argIndex+1)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the contact and read the flag it had, to tell whether consent changed
+	var wasEnabled bool
+	if birthdayEmailEnabled != nil {
+		err = tx.QueryRowContext(ctx, `
+			SELECT birthday_email_enabled FROM email_contacts WHERE tenant_id = $1 AND id = $2 FOR UPDATE
+		`, tenantID, contactID).Scan(&wasEnabled)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update contact birthday: %w", err)
+		}
+	}
 
 	var contact models.EmailContact
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(
+	err = tx.QueryRowContext(ctx, query, args...).Scan(
 		&contact.ID,
 		&contact.TenantID,
 		&contact.Email,
@@ -558,6 +843,8 @@ func (r *Repository) UpdateContactBirthday(ctx context.Context, tenantID, contac
 		&contact.ConsentIPAddress,
 		&contact.ConsentUserAgent,
 		&contact.AddedByUserID,
+		&contact.PreferredLanguage,
+		&contact.PrefMarketing,
 		&contact.CreatedAt,
 		&contact.UpdatedAt,
 	)
@@ -566,6 +853,16 @@ func (r *Repository) UpdateContactBirthday(ctx context.Context, tenantID, contac
 		return nil, fmt.Errorf("failed to update contact birthday: %w", err)
 	}
 
+	if birthdayEmailEnabled != nil && *birthdayEmailEnabled != wasEnabled {
+		if err := recordSubscriptionChange(ctx, tx, tenantID, contactID, models.TopicBirthdayCard, *birthdayEmailEnabled, evidence, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &contact, nil
 }
 
@@ -576,7 +873,7 @@ func (r *Repository) GetContactByID(ctx context.Context, tenantID, contactID str
 		       added_date, last_activity, emails_sent, emails_opened,
 		       birthday, birthday_email_enabled, consent_given, consent_date,
 		       consent_method, consent_ip_address, consent_user_agent,
-		       added_by_user_id, created_at, updated_at
+		       added_by_user_id, preferred_language, pref_marketing, created_at, updated_at
 		FROM email_contacts 
 		WHERE tenant_id = $1 AND id = $2
 	`
@@ -601,6 +898,8 @@ func (r *Repository) GetContactByID(ctx context.Context, tenantID, contactID str
 		&contact.ConsentIPAddress,
 		&contact.ConsentUserAgent,
 		&contact.AddedByUserID,
+		&contact.PreferredLanguage,
+		&contact.PrefMarketing,
 		&contact.CreatedAt,
 		&contact.UpdatedAt,
 	)
@@ -615,6 +914,76 @@ func (r *Repository) GetContactByID(ctx context.Context, tenantID, contactID str
 	return &contact, nil
 }
 
+// UpdateContactPreferences saves the contact's own changes from the preference center.
+// Turning birthday cards off records it like an unsubscribe; turning them on clears that.
+// Changes to birthday cards or marketing are recorded in the consent log with evidence.
+func (r *Repository) UpdateContactPreferences(ctx context.Context, tenantID, contactID string, req models.ContactPreferencesRequest, evidence models.ConsentEvidence) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the contact and read the consent it had, to tell what changed
+	var birthdayEnabled, marketing bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT birthday_email_enabled, pref_marketing FROM email_contacts WHERE tenant_id = $1 AND id = $2 FOR UPDATE
+	`, tenantID, contactID).Scan(&birthdayEnabled, &marketing)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("contact not found")
+		}
+		return fmt.Errorf("failed to get contact preferences: %w", err)
+	}
+
+	now := time.Now()
+	query := `
+		UPDATE email_contacts
+		SET first_name = NULLIF($1, ''),
+		    last_name = NULLIF($2, ''),
+		    birthday = NULLIF($3, ''),
+		    preferred_language = NULLIF($4, ''),
+		    birthday_unsubscribe_reason = CASE WHEN $5 THEN NULL WHEN birthday_email_enabled THEN 'preference_center' ELSE birthday_unsubscribe_reason END,
+		    birthday_unsubscribed_at = CASE WHEN $5 THEN NULL WHEN birthday_email_enabled THEN $7 ELSE birthday_unsubscribed_at END,
+		    birthday_email_enabled = $5,
+		    pref_marketing = $6,
+		    updated_at = $7
+		WHERE tenant_id = $8 AND id = $9
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		strings.TrimSpace(req.FirstName),
+		strings.TrimSpace(req.LastName),
+		req.Birthday,
+		req.Language,
+		req.BirthdayEmails,
+		req.PromotionalEmails,
+		now,
+		tenantID,
+		contactID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update contact preferences: %w", err)
+	}
+
+	if req.BirthdayEmails != birthdayEnabled {
+		if err := recordSubscriptionChange(ctx, tx, tenantID, contactID, models.TopicBirthdayCard, req.BirthdayEmails, evidence, now); err != nil {
+			return err
+		}
+	}
+	if req.PromotionalEmails != marketing {
+		if err := recordConsentEvent(ctx, tx, tenantID, contactID, models.ConsentTopicMarketing, req.PromotionalEmails, evidence, now); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
 // CreateEmailActivity creates a new email activity record
 func (r *Repository) CreateEmailActivity(activity *models.EmailActivity) error {
 	if activity.ID == "" {
@@ -875,128 +1244,1453 @@ func (r *Repository) ResetBirthdayUnsubscribeToken(ctx context.Context, tokenID
 	return nil
 }
 
-// GetPromotion retrieves a promotion by ID and tenant ID
-func (r *Repository) GetPromotion(ctx context.Context, promotionID, tenantID string) (*models.Promotion, error) {
+// ErrInvitationUsed is returned when a birthday invitation has already been completed
+var ErrInvitationUsed = errors.New("birthday invitation already used")
+
+// birthdayInvitationColumns is the column list scanned by scanBirthdayInvitation
+const birthdayInvitationColumns = `id, tenant_id, contact_id, token_hash, status, expires_at, sent_at, opened_at, completed_at, created_at`
+
+// scanBirthdayInvitation scans a row selected with birthdayInvitationColumns
+func scanBirthdayInvitation(row *sql.Row) (*models.BirthdayInvitation, error) {
+	var invitation models.BirthdayInvitation
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.TenantID,
+		&invitation.ContactID,
+		&invitation.TokenHash,
+		&invitation.Status,
+		&invitation.ExpiresAt,
+		&invitation.SentAt,
+		&invitation.OpenedAt,
+		&invitation.CompletedAt,
+		&invitation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// CreateBirthdayInvitation records a new pending invitation, keyed by the hash of its token
+func (r *Repository) CreateBirthdayInvitation(ctx context.Context, tenantID, contactID, tokenHash string, expiresAt time.Time) (*models.BirthdayInvitation, error) {
 	query := `
-		SELECT id, tenant_id, user_id, title, description, content, type, 
-		       target_audience, is_active, usage_count, max_uses, 
-		       valid_from, valid_to, created_at, updated_at
-		FROM promotions 
-		WHERE id = $1 AND tenant_id = $2
-	`
+		INSERT INTO birthday_invitations (id, tenant_id, contact_id, token_hash, status, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + birthdayInvitationColumns
 
-	var promotion models.Promotion
-	err := r.db.QueryRowContext(ctx, query, promotionID, tenantID).Scan(
-		&promotion.ID,
-		&promotion.TenantID,
-		&promotion.UserID,
-		&promotion.Title,
-		&promotion.Description,
-		&promotion.Content,
-		&promotion.Type,
-		&promotion.TargetAudience,
-		&promotion.IsActive,
-		&promotion.UsageCount,
-		&promotion.MaxUses,
-		&promotion.ValidFrom,
-		&promotion.ValidTo,
-		&promotion.CreatedAt,
-		&promotion.UpdatedAt,
-	)
+	invitation, err := scanBirthdayInvitation(r.db.QueryRowContext(ctx, query,
+		uuid.New().String(), tenantID, contactID, tokenHash, models.InvitationStatusPending, expiresAt, time.Now()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create birthday invitation: %w", err)
+	}
+
+	return invitation, nil
+}
+
+// GetBirthdayInvitationByTokenHash retrieves an invitation by the hash of its token
+func (r *Repository) GetBirthdayInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.BirthdayInvitation, error) {
+	query := `SELECT ` + birthdayInvitationColumns + ` FROM birthday_invitations WHERE token_hash = $1`
 
+	invitation, err := scanBirthdayInvitation(r.db.QueryRowContext(ctx, query, tokenHash))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to get promotion: %w", err)
+		return nil, fmt.Errorf("failed to get birthday invitation: %w", err)
 	}
 
-	return &promotion, nil
+	return invitation, nil
 }
 
-// CreateOutgoingEmailRecord creates a new outgoing email tracking record
-func (r *Repository) CreateOutgoingEmailRecord(ctx context.Context, req *models.CreateOutgoingEmailRequest) (*models.OutgoingEmail, error) {
-	id := uuid.New().String()
-	now := time.Now()
-
+// GetLatestBirthdayInvitation retrieves the most recent invitation sent to a contact
+func (r *Repository) GetLatestBirthdayInvitation(ctx context.Context, tenantID, contactID string) (*models.BirthdayInvitation, error) {
 	query := `
-		INSERT INTO outgoing_emails (
-			id, tenant_id, recipient_email, recipient_name, sender_email, sender_name,
-			subject, email_type, provider, provider_message_id, provider_response,
-			status, send_attempts, error_message, contact_id, newsletter_id, 
-			campaign_id, promotion_id, metadata, html_content, text_content, sent_at, created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, 
-			$16, $17, $18, $19, $20, $21, $22, $23, $24
-		)
-		RETURNING id, tenant_id, recipient_email, recipient_name, sender_email, 
-		          sender_name, subject, email_type, provider, provider_message_id, 
-		          provider_response, status, send_attempts, error_message, contact_id, 
-		          newsletter_id, campaign_id, promotion_id, metadata, html_content, text_content, sent_at, 
-		          delivered_at, created_at, updated_at
+		SELECT ` + birthdayInvitationColumns + `
+		FROM birthday_invitations
+		WHERE tenant_id = $1 AND contact_id = $2
+		ORDER BY created_at DESC
+		LIMIT 1
 	`
 
-	var email models.OutgoingEmail
-	err := r.db.QueryRowContext(ctx, query,
-		id, req.TenantID, req.RecipientEmail, req.RecipientName, req.SenderEmail, 
-		req.SenderName, req.Subject, req.EmailType, req.Provider, req.ProviderMessageID,
-		req.ProviderResponse, req.Status, req.SendAttempts, req.ErrorMessage, 
-		req.ContactID, req.NewsletterID, req.CampaignID, req.PromotionID, 
-		req.Metadata, req.HTMLContent, req.TextContent, now, now, now,
-	).Scan(
-		&email.ID, &email.TenantID, &email.RecipientEmail, &email.RecipientName,
-		&email.SenderEmail, &email.SenderName, &email.Subject, &email.EmailType,
-		&email.Provider, &email.ProviderMessageID, &email.ProviderResponse,
-		&email.Status, &email.SendAttempts, &email.ErrorMessage, &email.ContactID,
-		&email.NewsletterID, &email.CampaignID, &email.PromotionID, &email.Metadata,
-		&email.HTMLContent, &email.TextContent, &email.SentAt, &email.DeliveredAt, &email.CreatedAt, &email.UpdatedAt,
-	)
-
+	invitation, err := scanBirthdayInvitation(r.db.QueryRowContext(ctx, query, tenantID, contactID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create outgoing email record: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest birthday invitation: %w", err)
 	}
 
-	return &email, nil
+	return invitation, nil
 }
 
-// ===== NEW SPLIT TABLE METHODS =====
-
-// CreateEmailSend creates a new email send record in the email_sends table
-func (r *Repository) CreateEmailSend(ctx context.Context, req *models.CreateEmailSendRequest) (*models.EmailSend, error) {
-	id := uuid.New().String()
-	now := time.Now()
+// MarkBirthdayInvitationSent records the outcome of sending an invitation email: "sent"
+// with its send time, or "failed"
+func (r *Repository) MarkBirthdayInvitationSent(ctx context.Context, tenantID, invitationID string, sent bool, sentAt time.Time) error {
+	status := models.InvitationStatusFailed
+	if sent {
+		status = models.InvitationStatusSent
+	}
 
 	query := `
-		INSERT INTO email_sends (
-			id, tenant_id, recipient_email, recipient_name, sender_email, sender_name,
-			subject, email_type, provider, provider_message_id, status, send_attempts, 
-			error_message, contact_id, newsletter_id, campaign_id, promotion_id, 
-			sent_at, created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
-		)
-		RETURNING id, tenant_id, recipient_email, recipient_name, sender_email, 
-		          sender_name, subject, email_type, provider, provider_message_id, 
-		          status, send_attempts, error_message, contact_id, newsletter_id, 
-		          campaign_id, promotion_id, sent_at, created_at, updated_at
+		UPDATE birthday_invitations
+		SET status = $1,
+		    sent_at = CASE WHEN $2 THEN $3 ELSE sent_at END
+		WHERE tenant_id = $4 AND id = $5 AND status = $6
 	`
 
-	var emailSend models.EmailSend
-	err := r.db.QueryRowContext(ctx, query,
-		id, req.TenantID, req.RecipientEmail, req.RecipientName, req.SenderEmail, 
-		req.SenderName, req.Subject, req.EmailType, req.Provider, req.ProviderMessageID,
-		req.Status, req.SendAttempts, req.ErrorMessage, req.ContactID, req.NewsletterID, 
-		req.CampaignID, req.PromotionID, now, now, now,
-	).Scan(
-		&emailSend.ID, &emailSend.TenantID, &emailSend.RecipientEmail, &emailSend.RecipientName,
-		&emailSend.SenderEmail, &emailSend.SenderName, &emailSend.Subject, &emailSend.EmailType,
-		&emailSend.Provider, &emailSend.ProviderMessageID, &emailSend.Status, &emailSend.SendAttempts,
-		&emailSend.ErrorMessage, &emailSend.ContactID, &emailSend.NewsletterID, &emailSend.CampaignID,
-		&emailSend.PromotionID, &emailSend.SentAt, &emailSend.CreatedAt, &emailSend.UpdatedAt,
-	)
-
+	_, err := r.db.ExecContext(ctx, query, status, sent, sentAt, tenantID, invitationID, models.InvitationStatusPending)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create email send record: %w", err)
+		return fmt.Errorf("failed to mark birthday invitation as sent: %w", err)
+	}
+
+	return nil
+}
+
+// MarkBirthdayInvitationOpened records the first time the contact opened the invitation page
+func (r *Repository) MarkBirthdayInvitationOpened(ctx context.Context, invitationID string) error {
+	query := `
+		UPDATE birthday_invitations
+		SET status = $1,
+		    opened_at = COALESCE(opened_at, $2)
+		WHERE id = $3 AND completed_at IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, models.InvitationStatusOpened, time.Now(), invitationID)
+	if err != nil {
+		return fmt.Errorf("failed to mark birthday invitation as opened: %w", err)
+	}
+
+	return nil
+}
+
+// CompleteBirthdayInvitation spends the invitation and saves the contact's birthday, opting
+// them in to birthday emails. It returns ErrInvitationUsed if the invitation was already
+// completed, so a token can only ever be used once. The opt-in is recorded in the consent log
+// with evidence, which should carry the consent wording the contact agreed to.
+func (r *Repository) CompleteBirthdayInvitation(ctx context.Context, invitation *models.BirthdayInvitation, birthday string, evidence models.ConsentEvidence) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE birthday_invitations
+		SET status = $1,
+		    completed_at = $2,
+		    opened_at = COALESCE(opened_at, $2)
+		WHERE id = $3 AND completed_at IS NULL
+	`, models.InvitationStatusCompleted, now, invitation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to complete birthday invitation: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check completed birthday invitation: %w", err)
+	}
+	if rows == 0 {
+		return ErrInvitationUsed
+	}
+
+	var wasEnabled bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT birthday_email_enabled FROM email_contacts WHERE tenant_id = $1 AND id = $2 FOR UPDATE
+	`, invitation.TenantID, invitation.ContactID).Scan(&wasEnabled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("contact not found")
+		}
+		return fmt.Errorf("failed to get contact: %w", err)
+	}
+
+	result, err = tx.ExecContext(ctx, `
+		UPDATE email_contacts
+		SET birthday = $1,
+		    birthday_email_enabled = true,
+		    birthday_unsubscribe_reason = NULL,
+		    birthday_unsubscribed_at = NULL,
+		    updated_at = $2
+		WHERE tenant_id = $3 AND id = $4
+	`, birthday, now, invitation.TenantID, invitation.ContactID)
+	if err != nil {
+		return fmt.Errorf("failed to save contact birthday: %w", err)
+	}
+	rows, err = result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check saved contact birthday: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("contact not found")
+	}
+
+	// The explicit opt-in is evidence even when birthday emails were already on, but only a
+	// change belongs in the subscription history
+	if wasEnabled {
+		err = recordConsentEvent(ctx, tx, invitation.TenantID, invitation.ContactID, models.TopicBirthdayCard, true, evidence, now)
+	} else {
+		err = recordSubscriptionChange(ctx, tx, invitation.TenantID, invitation.ContactID, models.TopicBirthdayCard, true, evidence, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// contactSubscriptionsQuery selects the contact's birthday card flag once per stored topic row,
+// or once with NULL topic columns when the contact has none
+const contactSubscriptionsQuery = `
+	SELECT c.birthday_email_enabled, c.birthday_unsubscribed_at, s.topic, s.subscribed, s.updated_at
+	FROM email_contacts c
+	LEFT JOIN contact_subscriptions s ON s.contact_id = c.id
+	WHERE c.tenant_id = $1 AND c.id = $2
+`
+
+// subscriptionQueryer is the part of *sql.DB and *sql.Tx that queryContactSubscriptions needs
+type subscriptionQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// GetContactSubscriptions returns the contact's state for every subscription topic, in
+// models.SubscriptionTopics order, or nil if the contact doesn't exist
+func (r *Repository) GetContactSubscriptions(ctx context.Context, tenantID, contactID string) ([]models.ContactSubscription, error) {
+	return queryContactSubscriptions(ctx, r.db, contactSubscriptionsQuery, tenantID, contactID)
+}
+
+func queryContactSubscriptions(ctx context.Context, q subscriptionQueryer, query, tenantID, contactID string) ([]models.ContactSubscription, error) {
+	rows, err := q.QueryContext(ctx, query, tenantID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	found := false
+	var birthdayEnabled bool
+	var birthdayUnsubscribedAt *time.Time
+	stored := make(map[string]models.ContactSubscription)
+	for rows.Next() {
+		var topic sql.NullString
+		var subscribed sql.NullBool
+		var updatedAt *time.Time
+		if err := rows.Scan(&birthdayEnabled, &birthdayUnsubscribedAt, &topic, &subscribed, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan contact subscription: %w", err)
+		}
+		found = true
+		if topic.Valid {
+			stored[topic.String] = models.ContactSubscription{Topic: topic.String, Subscribed: subscribed.Bool, UpdatedAt: updatedAt}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read contact subscriptions: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	subscriptions := make([]models.ContactSubscription, 0, len(models.SubscriptionTopics))
+	for _, topic := range models.SubscriptionTopics {
+		switch sub, ok := stored[topic]; {
+		case topic == models.TopicBirthdayCard:
+			subscriptions = append(subscriptions, models.ContactSubscription{Topic: topic, Subscribed: birthdayEnabled, UpdatedAt: birthdayUnsubscribedAt})
+		case ok:
+			subscriptions = append(subscriptions, sub)
+		default:
+			subscriptions = append(subscriptions, models.ContactSubscription{Topic: topic, Subscribed: true})
+		}
+	}
+	return subscriptions, nil
+}
+
+// SetContactSubscriptions turns the given topics on or off for a contact and records each
+// actual change in contact_subscription_events and the consent log. It returns the topics
+// that changed.
+func (r *Repository) SetContactSubscriptions(ctx context.Context, tenantID, contactID string, topics map[string]bool, evidence models.ConsentEvidence) ([]string, error) {
+	for topic := range topics {
+		if !models.ValidSubscriptionTopic(topic) {
+			return nil, fmt.Errorf("unknown subscription topic %q", topic)
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the contact so concurrent changes record a consistent history
+	current, err := queryContactSubscriptions(ctx, tx, contactSubscriptionsQuery+" FOR UPDATE OF c", tenantID, contactID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("contact not found")
+	}
+
+	now := time.Now()
+	var changed []string
+	for _, sub := range current {
+		subscribed, ok := topics[sub.Topic]
+		if !ok || subscribed == sub.Subscribed {
+			continue
+		}
+
+		if sub.Topic == models.TopicBirthdayCard {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE email_contacts
+				SET birthday_email_enabled = $1,
+				    birthday_unsubscribe_reason = CASE WHEN $1 THEN NULL ELSE $2::text END,
+				    birthday_unsubscribed_at = CASE WHEN $1 THEN NULL ELSE $3::timestamp END,
+				    updated_at = $3
+				WHERE tenant_id = $4 AND id = $5
+			`, subscribed, evidence.Reason, now, tenantID, contactID)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO contact_subscriptions (tenant_id, contact_id, topic, subscribed, updated_at)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (contact_id, topic) DO UPDATE
+				SET subscribed = EXCLUDED.subscribed, updated_at = EXCLUDED.updated_at
+			`, tenantID, contactID, sub.Topic, subscribed, now)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s subscription: %w", sub.Topic, err)
+		}
+
+		if err := recordSubscriptionChange(ctx, tx, tenantID, contactID, sub.Topic, subscribed, evidence, now); err != nil {
+			return nil, err
+		}
+		changed = append(changed, sub.Topic)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return changed, nil
+}
+
+// recordSubscriptionChange appends a topic change to contact_subscription_events and to the
+// consent log
+func recordSubscriptionChange(ctx context.Context, tx *sql.Tx, tenantID, contactID, topic string, subscribed bool, evidence models.ConsentEvidence, at time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO contact_subscription_events (tenant_id, contact_id, topic, subscribed, source, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, tenantID, contactID, topic, subscribed, evidence.Source, evidence.Reason, at)
+	if err != nil {
+		return fmt.Errorf("failed to record subscription change: %w", err)
+	}
+
+	return recordConsentEvent(ctx, tx, tenantID, contactID, topic, subscribed, evidence, at)
+}
+
+// recordConsentEvent appends a consent change to consent_events along with the contact's
+// current email address, so the entry still says who consented once the address changes
+func recordConsentEvent(ctx context.Context, tx *sql.Tx, tenantID, contactID, topic string, granted bool, evidence models.ConsentEvidence, at time.Time) error {
+	action := models.ConsentWithdrawn
+	if granted {
+		action = models.ConsentGranted
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO consent_events (tenant_id, contact_id, email, topic, action, source, reason,
+		                            consent_text, ip_address, user_agent, actor_user_id, occurred_at)
+		SELECT tenant_id, id, email, $3::text, $4::text, $5::text, $6::text,
+		       $7::text, $8::text, $9::text, $10::text, $11::timestamp
+		FROM email_contacts
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, contactID, topic, action, evidence.Source, evidence.Reason,
+		evidence.ConsentText, evidence.IPAddress, evidence.UserAgent, evidence.ActorUserID, at)
+	if err != nil {
+		return fmt.Errorf("failed to record consent event: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check recorded consent event: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("contact not found")
+	}
+
+	return nil
+}
+
+// IsContactSubscribed reports whether email on topic may be sent to the contact. Birthday
+// promotions also need the contact's general marketing preference. A missing contact is
+// reported as not subscribed.
+func (r *Repository) IsContactSubscribed(ctx context.Context, tenantID, contactID, topic string) (bool, error) {
+	query := `
+		SELECT c.birthday_email_enabled, c.pref_marketing, COALESCE(s.subscribed, true)
+		FROM email_contacts c
+		LEFT JOIN contact_subscriptions s ON s.contact_id = c.id AND s.topic = $3
+		WHERE c.tenant_id = $1 AND c.id = $2
+	`
+
+	var birthdayEnabled, marketing, subscribed bool
+	err := r.db.QueryRowContext(ctx, query, tenantID, contactID, topic).Scan(&birthdayEnabled, &marketing, &subscribed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check contact subscription: %w", err)
+	}
+
+	switch topic {
+	case models.TopicBirthdayCard:
+		return birthdayEnabled, nil
+	case models.TopicBirthdayPromotion:
+		return marketing && subscribed, nil
+	default:
+		return subscribed, nil
+	}
+}
+
+// GetContactSubscriptionEvents returns the contact's subscription changes, newest first. A
+// limit of zero returns them all.
+func (r *Repository) GetContactSubscriptionEvents(ctx context.Context, tenantID, contactID string, limit int) ([]models.ContactSubscriptionEvent, error) {
+	query := `
+		SELECT id, tenant_id, contact_id, topic, subscribed, source, reason, created_at
+		FROM contact_subscription_events
+		WHERE tenant_id = $1 AND contact_id = $2
+		ORDER BY created_at DESC
+		LIMIT NULLIF($3::int, 0)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, contactID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact subscription events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]models.ContactSubscriptionEvent, 0)
+	for rows.Next() {
+		var event models.ContactSubscriptionEvent
+		if err := rows.Scan(&event.ID, &event.TenantID, &event.ContactID, &event.Topic, &event.Subscribed, &event.Source, &event.Reason, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan contact subscription event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read contact subscription events: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetConsentEvents returns a contact's complete consent log, oldest first. Entries are kept
+// after the contact itself is deleted.
+func (r *Repository) GetConsentEvents(ctx context.Context, tenantID, contactID string) ([]models.ConsentEvent, error) {
+	query := `
+		SELECT id, tenant_id, contact_id, email, topic, action, source, reason,
+		       consent_text, ip_address, user_agent, actor_user_id, occurred_at
+		FROM consent_events
+		WHERE tenant_id = $1 AND contact_id = $2
+		ORDER BY occurred_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consent events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]models.ConsentEvent, 0)
+	for rows.Next() {
+		var event models.ConsentEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.TenantID,
+			&event.ContactID,
+			&event.Email,
+			&event.Topic,
+			&event.Action,
+			&event.Source,
+			&event.Reason,
+			&event.ConsentText,
+			&event.IPAddress,
+			&event.UserAgent,
+			&event.ActorUserID,
+			&event.OccurredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan consent event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read consent events: %w", err)
+	}
+
+	return events, nil
+}
+
+// contactSendsCondition matches the email_sends (and legacy outgoing_emails) rows sent to a
+// contact: those linked to it, and those to its address without a link, such as test sends.
+// The parameters are tenant ID, contact ID and email address.
+const contactSendsCondition = `tenant_id = $1 AND (contact_id = $2 OR lower(recipient_email) = lower($3))`
+
+// emailHash is the suppression hash of an email address: hex SHA-256 of the trimmed,
+// lowercased address
+func emailHash(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+// getContactPersonalData retrieves every email_contacts column for a contact, including the
+// address fields GetContactByID leaves out
+func (r *Repository) getContactPersonalData(ctx context.Context, tenantID, contactID string) (*models.EmailContact, error) {
+	query := `
+		SELECT id, tenant_id, email, first_name, last_name, status,
+		       added_date, last_activity, emails_sent, emails_opened,
+		       birthday, birthday_email_enabled, birthday_unsubscribe_reason, birthday_unsubscribed_at,
+		       preferred_language, pref_marketing, consent_given, consent_date,
+		       consent_method, consent_ip_address, consent_user_agent, added_by_user_id,
+		       address, city, state, zip_code, country, phone_number, created_at, updated_at
+		FROM email_contacts
+		WHERE tenant_id = $1 AND id = $2
+	`
+
+	var contact models.EmailContact
+	err := r.db.QueryRowContext(ctx, query, tenantID, contactID).Scan(
+		&contact.ID,
+		&contact.TenantID,
+		&contact.Email,
+		&contact.FirstName,
+		&contact.LastName,
+		&contact.Status,
+		&contact.AddedDate,
+		&contact.LastActivity,
+		&contact.EmailsSent,
+		&contact.EmailsOpened,
+		&contact.Birthday,
+		&contact.BirthdayEmailEnabled,
+		&contact.BirthdayUnsubscribeReason,
+		&contact.BirthdayUnsubscribedAt,
+		&contact.PreferredLanguage,
+		&contact.PrefMarketing,
+		&contact.ConsentGiven,
+		&contact.ConsentDate,
+		&contact.ConsentMethod,
+		&contact.ConsentIPAddress,
+		&contact.ConsentUserAgent,
+		&contact.AddedByUserID,
+		&contact.Address,
+		&contact.City,
+		&contact.State,
+		&contact.ZipCode,
+		&contact.Country,
+		&contact.PhoneNumber,
+		&contact.CreatedAt,
+		&contact.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get contact personal data: %w", err)
+	}
+
+	return &contact, nil
+}
+
+// GetContactDataExport collects everything held about a contact for a data subject access
+// request: the contact row, subscriptions and their history, the consent log, every email
+// sent to them with its content and tracking events, their activity, unsubscribe tokens and
+// birthday invitations. It returns nil if the contact doesn't exist.
+func (r *Repository) GetContactDataExport(ctx context.Context, tenantID, contactID string) (*models.ContactDataExport, error) {
+	contact, err := r.getContactPersonalData(ctx, tenantID, contactID)
+	if err != nil || contact == nil {
+		return nil, err
+	}
+
+	export := &models.ContactDataExport{
+		GeneratedAt: time.Now().UTC(),
+		Contact:     *contact,
+	}
+
+	if export.Subscriptions, err = r.GetContactSubscriptions(ctx, tenantID, contactID); err != nil {
+		return nil, err
+	}
+	if export.SubscriptionEvents, err = r.GetContactSubscriptionEvents(ctx, tenantID, contactID, 0); err != nil {
+		return nil, err
+	}
+	if export.ConsentEvents, err = r.GetConsentEvents(ctx, tenantID, contactID); err != nil {
+		return nil, err
+	}
+	if export.Emails, err = r.getContactEmails(ctx, tenantID, contactID, contact.Email); err != nil {
+		return nil, err
+	}
+	if export.Activities, err = r.getContactActivities(ctx, tenantID, contactID); err != nil {
+		return nil, err
+	}
+	if export.UnsubscribeTokens, err = r.getContactUnsubscribeTokens(ctx, tenantID, contactID); err != nil {
+		return nil, err
+	}
+	if export.BirthdayInvitations, err = r.getContactBirthdayInvitations(ctx, tenantID, contactID); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// getContactEmails returns the emails sent to a contact, oldest first, with their content and
+// tracking events
+func (r *Repository) getContactEmails(ctx context.Context, tenantID, contactID, email string) ([]models.ContactDataEmail, error) {
+	query := `
+		SELECT s.id, s.tenant_id, s.recipient_email, s.recipient_name, s.sender_email,
+		       s.sender_name, s.subject, s.email_type, s.provider, s.provider_message_id,
+		       s.status, s.send_attempts, s.error_message, s.contact_id, s.newsletter_id,
+		       s.campaign_id, s.promotion_id, s.subject_variant, COALESCE(s.sent_at, s.created_at), s.created_at, s.updated_at,
+		       c.html_content, c.text_content
+		FROM email_sends s
+		LEFT JOIN LATERAL (
+			SELECT html_content, text_content FROM email_content WHERE email_send_id = s.id ORDER BY created_at LIMIT 1
+		) c ON true
+		WHERE s.id IN (SELECT id FROM email_sends WHERE ` + contactSendsCondition + `)
+		ORDER BY s.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, contactID, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact emails: %w", err)
+	}
+	defer rows.Close()
+
+	emails := make([]models.ContactDataEmail, 0)
+	index := make(map[string]int)
+	for rows.Next() {
+		var e models.ContactDataEmail
+		err := rows.Scan(
+			&e.ID, &e.TenantID, &e.RecipientEmail, &e.RecipientName,
+			&e.SenderEmail, &e.SenderName, &e.Subject, &e.EmailType,
+			&e.Provider, &e.ProviderMessageID, &e.Status, &e.SendAttempts,
+			&e.ErrorMessage, &e.ContactID, &e.NewsletterID, &e.CampaignID,
+			&e.PromotionID, &e.SubjectVariant, &e.SentAt, &e.CreatedAt, &e.UpdatedAt,
+			&e.HTMLContent, &e.TextContent,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contact email: %w", err)
+		}
+		e.Events = make([]models.ContactDataEmailEvent, 0)
+		index[e.ID] = len(emails)
+		emails = append(emails, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read contact emails: %w", err)
+	}
+	rows.Close()
+
+	eventRows, err := r.db.QueryContext(ctx, `
+		SELECT e.email_send_id, e.event_type, e.event_data, e.user_agent, e.ip_address, e.occurred_at
+		FROM email_events e
+		WHERE e.email_send_id IN (SELECT id FROM email_sends WHERE `+contactSendsCondition+`)
+		ORDER BY e.occurred_at
+	`, tenantID, contactID, email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact email events: %w", err)
+	}
+	defer eventRows.Close()
+
+	for eventRows.Next() {
+		var sendID string
+		var event models.ContactDataEmailEvent
+		if err := eventRows.Scan(&sendID, &event.EventType, &event.EventData, &event.UserAgent, &event.IPAddress, &event.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan contact email event: %w", err)
+		}
+		if i, ok := index[sendID]; ok {
+			emails[i].Events = append(emails[i].Events, event)
+		}
+	}
+	if err := eventRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read contact email events: %w", err)
+	}
+
+	return emails, nil
+}
+
+// getContactActivities returns a contact's email_activity rows, oldest first
+func (r *Repository) getContactActivities(ctx context.Context, tenantID, contactID string) ([]models.EmailActivity, error) {
+	query := `
+		SELECT id, tenant_id, contact_id, campaign_id, newsletter_id, activity_type, activity_data,
+		       user_agent, ip_address, webhook_id, webhook_data, occurred_at, created_at
+		FROM email_activity
+		WHERE tenant_id = $1 AND contact_id = $2
+		ORDER BY occurred_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact activities: %w", err)
+	}
+	defer rows.Close()
+
+	activities := make([]models.EmailActivity, 0)
+	for rows.Next() {
+		var a models.EmailActivity
+		err := rows.Scan(
+			&a.ID, &a.TenantID, &a.ContactID, &a.CampaignID, &a.NewsletterID, &a.ActivityType, &a.ActivityData,
+			&a.UserAgent, &a.IPAddress, &a.WebhookID, &a.WebhookData, &a.OccurredAt, &a.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contact activity: %w", err)
+		}
+		activities = append(activities, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read contact activities: %w", err)
+	}
+
+	return activities, nil
+}
+
+// getContactUnsubscribeTokens returns a contact's legacy birthday unsubscribe tokens
+func (r *Repository) getContactUnsubscribeTokens(ctx context.Context, tenantID, contactID string) ([]models.BirthdayUnsubscribeToken, error) {
+	query := `
+		SELECT id, tenant_id, contact_id, token, used, created_at, used_at
+		FROM birthday_unsubscribe_tokens
+		WHERE tenant_id = $1 AND contact_id = $2
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact unsubscribe tokens: %w", err)
+	}
+	defer rows.Close()
+
+	unsubTokens := make([]models.BirthdayUnsubscribeToken, 0)
+	for rows.Next() {
+		var t models.BirthdayUnsubscribeToken
+		if err := rows.Scan(&t.ID, &t.TenantID, &t.ContactID, &t.Token, &t.Used, &t.CreatedAt, &t.UsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan contact unsubscribe token: %w", err)
+		}
+		t.Scope = "birthday"
+		unsubTokens = append(unsubTokens, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read contact unsubscribe tokens: %w", err)
+	}
+
+	return unsubTokens, nil
+}
+
+// getContactBirthdayInvitations returns the birthday invitations sent to a contact
+func (r *Repository) getContactBirthdayInvitations(ctx context.Context, tenantID, contactID string) ([]models.BirthdayInvitation, error) {
+	query := `
+		SELECT id, tenant_id, contact_id, token_hash, status, expires_at, sent_at, opened_at, completed_at, created_at
+		FROM birthday_invitations
+		WHERE tenant_id = $1 AND contact_id = $2
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, contactID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact birthday invitations: %w", err)
+	}
+	defer rows.Close()
+
+	invitations := make([]models.BirthdayInvitation, 0)
+	for rows.Next() {
+		var inv models.BirthdayInvitation
+		err := rows.Scan(&inv.ID, &inv.TenantID, &inv.ContactID, &inv.TokenHash, &inv.Status,
+			&inv.ExpiresAt, &inv.SentAt, &inv.OpenedAt, &inv.CompletedAt, &inv.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan contact birthday invitation: %w", err)
+		}
+		invitations = append(invitations, inv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read contact birthday invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// EraseContact carries out a GDPR erasure in one transaction. Email content, activity and
+// legacy unsubscribe tokens are deleted, and so is the contact, which takes its subscriptions,
+// subscription history and invitations with it. Sends and their tracking events are kept for
+// aggregate reporting with the address, name, subject and event payloads removed, and the
+// consent log keeps its entries without the address, IP address or user agent. The erasure is
+// recorded with the hash of the address, which IsEmailSuppressed checks before every send. It
+// returns nil if the contact doesn't exist.
+func (r *Repository) EraseContact(ctx context.Context, tenantID, contactID string, reason, requestedByUserID *string) (*models.ContactErasure, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	erasure := models.ContactErasure{
+		TenantID:          tenantID,
+		ContactID:         contactID,
+		Reason:            reason,
+		RequestedByUserID: requestedByUserID,
+	}
+
+	var email string
+	err = tx.QueryRowContext(ctx, `
+		SELECT email, COALESCE(emails_sent, 0), COALESCE(emails_opened, 0)
+		FROM email_contacts
+		WHERE tenant_id = $1 AND id = $2
+		FOR UPDATE
+	`, tenantID, contactID).Scan(&email, &erasure.EmailsSent, &erasure.EmailsOpened)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get contact: %w", err)
+	}
+	erasure.EmailHash = emailHash(email)
+
+	// exec runs one erasure step and returns how many rows it touched
+	exec := func(step, query string, args ...interface{}) (int, error) {
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to %s: %w", step, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to check %s: %w", step, err)
+		}
+		return int(rows), nil
+	}
+
+	// Content and events first: they find their sends by the address the sends still carry
+	sends := `SELECT id FROM email_sends WHERE ` + contactSendsCondition
+	if erasure.EmailContentsDeleted, err = exec("delete email content",
+		`DELETE FROM email_content WHERE email_send_id IN (`+sends+`)`, tenantID, contactID, email); err != nil {
+		return nil, err
+	}
+	if erasure.EmailEventsScrubbed, err = exec("scrub email events",
+		`UPDATE email_events SET event_data = NULL, user_agent = NULL, ip_address = NULL WHERE email_send_id IN (`+sends+`)`,
+		tenantID, contactID, email); err != nil {
+		return nil, err
+	}
+	if erasure.EmailSendsPseudonymised, err = exec("pseudonymise email sends", `
+		UPDATE email_sends
+		SET recipient_email = $4, recipient_name = NULL, subject = $5, error_message = NULL,
+		    contact_id = NULL, updated_at = $6
+		WHERE `+contactSendsCondition,
+		tenantID, contactID, email, models.ErasedEmailAddress, models.ErasedSubject, time.Now()); err != nil {
+		return nil, err
+	}
+	if _, err = exec("pseudonymise outgoing emails", `
+		UPDATE outgoing_emails
+		SET recipient_email = $4, recipient_name = NULL, subject = $5, html_content = NULL,
+		    text_content = NULL, provider_response = NULL, metadata = NULL, error_message = NULL,
+		    contact_id = NULL, updated_at = $6
+		WHERE `+contactSendsCondition,
+		tenantID, contactID, email, models.ErasedEmailAddress, models.ErasedSubject, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if erasure.ActivitiesDeleted, err = exec("delete email activity",
+		`DELETE FROM email_activity WHERE tenant_id = $1 AND contact_id = $2`, tenantID, contactID); err != nil {
+		return nil, err
+	}
+	if erasure.UnsubscribeTokensDeleted, err = exec("delete unsubscribe tokens",
+		`DELETE FROM birthday_unsubscribe_tokens WHERE tenant_id = $1 AND contact_id = $2`, tenantID, contactID); err != nil {
+		return nil, err
+	}
+
+	// consent_events is append-only; the trigger lets this transaction scrub it
+	if _, err = tx.ExecContext(ctx, `SET LOCAL app.consent_events_erasure = 'on'`); err != nil {
+		return nil, fmt.Errorf("failed to allow consent log erasure: %w", err)
+	}
+	if _, err = exec("scrub consent events", `
+		UPDATE consent_events
+		SET email = $3, ip_address = NULL, user_agent = NULL, reason = NULL
+		WHERE tenant_id = $1 AND contact_id = $2
+	`, tenantID, contactID, models.ErasedEmailAddress); err != nil {
+		return nil, err
+	}
+
+	if _, err = exec("delete contact", `DELETE FROM email_contacts WHERE tenant_id = $1 AND id = $2`, tenantID, contactID); err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO contact_erasures (
+			tenant_id, contact_id, email_hash, reason, requested_by_user_id, emails_sent, emails_opened,
+			email_sends_pseudonymised, email_contents_deleted, email_events_scrubbed,
+			activities_deleted, unsubscribe_tokens_deleted
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, erased_at
+	`, erasure.TenantID, erasure.ContactID, erasure.EmailHash, erasure.Reason, erasure.RequestedByUserID,
+		erasure.EmailsSent, erasure.EmailsOpened, erasure.EmailSendsPseudonymised, erasure.EmailContentsDeleted,
+		erasure.EmailEventsScrubbed, erasure.ActivitiesDeleted, erasure.UnsubscribeTokensDeleted,
+	).Scan(&erasure.ID, &erasure.ErasedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record contact erasure: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &erasure, nil
+}
+
+// IsEmailSuppressed reports whether a contact with this address was erased from the tenant,
+// in which case nothing may be sent to it again
+func (r *Repository) IsEmailSuppressed(ctx context.Context, tenantID, email string) (bool, error) {
+	var suppressed bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM contact_erasures WHERE tenant_id = $1 AND email_hash = $2)
+	`, tenantID, emailHash(email)).Scan(&suppressed)
+	if err != nil {
+		return false, fmt.Errorf("failed to check email suppression: %w", err)
+	}
+	return suppressed, nil
+}
+
+// retentionTruncatedTextLength is how much of an email's text is kept when content is truncated
+const retentionTruncatedTextLength = 500
+
+// GetRetentionSettings retrieves a tenant's retention settings, or nil when it has none
+func (r *Repository) GetRetentionSettings(ctx context.Context, tenantID string) (*models.RetentionSettings, error) {
+	query := `
+		SELECT tenant_id, content_days, content_action, webhook_data_days, event_days, created_at, updated_at
+		FROM email_retention_settings
+		WHERE tenant_id = $1
+	`
+
+	var settings models.RetentionSettings
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&settings.TenantID,
+		&settings.ContentDays,
+		&settings.ContentAction,
+		&settings.WebhookDataDays,
+		&settings.EventDays,
+		&settings.CreatedAt,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get retention settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// UpsertRetentionSettings replaces a tenant's retention settings
+func (r *Repository) UpsertRetentionSettings(ctx context.Context, tenantID string, req models.UpdateRetentionSettingsRequest) (*models.RetentionSettings, error) {
+	query := `
+		INSERT INTO email_retention_settings (tenant_id, content_days, content_action, webhook_data_days, event_days, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, now(), now())
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			content_days = EXCLUDED.content_days,
+			content_action = EXCLUDED.content_action,
+			webhook_data_days = EXCLUDED.webhook_data_days,
+			event_days = EXCLUDED.event_days,
+			updated_at = now()
+		RETURNING tenant_id, content_days, content_action, webhook_data_days, event_days, created_at, updated_at
+	`
+
+	var settings models.RetentionSettings
+	err := r.db.QueryRowContext(ctx, query, tenantID, req.ContentDays, req.ContentAction, req.WebhookDataDays, req.EventDays).Scan(
+		&settings.TenantID,
+		&settings.ContentDays,
+		&settings.ContentAction,
+		&settings.WebhookDataDays,
+		&settings.EventDays,
+		&settings.CreatedAt,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save retention settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// GetRetentionPolicies resolves the retention policy of every tenant, filling in unset
+// settings from the defaults
+func (r *Repository) GetRetentionPolicies(ctx context.Context, defaults models.RetentionPolicy) ([]models.RetentionPolicy, error) {
+	query := `
+		SELECT t.id, s.content_days, COALESCE(s.content_action, ''), s.webhook_data_days, s.event_days
+		FROM tenants t
+		LEFT JOIN email_retention_settings s ON s.tenant_id = t.id
+		ORDER BY t.id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := make([]models.RetentionPolicy, 0)
+	for rows.Next() {
+		// Tenants without settings scan as all unset and get the defaults
+		var tenantID string
+		var settings models.RetentionSettings
+		if err := rows.Scan(&tenantID, &settings.ContentDays, &settings.ContentAction, &settings.WebhookDataDays, &settings.EventDays); err != nil {
+			return nil, fmt.Errorf("failed to scan retention policy: %w", err)
+		}
+		policies = append(policies, models.ResolveRetentionPolicy(tenantID, &settings, defaults))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read retention policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// PurgeEmailContent deletes or truncates up to limit of a tenant's email_content rows created
+// before the cutoff, returning how many it changed. Truncated rows keep the start of the text
+// and are not counted again.
+func (r *Repository) PurgeEmailContent(ctx context.Context, tenantID string, before time.Time, action string, limit int) (int, error) {
+	var query string
+	args := []interface{}{tenantID, before, limit}
+	if action == models.RetentionActionTruncate {
+		query = `
+			UPDATE email_content
+			SET html_content = NULL, text_content = LEFT(text_content, $4), updated_at = now()
+			WHERE id IN (
+				SELECT c.id FROM email_content c
+				JOIN email_sends s ON s.id = c.email_send_id
+				WHERE s.tenant_id = $1 AND c.created_at < $2
+				  AND (c.html_content IS NOT NULL OR LENGTH(c.text_content) > $4)
+				LIMIT $3
+			)
+		`
+		args = append(args, retentionTruncatedTextLength)
+	} else {
+		query = `
+			DELETE FROM email_content
+			WHERE id IN (
+				SELECT c.id FROM email_content c
+				JOIN email_sends s ON s.id = c.email_send_id
+				WHERE s.tenant_id = $1 AND c.created_at < $2
+				LIMIT $3
+			)
+		`
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge email content: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get purged email content count: %w", err)
+	}
+	return int(affected), nil
+}
+
+// ClearWebhookPayloads removes the raw webhook payload from up to limit of a tenant's
+// email_activity rows that occurred before the cutoff, returning how many it cleared
+func (r *Repository) ClearWebhookPayloads(ctx context.Context, tenantID string, before time.Time, limit int) (int, error) {
+	query := `
+		UPDATE email_activity
+		SET webhook_data = NULL
+		WHERE id IN (
+			SELECT id FROM email_activity
+			WHERE tenant_id = $1 AND occurred_at < $2 AND webhook_data IS NOT NULL
+			LIMIT $3
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, tenantID, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear webhook payloads: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get cleared webhook payload count: %w", err)
+	}
+	return int(affected), nil
+}
+
+// CompactEmailEvents deletes up to limit of a tenant's email_events that occurred before the
+// cutoff and adds them to the daily counts in email_event_daily_stats, returning how many it
+// compacted. Both happen in one statement, so a failure never loses or double counts events.
+func (r *Repository) CompactEmailEvents(ctx context.Context, tenantID string, before time.Time, limit int) (int, error) {
+	query := `
+		WITH compacted AS (
+			DELETE FROM email_events
+			WHERE id IN (
+				SELECT e.id FROM email_events e
+				JOIN email_sends s ON s.id = e.email_send_id
+				WHERE s.tenant_id = $1 AND e.occurred_at < $2
+				LIMIT $3
+			)
+			RETURNING email_send_id, event_type, occurred_at
+		), counted AS (
+			INSERT INTO email_event_daily_stats (tenant_id, day, email_type, event_type, event_count)
+			SELECT s.tenant_id, c.occurred_at::date, s.email_type, c.event_type, COUNT(*)
+			FROM compacted c
+			JOIN email_sends s ON s.id = c.email_send_id
+			GROUP BY s.tenant_id, c.occurred_at::date, s.email_type, c.event_type
+			ON CONFLICT (tenant_id, day, email_type, event_type)
+			DO UPDATE SET event_count = email_event_daily_stats.event_count + EXCLUDED.event_count
+		)
+		SELECT COUNT(*) FROM compacted
+	`
+
+	var compacted int
+	if err := r.db.QueryRowContext(ctx, query, tenantID, before, limit).Scan(&compacted); err != nil {
+		return 0, fmt.Errorf("failed to compact email events: %w", err)
+	}
+	return compacted, nil
+}
+
+// GetEmailEventDailyStats returns a tenant's compacted daily event counts from the given day on,
+// newest first
+func (r *Repository) GetEmailEventDailyStats(ctx context.Context, tenantID string, since time.Time) ([]models.EmailEventDailyStat, error) {
+	query := `
+		SELECT to_char(day, 'YYYY-MM-DD'), email_type, event_type, event_count
+		FROM email_event_daily_stats
+		WHERE tenant_id = $1 AND day >= $2::date
+		ORDER BY day DESC, email_type, event_type
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email event daily stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]models.EmailEventDailyStat, 0)
+	for rows.Next() {
+		var stat models.EmailEventDailyStat
+		if err := rows.Scan(&stat.Day, &stat.EmailType, &stat.EventType, &stat.EventCount); err != nil {
+			return nil, fmt.Errorf("failed to scan email event daily stat: %w", err)
+		}
+		stats = append(stats, stat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read email event daily stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// CreateRetentionPurgeRun records what a retention purge removed for a tenant
+func (r *Repository) CreateRetentionPurgeRun(ctx context.Context, run *models.RetentionPurgeRun) error {
+	query := `
+		INSERT INTO retention_purge_runs (tenant_id, run_id, content_deleted, content_truncated,
+		                                  webhook_payloads_cleared, events_compacted, ran_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query, run.TenantID, run.RunID, run.ContentDeleted, run.ContentTruncated,
+		run.WebhookPayloadsCleared, run.EventsCompacted, run.RanAt).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to record retention purge run: %w", err)
+	}
+	return nil
+}
+
+// GetRetentionPurgeRuns returns a tenant's most recent retention purge runs, newest first
+func (r *Repository) GetRetentionPurgeRuns(ctx context.Context, tenantID string, limit int) ([]models.RetentionPurgeRun, error) {
+	query := `
+		SELECT id, tenant_id, run_id, content_deleted, content_truncated,
+		       webhook_payloads_cleared, events_compacted, ran_at
+		FROM retention_purge_runs
+		WHERE tenant_id = $1
+		ORDER BY ran_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get retention purge runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]models.RetentionPurgeRun, 0)
+	for rows.Next() {
+		var run models.RetentionPurgeRun
+		if err := rows.Scan(&run.ID, &run.TenantID, &run.RunID, &run.ContentDeleted, &run.ContentTruncated,
+			&run.WebhookPayloadsCleared, &run.EventsCompacted, &run.RanAt); err != nil {
+			return nil, fmt.Errorf("failed to scan retention purge run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read retention purge runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// SaveBirthdayRun writes the summary row of a finished birthday batch run. Saving the same
+// workflow again replaces its row, so a retried activity records the run once.
+func (r *Repository) SaveBirthdayRun(ctx context.Context, run *models.BirthdayJobProgress) error {
+	query := `
+		INSERT INTO birthday_runs (tenant_id, workflow_id, run_id, trigger, status, birthday_date,
+		                           total_contacts, processed_count, sent_count, skipped_count,
+		                           already_sent_count, failed_count, started_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::date, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (workflow_id) DO UPDATE SET
+			run_id = EXCLUDED.run_id,
+			status = EXCLUDED.status,
+			birthday_date = EXCLUDED.birthday_date,
+			total_contacts = EXCLUDED.total_contacts,
+			processed_count = EXCLUDED.processed_count,
+			sent_count = EXCLUDED.sent_count,
+			skipped_count = EXCLUDED.skipped_count,
+			already_sent_count = EXCLUDED.already_sent_count,
+			failed_count = EXCLUDED.failed_count,
+			completed_at = EXCLUDED.completed_at
+		RETURNING id
+	`
+
+	err := r.db.QueryRowContext(ctx, query, run.TenantID, run.WorkflowID, run.RunID, run.Trigger, run.Status,
+		run.BirthdayDate, run.TotalContacts, run.ProcessedCount, run.SentCount, run.SkippedCount,
+		run.AlreadySentCount, run.FailedCount, run.StartedAt, run.CompletedAt).Scan(&run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save birthday run: %w", err)
+	}
+	return nil
+}
+
+const birthdayRunColumns = `
+	id, tenant_id, workflow_id, run_id, trigger, status, COALESCE(to_char(birthday_date, 'YYYY-MM-DD'), ''),
+	total_contacts, processed_count, sent_count, skipped_count, already_sent_count, failed_count,
+	started_at, completed_at
+`
+
+// scanBirthdayRun scans a row selected with birthdayRunColumns
+func scanBirthdayRun(row interface{ Scan(...interface{}) error }, run *models.BirthdayJobProgress) error {
+	return row.Scan(&run.ID, &run.TenantID, &run.WorkflowID, &run.RunID, &run.Trigger, &run.Status,
+		&run.BirthdayDate, &run.TotalContacts, &run.ProcessedCount, &run.SentCount, &run.SkippedCount,
+		&run.AlreadySentCount, &run.FailedCount, &run.StartedAt, &run.CompletedAt)
+}
+
+// GetBirthdayRun returns the summary of a tenant's finished birthday batch run, or nil while
+// the run has not finished
+func (r *Repository) GetBirthdayRun(ctx context.Context, tenantID, workflowID string) (*models.BirthdayJobProgress, error) {
+	query := `SELECT ` + birthdayRunColumns + ` FROM birthday_runs WHERE tenant_id = $1 AND workflow_id = $2`
+
+	var run models.BirthdayJobProgress
+	err := scanBirthdayRun(r.db.QueryRowContext(ctx, query, tenantID, workflowID), &run)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get birthday run: %w", err)
+	}
+	return &run, nil
+}
+
+// GetBirthdayRuns returns a tenant's most recent finished birthday batch runs, newest first
+func (r *Repository) GetBirthdayRuns(ctx context.Context, tenantID string, limit int) ([]models.BirthdayJobProgress, error) {
+	query := `SELECT ` + birthdayRunColumns + ` FROM birthday_runs WHERE tenant_id = $1 ORDER BY started_at DESC LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get birthday runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]models.BirthdayJobProgress, 0)
+	for rows.Next() {
+		var run models.BirthdayJobProgress
+		if err := scanBirthdayRun(rows, &run); err != nil {
+			return nil, fmt.Errorf("failed to scan birthday run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read birthday runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// GetPromotion retrieves a promotion by ID and tenant ID
+func (r *Repository) GetPromotion(ctx context.Context, promotionID, tenantID string) (*models.Promotion, error) {
+	query := `
+		SELECT id, tenant_id, user_id, title, description, content, type, 
+		       target_audience, is_active, usage_count, max_uses, 
+		       valid_from, valid_to, created_at, updated_at
+		FROM promotions 
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	var promotion models.Promotion
+	err := r.db.QueryRowContext(ctx, query, promotionID, tenantID).Scan(
+		&promotion.ID,
+		&promotion.TenantID,
+		&promotion.UserID,
+		&promotion.Title,
+		&promotion.Description,
+		&promotion.Content,
+		&promotion.Type,
+		&promotion.TargetAudience,
+		&promotion.IsActive,
+		&promotion.UsageCount,
+		&promotion.MaxUses,
+		&promotion.ValidFrom,
+		&promotion.ValidTo,
+		&promotion.CreatedAt,
+		&promotion.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get promotion: %w", err)
+	}
+
+	return &promotion, nil
+}
+
+// CreateOutgoingEmailRecord creates a new outgoing email tracking record
+func (r *Repository) CreateOutgoingEmailRecord(ctx context.Context, req *models.CreateOutgoingEmailRequest) (*models.OutgoingEmail, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	query := `
+		INSERT INTO outgoing_emails (
+			id, tenant_id, recipient_email, recipient_name, sender_email, sender_name,
+			subject, email_type, provider, provider_message_id, provider_response,
+			status, send_attempts, error_message, contact_id, newsletter_id, 
+			campaign_id, promotion_id, metadata, html_content, text_content, sent_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, 
+			$16, $17, $18, $19, $20, $21, $22, $23, $24
+		)
+		RETURNING id, tenant_id, recipient_email, recipient_name, sender_email, 
+		          sender_name, subject, email_type, provider, provider_message_id, 
+		          provider_response, status, send_attempts, error_message, contact_id, 
+		          newsletter_id, campaign_id, promotion_id, metadata, html_content, text_content, sent_at, 
+		          delivered_at, created_at, updated_at
+	`
+
+	var email models.OutgoingEmail
+	err := r.db.QueryRowContext(ctx, query,
+		id, req.TenantID, req.RecipientEmail, req.RecipientName, req.SenderEmail, 
+		req.SenderName, req.Subject, req.EmailType, req.Provider, req.ProviderMessageID,
+		req.ProviderResponse, req.Status, req.SendAttempts, req.ErrorMessage, 
+		req.ContactID, req.NewsletterID, req.CampaignID, req.PromotionID, 
+		req.Metadata, req.HTMLContent, req.TextContent, now, now, now,
+	).Scan(
+		&email.ID, &email.TenantID, &email.RecipientEmail, &email.RecipientName,
+		&email.SenderEmail, &email.SenderName, &email.Subject, &email.EmailType,
+		&email.Provider, &email.ProviderMessageID, &email.ProviderResponse,
+		&email.Status, &email.SendAttempts, &email.ErrorMessage, &email.ContactID,
+		&email.NewsletterID, &email.CampaignID, &email.PromotionID, &email.Metadata,
+		&email.HTMLContent, &email.TextContent, &email.SentAt, &email.DeliveredAt, &email.CreatedAt, &email.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create outgoing email record: %w", err)
+	}
+
+	return &email, nil
+}
+
+// ===== NEW SPLIT TABLE METHODS =====
+
+// GetDailySendLimit resolves a tenant's daily send limit the way the app resolves its monthly
+// limit: an active tenant_limits override, else the subscription plan, else the Free plan, else
+// fallback. A fallback of 0 is unlimited.
+func (r *Repository) GetDailySendLimit(ctx context.Context, tenantID string, fallback int) (models.DailySendLimit, error) {
+	query := `
+		SELECT
+			(SELECT daily_email_limit FROM tenant_limits
+			 WHERE tenant_id = $1 AND is_active = true AND (expires_at IS NULL OR expires_at > now())
+			 LIMIT 1),
+			EXISTS (SELECT 1 FROM subscriptions WHERE tenant_id = $1),
+			(SELECT p.daily_email_limit FROM subscriptions s
+			 JOIN subscription_plans p ON p.id = s.plan_id
+			 WHERE s.tenant_id = $1
+			 ORDER BY s.created_at DESC
+			 LIMIT 1),
+			EXISTS (SELECT 1 FROM subscription_plans WHERE name = 'Free'),
+			(SELECT daily_email_limit FROM subscription_plans WHERE name = 'Free' LIMIT 1)
+	`
+
+	var override, planLimit, freeLimit sql.NullInt64
+	var hasSubscription, hasFreePlan bool
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(&override, &hasSubscription, &planLimit, &hasFreePlan, &freeLimit)
+	if err != nil {
+		return models.DailySendLimit{}, fmt.Errorf("failed to get daily send limit: %w", err)
+	}
+
+	limitOf := func(v sql.NullInt64) *int {
+		if !v.Valid {
+			return nil
+		}
+		n := int(v.Int64)
+		return &n
+	}
+
+	switch {
+	case override.Valid:
+		return models.DailySendLimit{Limit: limitOf(override), Source: models.DailySendLimitOverride}, nil
+	case hasSubscription:
+		return models.DailySendLimit{Limit: limitOf(planLimit), Source: models.DailySendLimitPlan}, nil
+	case hasFreePlan:
+		return models.DailySendLimit{Limit: limitOf(freeLimit), Source: models.DailySendLimitFree}, nil
+	}
+
+	limit := models.DailySendLimit{Source: models.DailySendLimitDefault}
+	if fallback > 0 {
+		limit.Limit = &fallback
+	}
+	return limit, nil
+}
+
+// CountEmailSendsSince counts the emails a tenant has sent since the given time. Sends that
+// failed don't count.
+func (r *Repository) CountEmailSendsSince(ctx context.Context, tenantID string, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM email_sends WHERE tenant_id = $1 AND created_at >= $2 AND status <> 'failed'`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, tenantID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count email sends: %w", err)
+	}
+	return count, nil
+}
+
+// CreateEmailSend creates a new email send record in the email_sends table
+func (r *Repository) CreateEmailSend(ctx context.Context, req *models.CreateEmailSendRequest) (*models.EmailSend, error) {
+	id := uuid.New().String()
+	now := time.Now()
+
+	query := `
+		INSERT INTO email_sends (
+			id, tenant_id, recipient_email, recipient_name, sender_email, sender_name,
+			subject, email_type, provider, provider_message_id, status, send_attempts, 
+			error_message, contact_id, newsletter_id, campaign_id, promotion_id, 
+			subject_variant, sent_at, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
+		)
+		RETURNING id, tenant_id, recipient_email, recipient_name, sender_email, 
+		          sender_name, subject, email_type, provider, provider_message_id, 
+		          status, send_attempts, error_message, contact_id, newsletter_id, 
+		          campaign_id, promotion_id, subject_variant, sent_at, created_at, updated_at
+	`
+
+	var emailSend models.EmailSend
+	err := r.db.QueryRowContext(ctx, query,
+		id, req.TenantID, req.RecipientEmail, req.RecipientName, req.SenderEmail, 
+		req.SenderName, req.Subject, req.EmailType, req.Provider, req.ProviderMessageID,
+		req.Status, req.SendAttempts, req.ErrorMessage, req.ContactID, req.NewsletterID, 
+		req.CampaignID, req.PromotionID, req.SubjectVariant, now, now, now,
+	).Scan(
+		&emailSend.ID, &emailSend.TenantID, &emailSend.RecipientEmail, &emailSend.RecipientName,
+		&emailSend.SenderEmail, &emailSend.SenderName, &emailSend.Subject, &emailSend.EmailType,
+		&emailSend.Provider, &emailSend.ProviderMessageID, &emailSend.Status, &emailSend.SendAttempts,
+		&emailSend.ErrorMessage, &emailSend.ContactID, &emailSend.NewsletterID, &emailSend.CampaignID,
+		&emailSend.PromotionID, &emailSend.SubjectVariant, &emailSend.SentAt, &emailSend.CreatedAt, &emailSend.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create email send record: %w", err)
 	}
 
 	return &emailSend, nil
@@ -1086,6 +2780,7 @@ func (r *Repository) CreateCompleteEmail(ctx context.Context, req *models.Create
 		NewsletterID:      req.NewsletterID,
 		CampaignID:        req.CampaignID,
 		PromotionID:       req.PromotionID,
+		SubjectVariant:    req.SubjectVariant,
 	}
 
 	emailSend, err := r.createEmailSendTx(ctx, tx, emailSendReq)
@@ -1130,14 +2825,14 @@ func (r *Repository) createEmailSendTx(ctx context.Context, tx *sql.Tx, req *mod
 			id, tenant_id, recipient_email, recipient_name, sender_email, sender_name,
 			subject, email_type, provider, provider_message_id, status, send_attempts, 
 			error_message, contact_id, newsletter_id, campaign_id, promotion_id, 
-			sent_at, created_at, updated_at
+			subject_variant, sent_at, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
 		)
 		RETURNING id, tenant_id, recipient_email, recipient_name, sender_email, 
 		          sender_name, subject, email_type, provider, provider_message_id, 
 		          status, send_attempts, error_message, contact_id, newsletter_id, 
-		          campaign_id, promotion_id, sent_at, created_at, updated_at
+		          campaign_id, promotion_id, subject_variant, sent_at, created_at, updated_at
 	`
 
 	var emailSend models.EmailSend
@@ -1145,13 +2840,13 @@ func (r *Repository) createEmailSendTx(ctx context.Context, tx *sql.Tx, req *mod
 		id, req.TenantID, req.RecipientEmail, req.RecipientName, req.SenderEmail, 
 		req.SenderName, req.Subject, req.EmailType, req.Provider, req.ProviderMessageID,
 		req.Status, req.SendAttempts, req.ErrorMessage, req.ContactID, req.NewsletterID, 
-		req.CampaignID, req.PromotionID, now, now, now,
+		req.CampaignID, req.PromotionID, req.SubjectVariant, now, now, now,
 	).Scan(
 		&emailSend.ID, &emailSend.TenantID, &emailSend.RecipientEmail, &emailSend.RecipientName,
 		&emailSend.SenderEmail, &emailSend.SenderName, &emailSend.Subject, &emailSend.EmailType,
 		&emailSend.Provider, &emailSend.ProviderMessageID, &emailSend.Status, &emailSend.SendAttempts,
 		&emailSend.ErrorMessage, &emailSend.ContactID, &emailSend.NewsletterID, &emailSend.CampaignID,
-		&emailSend.PromotionID, &emailSend.SentAt, &emailSend.CreatedAt, &emailSend.UpdatedAt,
+		&emailSend.PromotionID, &emailSend.SubjectVariant, &emailSend.SentAt, &emailSend.CreatedAt, &emailSend.UpdatedAt,
 	)
 
 	if err != nil {
@@ -1213,7 +2908,7 @@ func (r *Repository) GetEmailSendByProviderMessageID(ctx context.Context, provid
 		SELECT id, tenant_id, recipient_email, recipient_name, sender_email, 
 		       sender_name, subject, email_type, provider, provider_message_id, 
 		       status, send_attempts, error_message, contact_id, newsletter_id, 
-		       campaign_id, promotion_id, sent_at, created_at, updated_at
+		       campaign_id, promotion_id, subject_variant, sent_at, created_at, updated_at
 		FROM email_sends
 		WHERE provider_message_id = $1
 	`
@@ -1224,7 +2919,7 @@ func (r *Repository) GetEmailSendByProviderMessageID(ctx context.Context, provid
 		&emailSend.SenderEmail, &emailSend.SenderName, &emailSend.Subject, &emailSend.EmailType,
 		&emailSend.Provider, &emailSend.ProviderMessageID, &emailSend.Status, &emailSend.SendAttempts,
 		&emailSend.ErrorMessage, &emailSend.ContactID, &emailSend.NewsletterID, &emailSend.CampaignID,
-		&emailSend.PromotionID, &emailSend.SentAt, &emailSend.CreatedAt, &emailSend.UpdatedAt,
+		&emailSend.PromotionID, &emailSend.SubjectVariant, &emailSend.SentAt, &emailSend.CreatedAt, &emailSend.UpdatedAt,
 	)
 
 	if err != nil {
@@ -1275,7 +2970,7 @@ func (r *Repository) GetEmailSendByID(ctx context.Context, emailSendID string) (
 		SELECT id, tenant_id, recipient_email, recipient_name, sender_email, 
 		       sender_name, subject, email_type, provider, provider_message_id, 
 		       status, send_attempts, error_message, contact_id, newsletter_id, 
-		       campaign_id, promotion_id, sent_at, created_at, updated_at
+		       campaign_id, promotion_id, subject_variant, sent_at, created_at, updated_at
 		FROM email_sends
 		WHERE id = $1
 	`
@@ -1286,7 +2981,7 @@ func (r *Repository) GetEmailSendByID(ctx context.Context, emailSendID string) (
 		&emailSend.SenderEmail, &emailSend.SenderName, &emailSend.Subject, &emailSend.EmailType,
 		&emailSend.Provider, &emailSend.ProviderMessageID, &emailSend.Status, &emailSend.SendAttempts,
 		&emailSend.ErrorMessage, &emailSend.ContactID, &emailSend.NewsletterID, &emailSend.CampaignID,
-		&emailSend.PromotionID, &emailSend.SentAt, &emailSend.CreatedAt, &emailSend.UpdatedAt,
+		&emailSend.PromotionID, &emailSend.SubjectVariant, &emailSend.SentAt, &emailSend.CreatedAt, &emailSend.UpdatedAt,
 	)
 
 	if err != nil {
@@ -1358,6 +3053,54 @@ func (r *Repository) GetEmailEventsByEmailSendID(ctx context.Context, emailSendI
 	return events, nil
 }
 
+// GetSubjectVariantStats aggregates sends, opens and clicks per subject variant for a tenant.
+// A send counts as opened or clicked once, however many events the provider reported.
+func (r *Repository) GetSubjectVariantStats(ctx context.Context, tenantID string, emailTypes []string, since time.Time) ([]models.SubjectVariantStats, error) {
+	query := `
+		SELECT s.subject_variant,
+		       COUNT(*) AS sends,
+		       COUNT(*) FILTER (WHERE EXISTS (
+		           SELECT 1 FROM email_events e WHERE e.email_send_id = s.id AND e.event_type = 'opened'
+		       )) AS opens,
+		       COUNT(*) FILTER (WHERE EXISTS (
+		           SELECT 1 FROM email_events e WHERE e.email_send_id = s.id AND e.event_type = 'clicked'
+		       )) AS clicks
+		FROM email_sends s
+		WHERE s.tenant_id = $1
+		  AND s.subject_variant IS NOT NULL
+		  AND s.status <> 'failed'
+		  AND s.email_type = ANY(string_to_array($2, ','))
+		  AND s.sent_at >= $3
+		GROUP BY s.subject_variant
+		ORDER BY s.subject_variant
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, strings.Join(emailTypes, ","), since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subject variant stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.SubjectVariantStats{}
+	for rows.Next() {
+		var stat models.SubjectVariantStats
+		if err := rows.Scan(&stat.Variant, &stat.Sends, &stat.Opens, &stat.Clicks); err != nil {
+			return nil, fmt.Errorf("failed to scan subject variant stats: %w", err)
+		}
+		if stat.Sends > 0 {
+			stat.OpenRate = float64(stat.Opens) / float64(stat.Sends)
+			stat.ClickRate = float64(stat.Clicks) / float64(stat.Sends)
+		}
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating subject variant stats: %w", err)
+	}
+
+	return stats, nil
+}
+
 // UpdateOutgoingEmailStatus updates the status of an outgoing email record
 func (r *Repository) UpdateOutgoingEmailStatus(ctx context.Context, emailID, status string, errorMessage *string) error {
 	now := time.Now()
@@ -1407,3 +3150,107 @@ func (r *Repository) GetOutgoingEmailByProviderMessageID(ctx context.Context, pr
 
 	return &email, nil
 }
+
+// CreateImageAsset creates an image asset and its variants in a single transaction
+func (r *Repository) CreateImageAsset(ctx context.Context, asset *models.ImageAsset) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	asset.ID = uuid.New().String()
+	asset.CreatedAt = time.Now()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO image_assets (id, tenant_id, source_url, original_filename, original_content_type,
+		                          original_width, original_height, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, asset.ID, asset.TenantID, asset.SourceURL, asset.OriginalFilename, asset.OriginalContentType,
+		asset.OriginalWidth, asset.OriginalHeight, asset.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create image asset: %w", err)
+	}
+
+	for i := range asset.Variants {
+		variant := &asset.Variants[i]
+		variant.ID = uuid.New().String()
+		variant.AssetID = asset.ID
+		variant.CreatedAt = asset.CreatedAt
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO image_asset_variants (id, asset_id, width, height, content_type, content_hash,
+			                                  storage_key, size_bytes, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, variant.ID, variant.AssetID, variant.Width, variant.Height, variant.ContentType, variant.ContentHash,
+			variant.StorageKey, variant.SizeBytes, variant.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create image asset variant: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetImageAssetBySourceURL retrieves a proxied image asset and its variants by the external URL
+func (r *Repository) GetImageAssetBySourceURL(ctx context.Context, sourceURL string) (*models.ImageAsset, error) {
+	query := `
+		SELECT id, tenant_id, source_url, original_filename, original_content_type,
+		       original_width, original_height, created_at
+		FROM image_assets
+		WHERE source_url = $1
+	`
+
+	var asset models.ImageAsset
+	err := r.db.QueryRowContext(ctx, query, sourceURL).Scan(
+		&asset.ID, &asset.TenantID, &asset.SourceURL, &asset.OriginalFilename, &asset.OriginalContentType,
+		&asset.OriginalWidth, &asset.OriginalHeight, &asset.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get image asset by source URL: %w", err)
+	}
+
+	variants, err := r.getImageAssetVariants(ctx, asset.ID)
+	if err != nil {
+		return nil, err
+	}
+	asset.Variants = variants
+
+	return &asset, nil
+}
+
+func (r *Repository) getImageAssetVariants(ctx context.Context, assetID string) ([]models.ImageAssetVariant, error) {
+	query := `
+		SELECT id, asset_id, width, height, content_type, content_hash, storage_key, size_bytes, created_at
+		FROM image_asset_variants
+		WHERE asset_id = $1
+		ORDER BY width
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image asset variants: %w", err)
+	}
+	defer rows.Close()
+
+	var variants []models.ImageAssetVariant
+	for rows.Next() {
+		var variant models.ImageAssetVariant
+		if err := rows.Scan(
+			&variant.ID, &variant.AssetID, &variant.Width, &variant.Height, &variant.ContentType,
+			&variant.ContentHash, &variant.StorageKey, &variant.SizeBytes, &variant.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan image asset variant: %w", err)
+		}
+		variants = append(variants, variant)
+	}
+
+	return variants, rows.Err()
+}
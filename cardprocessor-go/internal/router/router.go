@@ -1,8 +1,12 @@
 package router
 
 import (
+	"html/template"
+
+	"cardprocessor-go/internal/assets"
 	"cardprocessor-go/internal/config"
 	"cardprocessor-go/internal/handlers"
+	"cardprocessor-go/internal/i18n"
 	"cardprocessor-go/internal/middleware"
 	"cardprocessor-go/internal/repository"
 	"cardprocessor-go/internal/temporal"
@@ -11,7 +15,7 @@ import (
 )
 
 // SetupRouter configures and returns the Gin router with all routes
-func SetupRouter(cfg *config.Config, repo *repository.Repository, temporalClient *temporal.TemporalClient) *gin.Engine {
+func SetupRouter(cfg *config.Config, repo *repository.Repository, temporalClient *temporal.TemporalClient, assetService *assets.Service) *gin.Engine {
 	// Set Gin mode based on environment
 	if cfg.Server.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -19,7 +23,9 @@ func SetupRouter(cfg *config.Config, repo *repository.Repository, temporalClient
 
 	router := gin.New()
 
-	// Load HTML templates (relative to cardprocessor-go directory)
+	// Load HTML templates (relative to cardprocessor-go directory); {{dir .Lang}} gives the
+	// page's text direction
+	router.SetFuncMap(template.FuncMap{"dir": i18n.Direction})
 	router.LoadHTMLGlob("templates/*")
 
 	// Add middleware
@@ -47,6 +53,24 @@ func SetupRouter(cfg *config.Config, repo *repository.Repository, temporalClient
 	router.POST("/api/unsubscribe/birthday", birthdayHandler.ProcessBirthdayUnsubscribe)
 	router.GET("/api/resubscribe/birthday", birthdayHandler.ProcessBirthdayResubscribe)
 
+	// RFC 8058 one-click unsubscribe, the URL advertised in List-Unsubscribe
+	router.POST("/api/unsubscribe/one-click", birthdayHandler.ProcessOneClickUnsubscribe)
+
+	// Public preference center, authenticated by the contact's unsubscribe token
+	router.GET("/api/preferences", birthdayHandler.ShowPreferenceCenter)
+	router.POST("/api/preferences", birthdayHandler.UpdatePreferenceCenter)
+
+	// Public birthday invitation page, authenticated by the single-use invitation token
+	router.GET("/api/birthday-update", birthdayHandler.ShowBirthdayInvitationPage)
+	router.POST("/api/birthday-update", birthdayHandler.SubmitBirthdayInvitation)
+
+	// Self-hosted image assets (public so email clients can load them)
+	var assetHandler *handlers.AssetHandler
+	if assetService != nil {
+		assetHandler = handlers.NewAssetHandler(assetService)
+		router.GET("/assets/:file", assetHandler.ServeAsset)
+	}
+
 	// API routes with authentication
 	api := router.Group("/api")
 	api.Use(authMiddleware.RequireAuth())
@@ -55,6 +79,21 @@ func SetupRouter(cfg *config.Config, repo *repository.Repository, temporalClient
 		api.GET("/birthday-settings", birthdayHandler.GetBirthdaySettings)
 		api.PUT("/birthday-settings", birthdayHandler.UpdateBirthdaySettings)
 
+		// Birthday send schedule (Temporal Schedule per tenant)
+		api.GET("/birthday-schedule", birthdayHandler.GetBirthdaySchedule)
+		api.POST("/birthday-schedule", birthdayHandler.CreateBirthdaySchedule)
+		api.PUT("/birthday-schedule", birthdayHandler.UpdateBirthdaySchedule)
+		api.POST("/birthday-schedule/pause", birthdayHandler.PauseBirthdaySchedule)
+		api.POST("/birthday-schedule/resume", birthdayHandler.ResumeBirthdaySchedule)
+		api.DELETE("/birthday-schedule", birthdayHandler.DeleteBirthdaySchedule)
+
+		// Birthday batch runs (BirthdaySendWorkflow)
+		api.GET("/birthday-runs", birthdayHandler.GetBirthdayRuns)
+		api.POST("/birthday-runs", birthdayHandler.StartBirthdayRun)
+		api.GET("/birthday-runs/:id", birthdayHandler.GetBirthdayRun)
+		api.POST("/birthday-runs/:id/pause", birthdayHandler.PauseBirthdayRun)
+		api.POST("/birthday-runs/:id/resume", birthdayHandler.ResumeBirthdayRun)
+
 		// Birthday contacts endpoints
 		api.GET("/birthday-contacts", birthdayHandler.GetBirthdayContacts)
 
@@ -62,14 +101,43 @@ func SetupRouter(cfg *config.Config, repo *repository.Repository, temporalClient
 		api.PUT("/email-contacts/:contactId", birthdayHandler.UpdateContactBirthday)
 		api.PATCH("/email-contacts/birthday-email/bulk", birthdayHandler.UpdateBulkBirthdayEmailPreference)
 
+		// Contact subscription topics
+		api.GET("/email-contacts/:contactId/subscriptions", birthdayHandler.GetContactSubscriptions)
+		api.PUT("/email-contacts/:contactId/subscriptions", birthdayHandler.UpdateContactSubscriptions)
+		api.GET("/email-contacts/:contactId/subscriptions/history", birthdayHandler.GetContactSubscriptionHistory)
+
+		// Consent audit trail export
+		api.GET("/email-contacts/:contactId/consent-history", birthdayHandler.GetContactConsentHistory)
+
+		// GDPR data subject access and erasure
+		api.GET("/email-contacts/:contactId/data-export", birthdayHandler.ExportContactData)
+		api.POST("/email-contacts/:contactId/erase", birthdayHandler.EraseContact)
+
+		// Email data retention
+		api.GET("/retention-settings", birthdayHandler.GetRetentionSettings)
+		api.PUT("/retention-settings", birthdayHandler.UpdateRetentionSettings)
+		api.GET("/email-event-daily-stats", birthdayHandler.GetEmailEventDailyStats)
+
 		// Birthday invitation endpoints
 		api.POST("/birthday-invitation/:contactId", birthdayHandler.SendBirthdayInvitation)
+		api.GET("/birthday-invitation/:contactId", birthdayHandler.GetBirthdayInvitationStatus)
 
 		// Test birthday card endpoint
 		api.POST("/birthday-test", birthdayHandler.SendTestBirthdayCard)
+		api.POST("/birthday-preview", birthdayHandler.PreviewBirthdayCard)
+		api.GET("/birthday-subject-stats", birthdayHandler.GetSubjectVariantStats)
+
+		// Send workflow status and cancellation
+		api.GET("/workflows/:id", birthdayHandler.GetWorkflowStatus)
+		api.POST("/workflows/:id/cancel", birthdayHandler.CancelWorkflow)
 
 		// Generate unsubscribe token (authenticated endpoint for internal use)
 		api.POST("/birthday-unsubscribe-token/:contactId", birthdayHandler.GenerateBirthdayUnsubscribeToken)
+
+		// Image asset uploads
+		if assetHandler != nil {
+			api.POST("/assets", assetHandler.UploadAsset)
+		}
 	}
 
 	return router
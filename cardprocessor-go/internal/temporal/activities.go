@@ -6,23 +6,33 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"cardprocessor-go/internal/config"
+	"cardprocessor-go/internal/i18n"
 	"cardprocessor-go/internal/models"
+	"cardprocessor-go/internal/ratelimit"
 	"cardprocessor-go/internal/repository"
+	"cardprocessor-go/internal/tokens"
 
 	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
 )
 
 // Activity dependencies
 type ActivityDependencies struct {
 	Config *config.Config
 	Repo   *repository.Repository
+	// Send rate limits shared by every activity in the worker
+	ProviderLimiter *ratelimit.Limiter
+	TenantLimiter   *ratelimit.Limiter
 }
 
 var activityDeps *ActivityDependencies
@@ -30,18 +40,27 @@ var activityDeps *ActivityDependencies
 // SetActivityDependencies sets the activity dependencies
 func SetActivityDependencies(cfg *config.Config, repo *repository.Repository) {
 	activityDeps = &ActivityDependencies{
-		Config: cfg,
-		Repo:   repo,
+		Config:          cfg,
+		Repo:            repo,
+		ProviderLimiter: ratelimit.New(cfg.ProviderSendsPerMinute, cfg.ProviderSendBurst),
+		TenantLimiter:   ratelimit.New(cfg.TenantSendsPerMinute, cfg.TenantSendBurst),
 	}
 }
 
 // EmailContent represents prepared email content
 type EmailContent struct {
-	Subject     string `json:"subject"`
-	HTMLContent string `json:"htmlContent"`
-	TextContent string `json:"textContent"`
-	To          string `json:"to"`
-	From        string `json:"from"`
+	Subject        string `json:"subject"`
+	HTMLContent    string `json:"htmlContent"`
+	TextContent    string `json:"textContent"`
+	To             string `json:"to"`
+	From           string `json:"from"`
+	SubjectVariant string `json:"subjectVariant,omitempty"` // A/B subject variant ID, recorded on email_sends
+	// UnsubscribeToken is advertised in the List-Unsubscribe headers when set
+	UnsubscribeToken string `json:"unsubscribeToken,omitempty"`
+	// The send activities skip the email when the contact has unsubscribed from Topic;
+	// ContactID is empty for test sends, which are never skipped
+	ContactID string `json:"contactId,omitempty"`
+	Topic     string `json:"topic,omitempty"`
 }
 
 // EmailSendResult represents the result of sending an email
@@ -50,6 +69,7 @@ type EmailSendResult struct {
 	MessageID string `json:"messageId,omitempty"`
 	Provider  string `json:"provider,omitempty"`
 	Error     string `json:"error,omitempty"`
+	Skipped   bool   `json:"skipped,omitempty"` // Not sent because the contact unsubscribed from the email's topic or the address was erased
 }
 
 // EmailContext contains metadata for tracking outgoing emails
@@ -76,6 +96,7 @@ type TokenInput struct {
 type TokenResult struct {
 	Success bool   `json:"success"`
 	Token   string `json:"token,omitempty"`
+	TokenID string `json:"tokenId,omitempty"` // Database ID of the stored token, when it has one
 	Error   string `json:"error,omitempty"`
 }
 
@@ -88,6 +109,7 @@ type PrepareEmailInput struct {
 	TenantName       string `json:"tenantName"`
 	InvitationToken  string `json:"invitationToken"`
 	BaseURL          string `json:"baseUrl"`
+	Language         string `json:"language,omitempty"`
 }
 
 // SendEmailInput represents input for sending email
@@ -99,7 +121,7 @@ type SendEmailInput struct {
 	TextContent     string `json:"textContent"`
 	TenantID        string `json:"tenantId"`
 	ContactID       string `json:"contactId"`
-	InvitationToken string `json:"invitationToken"`
+	InvitationID    string `json:"invitationId,omitempty"`
 }
 
 // UpdateStatusInput represents input for updating status
@@ -112,7 +134,7 @@ type UpdateStatusInput struct {
 	Provider        string `json:"provider,omitempty"`
 	Error           string `json:"error,omitempty"`
 	InvitationSent  bool   `json:"invitationSent,omitempty"`
-	InvitationToken string `json:"invitationToken,omitempty"`
+	InvitationID    string `json:"invitationId,omitempty"`
 	SentAt          string `json:"sentAt"`
 }
 
@@ -160,6 +182,11 @@ type PreparePromotionalEmailInput struct {
 	Promotion        *models.Promotion `json:"promotion"`
 	BusinessName     string            `json:"businessName"`
 	UnsubscribeToken string            `json:"unsubscribeToken"`
+	Language         string            `json:"language,omitempty"`
+	// The promotional email gets its own promotions-only unsubscribe link when TenantID is set;
+	// ContactID is empty for test sends
+	TenantID  string `json:"tenantId,omitempty"`
+	ContactID string `json:"contactId,omitempty"`
 }
 
 // PrepareBirthdayTestEmailWithPromotion prepares birthday test email content with promotion data
@@ -167,19 +194,37 @@ func PrepareBirthdayTestEmailWithPromotion(ctx context.Context, input PrepareBir
 	logger := activity.GetLogger(ctx)
 	logger.Info("📧 Preparing birthday test email with promotion", "userId", input.WorkflowInput.UserID, "email", input.WorkflowInput.UserEmail)
 
+	// Leave the promotion out for contacts who only want the card
+	contactID := cardContactID(input.WorkflowInput)
+	if input.Promotion != nil {
+		allowed, err := subscriptionAllows(ctx, input.WorkflowInput.TenantID, contactID, models.TopicBirthdayPromotion)
+		if err != nil {
+			return EmailContent{}, err
+		}
+		if !allowed {
+			logger.Info("🚫 Contact unsubscribed from birthday promotions, sending the card without it", "contactId", contactID)
+			input.Promotion = nil
+		}
+	}
+
 	// Generate HTML content for birthday test card with promotion
 	htmlContent := generateBirthdayTestHTMLWithPromotion(input.WorkflowInput, input.Promotion)
 
 	// Generate text content (simplified version)
-	textContent := fmt.Sprintf("Happy Birthday %s!\n\n%s\n\nBest regards,\n%s",
-		input.WorkflowInput.UserFirstName, input.WorkflowInput.CustomMessage, input.WorkflowInput.SenderName)
+	textContent := birthdayTextContent(input.WorkflowInput)
+	text := i18n.GetCardText(input.WorkflowInput.Language)
 
 	return EmailContent{
-		Subject:     fmt.Sprintf("🎂 Happy Birthday %s!", input.WorkflowInput.UserFirstName),
-		HTMLContent: htmlContent,
-		TextContent: textContent,
-		To:          input.WorkflowInput.UserEmail,
-		From:        activityDeps.Config.DefaultFromEmail,
+		Subject:        birthdaySubject(input.WorkflowInput, fmt.Sprintf(text.BirthdaySubject, i18n.Isolate(input.WorkflowInput.Language, input.WorkflowInput.UserFirstName))),
+		HTMLContent:    htmlContent,
+		TextContent:    textContent,
+		To:             input.WorkflowInput.UserEmail,
+		From:           activityDeps.Config.DefaultFromEmail,
+		SubjectVariant: input.WorkflowInput.SubjectVariant,
+
+		UnsubscribeToken: themeUnsubscribeToken(input.WorkflowInput.CustomThemeData),
+		ContactID:        contactID,
+		Topic:            models.TopicBirthdayCard,
 	}, nil
 }
 
@@ -194,26 +239,137 @@ func PrepareBirthdayTestEmail(ctx context.Context, input BirthdayTestWorkflowInp
 	htmlContent := generateBirthdayTestHTML(input)
 
 	// Generate text content (simplified version)
-	textContent := fmt.Sprintf("Happy Birthday %s!\n\n%s\n\nBest regards,\n%s",
-		input.UserFirstName, input.CustomMessage, input.SenderName)
+	textContent := birthdayTextContent(input)
 
+	text := i18n.GetCardText(input.Language)
+	fallbackSubject := fmt.Sprintf(text.BirthdaySubject, i18n.Isolate(input.Language, input.UserFirstName))
+	if input.IsTest {
+		fallbackSubject += fmt.Sprintf(" (Test - %s template)", input.EmailTemplate)
+	}
+	subject := birthdaySubject(input, fallbackSubject)
 	logger.Info("✅ [SPLIT FLOW] Birthday email prepared WITHOUT promotion - ready to send",
-		"subject", fmt.Sprintf("🎉 Happy Birthday %s!", input.UserFirstName))
+		"subject", subject, "subjectVariant", input.SubjectVariant)
 
 	return EmailContent{
-		Subject:     fmt.Sprintf("🎉 Happy Birthday %s! (Test - %s template)", input.UserFirstName, input.EmailTemplate),
-		HTMLContent: htmlContent,
-		TextContent: textContent,
-		To:          input.UserEmail,
-		From:        input.FromEmail,
+		Subject:        subject,
+		HTMLContent:    htmlContent,
+		TextContent:    textContent,
+		To:             input.UserEmail,
+		From:           input.FromEmail,
+		SubjectVariant: input.SubjectVariant,
+
+		UnsubscribeToken: themeUnsubscribeToken(input.CustomThemeData),
+		ContactID:        cardContactID(input),
+		Topic:            models.TopicBirthdayCard,
 	}, nil
 }
 
+// cardContactID is the contact a birthday card goes to; test sends have none
+func cardContactID(input BirthdayTestWorkflowInput) string {
+	if input.IsTest {
+		return ""
+	}
+	return input.UserID
+}
+
+// subscriptionAllows reports whether email on topic may go to the contact. Emails without a
+// contact, such as test sends, are always allowed.
+func subscriptionAllows(ctx context.Context, tenantID, contactID, topic string) (bool, error) {
+	if contactID == "" || topic == "" {
+		return true, nil
+	}
+	allowed, err := activityDeps.Repo.IsContactSubscribed(ctx, tenantID, contactID, topic)
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s subscription: %w", topic, err)
+	}
+	return allowed, nil
+}
+
+// addressSuppressed reports whether the recipient address belongs to a contact erased from the
+// tenant, which must never be mailed again
+func addressSuppressed(ctx context.Context, tenantID, to string) (bool, error) {
+	suppressed, err := activityDeps.Repo.IsEmailSuppressed(ctx, tenantID, to)
+	if err != nil {
+		return false, fmt.Errorf("failed to check address suppression: %w", err)
+	}
+	return suppressed, nil
+}
+
+// suppressedResult is the send result for an email skipped because the address was erased
+func suppressedResult() EmailSendResult {
+	return EmailSendResult{
+		Success: false,
+		Skipped: true,
+		Error:   "recipient address was erased",
+	}
+}
+
+// unsubscribedResult is the send result for an email skipped because of the contact's opt-out
+func unsubscribedResult(topic string) EmailSendResult {
+	return EmailSendResult{
+		Success: false,
+		Skipped: true,
+		Error:   fmt.Sprintf("contact unsubscribed from %s", topic),
+	}
+}
+
+// themeUnsubscribeToken returns the unsubscribe token the workflow stored in CustomThemeData
+func themeUnsubscribeToken(customThemeData map[string]interface{}) string {
+	token, _ := customThemeData["unsubscribeToken"].(string)
+	return token
+}
+
+// birthdayTextContent builds the plain-text part of a birthday card in the card's language
+func birthdayTextContent(input BirthdayTestWorkflowInput) string {
+	text := i18n.GetCardText(input.Language)
+	message := input.CustomMessage
+	if strings.TrimSpace(message) == "" {
+		message = text.DefaultMessage
+	}
+	return fmt.Sprintf("%s\n\n%s\n\n%s\n%s",
+		fmt.Sprintf(text.HappyBirthdayName, i18n.Isolate(input.Language, input.UserFirstName)), message, text.BestRegards, input.SenderName)
+}
+
+// birthdaySubject renders the tenant's subject template, or returns fallback when none is set
+func birthdaySubject(input BirthdayTestWorkflowInput, fallback string) string {
+	if strings.TrimSpace(input.SubjectTemplate) == "" {
+		return fallback
+	}
+	subject := RenderSubject(input.SubjectTemplate, TemplateParams{
+		RecipientName: strings.TrimSpace(input.UserFirstName + " " + input.UserLastName),
+		BrandName:     input.TenantName,
+		SenderName:    input.SenderName,
+		Language:      input.Language,
+	})
+	if subject == "" {
+		return fallback
+	}
+	return subject
+}
+
 // SendBirthdayTestEmail sends birthday test email
 func SendBirthdayTestEmail(ctx context.Context, content EmailContent, tenantID string, emailType string) (EmailSendResult, error) {
 	logger := activity.GetLogger(ctx)
 	logger.Info("📤 Sending birthday test email", "to", content.To, "tenantId", tenantID, "emailType", emailType)
 
+	suppressed, err := addressSuppressed(ctx, tenantID, content.To)
+	if err != nil {
+		return EmailSendResult{Success: false, Error: err.Error()}, err
+	}
+	if suppressed {
+		logger.Info("🚫 Skipping email, recipient address was erased", "tenantId", tenantID)
+		return suppressedResult(), nil
+	}
+
+	allowed, err := subscriptionAllows(ctx, tenantID, content.ContactID, content.Topic)
+	if err != nil {
+		return EmailSendResult{Success: false, Error: err.Error()}, err
+	}
+	if !allowed {
+		logger.Info("🚫 Skipping email, contact unsubscribed", "contactId", content.ContactID, "topic", content.Topic)
+		return unsubscribedResult(content.Topic), nil
+	}
+
 	// Create EmailContext for tracking
 	emailCtx := &EmailContext{
 		TenantID:  tenantID,
@@ -223,22 +379,13 @@ func SendBirthdayTestEmail(ctx context.Context, content EmailContent, tenantID s
 		},
 	}
 
-	// Try different email providers in order of preference
-	providers := []string{"resend", "sendgrid", "mailgun"}
-
-	for _, provider := range providers {
-		result, err := sendEmailViaProvider(ctx, provider, content, emailCtx)
-		if err == nil && result.Success {
-			logger.Info("✅ Birthday test email sent successfully", "provider", provider, "messageId", result.MessageID)
-			return result, nil
-		}
-		logger.Warn("❌ Failed to send via provider", "provider", provider, "error", err)
+	result, err := sendViaProviders(ctx, content, emailCtx)
+	if err != nil {
+		return result, err
 	}
 
-	return EmailSendResult{
-		Success: false,
-		Error:   "All email providers failed",
-	}, fmt.Errorf("all email providers failed")
+	logger.Info("✅ Birthday test email sent successfully", "provider", result.Provider, "messageId", result.MessageID)
+	return result, nil
 }
 
 // PrepareBirthdayInvitationEmail prepares birthday invitation email content
@@ -250,11 +397,13 @@ func PrepareBirthdayInvitationEmail(ctx context.Context, input PrepareEmailInput
 	htmlContent := generateBirthdayInvitationHTML(input)
 
 	// Generate text content (simplified version)
-	textContent := fmt.Sprintf("Hi %s,\n\nWe'd love to help celebrate your special day! Please update your birthday information.\n\nClick here: %s/birthday-update?token=%s\n\nBest regards,\n%s",
-		input.ContactFirstName, input.BaseURL, input.InvitationToken, input.TenantName)
+	text := i18n.GetCardText(input.Language)
+	textContent := fmt.Sprintf("%s\n\n%s\n\n%s %s/api/birthday-update?token=%s\n\n%s\n%s",
+		fmt.Sprintf(text.InvitationGreeting, i18n.Isolate(input.Language, input.ContactFirstName)), text.InvitationTextBody,
+		text.InvitationClickHere, input.BaseURL, input.InvitationToken, text.BestRegards, input.TenantName)
 
 	return EmailContent{
-		Subject:     "🎂 Help us celebrate your special day!",
+		Subject:     text.InvitationSubject,
 		HTMLContent: htmlContent,
 		TextContent: textContent,
 		To:          input.ContactEmail,
@@ -267,6 +416,24 @@ func SendBirthdayInvitationEmail(ctx context.Context, input SendEmailInput) (Ema
 	logger := activity.GetLogger(ctx)
 	logger.Info("📤 Sending birthday invitation email", "to", input.To, "tenantId", input.TenantID, "contactId", input.ContactID)
 
+	suppressed, err := addressSuppressed(ctx, input.TenantID, input.To)
+	if err != nil {
+		return EmailSendResult{Success: false, Error: err.Error()}, err
+	}
+	if suppressed {
+		logger.Info("🚫 Skipping invitation, recipient address was erased", "tenantId", input.TenantID)
+		return suppressedResult(), nil
+	}
+
+	allowed, err := subscriptionAllows(ctx, input.TenantID, input.ContactID, models.TopicInvitations)
+	if err != nil {
+		return EmailSendResult{Success: false, Error: err.Error()}, err
+	}
+	if !allowed {
+		logger.Info("🚫 Skipping invitation, contact unsubscribed from invitations", "contactId", input.ContactID)
+		return unsubscribedResult(models.TopicInvitations), nil
+	}
+
 	content := EmailContent{
 		Subject:     input.Subject,
 		HTMLContent: input.HTMLContent,
@@ -282,71 +449,96 @@ func SendBirthdayInvitationEmail(ctx context.Context, input SendEmailInput) (Ema
 		EmailType: "invitation",
 		Metadata: map[string]interface{}{
 			"recipientEmail": input.To,
-			"invitationToken": input.InvitationToken,
+			"invitationId":   input.InvitationID,
 		},
 	}
 
-	// Try different email providers in order of preference
-	providers := []string{"resend", "sendgrid", "mailgun"}
-
-	for _, provider := range providers {
-		result, err := sendEmailViaProvider(ctx, provider, content, emailCtx)
-		if err == nil && result.Success {
-			logger.Info("✅ Birthday invitation email sent successfully", "provider", provider, "messageId", result.MessageID)
-			return result, nil
-		}
-		logger.Warn("❌ Failed to send via provider", "provider", provider, "error", err)
+	result, err := sendViaProviders(ctx, content, emailCtx)
+	if err != nil {
+		return result, err
 	}
 
-	return EmailSendResult{
-		Success: false,
-		Error:   "All email providers failed",
-	}, fmt.Errorf("all email providers failed")
+	logger.Info("✅ Birthday invitation email sent successfully", "provider", result.Provider, "messageId", result.MessageID)
+	return result, nil
 }
 
-// GenerateBirthdayInvitationToken generates a JWT token for birthday invitation
+// ErrTypeInvitationSecretNotSet is the error type of invitation tokens requested without an
+// INVITATION_TOKEN_SECRET
+const ErrTypeInvitationSecretNotSet = "InvitationSecretNotSet"
+
+// GenerateBirthdayInvitationToken issues a signed, expiring birthday invitation token and
+// stores its hash as a pending invitation, so the link can be used only once
 func GenerateBirthdayInvitationToken(ctx context.Context, input TokenInput) (TokenResult, error) {
 	logger := activity.GetLogger(ctx)
-	logger.Info("🔑 Generating birthday invitation token", "contactId", input.ContactID)
+	logger.Info("🔑 Generating birthday invitation token", "contactId", input.ContactID, "tenantId", input.TenantID)
 
-	// For now, generate a simple token (in production, use proper JWT)
-	token := fmt.Sprintf("birthday_invitation_%s_%d", input.ContactID, time.Now().Unix())
-
-	return TokenResult{
-		Success: true,
-		Token:   token,
-	}, nil
-}
+	expiresIn, err := parseExpiresIn(input.ExpiresIn)
+	if err != nil {
+		return TokenResult{Success: false, Error: err.Error()}, err
+	}
+	expiresAt := time.Now().Add(expiresIn)
 
-func GenerateBirthdayUnsubscribeToken(ctx context.Context, input TokenInput) (TokenResult, error) {
-	logger := activity.GetLogger(ctx)
-	logger.Info("🔑 Generating birthday unsubscribe token", "contactId", input.ContactID, "tenantId", input.TenantID)
+	// Retrying can't help until the secret is set
+	if activityDeps.Config.InvitationTokenSecret == "" {
+		logger.Error("❌ INVITATION_TOKEN_SECRET is not set, can't sign birthday invitations")
+		return TokenResult{Success: false, Error: "Invitation token secret is not configured"},
+			temporal.NewNonRetryableApplicationError("INVITATION_TOKEN_SECRET is not set", ErrTypeInvitationSecretNotSet, nil)
+	}
 
-	// Generate a secure random token
-	tokenBytes := make([]byte, 32)
-	_, err := rand.Read(tokenBytes)
+	token, err := tokens.NewInvitation(activityDeps.Config.InvitationTokenSecret, expiresAt)
 	if err != nil {
 		return TokenResult{Success: false, Error: "Failed to generate token"}, err
 	}
-	token := hex.EncodeToString(tokenBytes)
 
-	// Store the token in the database
-	if input.TenantID != "" && input.ContactID != "" {
-		// Store token in the database
-		logger.Info("💾 Storing unsubscribe token in database", "contactId", input.ContactID, "tenantId", input.TenantID)
-		
-		_, err = activityDeps.Repo.CreateBirthdayUnsubscribeToken(ctx, input.TenantID, input.ContactID, token)
-		if err != nil {
-			logger.Error("❌ Failed to store unsubscribe token in database", "error", err)
-			// Continue anyway - token can still be used even if not stored
-			// This allows test emails to work without requiring valid contact IDs
-		} else {
-			logger.Info("✅ Unsubscribe token stored successfully in database")
+	// Unlike unsubscribe tokens, an invitation that isn't stored can't be redeemed, so storing it must succeed
+	invitation, err := activityDeps.Repo.CreateBirthdayInvitation(ctx, input.TenantID, input.ContactID, tokens.Hash(token), expiresAt)
+	if err != nil {
+		logger.Error("❌ Failed to store birthday invitation", "error", err)
+		return TokenResult{Success: false, Error: "Failed to store invitation"}, err
+	}
+
+	logger.Info("✅ Birthday invitation stored", "invitationId", invitation.ID, "expiresAt", expiresAt)
+	return TokenResult{Success: true, Token: token, TokenID: invitation.ID}, nil
+}
+
+// parseExpiresIn parses a token lifetime such as "30d" or "12h"; empty means the invitation default
+func parseExpiresIn(value string) (time.Duration, error) {
+	if value == "" {
+		return invitationExpiryDays * 24 * time.Hour, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid token lifetime %q", value)
 		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid token lifetime %q", value)
+	}
+	return d, nil
+}
+
+// GenerateBirthdayUnsubscribeToken signs an unsubscribe token for the contact. Nothing is
+// stored: the link is verified against the keyring when used. Test sends have no contact ID,
+// and their links resolve to a notice instead of unsubscribing anyone.
+func GenerateBirthdayUnsubscribeToken(ctx context.Context, input TokenInput) (TokenResult, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("🔑 Generating birthday unsubscribe token", "contactId", input.ContactID, "tenantId", input.TenantID, "action", input.Action)
+
+	scope := unsubscribeScope(input.Action)
+	var token string
+	var err error
+	if activityDeps.Config.UnsubscribeKeyring == nil && scope == tokens.ScopeBirthday {
+		// Until UNSUBSCRIBE_TOKEN_KEYS is set, birthday cards keep getting stored random tokens
+		token, err = newLegacyUnsubscribeToken(ctx, input.TenantID, input.ContactID)
 	} else {
-		logger.Warn("⚠️  No tenant/contact ID provided - token will not be stored in database", 
-			"tenantId", input.TenantID, 
-			"contactId", input.ContactID)
+		token, err = newUnsubscribeToken(input.TenantID, input.ContactID, scope)
+	}
+	if err != nil {
+		logger.Error("❌ Failed to sign unsubscribe token", "error", err)
+		return TokenResult{Success: false, Error: "Failed to generate token"}, err
 	}
 
 	result := TokenResult{Success: true, Token: token}
@@ -354,6 +546,47 @@ func GenerateBirthdayUnsubscribeToken(ctx context.Context, input TokenInput) (To
 	return result, nil
 }
 
+// newUnsubscribeToken signs an unsubscribe token with the configured keyring
+func newUnsubscribeToken(tenantID, contactID, scope string) (string, error) {
+	keyring := activityDeps.Config.UnsubscribeKeyring
+	if keyring == nil {
+		return "", errors.New("unsubscribe token keys are not loaded")
+	}
+	return keyring.NewUnsubscribe(tenantID, contactID, scope, time.Now())
+}
+
+// newLegacyUnsubscribeToken issues a random token and stores it in birthday_unsubscribe_tokens,
+// the way birthday unsubscribe links were made before they were signed. It's only used while
+// no unsubscribe keys are configured.
+func newLegacyUnsubscribeToken(ctx context.Context, tenantID, contactID string) (string, error) {
+	logger := activity.GetLogger(ctx)
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", fmt.Errorf("failed to generate unsubscribe token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	if tenantID == "" || contactID == "" {
+		logger.Warn("⚠️ No tenant/contact ID provided - legacy unsubscribe token will not be stored", "tenantId", tenantID, "contactId", contactID)
+		return token, nil
+	}
+	if _, err := activityDeps.Repo.CreateBirthdayUnsubscribeToken(ctx, tenantID, contactID, token); err != nil {
+		return "", fmt.Errorf("failed to store unsubscribe token: %w", err)
+	}
+	logger.Warn("⚠️ UNSUBSCRIBE_TOKEN_KEYS is not set - issued a legacy unsubscribe token", "contactId", contactID, "tenantId", tenantID)
+	return token, nil
+}
+
+// unsubscribeScope maps a token action such as "unsubscribe_promotions" to its unsubscribe
+// scope, defaulting to birthday cards
+func unsubscribeScope(action string) string {
+	if scope, ok := strings.CutPrefix(action, "unsubscribe_"); ok && tokens.ValidScope(scope) {
+		return scope
+	}
+	return tokens.ScopeBirthday
+}
+
 // UpdateBirthdayTestStatus updates the birthday test status in database
 func UpdateBirthdayTestStatus(ctx context.Context, input UpdateStatusInput) error {
 	logger := activity.GetLogger(ctx)
@@ -365,13 +598,25 @@ func UpdateBirthdayTestStatus(ctx context.Context, input UpdateStatusInput) erro
 	return nil
 }
 
-// UpdateContactInvitationStatus updates contact invitation status in database
+// UpdateContactInvitationStatus records whether the contact's invitation email went out
 func UpdateContactInvitationStatus(ctx context.Context, input UpdateStatusInput) error {
 	logger := activity.GetLogger(ctx)
-	logger.Info("📊 Updating contact invitation status", "contactId", input.ContactID)
+	logger.Info("📊 Updating contact invitation status", "contactId", input.ContactID, "invitationId", input.InvitationID, "sent", input.InvitationSent)
 
-	// TODO: Implement database update for contact invitation status
-	// This would typically update the contact record with invitation tracking info
+	if input.InvitationID == "" {
+		logger.Warn("⚠️ No invitation ID provided - nothing to update")
+		return nil
+	}
+
+	sentAt, err := time.Parse(time.RFC3339, input.SentAt)
+	if err != nil {
+		sentAt = time.Now()
+	}
+
+	if err := activityDeps.Repo.MarkBirthdayInvitationSent(ctx, input.TenantID, input.InvitationID, input.InvitationSent, sentAt); err != nil {
+		logger.Error("❌ Failed to update invitation status", "error", err)
+		return err
+	}
 
 	return nil
 }
@@ -428,6 +673,16 @@ func recordOutgoingEmail(ctx context.Context, content EmailContent, result Email
 		errorMsg = &result.Error
 	}
 
+	var providerMessageID *string
+	if result.MessageID != "" {
+		providerMessageID = &result.MessageID
+	}
+
+	var subjectVariant *string
+	if content.SubjectVariant != "" {
+		subjectVariant = &content.SubjectVariant
+	}
+
 	req := &models.CreateCompleteEmailRequest{
 		TenantID:          emailCtx.TenantID,
 		RecipientEmail:    content.To,
@@ -437,7 +692,7 @@ func recordOutgoingEmail(ctx context.Context, content EmailContent, result Email
 		Subject:           content.Subject,
 		EmailType:         emailCtx.EmailType,
 		Provider:          result.Provider,
-		ProviderMessageID: &result.MessageID,
+		ProviderMessageID: providerMessageID,
 		Status:            status,
 		SendAttempts:      1,
 		ErrorMessage:      errorMsg,
@@ -445,6 +700,7 @@ func recordOutgoingEmail(ctx context.Context, content EmailContent, result Email
 		NewsletterID:      emailCtx.NewsletterID,
 		CampaignID:        emailCtx.CampaignID,
 		PromotionID:       emailCtx.PromotionID,
+		SubjectVariant:    subjectVariant,
 		HTMLContent:       &content.HTMLContent,
 		TextContent:       &content.TextContent,
 		Metadata:          metadataJSON,
@@ -461,8 +717,7 @@ func recordOutgoingEmail(ctx context.Context, content EmailContent, result Email
 			"error", err, 
 			"recipient", content.To,
 			"tenantId", emailCtx.TenantID)
-		// Don't fail the email send if tracking fails
-		return nil
+		return fmt.Errorf("failed to record email send: %w", err)
 	}
 
 	// Create an email event for the send result
@@ -522,27 +777,14 @@ func sendEmailViaProvider(ctx context.Context, provider string, content EmailCon
 // sendViaResend sends email via Resend API
 func sendViaResend(ctx context.Context, content EmailContent, emailCtx *EmailContext) (EmailSendResult, error) {
 	if activityDeps.Config.ResendAPIKey == "" {
-		return EmailSendResult{Success: false, Error: "Resend API key not configured"}, fmt.Errorf("resend API key not configured")
-	}
-
-	// Extract unsubscribe URL from HTML content to add to List-Unsubscribe header
-	// This prevents Resend from wrapping the unsubscribe link in click tracking
-	headers := make(map[string]string)
-	if strings.Contains(content.HTMLContent, "/api/unsubscribe/birthday?token=") {
-		// Extract the unsubscribe URL
-		start := strings.Index(content.HTMLContent, "http")
-		if start != -1 {
-			end := strings.Index(content.HTMLContent[start:], `"`)
-			if end != -1 {
-				unsubUrl := content.HTMLContent[start : start+end]
-				if strings.Contains(unsubUrl, "/api/unsubscribe/birthday?token=") {
-					headers["List-Unsubscribe"] = "<" + unsubUrl + ">"
-					headers["List-Unsubscribe-Post"] = "List-Unsubscribe=One-Click"
-				}
-			}
-		}
+		err := providerNotConfigured("resend", "API key not configured")
+		return EmailSendResult{Success: false, Error: err.Error()}, err
 	}
 
+	// Advertise one-click unsubscribe (RFC 8058). Putting the link in a header also keeps
+	// Resend from wrapping it in click tracking.
+	headers := listUnsubscribeHeaders(content.UnsubscribeToken)
+
 	payload := map[string]interface{}{
 		"from":    content.From,
 		"to":      []string{content.To},
@@ -569,12 +811,14 @@ func sendViaResend(ctx context.Context, content EmailContent, emailCtx *EmailCon
 	client := &http.Client{Timeout: 30 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
+		err = providerNetworkError(ctx, "resend", err)
 		return EmailSendResult{Success: false, Error: err.Error()}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 202 {
-		return EmailSendResult{Success: false, Error: "Resend API error"}, fmt.Errorf("resend API returned status %d", resp.StatusCode)
+		err := providerStatusError("resend", resp)
+		return EmailSendResult{Success: false, Error: err.Error()}, err
 	}
 
 	var result map[string]interface{}
@@ -591,30 +835,55 @@ func sendViaResend(ctx context.Context, content EmailContent, emailCtx *EmailCon
 		Provider:  "resend",
 	}
 
-	// Record outgoing email (best-effort)
+	// Record outgoing email (best-effort, don't fail the email send if tracking fails)
 	fmt.Printf("DEBUG: About to call recordOutgoingEmail - emailCtx: %+v\\n", emailCtx)
 	_ = recordOutgoingEmail(ctx, content, sendResult, emailCtx)
 	return sendResult, nil
 }
 
+// listUnsubscribeHeaders returns the List-Unsubscribe (RFC 2369) and List-Unsubscribe-Post
+// (RFC 8058) headers every provider sends for an email with an unsubscribe token: the one-click
+// endpoint, plus a mailto: address handled as inbound email when UNSUBSCRIBE_MAILTO is set.
+// It returns nil without a token.
+func listUnsubscribeHeaders(token string) map[string]string {
+	if token == "" {
+		return nil
+	}
+
+	baseURL := strings.TrimRight(activityDeps.Config.AppURL, "/")
+	targets := []string{"<" + baseURL + "/api/unsubscribe/one-click?token=" + url.QueryEscape(token) + ">"}
+	if mailbox := activityDeps.Config.UnsubscribeMailto; mailbox != "" {
+		targets = append(targets, "<mailto:"+mailbox+"?subject="+url.PathEscape("unsubscribe "+token)+">")
+	}
+
+	return map[string]string{
+		"List-Unsubscribe":      strings.Join(targets, ", "),
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
+
 // sendViaSendGrid sends email via SendGrid API
 func sendViaSendGrid(ctx context.Context, content EmailContent, emailCtx *EmailContext) (EmailSendResult, error) {
 	if activityDeps.Config.SendGridAPIKey == "" {
-		return EmailSendResult{Success: false, Error: "SendGrid API key not configured"}, fmt.Errorf("sendgrid API key not configured")
+		err := providerNotConfigured("sendgrid", "API key not configured")
+		return EmailSendResult{Success: false, Error: err.Error()}, err
 	}
 
-	// TODO: Implement SendGrid API call
-	return EmailSendResult{Success: false, Error: "SendGrid not implemented"}, fmt.Errorf("sendgrid not implemented")
+	// TODO: Implement SendGrid API call, sending listUnsubscribeHeaders(content.UnsubscribeToken) as "headers"
+	err := providerNotConfigured("sendgrid", "not implemented")
+	return EmailSendResult{Success: false, Error: err.Error()}, err
 }
 
 // sendViaMailgun sends email via Mailgun API
 func sendViaMailgun(ctx context.Context, content EmailContent, emailCtx *EmailContext) (EmailSendResult, error) {
 	if activityDeps.Config.MailgunAPIKey == "" || activityDeps.Config.MailgunDomain == "" {
-		return EmailSendResult{Success: false, Error: "Mailgun API key or domain not configured"}, fmt.Errorf("mailgun not configured")
+		err := providerNotConfigured("mailgun", "API key or domain not configured")
+		return EmailSendResult{Success: false, Error: err.Error()}, err
 	}
 
-	// TODO: Implement Mailgun API call
-	return EmailSendResult{Success: false, Error: "Mailgun not implemented"}, fmt.Errorf("mailgun not implemented")
+	// TODO: Implement Mailgun API call, sending listUnsubscribeHeaders(content.UnsubscribeToken) as "h:" fields
+	err := providerNotConfigured("mailgun", "not implemented")
+	return EmailSendResult{Success: false, Error: err.Error()}, err
 }
 
 // generateBirthdayTestHTML generates HTML content for birthday test card using the new template system
@@ -668,6 +937,9 @@ func generateBirthdayTestHTML(input BirthdayTestWorkflowInput) string {
 		fmt.Println("⚠️  [generateBirthdayTestHTML] CustomThemeData is nil")
 	}
 
+	// Personalized card image rendered by the workflow, if any
+	cardImageUrl, _ := input.CustomThemeData["cardImageUrl"].(string)
+
 	// Prepare template parameters - NO PROMOTION DATA (for split email flow)
 	params := TemplateParams{
 		RecipientName:        recipientName,
@@ -678,12 +950,15 @@ func generateBirthdayTestHTML(input BirthdayTestWorkflowInput) string {
 		PromotionContent:     "", // Empty - promotion sent separately
 		PromotionTitle:       "",
 		PromotionDescription: "",
+		ImageUrl:             cardImageUrl,
+		Preheader:            input.PreheaderText,
+		Language:             input.Language,
 		IsTest:               input.IsTest,
 		UnsubscribeToken:     unsubscribeToken,
 	}
 
-	// Render the template
-	return RenderBirthdayTemplate(templateId, params)
+	// Render the template and run the email-client compatibility pass
+	return RenderBirthdayEmail(templateId, params).HTML
 }
 
 // generateBirthdayTestHTMLWithPromotion generates HTML content for birthday test card with promotion data
@@ -737,6 +1012,9 @@ func generateBirthdayTestHTMLWithPromotion(input BirthdayTestWorkflowInput, prom
 		fmt.Println("⚠️  [generateBirthdayTestHTMLWithPromotion] CustomThemeData is nil")
 	}
 
+	// Personalized card image rendered by the workflow, if any
+	cardImageUrl, _ := input.CustomThemeData["cardImageUrl"].(string)
+
 	// Prepare template parameters with promotion data
 	params := TemplateParams{
 		RecipientName:    recipientName,
@@ -744,6 +1022,9 @@ func generateBirthdayTestHTMLWithPromotion(input BirthdayTestWorkflowInput, prom
 		BrandName:        input.TenantName,
 		CustomThemeData:  customThemeData,
 		SenderName:       input.SenderName,
+		ImageUrl:         cardImageUrl,
+		Preheader:        input.PreheaderText,
+		Language:         input.Language,
 		IsTest:           input.IsTest,
 		UnsubscribeToken: unsubscribeToken,
 	}
@@ -759,7 +1040,7 @@ func generateBirthdayTestHTMLWithPromotion(input BirthdayTestWorkflowInput, prom
 
 	// Render the template
 	fmt.Printf("🎨 [generateBirthdayTestHTMLWithPromotion] Rendering template with UnsubscribeToken: %v\n", params.UnsubscribeToken != "")
-	return RenderBirthdayTemplate(templateId, params)
+	return RenderBirthdayEmail(templateId, params).HTML
 }
 
 // Helper function to get map keys for debugging
@@ -771,44 +1052,58 @@ func getKeys(m map[string]interface{}) []string {
 	return keys
 }
 
+// invitationExpiryDays is how long birthday invitation links stay valid
+const invitationExpiryDays = 30
+
 // generateBirthdayInvitationHTML generates HTML content for birthday invitation matching server-node style
 func generateBirthdayInvitationHTML(input PrepareEmailInput) string {
+	lang := i18n.ResolveLanguage(input.Language)
+	dir := i18n.Direction(lang)
+	text := i18n.GetCardText(lang)
+
+	// List indentation sits on the side the text starts from
+	listMargin := "0 0 20px 20px"
+	if dir == i18n.DirectionRTL {
+		listMargin = "0 20px 20px 0"
+	}
+
 	contactName := input.ContactFirstName
 	if input.ContactLastName != "" {
 		contactName += " " + input.ContactLastName
 	}
 	if contactName == "" {
-		contactName = "Valued Customer"
+		contactName = text.ValuedCustomer
+	}
+
+	var benefits strings.Builder
+	for _, benefit := range text.InvitationBenefits {
+		benefits.WriteString("\n            <li>" + template.HTMLEscapeString(benefit) + "</li>")
 	}
 
 	return fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s" dir="%s">
 <head>
     <meta charset="utf-8">
-    <title>Birthday Information Request</title>
+    <title>%s</title>
 </head>
-<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+<body dir="%s" style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
     <div style="text-align: center; margin-bottom: 30px;">
-        <h1 style="color: #e91e63; margin: 0;">🎂 Birthday Celebration!</h1>
+        <h1 style="color: #e91e63; margin: 0;">%s</h1>
     </div>
     
     <div style="background: #f9f9f9; padding: 25px; border-radius: 8px; margin-bottom: 20px;">
-        <p style="margin: 0 0 15px 0; font-size: 16px;">Hi %s,</p>
+        <p style="margin: 0 0 15px 0; font-size: 16px;">%s</p>
         
-        <p style="margin: 0 0 15px 0;">We'd love to make your birthday extra special! To ensure you don't miss out on exclusive birthday promotions, special offers, and personalized birthday surprises, we'd like to add your birthday to our records.</p>
+        <p style="margin: 0 0 15px 0;">%s</p>
         
-        <p style="margin: 0 0 20px 0;">By sharing your birthday with us, you'll receive:</p>
+        <p style="margin: 0 0 20px 0;">%s</p>
         
-        <ul style="margin: 0 0 20px 20px; padding: 0;">
-            <li>🎁 Exclusive birthday discounts and offers</li>
-            <li>🎉 Special birthday promotions</li>
-            <li>📧 Personalized birthday messages</li>
-            <li>🌟 Early access to birthday-themed content</li>
+        <ul style="margin: %s; padding: 0;">%s
         </ul>
         
         <div style="text-align: center; margin: 25px 0;">
-            <a href="%s/birthday-update?token=%s" 
+            <a href="%s/api/birthday-update?token=%s" 
                style="background: linear-gradient(135deg, #e91e63, #f06292); 
                       color: white; 
                       padding: 12px 30px; 
@@ -817,23 +1112,36 @@ func generateBirthdayInvitationHTML(input PrepareEmailInput) string {
                       font-weight: bold; 
                       display: inline-block; 
                       box-shadow: 0 4px 8px rgba(233, 30, 99, 0.3);">
-                🎂 Add My Birthday
+                %s
             </a>
         </div>
         
-        <p style="margin: 15px 0 0 0; font-size: 14px; color: #666;">This link will expire in 30 days. Your privacy is important to us - we'll only use your birthday to send you special offers and birthday wishes.</p>
+        <p style="margin: 15px 0 0 0; font-size: 14px; color: #666;">%s</p>
     </div>
     
     <div style="border-top: 1px solid #eee; padding-top: 20px; font-size: 12px; color: #888; text-align: center;">
-        <p style="margin: 0;">Best regards,<br>%s</p>
-        <p style="margin: 10px 0 0 0;">This invitation was sent because you're a valued customer. If you'd prefer not to receive birthday-related communications, you can simply ignore this email.</p>
+        <p style="margin: 0;">%s<br>%s</p>
+        <p style="margin: 10px 0 0 0;">%s</p>
     </div>
 </body>
 </html>`,
-		template.HTMLEscapeString(contactName),
+		lang,
+		dir,
+		template.HTMLEscapeString(text.InvitationTitle),
+		dir,
+		template.HTMLEscapeString(text.InvitationHeading),
+		template.HTMLEscapeString(fmt.Sprintf(text.InvitationGreeting, i18n.Isolate(lang, contactName))),
+		template.HTMLEscapeString(text.InvitationIntro),
+		template.HTMLEscapeString(text.InvitationBenefitsIntro),
+		listMargin,
+		benefits.String(),
 		input.BaseURL,
 		input.InvitationToken,
+		template.HTMLEscapeString(text.InvitationButton),
+		template.HTMLEscapeString(i18n.Plural(lang, "invitation.expiry", invitationExpiryDays)+" "+text.InvitationPrivacy),
+		template.HTMLEscapeString(text.BestRegards),
 		template.HTMLEscapeString(input.TenantName),
+		template.HTMLEscapeString(text.InvitationFooter),
 	)
 }
 
@@ -845,8 +1153,18 @@ func PreparePromotionalEmail(ctx context.Context, input PreparePromotionalEmailI
 		"promotionId", input.Promotion.ID,
 		"promotionTitle", input.Promotion.Title)
 
+	// Unsubscribing from the promotion shouldn't also stop birthday cards
+	if input.TenantID != "" {
+		token, err := newUnsubscribeToken(input.TenantID, input.ContactID, tokens.ScopePromotions)
+		if err != nil {
+			logger.Warn("Failed to sign promotions unsubscribe token, keeping the birthday one", "error", err)
+		} else {
+			input.UnsubscribeToken = token
+		}
+	}
+
 	// Generate promotional email HTML
-	htmlBody := generatePromotionalHTML(input)
+	htmlBody := PrepareEmailHTML(generatePromotionalHTML(input)).HTML
 
 	// Handle optional description field
 	description := ""
@@ -854,12 +1172,18 @@ func PreparePromotionalEmail(ctx context.Context, input PreparePromotionalEmailI
 		description = *input.Promotion.Description
 	}
 
+	subject := fmt.Sprintf(i18n.GetCardText(input.Language).PromotionSubject, i18n.Isolate(input.Language, input.Promotion.Title))
+
 	return EmailContent{
 		To:          input.ToEmail,
 		From:        input.FromEmail,
-		Subject:     fmt.Sprintf("🎁 Special Offer: %s", input.Promotion.Title),
+		Subject:     subject,
 		HTMLContent: htmlBody,
-		TextContent: fmt.Sprintf("Special Offer: %s\n\n%s", input.Promotion.Title, description),
+		TextContent: fmt.Sprintf("%s\n\n%s", subject, description),
+
+		UnsubscribeToken: input.UnsubscribeToken,
+		ContactID:        input.ContactID,
+		Topic:            models.TopicBirthdayPromotion,
 	}, nil
 }
 
@@ -882,6 +1206,9 @@ func SendPromotionalEmail(ctx context.Context, content EmailContent, tenantID st
 			MessageID: "",
 		}, err
 	}
+	if result.Skipped {
+		return result, nil
+	}
 
 	logger.Info("✅ Promotional email sent successfully",
 		"messageId", result.MessageID,
@@ -892,29 +1219,31 @@ func SendPromotionalEmail(ctx context.Context, content EmailContent, tenantID st
 
 // generatePromotionalHTML generates the HTML content for the promotional email
 func generatePromotionalHTML(input PreparePromotionalEmailInput) string {
+	lang := i18n.ResolveLanguage(input.Language)
+	dir := i18n.Direction(lang)
+	text := i18n.GetCardText(lang)
+
 	unsubscribeURL := ""
 	if input.UnsubscribeToken != "" {
-		unsubscribeURL = fmt.Sprintf("%s/api/birthday/unsubscribe/%s",
-			"https://app.example.com",
-			input.UnsubscribeToken)
+		unsubscribeURL = unsubscribePageURL(input.UnsubscribeToken)
 	}
 
 	return fmt.Sprintf(`<!DOCTYPE html>
-<html lang="en">
+<html lang="%s" dir="%s">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>%s</title>
 </head>
-<body style="margin: 0; padding: 0; font-family: Arial, sans-serif; background-color: #f4f4f4;">
-    <table role="presentation" style="width: 100%%; border-collapse: collapse;">
+<body dir="%s" style="margin: 0; padding: 0; font-family: Arial, sans-serif; background-color: #f4f4f4;">
+    <table role="presentation" dir="%s" style="width: 100%%; border-collapse: collapse;">
         <tr>
             <td align="center" style="padding: 40px 0;">
-                <table role="presentation" style="width: 600px; border-collapse: collapse; background-color: #ffffff; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);">
+                <table role="presentation" dir="%s" style="width: 600px; border-collapse: collapse; background-color: #ffffff; box-shadow: 0 4px 6px rgba(0, 0, 0, 0.1);">
                     <!-- Header -->
                     <tr>
                         <td style="padding: 40px 40px 20px; text-align: center; background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);">
-                            <h1 style="margin: 0; color: #ffffff; font-size: 28px; font-weight: bold;">🎁 Special Offer</h1>
+                            <h1 style="margin: 0; color: #ffffff; font-size: 28px; font-weight: bold;">%s</h1>
                         </td>
                     </tr>
                     
@@ -929,7 +1258,7 @@ func generatePromotionalHTML(input PreparePromotionalEmailInput) string {
                             <!-- CTA Button -->
                             <div style="text-align: center; margin: 30px 0;">
                                 <a href="#" style="display: inline-block; padding: 15px 40px; background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%); color: #ffffff; text-decoration: none; border-radius: 5px; font-weight: bold; font-size: 16px;">
-                                    Claim Your Offer
+                                    %s
                                 </a>
                             </div>
                         </td>
@@ -950,15 +1279,22 @@ func generatePromotionalHTML(input PreparePromotionalEmailInput) string {
     </table>
 </body>
 </html>`,
+		lang,
+		dir,
 		input.Promotion.Title,
+		dir,
+		dir,
+		dir,
+		template.HTMLEscapeString(text.PromotionHeading),
 		input.Promotion.Title,
 		input.Promotion.Content,
+		template.HTMLEscapeString(text.PromotionButton),
 		input.BusinessName,
 		func() string {
 			if unsubscribeURL != "" {
 				return fmt.Sprintf(`<p style="margin: 0; color: #6c757d; font-size: 12px; text-align: center;">
-                    <a href="%s" style="color: #6c757d; text-decoration: underline;">Unsubscribe from birthday emails</a>
-                </p>`, unsubscribeURL)
+                    <a href="%s" style="color: #6c757d; text-decoration: underline;">%s</a>
+                </p>`, unsubscribeURL, template.HTMLEscapeString(text.PromotionUnsubscribe))
 			}
 			return ""
 		}())
@@ -970,7 +1306,7 @@ type InsertOutgoingEmailInput struct {
 	RecipientEmail string                 `json:"recipientEmail"`
 	RecipientName  *string                `json:"recipientName,omitempty"`
 	SenderEmail    string                 `json:"senderEmail"`
-	SenderName     *string                `json::"senderName,omitempty"`
+	SenderName     *string                `json:"senderName,omitempty"`
 	Subject        string                 `json:"subject"`
 	EmailType      string                 `json:"emailType"` // 'birthday_card', 'test_card', 'promotional', 'newsletter', 'invitation', 'appointment_reminder'
 	Provider       string                 `json:"provider"`  // 'resend', 'sendgrid', 'mailgun', 'other'
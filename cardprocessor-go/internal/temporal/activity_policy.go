@@ -0,0 +1,120 @@
+package temporal
+
+import (
+	"time"
+
+	"cardprocessor-go/internal/config"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// ActivityPolicy is the timeout and retry policy of one kind of activity
+type ActivityPolicy struct {
+	StartToCloseTimeout time.Duration `json:"startToCloseTimeout"`
+	HeartbeatTimeout    time.Duration `json:"heartbeatTimeout,omitempty"`
+	InitialInterval     time.Duration `json:"initialInterval"`
+	MaximumInterval     time.Duration `json:"maximumInterval"`
+	BackoffCoefficient  float64       `json:"backoffCoefficient"`
+	MaximumAttempts     int32         `json:"maximumAttempts"`
+}
+
+// ActivityPolicies are the policies a send workflow runs its activities with. They travel in
+// the workflow input, so a workflow keeps the policies it was started with, and workflows
+// started before they existed fall back to DefaultActivityPolicies.
+type ActivityPolicies struct {
+	Prepare  ActivityPolicy `json:"prepare"`  // tokens, card images, promotions and email content
+	Send     ActivityPolicy `json:"send"`     // the provider send itself
+	Tracking ActivityPolicy `json:"tracking"` // status updates and email_sends records
+}
+
+// DefaultActivityPolicies are the policies every activity ran with before they were configurable
+func DefaultActivityPolicies() ActivityPolicies {
+	policy := ActivityPolicy{
+		StartToCloseTimeout: 5 * time.Minute,
+		HeartbeatTimeout:    1 * time.Minute,
+		InitialInterval:     1 * time.Second,
+		MaximumInterval:     30 * time.Second,
+		BackoffCoefficient:  2.0,
+		MaximumAttempts:     3,
+	}
+	return ActivityPolicies{Prepare: policy, Send: policy, Tracking: policy}
+}
+
+// ActivityPoliciesFromConfig builds the policies from the worker's config. Send retries start
+// BIRTHDAY_RETRY_DELAY apart and a send is retried up to BIRTHDAY_MAX_RETRIES times. None of
+// these activities heartbeat, so the configured policies have no heartbeat timeout and the
+// start-to-close timeout is what limits an attempt.
+func ActivityPoliciesFromConfig(cfg *config.Config) ActivityPolicies {
+	defaults := DefaultActivityPolicies()
+	return ActivityPolicies{
+		Prepare: ActivityPolicy{
+			StartToCloseTimeout: policySeconds(cfg.ActivityPrepareTimeout, defaults.Prepare.StartToCloseTimeout),
+			InitialInterval:     defaults.Prepare.InitialInterval,
+			MaximumInterval:     defaults.Prepare.MaximumInterval,
+			BackoffCoefficient:  defaults.Prepare.BackoffCoefficient,
+			MaximumAttempts:     policyAttempts(cfg.ActivityPrepareMaxAttempts),
+		},
+		Send: ActivityPolicy{
+			StartToCloseTimeout: policySeconds(cfg.ActivitySendTimeout, defaults.Send.StartToCloseTimeout),
+			InitialInterval:     policySeconds(cfg.BirthdayRetryDelay, defaults.Send.InitialInterval),
+			MaximumInterval:     policySeconds(cfg.ActivitySendMaxRetryDelay, defaults.Send.MaximumInterval),
+			BackoffCoefficient:  defaults.Send.BackoffCoefficient,
+			MaximumAttempts:     policyAttempts(cfg.BirthdayMaxRetries + 1),
+		},
+		Tracking: ActivityPolicy{
+			StartToCloseTimeout: policySeconds(cfg.ActivityTrackingTimeout, defaults.Tracking.StartToCloseTimeout),
+			InitialInterval:     defaults.Tracking.InitialInterval,
+			MaximumInterval:     defaults.Tracking.MaximumInterval,
+			BackoffCoefficient:  defaults.Tracking.BackoffCoefficient,
+			MaximumAttempts:     policyAttempts(cfg.ActivityTrackingMaxAttempts),
+		},
+	}
+}
+
+// policySeconds converts a config value in seconds, using fallback when it isn't positive
+func policySeconds(value int, fallback time.Duration) time.Duration {
+	if value <= 0 {
+		return fallback
+	}
+	return time.Duration(value) * time.Second
+}
+
+// policyAttempts keeps a configured attempt count at one or more; Temporal reads 0 as unlimited
+func policyAttempts(value int) int32 {
+	if value < 1 {
+		return 1
+	}
+	return int32(value)
+}
+
+// orDefault returns the policies, or the defaults for a workflow started without any
+func (p *ActivityPolicies) orDefault() ActivityPolicies {
+	if p == nil {
+		return DefaultActivityPolicies()
+	}
+	return *p
+}
+
+// ActivityOptions are the workflow activity options for the policy
+func (p ActivityPolicy) ActivityOptions() workflow.ActivityOptions {
+	maximumInterval := p.MaximumInterval
+	if maximumInterval < p.InitialInterval {
+		maximumInterval = p.InitialInterval
+	}
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: p.StartToCloseTimeout,
+		HeartbeatTimeout:    p.HeartbeatTimeout,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    p.InitialInterval,
+			MaximumInterval:    maximumInterval,
+			BackoffCoefficient: p.BackoffCoefficient,
+			MaximumAttempts:    p.MaximumAttempts,
+		},
+	}
+}
+
+// withActivityPolicy returns ctx with its activities running under the policy
+func withActivityPolicy(ctx workflow.Context, p ActivityPolicy) workflow.Context {
+	return workflow.WithActivityOptions(ctx, p.ActivityOptions())
+}
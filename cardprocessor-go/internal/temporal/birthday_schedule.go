@@ -0,0 +1,576 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cardprocessor-go/internal/i18n"
+	"cardprocessor-go/internal/models"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Birthday run query and signals
+const (
+	QueryBirthdayRunProgress   = "progress"   // returns the run's models.BirthdayJobProgress
+	SignalSetBirthdayRunPaused = "set-paused" // true stops starting new cards, false resumes
+)
+
+// defaultBirthdayRunConcurrency is how many card workflows a run keeps going at once when the
+// input doesn't say
+const defaultBirthdayRunConcurrency = 10
+
+// birthdayRunPageSize is how many contacts a birthday run sends cards to before it continues as
+// new, which keeps each run's history well inside Temporal's size and event limits
+const birthdayRunPageSize = 500
+
+// BirthdaySendWorkflowInput is what a tenant's birthday schedule, or a manual run, passes to
+// BirthdaySendWorkflow
+type BirthdaySendWorkflowInput struct {
+	TenantID    string `json:"tenantId"`
+	Timezone    string `json:"timezone"`
+	DaysAhead   int    `json:"daysAhead"`
+	Trigger     string `json:"trigger,omitempty"`     // schedule, manual
+	Concurrency int    `json:"concurrency,omitempty"` // card workflows running at once
+	// ActivityPolicies are used for the run's own activities and passed on to its cards; nil uses
+	// DefaultActivityPolicies
+	ActivityPolicies *ActivityPolicies `json:"activityPolicies,omitempty"`
+	// Continuation is set when a run continues as new after a page of contacts
+	Continuation *BirthdayRunContinuation `json:"continuation,omitempty"`
+}
+
+// BirthdayRunContinuation is where a birthday run left off when it continued as new
+type BirthdayRunContinuation struct {
+	AfterContactID string                     `json:"afterContactId"` // last contact of the finished page
+	Progress       models.BirthdayJobProgress `json:"progress"`
+	Paused         bool                       `json:"paused"`
+}
+
+// ListBirthdayRecipientsInput selects one page of the contacts a birthday run sends cards to
+type ListBirthdayRecipientsInput struct {
+	TenantID       string    `json:"tenantId"`
+	Timezone       string    `json:"timezone"`
+	DaysAhead      int       `json:"daysAhead"`
+	RunAt          time.Time `json:"runAt"`
+	BirthdayDate   string    `json:"birthdayDate,omitempty"` // set after the first page, so the run keeps its day
+	AfterContactID string    `json:"afterContactId,omitempty"`
+	Limit          int       `json:"limit"`
+}
+
+// BirthdayRecipients is one page of the contacts a birthday run sends cards to
+type BirthdayRecipients struct {
+	BirthdayDate string   `json:"birthdayDate"`
+	ContactIDs   []string `json:"contactIds"`
+	Total        int      `json:"total"` // contacts in the whole run, counted with the first page
+	HasMore      bool     `json:"hasMore"`
+}
+
+// PrepareScheduledBirthdayCardInput names the contact of one scheduled card
+type PrepareScheduledBirthdayCardInput struct {
+	TenantID  string `json:"tenantId"`
+	ContactID string `json:"contactId"`
+	Timezone  string `json:"timezone"`
+}
+
+// ScheduledBirthdayCard is a scheduled card built from the tenant's birthday settings
+type ScheduledBirthdayCard struct {
+	Card    BirthdayTestWorkflowInput `json:"card"`
+	Skipped bool                      `json:"skipped"` // the contact is gone or opted out, or birthday emails are off
+}
+
+// PrepareScheduledBirthdayCardsInput selects the cards for one scheduled run
+type PrepareScheduledBirthdayCardsInput struct {
+	TenantID  string    `json:"tenantId"`
+	Timezone  string    `json:"timezone"`
+	DaysAhead int       `json:"daysAhead"`
+	RunAt     time.Time `json:"runAt"`
+}
+
+// ScheduledBirthdayCards are the cards one scheduled run sends
+type ScheduledBirthdayCards struct {
+	BirthdayDate string                      `json:"birthdayDate"`
+	Cards        []BirthdayTestWorkflowInput `json:"cards"`
+}
+
+// BirthdaySendWorkflow sends a tenant's birthday cards as one batch run. It finds the contacts
+// whose birthday is DaysAhead days from today in the tenant's timezone and runs a
+// BirthdayTestWorkflow for each, with at most Concurrency of them going at once. Card workflow
+// IDs are fixed per contact and birthday, so a run that is retried or triggered twice never
+// sends a card twice.
+//
+// Contacts are read birthdayRunPageSize at a time and passed to the cards as IDs; each card
+// loads its content when it starts. Once a page's cards have ended the run continues as new
+// with the next page, carrying its progress along, so the workflow ID stays the same.
+//
+// The run's progress can be read with the "progress" query, and the "pause" and "resume"
+// signals hold back and release cards that haven't started yet. When the run ends it records
+// its summary in birthday_runs. Canceling the run cancels the cards still in flight.
+func BirthdaySendWorkflow(ctx workflow.Context, input BirthdaySendWorkflowInput) (models.BirthdayJobProgress, error) {
+	logger := workflow.GetLogger(ctx)
+	logger.Info("🎂 Starting birthday send workflow", "tenantId", input.TenantID, "trigger", input.Trigger)
+
+	info := workflow.GetInfo(ctx)
+	progress := models.BirthdayJobProgress{
+		TenantID:   input.TenantID,
+		WorkflowID: info.WorkflowExecution.ID,
+		RunID:      info.WorkflowExecution.RunID,
+		Trigger:    input.Trigger,
+		Status:     models.BirthdayRunRunning,
+		StartedAt:  workflow.Now(ctx).UTC(),
+	}
+	if progress.Trigger == "" {
+		progress.Trigger = models.BirthdayRunTriggerSchedule
+	}
+	paused := false
+	afterContactID := ""
+	if input.Continuation != nil {
+		progress = input.Continuation.Progress
+		progress.RunID = info.WorkflowExecution.RunID
+		paused = input.Continuation.Paused
+		afterContactID = input.Continuation.AfterContactID
+	}
+	concurrency := input.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBirthdayRunConcurrency
+	}
+
+	err := workflow.SetQueryHandler(ctx, QueryBirthdayRunProgress, func() (models.BirthdayJobProgress, error) {
+		return progress, nil
+	})
+	if err != nil {
+		logger.Error("Failed to register progress query handler", "error", err)
+		return progress, err
+	}
+
+	// Pause and resume share one channel, so they apply in the order they were sent
+	pausedCh := workflow.GetSignalChannel(ctx, SignalSetBirthdayRunPaused)
+	setPaused := func(value bool) {
+		if value != paused {
+			if value {
+				logger.Info("⏸️ Birthday send workflow paused", "tenantId", input.TenantID)
+			} else {
+				logger.Info("▶️ Birthday send workflow resumed", "tenantId", input.TenantID)
+			}
+		}
+		paused = value
+		if paused {
+			progress.Status = models.BirthdayRunPaused
+		} else {
+			progress.Status = models.BirthdayRunRunning
+		}
+	}
+	workflow.Go(ctx, func(ctx workflow.Context) {
+		for {
+			var value bool
+			pausedCh.Receive(ctx, &value)
+			setPaused(value)
+		}
+	})
+
+	policies := input.ActivityPolicies.orDefault()
+	ctx = withActivityPolicy(ctx, policies.Prepare)
+
+	// finish records the run's summary, even when the run itself was canceled
+	finish := func(status string) {
+		progress.Status = status
+		completedAt := workflow.Now(ctx).UTC()
+		progress.CompletedAt = &completedAt
+
+		recordCtx, _ := workflow.NewDisconnectedContext(withActivityPolicy(ctx, policies.Tracking))
+		if err := workflow.ExecuteActivity(recordCtx, RecordBirthdayRun, progress).Get(recordCtx, &progress.ID); err != nil {
+			logger.Error("Failed to record birthday run", "error", err)
+		}
+	}
+
+	// Find the cards to send: a page of contact IDs, or every card in full for runs started
+	// before changeBirthdayRunPages
+	var cards []BirthdayTestWorkflowInput
+	hasMore := false
+	if workflow.GetVersion(ctx, changeBirthdayRunPages, workflow.DefaultVersion, versionBirthdayRunPages) == workflow.DefaultVersion {
+		var scheduled ScheduledBirthdayCards
+		err = workflow.ExecuteActivity(ctx, PrepareScheduledBirthdayCards, PrepareScheduledBirthdayCardsInput{
+			TenantID:  input.TenantID,
+			Timezone:  input.Timezone,
+			DaysAhead: input.DaysAhead,
+			RunAt:     workflow.Now(ctx),
+		}).Get(ctx, &scheduled)
+		progress.BirthdayDate = scheduled.BirthdayDate
+		progress.TotalContacts = len(scheduled.Cards)
+		cards = scheduled.Cards
+	} else {
+		var recipients BirthdayRecipients
+		err = workflow.ExecuteActivity(ctx, ListBirthdayRecipients, ListBirthdayRecipientsInput{
+			TenantID:       input.TenantID,
+			Timezone:       input.Timezone,
+			DaysAhead:      input.DaysAhead,
+			RunAt:          workflow.Now(ctx),
+			BirthdayDate:   progress.BirthdayDate,
+			AfterContactID: afterContactID,
+			Limit:          birthdayRunPageSize,
+		}).Get(ctx, &recipients)
+		if input.Continuation == nil {
+			progress.BirthdayDate = recipients.BirthdayDate
+			progress.TotalContacts = recipients.Total
+		}
+		for _, contactID := range recipients.ContactIDs {
+			cards = append(cards, BirthdayTestWorkflowInput{
+				UserID:        contactID,
+				TenantID:      input.TenantID,
+				Timezone:      input.Timezone,
+				ScheduledCard: true,
+			})
+			afterContactID = contactID
+		}
+		hasMore = recipients.HasMore
+	}
+	if temporal.IsCanceledError(err) {
+		finish(models.BirthdayRunCanceled)
+		return progress, err
+	}
+	if err != nil {
+		logger.Error("Failed to prepare birthday cards", "error", err)
+		finish(models.BirthdayRunFailed)
+		return progress, err
+	}
+
+	// Start cards while there is room and the run isn't paused; each one is counted as it ends
+	running := 0
+	for _, card := range cards {
+		err = workflow.Await(ctx, func() bool { return !paused && running < concurrency })
+		if err != nil {
+			break
+		}
+
+		card := card
+		card.ActivityPolicies = input.ActivityPolicies
+		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+			WorkflowID:            fmt.Sprintf("%s%s-%s", birthdayCardPrefix, card.UserID, progress.BirthdayDate),
+			WorkflowIDReusePolicy: enumspb.WORKFLOW_ID_REUSE_POLICY_REJECT_DUPLICATE,
+			Memo:                  map[string]interface{}{memoTenantID: input.TenantID},
+		})
+		child := workflow.ExecuteChildWorkflow(childCtx, BirthdayTestWorkflow, card)
+		running++
+
+		workflow.Go(ctx, func(ctx workflow.Context) {
+			defer func() { running-- }()
+
+			var result BirthdayTestWorkflowResult
+			err := child.Get(ctx, &result)
+			switch {
+			case temporal.IsCanceledError(err):
+				return
+			case temporal.IsWorkflowExecutionAlreadyStartedError(err):
+				progress.AlreadySentCount++
+			case err != nil:
+				logger.Error("Birthday card workflow failed", "contactId", card.UserID, "error", err)
+				progress.FailedCount++
+			case result.Success:
+				progress.SentCount++
+			case result.Skipped:
+				progress.SkippedCount++
+			default:
+				logger.Warn("Birthday card was not sent", "contactId", card.UserID, "error", result.Error)
+				progress.FailedCount++
+			}
+			progress.ProcessedCount++
+		})
+	}
+
+	// Wait for the cards still in flight; on cancellation they are being canceled with the run
+	waitCtx, _ := workflow.NewDisconnectedContext(ctx)
+	_ = workflow.Await(waitCtx, func() bool { return running == 0 })
+
+	if ctx.Err() != nil {
+		logger.Info("🛑 Birthday send workflow canceled", "tenantId", input.TenantID,
+			"processed", progress.ProcessedCount, "total", progress.TotalContacts)
+		finish(models.BirthdayRunCanceled)
+		return progress, temporal.NewCanceledError()
+	}
+
+	if hasMore {
+		// Signals not yet handled would be lost with this run, so apply them first
+		var value bool
+		for pausedCh.ReceiveAsync(&value) {
+			setPaused(value)
+		}
+
+		logger.Info("➡️ Birthday send workflow continuing with the next page", "tenantId", input.TenantID,
+			"processed", progress.ProcessedCount, "total", progress.TotalContacts)
+		next := input
+		next.Continuation = &BirthdayRunContinuation{
+			AfterContactID: afterContactID,
+			Progress:       progress,
+			Paused:         paused,
+		}
+		return progress, workflow.NewContinueAsNewError(ctx, BirthdaySendWorkflow, next)
+	}
+
+	finish(models.BirthdayRunCompleted)
+	logger.Info("✅ Birthday send workflow completed",
+		"tenantId", input.TenantID,
+		"birthdayDate", progress.BirthdayDate,
+		"sent", progress.SentCount,
+		"skipped", progress.SkippedCount,
+		"alreadySent", progress.AlreadySentCount,
+		"failed", progress.FailedCount)
+
+	return progress, nil
+}
+
+// ListBirthdayRecipients returns one page of the contacts, in ID order, whose birthday is
+// DaysAhead days after RunAt in the tenant's timezone (or on BirthdayDate, once the run has one).
+// The page is empty when birthday emails are disabled. In years without 29 February, those
+// birthdays are sent with the 28th's.
+func ListBirthdayRecipients(ctx context.Context, input ListBirthdayRecipientsInput) (BirthdayRecipients, error) {
+	logger := activity.GetLogger(ctx)
+
+	day, err := birthdayRunDay(ctx, input.TenantID, input.Timezone, input.DaysAhead, input.RunAt, input.BirthdayDate)
+	if err != nil {
+		return BirthdayRecipients{}, err
+	}
+	recipients := BirthdayRecipients{
+		BirthdayDate: day.Format("2006-01-02"),
+		ContactIDs:   []string{},
+	}
+
+	settings, err := activityDeps.Repo.GetBirthdaySettings(ctx, input.TenantID)
+	if err != nil {
+		return recipients, err
+	}
+	if settings == nil || !settings.Enabled {
+		logger.Info("🎂 Birthday emails are disabled, nothing to send", "tenantId", input.TenantID)
+		return recipients, nil
+	}
+
+	monthDays := birthdayMonthDays(day)
+	if input.AfterContactID == "" {
+		recipients.Total, err = activityDeps.Repo.CountContactsWithBirthdayOn(ctx, input.TenantID, monthDays...)
+		if err != nil {
+			return recipients, err
+		}
+	}
+
+	// One extra ID tells whether another page follows
+	limit := input.Limit
+	if limit <= 0 {
+		limit = birthdayRunPageSize
+	}
+	ids, err := activityDeps.Repo.GetContactIDsWithBirthdayOn(ctx, input.TenantID, input.AfterContactID, limit+1, monthDays...)
+	if err != nil {
+		return recipients, err
+	}
+	if len(ids) > limit {
+		ids = ids[:limit]
+		recipients.HasMore = true
+	}
+	recipients.ContactIDs = ids
+
+	logger.Info("🎂 Listed birthday recipients", "tenantId", input.TenantID,
+		"birthdayDate", recipients.BirthdayDate, "contacts", len(ids), "hasMore", recipients.HasMore)
+	return recipients, nil
+}
+
+// PrepareScheduledBirthdayCard builds a scheduled card for one contact from the tenant's birthday
+// settings. The card is skipped when birthday emails are off, or the contact is gone, no longer
+// active or has turned birthday emails off.
+func PrepareScheduledBirthdayCard(ctx context.Context, input PrepareScheduledBirthdayCardInput) (ScheduledBirthdayCard, error) {
+	logger := activity.GetLogger(ctx)
+
+	settings, err := activityDeps.Repo.GetBirthdaySettings(ctx, input.TenantID)
+	if err != nil {
+		return ScheduledBirthdayCard{}, err
+	}
+	if settings == nil || !settings.Enabled {
+		logger.Info("🎂 Birthday emails are disabled, skipping card", "tenantId", input.TenantID, "contactId", input.ContactID)
+		return ScheduledBirthdayCard{Skipped: true}, nil
+	}
+
+	contact, err := activityDeps.Repo.GetContactByID(ctx, input.TenantID, input.ContactID)
+	if err != nil {
+		return ScheduledBirthdayCard{}, err
+	}
+	if contact == nil || contact.Status != "active" || !contact.BirthdayEmailEnabled {
+		logger.Info("🎂 Contact no longer gets birthday cards, skipping", "tenantId", input.TenantID, "contactId", input.ContactID)
+		return ScheduledBirthdayCard{Skipped: true}, nil
+	}
+
+	builder := newBirthdayCardBuilder(ctx, input.TenantID, input.Timezone, settings)
+	return ScheduledBirthdayCard{Card: builder.card(*contact)}, nil
+}
+
+// PrepareScheduledBirthdayCards builds the birthday card for every contact whose birthday is
+// DaysAhead days after RunAt in the tenant's timezone, using the tenant's birthday settings.
+// Nothing is sent when birthday emails are disabled. Only runs started before
+// changeBirthdayRunPages use it; newer runs page through ListBirthdayRecipients.
+func PrepareScheduledBirthdayCards(ctx context.Context, input PrepareScheduledBirthdayCardsInput) (ScheduledBirthdayCards, error) {
+	logger := activity.GetLogger(ctx)
+
+	day, err := birthdayRunDay(ctx, input.TenantID, input.Timezone, input.DaysAhead, input.RunAt, "")
+	if err != nil {
+		return ScheduledBirthdayCards{}, err
+	}
+	cards := ScheduledBirthdayCards{
+		BirthdayDate: day.Format("2006-01-02"),
+		Cards:        []BirthdayTestWorkflowInput{},
+	}
+
+	settings, err := activityDeps.Repo.GetBirthdaySettings(ctx, input.TenantID)
+	if err != nil {
+		return cards, err
+	}
+	if settings == nil || !settings.Enabled {
+		logger.Info("🎂 Birthday emails are disabled, nothing to send", "tenantId", input.TenantID)
+		return cards, nil
+	}
+
+	contacts, err := activityDeps.Repo.GetContactsWithBirthdayOn(ctx, input.TenantID, birthdayMonthDays(day)...)
+	if err != nil {
+		return cards, err
+	}
+	if len(contacts) == 0 {
+		logger.Info("🎂 No birthdays to send", "tenantId", input.TenantID, "birthdayDate", cards.BirthdayDate)
+		return cards, nil
+	}
+
+	builder := newBirthdayCardBuilder(ctx, input.TenantID, input.Timezone, settings)
+	for _, contact := range contacts {
+		cards.Cards = append(cards.Cards, builder.card(contact))
+	}
+
+	logger.Info("🎂 Prepared scheduled birthday cards", "tenantId", input.TenantID,
+		"birthdayDate", cards.BirthdayDate, "cards", len(cards.Cards))
+	return cards, nil
+}
+
+// birthdayRunDay is the day whose birthdays a run sends: birthdayDate when the run already has
+// one, otherwise daysAhead days after runAt in the tenant's timezone
+func birthdayRunDay(ctx context.Context, tenantID, timezone string, daysAhead int, runAt time.Time, birthdayDate string) (time.Time, error) {
+	if birthdayDate != "" {
+		day, err := time.Parse("2006-01-02", birthdayDate)
+		if err != nil {
+			return time.Time{}, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("invalid birthday date %q", birthdayDate), "InvalidBirthdayDate", err)
+		}
+		return day, nil
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		activity.GetLogger(ctx).Warn("⚠️ Unknown schedule timezone, using UTC", "tenantId", tenantID, "timezone", timezone)
+		loc = time.UTC
+	}
+	return runAt.In(loc).AddDate(0, 0, daysAhead), nil
+}
+
+// birthdayMonthDays are the MM-DD birthdays sent on day: 29 February birthdays go with the 28th
+// in years without one
+func birthdayMonthDays(day time.Time) []string {
+	monthDays := []string{day.Format("01-02")}
+	if day.Month() == time.February && day.Day() == 28 && day.AddDate(0, 0, 1).Month() == time.March {
+		monthDays = append(monthDays, "02-29")
+	}
+	return monthDays
+}
+
+// birthdayCardBuilder turns contacts into cards with a tenant's birthday settings
+type birthdayCardBuilder struct {
+	tenantID        string
+	timezone        string
+	tenantName      string
+	settings        *models.BirthdaySettings
+	themeData       map[string]interface{}
+	subjectVariants []SubjectVariant
+	defaultLanguage string
+}
+
+// newBirthdayCardBuilder reads what every card of a tenant shares from its birthday settings.
+// Invalid theme data or subject variants are logged and left out.
+func newBirthdayCardBuilder(ctx context.Context, tenantID, timezone string, settings *models.BirthdaySettings) birthdayCardBuilder {
+	logger := activity.GetLogger(ctx)
+	builder := birthdayCardBuilder{
+		tenantID:        tenantID,
+		timezone:        timezone,
+		tenantName:      "Your Company",
+		settings:        settings,
+		defaultLanguage: i18n.ResolveLanguage(settings.DefaultLanguage),
+	}
+
+	if company, err := activityDeps.Repo.GetCompany(ctx, tenantID); err != nil {
+		logger.Warn("⚠️ Failed to fetch company", "tenantId", tenantID, "error", err)
+	} else if company != nil && company.Name != "" {
+		builder.tenantName = company.Name
+	}
+
+	if settings.CustomThemeData != nil && *settings.CustomThemeData != "" {
+		if err := json.Unmarshal([]byte(*settings.CustomThemeData), &builder.themeData); err != nil {
+			logger.Warn("⚠️ Ignoring invalid custom theme data", "tenantId", tenantID, "error", err)
+			builder.themeData = nil
+		}
+	}
+
+	if settings.SubjectVariants != nil {
+		variants, err := ParseSubjectVariants(*settings.SubjectVariants)
+		if err != nil {
+			logger.Warn("⚠️ Ignoring invalid subject variants", "tenantId", tenantID, "error", err)
+		} else {
+			builder.subjectVariants = variants
+		}
+	}
+
+	return builder
+}
+
+// card builds a contact's birthday card in the contact's language
+func (b birthdayCardBuilder) card(contact models.EmailContact) BirthdayTestWorkflowInput {
+	language := i18n.ResolveLanguage(stringValue(contact.PreferredLanguage), b.settings.DefaultLanguage)
+	message := b.settings.CustomMessage
+	if b.settings.LocalizedMessages != nil && language != b.defaultLanguage {
+		message = LocalizedMessage(*b.settings.LocalizedMessages, language, message)
+	}
+
+	return BirthdayTestWorkflowInput{
+		UserID:                contact.ID,
+		UserEmail:             contact.Email,
+		UserFirstName:         stringValue(contact.FirstName),
+		UserLastName:          stringValue(contact.LastName),
+		TenantID:              b.tenantID,
+		TenantName:            b.tenantName,
+		FromEmail:             activityDeps.Config.DefaultFromEmail,
+		EmailTemplate:         b.settings.EmailTemplate,
+		CustomMessage:         message,
+		CustomThemeData:       b.themeData,
+		SenderName:            b.settings.SenderName,
+		PromotionID:           stringValue(b.settings.PromotionID),
+		SplitPromotionalEmail: b.settings.SplitPromotionalEmail,
+		PromotionDelay:        stringValue(b.settings.PromotionDelay),
+		Timezone:              b.timezone,
+		PersonalizedCardImage: b.settings.PersonalizedCardImage,
+		ContactBirthday:       stringValue(contact.Birthday),
+		SubjectTemplate:       stringValue(b.settings.SubjectTemplate),
+		PreheaderText:         stringValue(b.settings.PreheaderText),
+		SubjectVariants:       b.subjectVariants,
+		Language:              language,
+	}
+}
+
+// RecordBirthdayRun saves the summary row of a finished birthday run and returns its ID
+func RecordBirthdayRun(ctx context.Context, run models.BirthdayJobProgress) (string, error) {
+	if err := activityDeps.Repo.SaveBirthdayRun(ctx, &run); err != nil {
+		activity.GetLogger(ctx).Error("❌ Failed to record birthday run", "workflowId", run.WorkflowID, "error", err)
+		return "", err
+	}
+	return run.ID, nil
+}
+
+// stringValue returns the string a pointer holds, or "" for nil
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
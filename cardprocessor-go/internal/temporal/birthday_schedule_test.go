@@ -0,0 +1,190 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cardprocessor-go/internal/models"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// newBirthdayRunTestEnv runs birthday runs with their cards mocked: contact "skip-*" is
+// skipped, every other card is sent
+func newBirthdayRunTestEnv() (*testsuite.TestWorkflowEnvironment, *[]BirthdayTestWorkflowInput) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(BirthdaySendWorkflow)
+	env.RegisterWorkflow(BirthdayTestWorkflow)
+	env.RegisterActivity(ListBirthdayRecipients)
+	env.RegisterActivity(RecordBirthdayRun)
+
+	var cards []BirthdayTestWorkflowInput
+	env.OnWorkflow(BirthdayTestWorkflow, mock.Anything, mock.Anything).Return(
+		func(ctx workflow.Context, input BirthdayTestWorkflowInput) (BirthdayTestWorkflowResult, error) {
+			cards = append(cards, input)
+			if strings.HasPrefix(input.UserID, "skip-") {
+				return BirthdayTestWorkflowResult{Skipped: true}, nil
+			}
+			return BirthdayTestWorkflowResult{Success: true}, nil
+		})
+	return env, &cards
+}
+
+func TestBirthdaySendWorkflowContinuesAsNewAfterEachPage(t *testing.T) {
+	env, cards := newBirthdayRunTestEnv()
+	env.OnActivity(ListBirthdayRecipients, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, input ListBirthdayRecipientsInput) (BirthdayRecipients, error) {
+			require.Empty(t, input.AfterContactID)
+			require.Empty(t, input.BirthdayDate)
+			require.Equal(t, birthdayRunPageSize, input.Limit)
+			return BirthdayRecipients{
+				BirthdayDate: "2026-10-19",
+				ContactIDs:   []string{"contact-1", "skip-2"},
+				Total:        3,
+				HasMore:      true,
+			}, nil
+		})
+
+	env.ExecuteWorkflow(BirthdaySendWorkflow, BirthdaySendWorkflowInput{TenantID: "tenant-1", Timezone: "Europe/Berlin"})
+
+	require.True(t, env.IsWorkflowCompleted())
+	var continueAsNew *workflow.ContinueAsNewError
+	require.True(t, errors.As(env.GetWorkflowError(), &continueAsNew), "got %v", env.GetWorkflowError())
+	env.AssertNotCalled(t, "RecordBirthdayRun", mock.Anything, mock.Anything)
+
+	// The cards carry only the contact and are loaded when they start
+	require.Len(t, *cards, 2)
+	for _, card := range *cards {
+		require.True(t, card.ScheduledCard)
+		require.Equal(t, "tenant-1", card.TenantID)
+		require.Equal(t, "Europe/Berlin", card.Timezone)
+		require.Empty(t, card.UserEmail)
+		require.Nil(t, card.CustomThemeData)
+	}
+
+	var next BirthdaySendWorkflowInput
+	require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(continueAsNew.Input, &next))
+	require.Equal(t, "tenant-1", next.TenantID)
+	require.NotNil(t, next.Continuation)
+	require.Equal(t, "skip-2", next.Continuation.AfterContactID)
+	progress := next.Continuation.Progress
+	require.Equal(t, "2026-10-19", progress.BirthdayDate)
+	require.Equal(t, 3, progress.TotalContacts)
+	require.Equal(t, 2, progress.ProcessedCount)
+	require.Equal(t, 1, progress.SentCount)
+	require.Equal(t, 1, progress.SkippedCount)
+}
+
+func TestBirthdaySendWorkflowFinishesOnTheLastPage(t *testing.T) {
+	env, cards := newBirthdayRunTestEnv()
+	env.OnActivity(ListBirthdayRecipients, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, input ListBirthdayRecipientsInput) (BirthdayRecipients, error) {
+			require.Equal(t, "skip-2", input.AfterContactID)
+			require.Equal(t, "2026-10-19", input.BirthdayDate)
+			return BirthdayRecipients{BirthdayDate: "2026-10-19", ContactIDs: []string{"contact-3"}}, nil
+		})
+	var recorded models.BirthdayJobProgress
+	env.OnActivity(RecordBirthdayRun, mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, run models.BirthdayJobProgress) (string, error) {
+			recorded = run
+			return "run-1", nil
+		})
+
+	env.ExecuteWorkflow(BirthdaySendWorkflow, BirthdaySendWorkflowInput{
+		TenantID: "tenant-1",
+		Continuation: &BirthdayRunContinuation{
+			AfterContactID: "skip-2",
+			Progress: models.BirthdayJobProgress{
+				TenantID:       "tenant-1",
+				Trigger:        models.BirthdayRunTriggerSchedule,
+				Status:         models.BirthdayRunRunning,
+				BirthdayDate:   "2026-10-19",
+				TotalContacts:  3,
+				ProcessedCount: 2,
+				SentCount:      1,
+				SkippedCount:   1,
+			},
+		},
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	require.Len(t, *cards, 1)
+
+	var progress models.BirthdayJobProgress
+	require.NoError(t, env.GetWorkflowResult(&progress))
+	require.Equal(t, "run-1", progress.ID)
+	require.Equal(t, models.BirthdayRunCompleted, recorded.Status)
+	require.Equal(t, 3, recorded.TotalContacts)
+	require.Equal(t, 3, recorded.ProcessedCount)
+	require.Equal(t, 2, recorded.SentCount)
+	require.Equal(t, 1, recorded.SkippedCount)
+}
+
+func TestBirthdayTestWorkflowSkipsScheduledCardOfGoneContact(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterWorkflow(BirthdayTestWorkflow)
+	env.RegisterActivity(PrepareScheduledBirthdayCard)
+	env.OnActivity(PrepareScheduledBirthdayCard, mock.Anything, PrepareScheduledBirthdayCardInput{
+		TenantID:  "tenant-1",
+		ContactID: "contact-1",
+		Timezone:  "UTC",
+	}).Return(ScheduledBirthdayCard{Skipped: true}, nil)
+
+	env.ExecuteWorkflow(BirthdayTestWorkflow, BirthdayTestWorkflowInput{
+		UserID:        "contact-1",
+		TenantID:      "tenant-1",
+		Timezone:      "UTC",
+		ScheduledCard: true,
+	})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+	var result BirthdayTestWorkflowResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	require.True(t, result.Skipped)
+	require.False(t, result.Success)
+	env.AssertExpectations(t)
+}
+
+func TestBirthdaySendWorkflowCarriesTheLastPauseSignalOver(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals []bool
+		paused  bool
+	}{
+		{"pause then resume", []bool{true, false}, false},
+		{"resume then pause", []bool{false, true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, _ := newBirthdayRunTestEnv()
+			// The signals arrive together while the page is loading
+			env.OnActivity(ListBirthdayRecipients, mock.Anything, mock.Anything).
+				Return(BirthdayRecipients{BirthdayDate: "2026-10-19", Total: 1, HasMore: true}, nil).
+				After(time.Minute)
+			env.RegisterDelayedCallback(func() {
+				for _, paused := range tt.signals {
+					env.SignalWorkflow(SignalSetBirthdayRunPaused, paused)
+				}
+			}, time.Second)
+
+			env.ExecuteWorkflow(BirthdaySendWorkflow, BirthdaySendWorkflowInput{TenantID: "tenant-1"})
+
+			var continueAsNew *workflow.ContinueAsNewError
+			require.True(t, errors.As(env.GetWorkflowError(), &continueAsNew), "got %v", env.GetWorkflowError())
+			var next BirthdaySendWorkflowInput
+			require.NoError(t, converter.GetDefaultDataConverter().FromPayloads(continueAsNew.Input, &next))
+			require.Equal(t, tt.paused, next.Continuation.Paused)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cardprocessor-go/internal/assets"
+	"cardprocessor-go/internal/i18n"
+
+	"go.temporal.io/sdk/activity"
+)
+
+// CardImageRenderer renders personalized card images and returns their public URL
+type CardImageRenderer interface {
+	RenderCardImage(ctx context.Context, req assets.CardImageRequest) (string, error)
+}
+
+// cardImageRenderer is used by GenerateBirthdayCardImage; nil disables personalized images
+var cardImageRenderer CardImageRenderer
+
+// SetCardImageRenderer sets the renderer used for personalized card images
+func SetCardImageRenderer(renderer CardImageRenderer) {
+	cardImageRenderer = renderer
+}
+
+// CardImageInput represents the input for rendering a personalized card image
+type CardImageInput struct {
+	TenantID        string                 `json:"tenantId"`
+	ContactID       string                 `json:"contactId"`
+	Name            string                 `json:"name"`
+	Birthday        string                 `json:"birthday,omitempty"` // YYYY-MM-DD, used for the age
+	EmailTemplate   string                 `json:"emailTemplate"`
+	CustomThemeData map[string]interface{} `json:"customThemeData"`
+	Year            int                    `json:"year"`
+	Language        string                 `json:"language,omitempty"`
+}
+
+// CardImageResult represents the rendered card image
+type CardImageResult struct {
+	Success  bool   `json:"success"`
+	ImageURL string `json:"imageUrl,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// GenerateBirthdayCardImage renders (or reuses) the personalized card image for a contact
+func GenerateBirthdayCardImage(ctx context.Context, input CardImageInput) (CardImageResult, error) {
+	logger := activity.GetLogger(ctx)
+	logger.Info("🖼️ Generating personalized card image", "contactId", input.ContactID, "template", input.EmailTemplate, "year", input.Year)
+
+	if cardImageRenderer == nil {
+		return CardImageResult{Success: false, Error: "card image rendering is not configured"}, nil
+	}
+
+	// The card fonts have no shaping or bidi support, so right-to-left text keeps the template header
+	if i18n.IsRTL(input.Language) || i18n.ContainsRTL(input.Name) {
+		return CardImageResult{Success: false, Error: "card images do not support right-to-left text"}, nil
+	}
+
+	req := buildCardImageRequest(input)
+	imageURL, err := cardImageRenderer.RenderCardImage(ctx, req)
+	if err != nil {
+		logger.Error("Failed to render card image", "error", err)
+		return CardImageResult{Success: false, Error: err.Error()}, err
+	}
+
+	logger.Info("✅ Card image ready", "url", imageURL, "templateVersion", req.TemplateVersion())
+	return CardImageResult{Success: true, ImageURL: imageURL}, nil
+}
+
+// buildCardImageRequest resolves the template background, colors and logo from the theme data
+func buildCardImageRequest(input CardImageInput) assets.CardImageRequest {
+	templateId := ParseTemplateId(input.EmailTemplate)
+
+	colors := themeColors[templateId]
+	if colors.Primary == "" {
+		colors = themeColors[TemplateDefault]
+	}
+	background := themeHeaders[templateId]
+	if templateId == TemplateCustom {
+		background = ""
+	}
+
+	themeData := input.CustomThemeData
+	if themes, ok := themeData["themes"].(map[string]interface{}); ok {
+		if specific, ok := themes[string(templateId)].(map[string]interface{}); ok {
+			themeData = specific
+		}
+	}
+
+	for _, key := range []string{"imageUrl", "backgroundUrl"} {
+		if value, ok := themeData[key].(string); ok && value != "" {
+			background = value
+			break
+		}
+	}
+	if value, ok := themeData["primaryColor"].(string); ok && value != "" {
+		colors.Primary = value
+	}
+	if value, ok := themeData["secondaryColor"].(string); ok && value != "" {
+		colors.Secondary = value
+	}
+
+	logo, _ := themeData["logoUrl"].(string)
+	if logo == "" {
+		logo, _ = input.CustomThemeData["logoUrl"].(string)
+	}
+
+	return assets.CardImageRequest{
+		TemplateID:     string(templateId),
+		BackgroundURL:  background,
+		LogoURL:        logo,
+		PrimaryColor:   colors.Primary,
+		SecondaryColor: colors.Secondary,
+		ContactID:      input.ContactID,
+		Name:           input.Name,
+		Age:            ageOnBirthday(input.Birthday, input.Year),
+		Year:           input.Year,
+		Language:       input.Language,
+	}
+}
+
+// ageOnBirthday returns the age reached in year, or 0 when the birth year is missing or implausible
+func ageOnBirthday(birthday string, year int) int {
+	if len(birthday) < 10 {
+		return 0
+	}
+	born, err := time.Parse("2006-01-02", birthday[:10])
+	if err != nil {
+		fmt.Printf("⚠️ [ageOnBirthday] Unparseable birthday %q: %v\n", birthday, err)
+		return 0
+	}
+	age := year - born.Year()
+	if born.Year() <= 1900 || age < 1 || age > 120 {
+		return 0
+	}
+	return age
+}
+
+// cardImageName picks the name printed on the card image
+func cardImageName(firstName, lastName string) string {
+	if name := strings.TrimSpace(firstName); name != "" {
+		return name
+	}
+	return strings.TrimSpace(lastName)
+}
@@ -2,16 +2,77 @@ package temporal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"cardprocessor-go/internal/config"
+	"cardprocessor-go/internal/models"
 
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
 	"go.temporal.io/sdk/worker"
 )
 
+// memoTenantID is the memo field recording which tenant a send workflow belongs to
+const memoTenantID = "tenantId"
+
+// Workflow type names of the send workflows exposed through the status API
+const (
+	sendWorkflowTestType       = "BirthdayTestWorkflow"
+	sendWorkflowInvitationType = "BirthdayInvitationWorkflow"
+	birthdayRunWorkflowType    = "BirthdaySendWorkflow"
+)
+
+// birthdaySchedulePrefix starts the ID of every tenant's birthday schedule
+const birthdaySchedulePrefix = "birthday-schedule-"
+
+// birthdayCardPrefix starts the ID of every scheduled card workflow,
+// birthday-card-<contact ID>-<birthday date>
+const birthdayCardPrefix = "birthday-card-"
+
+// BirthdayScheduleInfo is the state of a tenant's birthday schedule in Temporal
+type BirthdayScheduleInfo struct {
+	ScheduleID string                `json:"scheduleId"`
+	Paused     bool                  `json:"paused"`
+	NextRuns   []time.Time           `json:"nextRuns"`
+	RecentRuns []BirthdayScheduleRun `json:"recentRuns"`
+}
+
+// BirthdayScheduleRun is one run the birthday schedule has started
+type BirthdayScheduleRun struct {
+	ScheduledAt time.Time `json:"scheduledAt"`
+	StartedAt   time.Time `json:"startedAt"`
+	WorkflowID  string    `json:"workflowId,omitempty"`
+	RunID       string    `json:"runId,omitempty"`
+}
+
+// ErrWorkflowNotRunning is returned when cancelling a workflow that has already finished
+var ErrWorkflowNotRunning = errors.New("workflow is not running")
+
+// ErrAdvancedVisibilityRequired is returned when the Temporal server rejects a visibility query
+// that needs advanced visibility, such as WorkflowId STARTS_WITH
+var ErrAdvancedVisibilityRequired = errors.New("the Temporal server doesn't support this visibility query; it needs advanced visibility")
+
+// SendWorkflowStatus describes a send workflow for the status API
+type SendWorkflowStatus struct {
+	WorkflowID   string      `json:"workflowId"`
+	RunID        string      `json:"runId"`
+	WorkflowType string      `json:"workflowType"`
+	Status       string      `json:"status"` // Running, Completed, Failed, Canceled, Terminated, TimedOut
+	CurrentStep  string      `json:"currentStep,omitempty"`
+	StartedAt    *time.Time  `json:"startedAt,omitempty"`
+	ClosedAt     *time.Time  `json:"closedAt,omitempty"`
+	Result       interface{} `json:"result,omitempty"` // *BirthdayTestWorkflowResult or *BirthdayInvitationWorkflowResult
+	Error        string      `json:"error,omitempty"`
+}
+
 // TemporalClient wraps the Temporal client and worker
 type TemporalClient struct {
 	client client.Client
@@ -38,8 +99,10 @@ func NewTemporalClient(cfg *config.Config) (*TemporalClient, error) {
 
 	// Create worker
 	w := worker.New(c, cfg.TemporalTaskQueue, worker.Options{
-		MaxConcurrentActivityExecutionSize:     10,
-		MaxConcurrentWorkflowTaskExecutionSize: 5,
+		MaxConcurrentActivityExecutionSize:     cfg.TemporalWorker.MaxConcurrentActivities,
+		MaxConcurrentWorkflowTaskExecutionSize: cfg.TemporalWorker.MaxConcurrentWorkflowTasks,
+		MaxConcurrentActivityTaskPollers:       cfg.TemporalWorker.ActivityPollers,
+		MaxConcurrentWorkflowTaskPollers:       cfg.TemporalWorker.WorkflowPollers,
 	})
 
 	// Register activities
@@ -71,13 +134,23 @@ func (tc *TemporalClient) Stop() {
 	}
 }
 
+// activityPolicies are the activity policies new send workflows are started with
+func (tc *TemporalClient) activityPolicies() *ActivityPolicies {
+	policies := ActivityPoliciesFromConfig(tc.config)
+	return &policies
+}
+
 // StartBirthdayTestWorkflow starts a birthday test workflow
 func (tc *TemporalClient) StartBirthdayTestWorkflow(ctx context.Context, input BirthdayTestWorkflowInput) (client.WorkflowRun, error) {
 	workflowID := fmt.Sprintf("birthday-test-%s-%d", input.UserID, time.Now().Unix())
+	if input.ActivityPolicies == nil {
+		input.ActivityPolicies = tc.activityPolicies()
+	}
 
 	workflowOptions := client.StartWorkflowOptions{
 		ID:        workflowID,
 		TaskQueue: tc.config.TemporalTaskQueue,
+		Memo:      map[string]interface{}{memoTenantID: input.TenantID},
 	}
 
 	workflowRun, err := tc.client.ExecuteWorkflow(ctx, workflowOptions, BirthdayTestWorkflow, input)
@@ -92,10 +165,14 @@ func (tc *TemporalClient) StartBirthdayTestWorkflow(ctx context.Context, input B
 // StartBirthdayInvitationWorkflow starts a birthday invitation workflow
 func (tc *TemporalClient) StartBirthdayInvitationWorkflow(ctx context.Context, input BirthdayInvitationWorkflowInput) (client.WorkflowRun, error) {
 	workflowID := fmt.Sprintf("birthday-invitation-%s-%d", input.ContactID, time.Now().Unix())
+	if input.ActivityPolicies == nil {
+		input.ActivityPolicies = tc.activityPolicies()
+	}
 
 	workflowOptions := client.StartWorkflowOptions{
 		ID:        workflowID,
 		TaskQueue: tc.config.TemporalTaskQueue,
+		Memo:      map[string]interface{}{memoTenantID: input.TenantID},
 	}
 
 	workflowRun, err := tc.client.ExecuteWorkflow(ctx, workflowOptions, BirthdayInvitationWorkflow, input)
@@ -107,6 +184,499 @@ func (tc *TemporalClient) StartBirthdayInvitationWorkflow(ctx context.Context, i
 	return workflowRun, nil
 }
 
+// SyncRetentionPurgeSchedule creates the retention purge schedule, or brings an existing one in
+// line with RETENTION_PURGE_CRON. An empty cron deletes the schedule. A purge workflow left
+// running from the old cron setup is terminated, so the purge doesn't run twice.
+func (tc *TemporalClient) SyncRetentionPurgeSchedule(ctx context.Context) error {
+	if err := tc.terminateRetentionPurgeCron(ctx); err != nil {
+		return err
+	}
+
+	handle := tc.client.ScheduleClient().GetHandle(ctx, RetentionPurgeScheduleID)
+	if tc.config.RetentionPurgeCron == "" {
+		if err := handle.Delete(ctx); err != nil {
+			if isNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to delete retention purge schedule: %w", err)
+		}
+		log.Printf("🗑️ Deleted retention purge schedule %s", RetentionPurgeScheduleID)
+		return nil
+	}
+
+	spec := client.ScheduleSpec{
+		CronExpressions: []string{tc.config.RetentionPurgeCron},
+		TimeZoneName:    "UTC",
+	}
+	action := &client.ScheduleWorkflowAction{
+		ID:        RetentionPurgeWorkflowID,
+		Workflow:  RetentionPurgeWorkflow,
+		TaskQueue: tc.config.TemporalTaskQueue,
+	}
+
+	if _, err := handle.Describe(ctx); err != nil {
+		if !isNotFound(err) {
+			return fmt.Errorf("failed to describe retention purge schedule: %w", err)
+		}
+		_, err = tc.client.ScheduleClient().Create(ctx, client.ScheduleOptions{
+			ID:            RetentionPurgeScheduleID,
+			Spec:          spec,
+			Action:        action,
+			Overlap:       enumspb.SCHEDULE_OVERLAP_POLICY_SKIP,
+			CatchupWindow: time.Hour,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create retention purge schedule: %w", err)
+		}
+		log.Printf("✅ Created retention purge schedule %s (cron: %s UTC)", RetentionPurgeScheduleID, tc.config.RetentionPurgeCron)
+		return nil
+	}
+
+	err := handle.Update(ctx, client.ScheduleUpdateOptions{
+		DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+			updated := input.Description.Schedule
+			updated.Spec = &spec
+			updated.Action = action
+			return &client.ScheduleUpdate{Schedule: &updated}, nil
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update retention purge schedule: %w", err)
+	}
+
+	log.Printf("✅ Updated retention purge schedule %s (cron: %s UTC)", RetentionPurgeScheduleID, tc.config.RetentionPurgeCron)
+	return nil
+}
+
+// terminateRetentionPurgeCron ends the cron workflow that ran the purge before it moved to a
+// schedule, if it is still running
+func (tc *TemporalClient) terminateRetentionPurgeCron(ctx context.Context) error {
+	resp, err := tc.client.DescribeWorkflowExecution(ctx, RetentionPurgeWorkflowID, "")
+	if err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to describe retention purge cron workflow: %w", err)
+	}
+	if resp.GetWorkflowExecutionInfo().GetStatus() != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		return nil
+	}
+
+	err = tc.client.TerminateWorkflow(ctx, RetentionPurgeWorkflowID, "", "Replaced by the retention purge schedule")
+	if err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to terminate retention purge cron workflow: %w", err)
+	}
+	log.Printf("🛑 Terminated retention purge cron workflow %s", RetentionPurgeWorkflowID)
+	return nil
+}
+
+// DescribeSendWorkflow reports the status, current step and, once it has finished, the
+// result of one of a tenant's send workflows. It returns nil when the workflow doesn't exist
+// or belongs to another tenant.
+func (tc *TemporalClient) DescribeSendWorkflow(ctx context.Context, tenantID, workflowID string) (*SendWorkflowStatus, error) {
+	resp, err := tc.client.DescribeWorkflowExecution(ctx, workflowID, "")
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe workflow: %w", err)
+	}
+
+	info := resp.GetWorkflowExecutionInfo()
+	workflowType := info.GetType().GetName()
+	if workflowType != sendWorkflowTestType && workflowType != sendWorkflowInvitationType {
+		return nil, nil
+	}
+	if workflowTenantID(info.GetMemo()) != tenantID {
+		return nil, nil
+	}
+
+	runID := info.GetExecution().GetRunId()
+	status := &SendWorkflowStatus{
+		WorkflowID:   workflowID,
+		RunID:        runID,
+		WorkflowType: workflowType,
+		Status:       info.GetStatus().String(),
+		StartedAt:    info.GetStartTime(),
+		ClosedAt:     info.GetCloseTime(),
+	}
+
+	// Closed workflows answer the query by replaying their history, so this works after the
+	// send has finished as long as a worker is running
+	if value, err := tc.client.QueryWorkflow(ctx, workflowID, runID, QueryCurrentStep); err != nil {
+		log.Printf("⚠️ Failed to query current step of workflow %s: %v", workflowID, err)
+	} else if err := value.Get(&status.CurrentStep); err != nil {
+		log.Printf("⚠️ Failed to decode current step of workflow %s: %v", workflowID, err)
+	}
+
+	if info.GetStatus() == enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		return status, nil
+	}
+
+	run := tc.client.GetWorkflow(ctx, workflowID, runID)
+	if workflowType == sendWorkflowTestType {
+		var result BirthdayTestWorkflowResult
+		if err := run.Get(ctx, &result); err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Result = &result
+		}
+	} else {
+		var result BirthdayInvitationWorkflowResult
+		if err := run.Get(ctx, &result); err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Result = &result
+		}
+	}
+
+	return status, nil
+}
+
+// CancelSendWorkflow requests cancellation of one of a tenant's running send workflows. Emails
+// that haven't gone out yet, such as the promotional email during the split flow delay, are not
+// sent. It returns nil when the workflow doesn't exist or belongs to another tenant, and
+// ErrWorkflowNotRunning when it has already finished.
+func (tc *TemporalClient) CancelSendWorkflow(ctx context.Context, tenantID, workflowID string) (*SendWorkflowStatus, error) {
+	status, err := tc.DescribeSendWorkflow(ctx, tenantID, workflowID)
+	if err != nil || status == nil {
+		return status, err
+	}
+	if status.Status != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING.String() {
+		return status, ErrWorkflowNotRunning
+	}
+
+	if err := tc.client.CancelWorkflow(ctx, workflowID, status.RunID); err != nil {
+		return nil, fmt.Errorf("failed to cancel workflow: %w", err)
+	}
+
+	log.Printf("🛑 Requested cancellation of workflow: %s", workflowID)
+	return status, nil
+}
+
+// CancelPendingPromotions signals the contact's running birthday card workflows not to send
+// their split promotional email, and returns how many were signaled. Cards that have already
+// sent the promotion, or never split it, ignore the signal.
+//
+// Only scheduled cards (birthday-card-<contactId>-<date>) are matched. Test cards
+// (birthday-test-*) go to the user who asked for them, not to a contact, so an unsubscribe
+// never concerns them. The lookup uses WorkflowId STARTS_WITH, which needs advanced visibility
+// (Temporal 1.20+ with SQL, or Elasticsearch); on servers without it this returns
+// ErrAdvancedVisibilityRequired and the promotions are only stopped by the send-time
+// subscription check.
+func (tc *TemporalClient) CancelPendingPromotions(ctx context.Context, tenantID, contactID, reason string) (int, error) {
+	if contactID == "" || strings.ContainsAny(contactID, `'"\`) {
+		return 0, fmt.Errorf("invalid contact ID: %q", contactID)
+	}
+	// Only the contact's cards are listed; their IDs start with the contact ID
+	prefix := birthdayCardPrefix + contactID + "-"
+	request := &workflowservice.ListWorkflowExecutionsRequest{
+		Namespace: tc.config.TemporalNamespace,
+		Query: fmt.Sprintf("WorkflowType = '%s' AND ExecutionStatus = 'Running' AND WorkflowId STARTS_WITH '%s'",
+			sendWorkflowTestType, prefix),
+	}
+
+	signaled := 0
+	for {
+		resp, err := tc.client.ListWorkflow(ctx, request)
+		if isInvalidArgument(err) {
+			return signaled, fmt.Errorf("failed to list card workflows: %w: %v", ErrAdvancedVisibilityRequired, err)
+		}
+		if err != nil {
+			return signaled, fmt.Errorf("failed to list card workflows: %w", err)
+		}
+
+		for _, info := range resp.GetExecutions() {
+			execution := info.GetExecution()
+			if workflowTenantID(info.GetMemo()) != tenantID {
+				continue
+			}
+			err := tc.client.SignalWorkflow(ctx, execution.GetWorkflowId(), execution.GetRunId(), SignalCancelPromotion, reason)
+			if err != nil {
+				// The card may have finished since it was listed
+				if isNotFound(err) {
+					continue
+				}
+				return signaled, fmt.Errorf("failed to signal card workflow: %w", err)
+			}
+			signaled++
+			log.Printf("🚫 Canceled pending promotion of card workflow: %s (%s)", execution.GetWorkflowId(), reason)
+		}
+
+		if len(resp.GetNextPageToken()) == 0 {
+			return signaled, nil
+		}
+		request.NextPageToken = resp.GetNextPageToken()
+	}
+}
+
+// StartBirthdaySendWorkflow starts a birthday run for the tenant now, using their schedule's
+// timezone and days ahead. Cards the tenant's schedule has already sent are not sent again.
+func (tc *TemporalClient) StartBirthdaySendWorkflow(ctx context.Context, schedule models.BirthdaySchedule) (client.WorkflowRun, error) {
+	workflowID := fmt.Sprintf("birthday-send-%s-manual-%d", schedule.TenantID, time.Now().Unix())
+
+	workflowOptions := client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: tc.config.TemporalTaskQueue,
+		Memo:      map[string]interface{}{memoTenantID: schedule.TenantID},
+	}
+
+	workflowRun, err := tc.client.ExecuteWorkflow(ctx, workflowOptions, BirthdaySendWorkflow, BirthdaySendWorkflowInput{
+		TenantID:         schedule.TenantID,
+		Timezone:         schedule.Timezone,
+		DaysAhead:        schedule.DaysAhead,
+		Trigger:          models.BirthdayRunTriggerManual,
+		Concurrency:      tc.config.BirthdayBatchSize,
+		ActivityPolicies: tc.activityPolicies(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start birthday send workflow: %w", err)
+	}
+
+	log.Printf("✅ Started birthday send workflow: %s", workflowID)
+	return workflowRun, nil
+}
+
+// DescribeBirthdayRun reports the progress of one of a tenant's birthday runs by querying the
+// workflow. It returns nil when the workflow doesn't exist, isn't a birthday run or belongs to
+// another tenant.
+func (tc *TemporalClient) DescribeBirthdayRun(ctx context.Context, tenantID, workflowID string) (*models.BirthdayJobProgress, error) {
+	resp, err := tc.client.DescribeWorkflowExecution(ctx, workflowID, "")
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe workflow: %w", err)
+	}
+
+	info := resp.GetWorkflowExecutionInfo()
+	if info.GetType().GetName() != birthdayRunWorkflowType || workflowTenantID(info.GetMemo()) != tenantID {
+		return nil, nil
+	}
+
+	runID := info.GetExecution().GetRunId()
+	progress := &models.BirthdayJobProgress{
+		TenantID:   tenantID,
+		WorkflowID: workflowID,
+		RunID:      runID,
+		Status:     models.BirthdayRunRunning,
+	}
+	if info.GetStartTime() != nil {
+		progress.StartedAt = *info.GetStartTime()
+	}
+
+	if value, err := tc.client.QueryWorkflow(ctx, workflowID, runID, QueryBirthdayRunProgress); err != nil {
+		log.Printf("⚠️ Failed to query progress of birthday run %s: %v", workflowID, err)
+	} else if err := value.Get(progress); err != nil {
+		log.Printf("⚠️ Failed to decode progress of birthday run %s: %v", workflowID, err)
+	}
+
+	// A run that was terminated or timed out never recorded how it ended
+	running := info.GetStatus() == enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING
+	if !running && (progress.Status == models.BirthdayRunRunning || progress.Status == models.BirthdayRunPaused) {
+		progress.Status = strings.ToLower(info.GetStatus().String())
+		progress.CompletedAt = info.GetCloseTime()
+	}
+
+	return progress, nil
+}
+
+// SignalBirthdayRun signals one of a tenant's running birthday runs to pause or resume. It returns nil when the workflow doesn't exist, isn't a birthday run or belongs to
+// another tenant, and ErrWorkflowNotRunning when it has already finished.
+func (tc *TemporalClient) SignalBirthdayRun(ctx context.Context, tenantID, workflowID string, paused bool) (*models.BirthdayJobProgress, error) {
+	progress, err := tc.DescribeBirthdayRun(ctx, tenantID, workflowID)
+	if err != nil || progress == nil {
+		return progress, err
+	}
+	if progress.Status != models.BirthdayRunRunning && progress.Status != models.BirthdayRunPaused {
+		return progress, ErrWorkflowNotRunning
+	}
+
+	if err := tc.client.SignalWorkflow(ctx, workflowID, progress.RunID, SignalSetBirthdayRunPaused, paused); err != nil {
+		return nil, fmt.Errorf("failed to signal workflow: %w", err)
+	}
+
+	log.Printf("📨 Sent %s=%t signal to birthday run: %s", SignalSetBirthdayRunPaused, paused, workflowID)
+	return progress, nil
+}
+
+// BirthdayScheduleID is the ID of the Temporal Schedule that sends a tenant's birthday cards
+func BirthdayScheduleID(tenantID string) string {
+	return birthdaySchedulePrefix + tenantID
+}
+
+// SyncBirthdaySchedule creates the tenant's birthday schedule, or brings an existing one in line
+// with the stored send hour, timezone, days ahead and paused state
+func (tc *TemporalClient) SyncBirthdaySchedule(ctx context.Context, schedule models.BirthdaySchedule) error {
+	scheduleID := BirthdayScheduleID(schedule.TenantID)
+	spec := client.ScheduleSpec{
+		CronExpressions: []string{fmt.Sprintf("0 %d * * *", schedule.SendHour)},
+		TimeZoneName:    schedule.Timezone,
+	}
+	action := &client.ScheduleWorkflowAction{
+		ID:       "birthday-send-" + schedule.TenantID,
+		Workflow: BirthdaySendWorkflow,
+		Args: []interface{}{BirthdaySendWorkflowInput{
+			TenantID:         schedule.TenantID,
+			Timezone:         schedule.Timezone,
+			DaysAhead:        schedule.DaysAhead,
+			Trigger:          models.BirthdayRunTriggerSchedule,
+			Concurrency:      tc.config.BirthdayBatchSize,
+			ActivityPolicies: tc.activityPolicies(),
+		}},
+		TaskQueue: tc.config.TemporalTaskQueue,
+		Memo:      map[string]interface{}{memoTenantID: schedule.TenantID},
+	}
+
+	handle := tc.client.ScheduleClient().GetHandle(ctx, scheduleID)
+	description, err := handle.Describe(ctx)
+	if err != nil {
+		if !isNotFound(err) {
+			return fmt.Errorf("failed to describe birthday schedule: %w", err)
+		}
+		_, err = tc.client.ScheduleClient().Create(ctx, client.ScheduleOptions{
+			ID:            scheduleID,
+			Spec:          spec,
+			Action:        action,
+			Overlap:       enumspb.SCHEDULE_OVERLAP_POLICY_SKIP,
+			CatchupWindow: time.Hour,
+			Paused:        schedule.Paused,
+			Memo:          map[string]interface{}{memoTenantID: schedule.TenantID},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create birthday schedule: %w", err)
+		}
+		log.Printf("✅ Created birthday schedule %s (%02d:00 %s, %d day(s) ahead)", scheduleID, schedule.SendHour, schedule.Timezone, schedule.DaysAhead)
+		return nil
+	}
+
+	err = handle.Update(ctx, client.ScheduleUpdateOptions{
+		DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+			updated := input.Description.Schedule
+			updated.Spec = &spec
+			updated.Action = action
+			return &client.ScheduleUpdate{Schedule: &updated}, nil
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update birthday schedule: %w", err)
+	}
+
+	paused := description.Schedule.State != nil && description.Schedule.State.Paused
+	if schedule.Paused && !paused {
+		err = handle.Pause(ctx, client.SchedulePauseOptions{Note: "Paused from birthday settings"})
+	} else if !schedule.Paused && paused {
+		err = handle.Unpause(ctx, client.ScheduleUnpauseOptions{Note: "Resumed from birthday settings"})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to change birthday schedule state: %w", err)
+	}
+
+	log.Printf("✅ Updated birthday schedule %s (%02d:00 %s, %d day(s) ahead, paused: %v)", scheduleID, schedule.SendHour, schedule.Timezone, schedule.DaysAhead, schedule.Paused)
+	return nil
+}
+
+// DescribeBirthdaySchedule reports the state of a tenant's birthday schedule in Temporal, or
+// nil when it has none
+func (tc *TemporalClient) DescribeBirthdaySchedule(ctx context.Context, tenantID string) (*BirthdayScheduleInfo, error) {
+	scheduleID := BirthdayScheduleID(tenantID)
+	description, err := tc.client.ScheduleClient().GetHandle(ctx, scheduleID).Describe(ctx)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe birthday schedule: %w", err)
+	}
+
+	info := &BirthdayScheduleInfo{
+		ScheduleID: scheduleID,
+		Paused:     description.Schedule.State != nil && description.Schedule.State.Paused,
+		NextRuns:   description.Info.NextActionTimes,
+		RecentRuns: make([]BirthdayScheduleRun, 0, len(description.Info.RecentActions)),
+	}
+	for _, action := range description.Info.RecentActions {
+		run := BirthdayScheduleRun{ScheduledAt: action.ScheduleTime, StartedAt: action.ActualTime}
+		if action.StartWorkflowResult != nil {
+			run.WorkflowID = action.StartWorkflowResult.WorkflowID
+			run.RunID = action.StartWorkflowResult.FirstExecutionRunID
+		}
+		info.RecentRuns = append(info.RecentRuns, run)
+	}
+
+	return info, nil
+}
+
+// DeleteBirthdaySchedule removes a tenant's birthday schedule; a missing schedule is not an error
+func (tc *TemporalClient) DeleteBirthdaySchedule(ctx context.Context, tenantID string) error {
+	scheduleID := BirthdayScheduleID(tenantID)
+	if err := tc.client.ScheduleClient().GetHandle(ctx, scheduleID).Delete(ctx); err != nil && !isNotFound(err) {
+		return fmt.Errorf("failed to delete birthday schedule: %w", err)
+	}
+	log.Printf("🗑️ Deleted birthday schedule %s", scheduleID)
+	return nil
+}
+
+// ReconcileBirthdaySchedules makes Temporal match the stored schedules: each one is created or
+// updated, and birthday schedules without a stored schedule are deleted. A tenant that fails is
+// logged and the rest still go ahead.
+func (tc *TemporalClient) ReconcileBirthdaySchedules(ctx context.Context, schedules []models.BirthdaySchedule) error {
+	wanted := make(map[string]bool, len(schedules))
+	for _, schedule := range schedules {
+		wanted[BirthdayScheduleID(schedule.TenantID)] = true
+		if err := tc.SyncBirthdaySchedule(ctx, schedule); err != nil {
+			log.Printf("⚠️ Failed to reconcile birthday schedule for tenant %s: %v", schedule.TenantID, err)
+		}
+	}
+
+	iter, err := tc.client.ScheduleClient().List(ctx, client.ScheduleListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list schedules: %w", err)
+	}
+	for iter.HasNext() {
+		entry, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("failed to list schedules: %w", err)
+		}
+		if !strings.HasPrefix(entry.ID, birthdaySchedulePrefix) || wanted[entry.ID] {
+			continue
+		}
+		if err := tc.client.ScheduleClient().GetHandle(ctx, entry.ID).Delete(ctx); err != nil && !isNotFound(err) {
+			log.Printf("⚠️ Failed to delete orphaned birthday schedule %s: %v", entry.ID, err)
+			continue
+		}
+		log.Printf("🗑️ Deleted orphaned birthday schedule %s", entry.ID)
+	}
+
+	log.Printf("✅ Reconciled %d birthday schedule(s)", len(schedules))
+	return nil
+}
+
+// isNotFound reports whether err is Temporal's not found error
+func isNotFound(err error) bool {
+	var notFound *serviceerror.NotFound
+	return errors.As(err, &notFound)
+}
+
+// isInvalidArgument reports whether err is Temporal's invalid argument error, which a server
+// returns for a visibility query it can't run
+func isInvalidArgument(err error) bool {
+	var invalidArgument *serviceerror.InvalidArgument
+	return errors.As(err, &invalidArgument)
+}
+
+// workflowTenantID reads the tenant a workflow was started for from its memo
+func workflowTenantID(memo *commonpb.Memo) string {
+	payload, ok := memo.GetFields()[memoTenantID]
+	if !ok {
+		return ""
+	}
+	var tenantID string
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &tenantID); err != nil {
+		return ""
+	}
+	return tenantID
+}
+
 // GetWorkflowResult gets the result of a workflow
 func (tc *TemporalClient) GetWorkflowResult(ctx context.Context, workflowID string, result interface{}) error {
 	workflowHandle := tc.client.GetWorkflow(ctx, workflowID, "")
@@ -0,0 +1,574 @@
+package temporal
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cardprocessor-go/internal/i18n"
+)
+
+// ComponentType identifies a card layout component
+type ComponentType string
+
+const (
+	ComponentBody        ComponentType = "body"
+	ComponentSection     ComponentType = "section"
+	ComponentColumn      ComponentType = "column"
+	ComponentImage       ComponentType = "image"
+	ComponentText        ComponentType = "text"
+	ComponentButton      ComponentType = "button"
+	ComponentDivider     ComponentType = "divider"
+	ComponentPromotion   ComponentType = "promotion"
+	ComponentUnsubscribe ComponentType = "unsubscribe"
+)
+
+// Component is a node in a card layout tree. Attributes use CSS property names
+// ("padding", "background-color", "font-size", ...) plus component-specific keys
+// such as "src", "href", "alt", "width" and "tag".
+type Component struct {
+	Type       ComponentType     `json:"type"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Content    string            `json:"content,omitempty"`
+	Children   []Component       `json:"children,omitempty"`
+}
+
+// contentComponents can appear directly in a section or inside a column
+var contentComponents = []ComponentType{
+	ComponentImage, ComponentText, ComponentButton, ComponentDivider, ComponentPromotion, ComponentUnsubscribe,
+}
+
+// allowedChildren defines valid nesting for container components
+var allowedChildren = map[ComponentType][]ComponentType{
+	ComponentBody:    {ComponentSection},
+	ComponentSection: append([]ComponentType{ComponentColumn}, contentComponents...),
+	ComponentColumn:  contentComponents,
+}
+
+// CSS properties each component copies from its attributes onto its main element
+var componentStyleKeys = map[ComponentType][]string{
+	ComponentSection: {"padding", "background", "background-color", "text-align", "border-top", "border-bottom"},
+	ComponentColumn:  {"padding", "background-color", "text-align"},
+	ComponentText:    {"margin", "padding", "color", "font-family", "font-size", "font-weight", "font-style", "line-height", "text-align", "border-top", "border-bottom"},
+}
+
+// Building blocks of the CSS values components accept
+const (
+	cssColor  = `(?:#[0-9a-fA-F]{3,8}|[a-zA-Z]+|(?:rgba?|hsla?)\(\s*[0-9.%\s,/]+\))`
+	cssLength = `(?:0|-?[0-9]*\.?[0-9]+(?:px|em|rem|%|pt))`
+)
+
+var (
+	cssColorValue    = `^` + cssColor + `$`
+	cssLengthValue   = `^` + cssLength + `$`
+	cssLengthsValue  = `^` + cssLength + `(?:\s+` + cssLength + `){0,3}$`
+	cssMarginValue   = `^(?:` + cssLength + `|auto)(?:\s+(?:` + cssLength + `|auto)){0,3}$`
+	cssGradientValue = `(?:linear|radial)-gradient\(\s*` + cssGradientArg + `(?:\s*,\s*` + cssGradientArg + `)*\s*\)`
+	cssGradientArg   = `(?:-?[0-9]+deg|to(?:\s+(?:top|bottom|left|right)){1,2}|` + cssColor + `(?:\s+` + cssLength + `)?)`
+	cssBorderToken   = `(?:` + cssLength + `|none|solid|dashed|dotted|double|` + cssColor + `)`
+	cssFontName      = `(?:[a-zA-Z0-9 -]+|'[a-zA-Z0-9 -]+'|"[a-zA-Z0-9 -]+")`
+)
+
+// styleValuePatterns are the values each CSS attribute accepts: colors, lengths, gradients
+// and keywords. Nothing else reaches a style attribute, so a value can't add declarations or
+// load a url() past safeURL and cssURL.
+var styleValuePatterns = map[string]*regexp.Regexp{
+	"padding":            regexp.MustCompile(cssLengthsValue),
+	"margin":             regexp.MustCompile(cssMarginValue),
+	"background":         regexp.MustCompile(`^(?:` + cssColor + `|` + cssGradientValue + `)$`),
+	"background-color":   regexp.MustCompile(cssColorValue),
+	"color":              regexp.MustCompile(cssColorValue),
+	"text-align":         regexp.MustCompile(`^(?:left|center|right|justify)$`),
+	"align":              regexp.MustCompile(`^(?:left|center|right)$`),
+	"vertical-align":     regexp.MustCompile(`^(?:top|middle|bottom|baseline)$`),
+	"border-top":         regexp.MustCompile(`^` + cssBorderToken + `(?:\s+` + cssBorderToken + `){0,2}$`),
+	"border-bottom":      regexp.MustCompile(`^` + cssBorderToken + `(?:\s+` + cssBorderToken + `){0,2}$`),
+	"border-width":       regexp.MustCompile(cssLengthValue),
+	"border-style":       regexp.MustCompile(`^(?:none|solid|dashed|dotted|double)$`),
+	"border-color":       regexp.MustCompile(cssColorValue),
+	"border-radius":      regexp.MustCompile(cssLengthsValue),
+	"font-family":        regexp.MustCompile(`^` + cssFontName + `(?:\s*,\s*` + cssFontName + `)*$`),
+	"font-size":          regexp.MustCompile(cssLengthValue),
+	"font-weight":        regexp.MustCompile(`^(?:normal|bold|bolder|lighter|[1-9]00)$`),
+	"font-style":         regexp.MustCompile(`^(?:normal|italic|oblique)$`),
+	"line-height":        regexp.MustCompile(`^(?:normal|[0-9]*\.?[0-9]+|` + cssLength + `)$`),
+	"height":             regexp.MustCompile(cssLengthValue),
+	"card-background":    regexp.MustCompile(cssColorValue),
+	"card-border-radius": regexp.MustCompile(cssLengthsValue),
+	"card-shadow":        regexp.MustCompile(`^(?:none|(?:inset\s+)?(?:` + cssLength + `\s+){1,3}` + cssLength + `\s+` + cssColor + `)$`),
+}
+
+// validStyleValue reports whether value is allowed for the attribute key. Attributes that
+// aren't CSS values, like src or href, are checked where they're used.
+func validStyleValue(key, value string) bool {
+	pattern, ok := styleValuePatterns[key]
+	return !ok || pattern.MatchString(strings.TrimSpace(value))
+}
+
+// textTags are the elements a text component may render as
+var textTags = map[string]bool{"div": true, "p": true, "h1": true, "h2": true, "h3": true, "h4": true, "span": true}
+
+// NewComponent creates a component with the given attributes and children
+func NewComponent(componentType ComponentType, attributes map[string]string, children ...Component) Component {
+	return Component{Type: componentType, Attributes: attributes, Children: children}
+}
+
+// attr returns the attribute value, or fallback when unset or not a value the attribute allows
+func (c Component) attr(key, fallback string) string {
+	if value, ok := c.Attributes[key]; ok && value != "" && validStyleValue(key, value) {
+		return value
+	}
+	return fallback
+}
+
+// style builds a style attribute from the component's CSS attributes over the given defaults
+func (c Component) style(defaults map[string]string, keys ...string) string {
+	var parts []string
+	for _, key := range keys {
+		value := c.attr(key, defaults[key])
+		if value == "" {
+			continue
+		}
+		parts = append(parts, key+": "+value)
+	}
+	return template.HTMLEscapeString(strings.Join(parts, "; "))
+}
+
+// Validate checks component types and nesting for the whole tree
+func (c Component) Validate() error {
+	allowed, isContainer := allowedChildren[c.Type]
+	if !isContainer && !isContentComponent(c.Type) {
+		return fmt.Errorf("unknown component type %q", c.Type)
+	}
+	if !isContainer && len(c.Children) > 0 {
+		return fmt.Errorf("component %q cannot have children", c.Type)
+	}
+
+	hasColumns := false
+	for i, child := range c.Children {
+		if !containsType(allowed, child.Type) {
+			return fmt.Errorf("component %q is not allowed inside %q (child %d)", child.Type, c.Type, i)
+		}
+		if child.Type == ComponentColumn {
+			hasColumns = true
+		} else if hasColumns {
+			return fmt.Errorf("section mixes columns with %q; wrap content in a column", child.Type)
+		}
+		if err := child.Validate(); err != nil {
+			return err
+		}
+	}
+	if hasColumns && c.Children[0].Type != ComponentColumn {
+		return fmt.Errorf("section mixes columns with %q; wrap content in a column", c.Children[0].Type)
+	}
+
+	keys := make([]string, 0, len(c.Attributes))
+	for key := range c.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value := c.Attributes[key]; value != "" && !validStyleValue(key, value) {
+			return fmt.Errorf("component %q has an invalid %s value %q", c.Type, key, c.Attributes[key])
+		}
+	}
+
+	switch c.Type {
+	case ComponentImage:
+		if c.attr("src", "") == "" && c.attr("background", "") == "" {
+			return fmt.Errorf("image component requires a src or background attribute")
+		}
+	case ComponentButton:
+		if c.attr("href", "") == "" {
+			return fmt.Errorf("button component requires an href attribute")
+		}
+	}
+
+	return nil
+}
+
+func isContentComponent(componentType ComponentType) bool {
+	return containsType(contentComponents, componentType)
+}
+
+func containsType(types []ComponentType, componentType ComponentType) bool {
+	for _, t := range types {
+		if t == componentType {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseComponentLayout decodes a layout from custom theme JSON. The value may be a
+// body component, a single section, or an array of sections.
+func ParseComponentLayout(raw interface{}) (Component, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return Component{}, fmt.Errorf("failed to encode layout: %w", err)
+	}
+
+	var root Component
+	if _, isList := raw.([]interface{}); isList {
+		var sections []Component
+		if err := json.Unmarshal(data, &sections); err != nil {
+			return Component{}, fmt.Errorf("failed to decode layout sections: %w", err)
+		}
+		root = NewComponent(ComponentBody, nil, sections...)
+	} else {
+		if err := json.Unmarshal(data, &root); err != nil {
+			return Component{}, fmt.Errorf("failed to decode layout: %w", err)
+		}
+		if root.Type == ComponentSection {
+			root = NewComponent(ComponentBody, nil, root)
+		}
+	}
+
+	if root.Type != ComponentBody {
+		return Component{}, fmt.Errorf("layout root must be a body or section, got %q", root.Type)
+	}
+	if err := root.Validate(); err != nil {
+		return Component{}, err
+	}
+	return root, nil
+}
+
+// CompileLayout compiles a component tree into responsive table-based email HTML
+func CompileLayout(root Component, params TemplateParams) (string, error) {
+	if root.Type != ComponentBody {
+		root = NewComponent(ComponentBody, nil, root)
+	}
+	if err := root.Validate(); err != nil {
+		return "", fmt.Errorf("invalid layout: %w", err)
+	}
+	if i18n.IsRTL(params.Language) {
+		root = mirrorComponent(root)
+	}
+	return compileBody(root, params), nil
+}
+
+// mirrorComponent returns a copy of the tree with horizontal alignment, padding and margins
+// flipped for right-to-left languages. Column order is mirrored by the table's dir attribute.
+func mirrorComponent(c Component) Component {
+	mirrored := Component{Type: c.Type, Content: c.Content}
+	if c.Attributes != nil {
+		mirrored.Attributes = make(map[string]string, len(c.Attributes))
+		for key, value := range c.Attributes {
+			switch key {
+			case "text-align", "align":
+				value = mirrorSide(value)
+			case "padding", "margin":
+				value = mirrorBox(value)
+			}
+			mirrored.Attributes[key] = value
+		}
+	}
+	for _, child := range c.Children {
+		mirrored.Children = append(mirrored.Children, mirrorComponent(child))
+	}
+	return mirrored
+}
+
+// mirrorSide swaps "left" and "right"
+func mirrorSide(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "left":
+		return "right"
+	case "right":
+		return "left"
+	}
+	return value
+}
+
+// mirrorBox swaps the right and left values of a four-value padding or margin shorthand
+func mirrorBox(value string) string {
+	parts := strings.Fields(value)
+	if len(parts) != 4 {
+		return value
+	}
+	parts[1], parts[3] = parts[3], parts[1]
+	return strings.Join(parts, " ")
+}
+
+func compileBody(c Component, params TemplateParams) string {
+	width := pixelInt(c.attr("width", ""), 600)
+
+	var sections strings.Builder
+	for _, section := range c.Children {
+		sections.WriteString(compileSection(section, params, width))
+	}
+
+	bodyStyle := c.style(map[string]string{
+		"margin":      "0",
+		"padding":     "20px",
+		"font-family": "'Segoe UI', Tahoma, Geneva, Verdana, sans-serif",
+	}, "margin", "padding", "font-family", "background-color", "background")
+
+	cardStyle := template.HTMLEscapeString(fmt.Sprintf(
+		"width: 100%%; max-width: %dpx; background-color: %s; border-radius: %s; overflow: hidden; box-shadow: %s",
+		width,
+		c.attr("card-background", "#ffffff"),
+		c.attr("card-border-radius", "12px"),
+		c.attr("card-shadow", "0 20px 40px rgba(0,0,0,0.1)"),
+	))
+
+	lang := i18n.ResolveLanguage(params.Language)
+	dir := i18n.Direction(lang)
+
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="%s" dir="%s">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<style>
+@media only screen and (max-width: %dpx) {
+	.card-column { display: block !important; width: 100%% !important; }
+}
+</style>
+</head>
+<body dir="%s" style="%s">
+%s<table role="presentation" dir="%s" width="100%%" cellpadding="0" cellspacing="0" border="0">
+<tr><td align="center">
+<table role="presentation" dir="%s" width="%d" cellpadding="0" cellspacing="0" border="0" style="%s">
+%s</table>
+</td></tr>
+</table>
+</body>
+</html>`, lang, dir, width+20, dir, bodyStyle, compilePreheader(params), dir, dir, width, cardStyle, sections.String())
+}
+
+// compilePreheader renders the hidden inbox preview text. Filler characters after the text
+// stop clients from padding the preview with the start of the card.
+func compilePreheader(params TemplateParams) string {
+	preheader := RenderSubject(params.Preheader, params)
+	if preheader == "" {
+		return ""
+	}
+	return fmt.Sprintf(`<div style="display: none; max-height: 0; overflow: hidden; mso-hide: all">%s%s</div>
+`, template.HTMLEscapeString(preheader), strings.Repeat("&#847;&zwnj;&nbsp;", 40))
+}
+
+func compileSection(c Component, params TemplateParams, width int) string {
+	children := c.Children
+	if len(children) > 0 && children[0].Type != ComponentColumn {
+		// Content placed directly in a section lives in one full-width column
+		children = []Component{NewComponent(ComponentColumn, nil, children...)}
+	}
+
+	var columns strings.Builder
+	empty := true
+	for _, column := range children {
+		html, hasContent := compileColumn(column, params, width, len(children))
+		if hasContent {
+			empty = false
+		}
+		columns.WriteString(html)
+	}
+	if empty {
+		return ""
+	}
+
+	style := c.style(nil, componentStyleKeys[ComponentSection]...)
+	if style != "" {
+		style = ` style="` + style + `"`
+	}
+
+	return fmt.Sprintf(`<tr><td%s>
+<table role="presentation" width="100%%" cellpadding="0" cellspacing="0" border="0"><tr>
+%s</tr></table>
+</td></tr>
+`, style, columns.String())
+}
+
+func compileColumn(c Component, params TemplateParams, width, siblings int) (string, bool) {
+	percent := 100.0 / float64(siblings)
+	if value := strings.TrimSuffix(c.attr("width", ""), "%"); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed > 0 && parsed <= 100 {
+			percent = parsed
+		}
+	}
+	columnWidth := int(float64(width) * percent / 100)
+
+	var content strings.Builder
+	for _, child := range c.Children {
+		content.WriteString(compileContent(child, params, columnWidth))
+	}
+
+	style := c.style(nil, componentStyleKeys[ComponentColumn]...)
+	if style != "" {
+		style += "; "
+	}
+	style += fmt.Sprintf("width: %s%%", formatPercent(percent))
+
+	return fmt.Sprintf(`<td class="card-column" width="%s%%" valign="%s" style="%s">
+%s</td>
+`, formatPercent(percent), template.HTMLEscapeString(c.attr("vertical-align", "top")), style, content.String()),
+		strings.TrimSpace(content.String()) != ""
+}
+
+func compileContent(c Component, params TemplateParams, width int) string {
+	switch c.Type {
+	case ComponentText:
+		return compileText(c, params)
+	case ComponentImage:
+		return compileImage(c, width)
+	case ComponentButton:
+		return compileButton(c, params)
+	case ComponentDivider:
+		return compileDivider(c)
+	case ComponentPromotion:
+		return renderPromotionContent(params)
+	case ComponentUnsubscribe:
+		return renderUnsubscribeSection(params)
+	}
+	return ""
+}
+
+// compileText renders text content. Rich content goes through sanitizeHTMLContent;
+// format "plain" escapes everything after placeholder substitution.
+func compileText(c Component, params TemplateParams) string {
+	if c.Content == "" {
+		return ""
+	}
+
+	tag := strings.ToLower(c.attr("tag", "div"))
+	if !textTags[tag] {
+		tag = "div"
+	}
+
+	content := ""
+	if c.attr("format", "") == "plain" {
+		content = template.HTMLEscapeString(processPlaceholders(c.Content, params))
+	} else {
+		content = sanitizeHTMLContent(c.Content, params)
+	}
+
+	return fmt.Sprintf(`<%s style="%s">%s</%s>
+`, tag, c.style(map[string]string{"margin": "0"}, componentStyleKeys[ComponentText]...), content, tag)
+}
+
+// compileImage renders an <img>, or a cover background cell when a height is set
+func compileImage(c Component, width int) string {
+	src := safeURL(c.attr("src", ""))
+	radius := c.attr("border-radius", "")
+
+	if height := c.attr("height", ""); height != "" {
+		background := c.attr("background", "")
+		if src != "" {
+			background = cssURL(src)
+		}
+		style := fmt.Sprintf("height: %s; background: %s; background-size: cover; background-position: center", height, background)
+		if radius != "" {
+			style += "; border-radius: " + radius
+		}
+		return fmt.Sprintf(`<table role="presentation" width="100%%" cellpadding="0" cellspacing="0" border="0"><tr><td style="%s">&nbsp;</td></tr></table>
+`, template.HTMLEscapeString(style))
+	}
+
+	if src == "" {
+		return ""
+	}
+
+	imageWidth := pixelInt(c.attr("width", ""), width)
+	if imageWidth > width {
+		imageWidth = width
+	}
+	style := fmt.Sprintf("display: block; width: 100%%; max-width: %dpx; height: auto; border: 0", imageWidth)
+	if radius != "" {
+		style += "; border-radius: " + radius
+	}
+	img := fmt.Sprintf(`<img src="%s" alt="%s" width="%d" style="%s">`,
+		template.HTMLEscapeString(src), template.HTMLEscapeString(c.attr("alt", "")), imageWidth, template.HTMLEscapeString(style))
+
+	if href := safeURL(c.attr("href", "")); href != "" {
+		img = fmt.Sprintf(`<a href="%s" target="_blank">%s</a>`, template.HTMLEscapeString(href), img)
+	}
+
+	return fmt.Sprintf(`<table role="presentation" width="100%%" cellpadding="0" cellspacing="0" border="0"><tr><td align="%s">%s</td></tr></table>
+`, template.HTMLEscapeString(c.attr("align", "center")), img)
+}
+
+// compileButton renders a bulletproof table button
+func compileButton(c Component, params TemplateParams) string {
+	href := safeURL(processURLPlaceholders(c.attr("href", ""), params))
+	if href == "" {
+		return ""
+	}
+
+	background := c.attr("background-color", "#667eea")
+	cellStyle := fmt.Sprintf("border-radius: %s; background-color: %s", c.attr("border-radius", "6px"), background)
+	linkStyle := fmt.Sprintf("display: inline-block; padding: %s; color: %s; font-size: %s; font-weight: %s; text-decoration: none; border-radius: %s",
+		c.attr("padding", "12px 24px"), c.attr("color", "#ffffff"), c.attr("font-size", "16px"), c.attr("font-weight", "600"), c.attr("border-radius", "6px"))
+
+	return fmt.Sprintf(`<table role="presentation" cellpadding="0" cellspacing="0" border="0" align="%s" style="margin: %s"><tr><td bgcolor="%s" style="%s"><a href="%s" target="_blank" style="%s">%s</a></td></tr></table>
+`,
+		template.HTMLEscapeString(c.attr("align", "center")),
+		template.HTMLEscapeString(c.attr("margin", "20px auto")),
+		template.HTMLEscapeString(background),
+		template.HTMLEscapeString(cellStyle),
+		template.HTMLEscapeString(href),
+		template.HTMLEscapeString(linkStyle),
+		template.HTMLEscapeString(processPlaceholders(c.Content, params)),
+	)
+}
+
+func compileDivider(c Component) string {
+	border := fmt.Sprintf("%s %s %s", c.attr("border-width", "1px"), c.attr("border-style", "solid"), c.attr("border-color", "#e2e8f0"))
+	return fmt.Sprintf(`<table role="presentation" width="100%%" cellpadding="0" cellspacing="0" border="0"><tr><td style="padding: %s"><div style="border-top: %s; font-size: 0; line-height: 0">&nbsp;</div></td></tr></table>
+`, template.HTMLEscapeString(c.attr("padding", "10px 0")), template.HTMLEscapeString(border))
+}
+
+// safeURL returns the URL if it uses an allowed scheme, otherwise ""
+func safeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "mailto":
+		return raw
+	case "":
+		if strings.HasPrefix(raw, "/") {
+			return raw
+		}
+	}
+	return ""
+}
+
+// cssURL writes a url() value with src as a single-quoted CSS string, escaping every character
+// that could end the string, the declaration or a double-quoted style attribute
+func cssURL(src string) string {
+	var b strings.Builder
+	b.WriteString(`url('`)
+	for _, r := range src {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-._~:/?#[]@!$&*+,=%", r) {
+			b.WriteRune(r)
+			continue
+		}
+		fmt.Fprintf(&b, "\\%x ", r)
+	}
+	b.WriteString(`')`)
+	return b.String()
+}
+
+// pixelInt parses values like "600" or "600px", returning fallback on failure
+func pixelInt(value string, fallback int) int {
+	parsed, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "px"))
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
+
+func formatPercent(percent float64) string {
+	return strconv.FormatFloat(percent, 'f', -1, 64)
+}
@@ -0,0 +1,102 @@
+package temporal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSSURL(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"https://cdn.example.com/cake.png?w=600&h=200", `url('https://cdn.example.com/cake.png?w=600&h=200')`},
+		{"/assets/a%20b.png", `url('/assets/a%20b.png')`},
+		{"https://x.test/a'b", `url('https://x.test/a\27 b')`},
+		{`https://x.test/a");color:red;("`, `url('https://x.test/a\22 \29 \3b color:red\3b \28 \22 ')`},
+		{"https://x.test/a\\b\nc", `url('https://x.test/a\5c b\a c')`},
+	}
+	for _, tt := range tests {
+		if got := cssURL(tt.src); got != tt.want {
+			t.Errorf("cssURL(%q) = %s, want %s", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestCompileImageEscapesBackgroundURL(t *testing.T) {
+	c := Component{Type: ComponentImage, Attributes: map[string]string{
+		"src":    `https://x.test/a');background:url('https://evil.test/`,
+		"height": "200px",
+	}}
+	got := compileImage(c, 600)
+	if strings.Contains(got, "&#39;);background") {
+		t.Errorf("compileImage left the url() string open: %s", got)
+	}
+	if !strings.Contains(got, `url(&#39;https://x.test/a\27 \29 \3b background:url\28 \27 https://evil.test/&#39;)`) {
+		t.Errorf("compileImage = %s, want the src escaped inside url()", got)
+	}
+}
+
+func TestStyleValuesRejectInjection(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		want  bool
+	}{
+		{"color", "#4a5568", true},
+		{"color", "rgba(0, 0, 0, 0.5)", true},
+		{"color", "red", true},
+		{"background", "linear-gradient(135deg, #667eea 0%, #764ba2 100%)", true},
+		{"padding", "20px 30px 10px 30px", true},
+		{"margin", "20px auto", true},
+		{"font-family", "'Segoe UI', Tahoma, sans-serif", true},
+		{"font-weight", "600", true},
+		{"line-height", "1.6", true},
+		{"border-top", "1px solid #e2e8f0", true},
+		{"card-shadow", "0 20px 40px rgba(0,0,0,0.1)", true},
+		{"src", "https://images.example.com/cake.png", true}, // not a CSS attribute
+
+		{"color", "red; position:fixed", false},
+		{"color", "red;background:url(https://tracker.test/p.gif)", false},
+		{"background", "url(https://tracker.test/p.gif)", false},
+		{"background", "linear-gradient(135deg, url(https://tracker.test/p.gif), #fff)", false},
+		{"background-color", "expression(alert(1))", false},
+		{"color", `\72 ed`, false},
+		{"font-family", "Arial; behavior:url(x.htc)", false},
+		{"font-weight", "heavy", false},
+		{"text-align", "center;float:left", false},
+		{"align", "justify", false},
+		{"padding", "10px }", false},
+		{"height", "200px\" onmouseover=\"alert(1)", false},
+	}
+	for _, tt := range tests {
+		if got := validStyleValue(tt.key, tt.value); got != tt.want {
+			t.Errorf("validStyleValue(%q, %q) = %v, want %v", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestComponentStyleDropsInvalidValues(t *testing.T) {
+	c := Component{Type: ComponentText, Attributes: map[string]string{
+		"color":     "red; position:fixed; background:url(https://tracker.test/p.gif)",
+		"font-size": "16px",
+	}}
+	got := c.style(map[string]string{"color": "#000000"}, "color", "font-size")
+	if got != "color: #000000; font-size: 16px" {
+		t.Errorf("style() = %q, want the invalid color replaced by its default", got)
+	}
+	if err := c.Validate(); err == nil || !strings.Contains(err.Error(), "invalid color value") {
+		t.Errorf("Validate() = %v, want the invalid color rejected", err)
+	}
+
+	image := Component{Type: ComponentImage, Attributes: map[string]string{
+		"background": "url(https://tracker.test/p.gif)",
+		"height":     "200px",
+	}}
+	if err := image.Validate(); err == nil {
+		t.Error("Validate() accepted a url() background")
+	}
+	if got := compileImage(image, 600); strings.Contains(got, "tracker.test") {
+		t.Errorf("compileImage = %s, want the url() background dropped", got)
+	}
+}
@@ -0,0 +1,789 @@
+package temporal
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// Email clients referenced by compatibility issues
+const (
+	ClientGmail          = "gmail"
+	ClientOutlookWindows = "outlook-windows"
+	ClientOutlookCom     = "outlook.com"
+	ClientAppleMail      = "apple-mail"
+	ClientYahoo          = "yahoo"
+)
+
+// Compatibility issue severities
+const (
+	SeverityInfo    = "info"    // handled automatically by the pipeline
+	SeverityWarning = "warning" // renders degraded in some clients
+	SeverityError   = "error"   // content is stripped or broken in some clients
+)
+
+// CompatibilityIssue describes a construct that some email clients strip or ignore
+type CompatibilityIssue struct {
+	Severity  string   `json:"severity"`
+	Construct string   `json:"construct"`
+	Clients   []string `json:"clients"`
+	Message   string   `json:"message"`
+	Count     int      `json:"count"`
+}
+
+// RenderedEmail is the result of rendering a card and running the compatibility pass
+type RenderedEmail struct {
+	HTML   string               `json:"html"`
+	Issues []CompatibilityIssue `json:"issues"`
+}
+
+// cssDeclaration is a single "property: value" pair
+type cssDeclaration struct {
+	Property  string
+	Value     string
+	Important bool
+}
+
+// cssRule is an inlinable rule from a <style> block
+type cssRule struct {
+	Selector     compoundSelector
+	Declarations []cssDeclaration
+	Specificity  int
+	Order        int
+}
+
+// compoundSelector is a selector without combinators, e.g. "td.header#top"
+type compoundSelector struct {
+	Tag     string
+	ID      string
+	Classes []string
+}
+
+// cssSupportRule flags a CSS property/value combination with limited client support
+type cssSupportRule struct {
+	Property  string
+	Value     *regexp.Regexp // nil matches any value
+	Construct string
+	Clients   []string
+	Severity  string
+	Message   string
+}
+
+var (
+	cssCommentPattern      = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	inlinableSelector      = regexp.MustCompile(`^(\*|[a-zA-Z][a-zA-Z0-9-]*)?([.#][a-zA-Z_][\w-]*)*$`)
+	selectorPartPattern    = regexp.MustCompile(`[.#]?[^.#]+`)
+	cssURLPattern          = regexp.MustCompile(`url\(\s*['"]?([^'")]+)['"]?\s*\)`)
+	cssHexColorPattern     = regexp.MustCompile(`#(?:[0-9a-fA-F]{6}|[0-9a-fA-F]{3})\b`)
+	cssPixelPattern        = regexp.MustCompile(`^(\d+(?:\.\d+)?)px$`)
+	cssRemPattern          = regexp.MustCompile(`(\d*\.?\d+)rem\b`)
+	cssViewportUnitPattern = regexp.MustCompile(`\d(vh|vw|vmin|vmax)\b`)
+)
+
+// unsupportedElements are stripped or rendered inert by most email clients
+var unsupportedElements = map[string]CompatibilityIssue{
+	"script": {Severity: SeverityError, Construct: "<script>", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientAppleMail, ClientYahoo}, Message: "Scripts are removed by every major email client"},
+	"form":   {Severity: SeverityError, Construct: "<form>", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom}, Message: "Forms are stripped or disabled; link to a landing page instead"},
+	"iframe": {Severity: SeverityError, Construct: "<iframe>", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientYahoo}, Message: "Embedded frames are removed"},
+	"video":  {Severity: SeverityError, Construct: "<video>", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientYahoo}, Message: "Video is not played; use a linked thumbnail image"},
+	"audio":  {Severity: SeverityError, Construct: "<audio>", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientYahoo}, Message: "Audio is not played"},
+	"object": {Severity: SeverityError, Construct: "<object>", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientAppleMail, ClientYahoo}, Message: "Embedded objects are removed"},
+	"embed":  {Severity: SeverityError, Construct: "<embed>", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientAppleMail, ClientYahoo}, Message: "Embedded objects are removed"},
+	"svg":    {Severity: SeverityWarning, Construct: "<svg>", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom}, Message: "Inline SVG is not rendered; use PNG images"},
+}
+
+// unsupportedCSS lists inline CSS with partial support, checked after inlining
+var unsupportedCSS = []cssSupportRule{
+	{Property: "display", Value: regexp.MustCompile(`^(inline-)?(flex|grid)$`), Construct: "display:flex/grid", Clients: []string{ClientOutlookWindows, ClientGmail}, Severity: SeverityWarning, Message: "Flexbox and grid layouts collapse; use tables for layout"},
+	{Property: "position", Value: regexp.MustCompile(`^(absolute|relative|fixed|sticky)$`), Construct: "position", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom}, Severity: SeverityWarning, Message: "CSS positioning is stripped"},
+	{Property: "box-shadow", Construct: "box-shadow", Clients: []string{ClientOutlookWindows, ClientOutlookCom}, Severity: SeverityInfo, Message: "Shadows are ignored"},
+	{Property: "border-radius", Construct: "border-radius", Clients: []string{ClientOutlookWindows}, Severity: SeverityInfo, Message: "Rounded corners render square"},
+	{Property: "max-width", Construct: "max-width", Clients: []string{ClientOutlookWindows}, Severity: SeverityInfo, Message: "max-width is ignored; content may stretch to full width"},
+	{Property: "transform", Construct: "transform", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientYahoo}, Severity: SeverityWarning, Message: "Transforms are ignored"},
+	{Property: "animation", Construct: "animation", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientYahoo}, Severity: SeverityWarning, Message: "Animations are ignored"},
+	{Property: "transition", Construct: "transition", Clients: []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientYahoo}, Severity: SeverityInfo, Message: "Transitions are ignored"},
+}
+
+// RenderBirthdayEmail renders a birthday card and runs the email-client compatibility pass on it
+func RenderBirthdayEmail(templateId BirthdayTemplateId, params TemplateParams) RenderedEmail {
+	return PrepareEmailHTML(RenderBirthdayTemplate(templateId, params))
+}
+
+// PrepareEmailHTML swaps external images for proxied copies (when an ImageProxy is set),
+// inlines <style> rules into style attributes, adds MSO conditional fallbacks for
+// gradients and background images, and reports unsupported constructs.
+// If the document cannot be parsed, the original HTML is returned unchanged.
+func PrepareEmailHTML(rawHTML string) RenderedEmail {
+	rawHTML = rewriteImageURLs(rawHTML)
+
+	doc, err := html.Parse(strings.NewReader(rawHTML))
+	if err != nil {
+		fmt.Printf("⚠️ [PrepareEmailHTML] Failed to parse HTML, skipping compatibility pass: %v\n", err)
+		return RenderedEmail{HTML: rawHTML, Issues: []CompatibilityIssue{}}
+	}
+
+	issues := newIssueCollector()
+
+	// Step 1: Collect and remove <style> blocks, keeping rules that cannot be inlined
+	rules, retained := extractStyleRules(doc, issues)
+
+	// Step 2: Inline the collected rules into style attributes
+	if len(rules) > 0 {
+		inlineStyleRules(doc, rules)
+	}
+	if len(retained) > 0 {
+		appendRetainedStyles(doc, retained)
+	}
+
+	// Step 3: Normalise units and add Outlook fallbacks
+	walkElements(doc, func(n *html.Node) {
+		normalizeInlineStyle(n, issues)
+		addMSOFallbacks(n, issues)
+	})
+
+	// Step 4: Flag anything the pipeline could not fix
+	walkElements(doc, func(n *html.Node) {
+		flagUnsupportedConstructs(n, issues)
+	})
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		fmt.Printf("⚠️ [PrepareEmailHTML] Failed to render HTML, skipping compatibility pass: %v\n", err)
+		return RenderedEmail{HTML: rawHTML, Issues: []CompatibilityIssue{}}
+	}
+
+	return RenderedEmail{
+		HTML:   buf.String(),
+		Issues: issues.list(),
+	}
+}
+
+// extractStyleRules removes <style> elements and returns their inlinable rules plus the
+// raw text of rules that must stay in a <style> block (media queries, pseudo-classes, ...)
+func extractStyleRules(doc *html.Node, issues *issueCollector) ([]cssRule, []string) {
+	var styleNodes []*html.Node
+	walkElements(doc, func(n *html.Node) {
+		if n.Data == "style" {
+			styleNodes = append(styleNodes, n)
+		}
+		if n.Data == "link" && strings.EqualFold(getAttr(n, "rel"), "stylesheet") {
+			issues.add(CompatibilityIssue{
+				Severity:  SeverityError,
+				Construct: "<link rel=\"stylesheet\">",
+				Clients:   []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientYahoo},
+				Message:   "External stylesheets are not loaded; styles must be inline",
+			})
+		}
+	})
+
+	var rules []cssRule
+	var retained []string
+	order := 0
+	for _, n := range styleNodes {
+		var css strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			css.WriteString(c.Data)
+		}
+		parsed, kept := parseStylesheet(css.String(), &order, issues)
+		rules = append(rules, parsed...)
+		retained = append(retained, kept...)
+		n.Parent.RemoveChild(n)
+	}
+
+	return rules, retained
+}
+
+// parseStylesheet splits a stylesheet into inlinable rules and retained rule text
+func parseStylesheet(css string, order *int, issues *issueCollector) ([]cssRule, []string) {
+	css = cssCommentPattern.ReplaceAllString(css, "")
+
+	var rules []cssRule
+	var retained []string
+	for {
+		css = strings.TrimSpace(css)
+		if css == "" {
+			break
+		}
+
+		// At-rules (@media, @font-face, @import) cannot be inlined
+		if strings.HasPrefix(css, "@") {
+			semi := strings.Index(css, ";")
+			brace := strings.Index(css, "{")
+			var block string
+			if semi != -1 && (brace == -1 || semi < brace) {
+				block, css = css[:semi+1], css[semi+1:]
+			} else if end := matchingBrace(css, brace); brace != -1 && end != -1 {
+				block, css = css[:end+1], css[end+1:]
+			} else {
+				block, css = css, ""
+			}
+			lower := strings.ToLower(block)
+			if strings.HasPrefix(lower, "@font-face") || strings.HasPrefix(lower, "@import") {
+				issues.add(CompatibilityIssue{
+					Severity:  SeverityWarning,
+					Construct: "web fonts",
+					Clients:   []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientYahoo},
+					Message:   "Web fonts are not loaded; the fallback font stack is used",
+				})
+			}
+			retained = append(retained, block)
+			continue
+		}
+
+		open := strings.Index(css, "{")
+		if open == -1 {
+			break
+		}
+		end := strings.Index(css[open:], "}")
+		if end == -1 {
+			break
+		}
+		end += open
+
+		prelude := css[:open]
+		body := strings.TrimSpace(css[open+1 : end])
+		css = css[end+1:]
+
+		declarations := parseDeclarations(body)
+		for _, selector := range strings.Split(prelude, ",") {
+			selector = strings.TrimSpace(selector)
+			if selector == "" {
+				continue
+			}
+			compound, ok := parseCompoundSelector(selector)
+			if !ok {
+				retained = append(retained, fmt.Sprintf("%s { %s }", selector, body))
+				issues.add(CompatibilityIssue{
+					Severity:  SeverityWarning,
+					Construct: "non-inlinable CSS selector",
+					Clients:   []string{ClientGmail, ClientYahoo},
+					Message:   "Rules using combinators or pseudo-classes stay in a <style> block, which some clients strip",
+				})
+				continue
+			}
+			rules = append(rules, cssRule{
+				Selector:     compound,
+				Declarations: declarations,
+				Specificity:  compound.specificity(),
+				Order:        *order,
+			})
+			*order++
+		}
+	}
+
+	return rules, retained
+}
+
+// matchingBrace returns the index of the brace closing the one at open, or -1
+func matchingBrace(s string, open int) int {
+	if open < 0 {
+		return -1
+	}
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseCompoundSelector parses selectors like "p", ".note", "td.cell#main"
+func parseCompoundSelector(selector string) (compoundSelector, bool) {
+	if !inlinableSelector.MatchString(selector) {
+		return compoundSelector{}, false
+	}
+
+	var sel compoundSelector
+	for _, part := range selectorPartPattern.FindAllString(selector, -1) {
+		switch part[0] {
+		case '.':
+			sel.Classes = append(sel.Classes, part[1:])
+		case '#':
+			sel.ID = part[1:]
+		default:
+			if part != "*" {
+				sel.Tag = strings.ToLower(part)
+			}
+		}
+	}
+	return sel, true
+}
+
+// specificity returns a comparable CSS specificity for the selector
+func (s compoundSelector) specificity() int {
+	specificity := len(s.Classes) * 10
+	if s.ID != "" {
+		specificity += 100
+	}
+	if s.Tag != "" {
+		specificity++
+	}
+	return specificity
+}
+
+// matches reports whether the element satisfies the selector
+func (s compoundSelector) matches(n *html.Node) bool {
+	if s.Tag != "" && n.Data != s.Tag {
+		return false
+	}
+	if s.ID != "" && getAttr(n, "id") != s.ID {
+		return false
+	}
+	if len(s.Classes) > 0 {
+		classes := strings.Fields(getAttr(n, "class"))
+		for _, want := range s.Classes {
+			found := false
+			for _, have := range classes {
+				if have == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// inlineStyleRules applies stylesheet rules to matching elements; existing inline
+// declarations win unless the stylesheet declaration is !important
+func inlineStyleRules(doc *html.Node, rules []cssRule) {
+	walkElements(doc, func(n *html.Node) {
+		var matched []cssRule
+		for _, rule := range rules {
+			if rule.Selector.matches(n) {
+				matched = append(matched, rule)
+			}
+		}
+		if len(matched) == 0 {
+			return
+		}
+
+		sort.SliceStable(matched, func(i, j int) bool {
+			if matched[i].Specificity != matched[j].Specificity {
+				return matched[i].Specificity < matched[j].Specificity
+			}
+			return matched[i].Order < matched[j].Order
+		})
+
+		var declarations []cssDeclaration
+		for _, rule := range matched {
+			declarations = mergeDeclarations(declarations, rule.Declarations)
+		}
+		declarations = mergeDeclarations(declarations, parseDeclarations(getAttr(n, "style")))
+		setAttr(n, "style", serializeDeclarations(declarations))
+	})
+}
+
+// appendRetainedStyles puts rules that could not be inlined back into a single <style> in <head>
+func appendRetainedStyles(doc *html.Node, retained []string) {
+	head := findElement(doc, "head")
+	if head == nil {
+		return
+	}
+	style := &html.Node{Type: html.ElementNode, Data: "style"}
+	style.AppendChild(&html.Node{Type: html.TextNode, Data: "\n" + strings.Join(retained, "\n") + "\n"})
+	head.AppendChild(style)
+}
+
+// normalizeInlineStyle converts units that Outlook does not understand
+func normalizeInlineStyle(n *html.Node, issues *issueCollector) {
+	style := getAttr(n, "style")
+	if style == "" {
+		return
+	}
+
+	// Outlook for Windows ignores rem units, so resolve them against the 16px default
+	if cssRemPattern.MatchString(style) {
+		style = cssRemPattern.ReplaceAllStringFunc(style, func(match string) string {
+			value, err := strconv.ParseFloat(strings.TrimSuffix(match, "rem"), 64)
+			if err != nil {
+				return match
+			}
+			return strconv.FormatFloat(value*16, 'f', -1, 64) + "px"
+		})
+		setAttr(n, "style", style)
+	}
+
+	if cssViewportUnitPattern.MatchString(style) {
+		issues.add(CompatibilityIssue{
+			Severity:  SeverityWarning,
+			Construct: "viewport units",
+			Clients:   []string{ClientOutlookWindows, ClientGmail},
+			Message:   "vh/vw units are not supported; use pixel sizes",
+		})
+	}
+}
+
+// addMSOFallbacks adds solid background colours for gradients and wraps elements with
+// background images or gradients in VML so Outlook for Windows still shows them
+func addMSOFallbacks(n *html.Node, issues *issueCollector) {
+	style := getAttr(n, "style")
+	if style == "" || (!strings.Contains(style, "gradient(") && !strings.Contains(style, "url(")) {
+		return
+	}
+
+	declarations := parseDeclarations(style)
+	var imageURL, gradient string
+	for _, d := range declarations {
+		if d.Property != "background" && d.Property != "background-image" {
+			continue
+		}
+		if match := cssURLPattern.FindStringSubmatch(d.Value); match != nil {
+			// VML doesn't read CSS escapes, such as the ones cssURL writes
+			imageURL = cssUnescape(match[1])
+		}
+		if strings.Contains(d.Value, "gradient(") {
+			gradient = d.Value
+		}
+	}
+	if imageURL == "" && gradient == "" {
+		return
+	}
+
+	colors := cssHexColorPattern.FindAllString(gradient, -1)
+	fallbackColor := declarationValue(declarations, "background-color")
+	if fallbackColor == "" && len(colors) > 0 {
+		fallbackColor = colors[0]
+		// Clients without gradient support fall back to background-color
+		declarations = append([]cssDeclaration{{Property: "background-color", Value: fallbackColor}}, declarations...)
+		setAttr(n, "style", serializeDeclarations(declarations))
+	}
+
+	// Outlook honours bgcolor on body and table cells
+	if (n.Data == "body" || n.Data == "td" || n.Data == "table") && fallbackColor != "" && getAttr(n, "bgcolor") == "" {
+		setAttr(n, "bgcolor", fallbackColor)
+	}
+
+	if imageURL != "" {
+		issues.add(CompatibilityIssue{
+			Severity:  SeverityInfo,
+			Construct: "CSS background image",
+			Clients:   []string{ClientOutlookWindows},
+			Message:   "Outlook ignores CSS background images; a VML fallback was added",
+		})
+	} else {
+		issues.add(CompatibilityIssue{
+			Severity:  SeverityInfo,
+			Construct: "CSS gradient",
+			Clients:   []string{ClientOutlookWindows, ClientOutlookCom},
+			Message:   "Gradients are not supported everywhere; a solid colour and VML fallback were added",
+		})
+	}
+
+	// VML needs explicit dimensions and cannot wrap the document body
+	if n.Data == "body" || n.Data == "html" {
+		return
+	}
+	height := pixelValue(declarationValue(declarations, "height"))
+	if height == "" {
+		return
+	}
+	width := pixelValue(declarationValue(declarations, "width"))
+	if width == "" {
+		width = "600px"
+	}
+
+	var fill string
+	if imageURL != "" {
+		fill = fmt.Sprintf(`<v:fill type="frame" src="%s" color="%s" />`, vmlAttr(imageURL), vmlAttr(defaultString(fallbackColor, "#ffffff")))
+	} else {
+		color2 := fallbackColor
+		if len(colors) > 1 {
+			color2 = colors[len(colors)-1]
+		}
+		fill = fmt.Sprintf(`<v:fill type="gradient" color="%s" color2="%s" angle="135" />`, vmlAttr(fallbackColor), vmlAttr(color2))
+	}
+
+	open := &html.Node{
+		Type: html.CommentNode,
+		Data: fmt.Sprintf(`[if gte mso 9]><v:rect xmlns:v="urn:schemas-microsoft-com:vml" fill="true" stroke="false" style="width:%s;height:%s;">%s<v:textbox inset="0,0,0,0"><![endif]`, width, height, fill),
+	}
+	closing := &html.Node{
+		Type: html.CommentNode,
+		Data: `[if gte mso 9]></v:textbox></v:rect><![endif]`,
+	}
+	n.InsertBefore(open, n.FirstChild)
+	n.AppendChild(closing)
+}
+
+// flagUnsupportedConstructs records elements and inline CSS with poor client support
+func flagUnsupportedConstructs(n *html.Node, issues *issueCollector) {
+	if issue, ok := unsupportedElements[n.Data]; ok {
+		issues.add(issue)
+	}
+
+	style := getAttr(n, "style")
+	if style == "" {
+		return
+	}
+
+	for _, d := range parseDeclarations(style) {
+		if strings.Contains(d.Value, "var(") {
+			issues.add(CompatibilityIssue{
+				Severity:  SeverityWarning,
+				Construct: "CSS custom properties",
+				Clients:   []string{ClientGmail, ClientOutlookWindows, ClientOutlookCom, ClientYahoo},
+				Message:   "var() values are dropped; use literal values",
+			})
+		}
+		for _, rule := range unsupportedCSS {
+			if d.Property != rule.Property {
+				continue
+			}
+			if rule.Value != nil && !rule.Value.MatchString(strings.ToLower(d.Value)) {
+				continue
+			}
+			issues.add(CompatibilityIssue{
+				Severity:  rule.Severity,
+				Construct: rule.Construct,
+				Clients:   rule.Clients,
+				Message:   rule.Message,
+			})
+		}
+	}
+}
+
+// parseDeclarations parses a declaration block, ignoring semicolons inside quotes and parentheses
+func parseDeclarations(block string) []cssDeclaration {
+	var declarations []cssDeclaration
+	for _, part := range splitDeclarations(block) {
+		colon := strings.Index(part, ":")
+		if colon == -1 {
+			continue
+		}
+		property := strings.ToLower(strings.TrimSpace(part[:colon]))
+		value := strings.TrimSpace(part[colon+1:])
+		if property == "" || value == "" {
+			continue
+		}
+		important := false
+		if idx := strings.Index(strings.ToLower(value), "!important"); idx != -1 {
+			important = true
+			value = strings.TrimSpace(value[:idx])
+		}
+		declarations = append(declarations, cssDeclaration{Property: property, Value: value, Important: important})
+	}
+	return declarations
+}
+
+// splitDeclarations splits on ';' outside of quotes and parentheses
+func splitDeclarations(block string) []string {
+	var parts []string
+	var quote byte
+	depth := 0
+	start := 0
+	for i := 0; i < len(block); i++ {
+		c := block[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+		case c == ';' && depth == 0:
+			parts = append(parts, block[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(block) {
+		parts = append(parts, block[start:])
+	}
+	return parts
+}
+
+// mergeDeclarations overlays declarations on base, keeping base entries marked !important
+func mergeDeclarations(base, overlay []cssDeclaration) []cssDeclaration {
+	result := append([]cssDeclaration(nil), base...)
+	for _, d := range overlay {
+		replaced := false
+		for i := range result {
+			if result[i].Property != d.Property {
+				continue
+			}
+			if !result[i].Important || d.Important {
+				result[i] = d
+			}
+			replaced = true
+			break
+		}
+		if !replaced {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// serializeDeclarations renders declarations back into a style attribute value
+func serializeDeclarations(declarations []cssDeclaration) string {
+	parts := make([]string, 0, len(declarations))
+	for _, d := range declarations {
+		value := d.Value
+		if d.Important {
+			value += " !important"
+		}
+		parts = append(parts, d.Property+": "+value)
+	}
+	return strings.Join(parts, "; ") + ";"
+}
+
+// declarationValue returns the value of the last declaration for property
+func declarationValue(declarations []cssDeclaration, property string) string {
+	value := ""
+	for _, d := range declarations {
+		if d.Property == property {
+			value = d.Value
+		}
+	}
+	return value
+}
+
+// pixelValue returns value if it is a plain pixel length, otherwise ""
+func pixelValue(value string) string {
+	if cssPixelPattern.MatchString(strings.TrimSpace(value)) {
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
+
+// cssUnescape decodes the escapes of a CSS string: a backslash and up to six hex digits,
+// ending at an optional whitespace character, or a backslash before any other character
+func cssUnescape(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		j := i + 1
+		for j < len(value) && j-i <= 6 && isHexDigit(value[j]) {
+			j++
+		}
+		if j == i+1 {
+			b.WriteByte(value[j])
+			i = j
+			continue
+		}
+		code, _ := strconv.ParseUint(value[i+1:j], 16, 32)
+		if code == 0 || code > unicode.MaxRune || code >= 0xD800 && code <= 0xDFFF {
+			code = unicode.ReplacementChar
+		}
+		b.WriteRune(rune(code))
+		if j < len(value) && strings.IndexByte(" \t\n\r\f", value[j]) != -1 {
+			j++
+		}
+		i = j - 1
+	}
+	return b.String()
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// vmlAttr makes a value safe for a VML attribute inside a conditional comment.
+// html.Render already escapes '&' in comment data, so only quotes and angle brackets are encoded.
+func vmlAttr(value string) string {
+	return strings.NewReplacer(`"`, "%22", "<", "%3C", ">", "%3E").Replace(value)
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// walkElements calls fn for every element node, in document order
+func walkElements(n *html.Node, fn func(*html.Node)) {
+	if n.Type == html.ElementNode {
+		fn(n)
+	}
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling // fn may remove or wrap c's children, never c's siblings
+		walkElements(c, fn)
+		c = next
+	}
+}
+
+func findElement(n *html.Node, tag string) *html.Node {
+	var found *html.Node
+	walkElements(n, func(el *html.Node) {
+		if found == nil && el.Data == tag {
+			found = el
+		}
+	})
+	return found
+}
+
+func getAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, val string) {
+	for i, attr := range n.Attr {
+		if attr.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+// issueCollector de-duplicates issues by construct and counts occurrences
+type issueCollector struct {
+	order  []string
+	issues map[string]*CompatibilityIssue
+}
+
+func newIssueCollector() *issueCollector {
+	return &issueCollector{issues: make(map[string]*CompatibilityIssue)}
+}
+
+func (ic *issueCollector) add(issue CompatibilityIssue) {
+	if existing, ok := ic.issues[issue.Construct]; ok {
+		existing.Count++
+		return
+	}
+	issue.Count = 1
+	ic.issues[issue.Construct] = &issue
+	ic.order = append(ic.order, issue.Construct)
+}
+
+func (ic *issueCollector) list() []CompatibilityIssue {
+	result := make([]CompatibilityIssue, 0, len(ic.order))
+	for _, construct := range ic.order {
+		result = append(result, *ic.issues[construct])
+	}
+	return result
+}
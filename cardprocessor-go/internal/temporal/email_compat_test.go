@@ -0,0 +1,204 @@
+package temporal
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// styleByID renders rawHTML through PrepareEmailHTML and returns the style attribute of each
+// element with an id
+func styleByID(t *testing.T, rawHTML string) (map[string]string, RenderedEmail) {
+	t.Helper()
+	rendered := PrepareEmailHTML(rawHTML)
+	doc, err := html.Parse(strings.NewReader(rendered.HTML))
+	if err != nil {
+		t.Fatalf("failed to parse rendered HTML: %v", err)
+	}
+	styles := make(map[string]string)
+	walkElements(doc, func(n *html.Node) {
+		if id := getAttr(n, "id"); id != "" {
+			styles[id] = getAttr(n, "style")
+		}
+	})
+	return styles, rendered
+}
+
+func TestPrepareEmailHTMLInlinesBySpecificity(t *testing.T) {
+	styles, _ := styleByID(t, `<html><head><style>
+		p { color: red; }
+		#intro { color: green; }
+		.note { color: blue; margin: 0 }
+		p.note { color: orange; }
+		.late { color: gray }
+		.late2 { color: black }
+	</style></head><body>
+		<p id="plain">a</p>
+		<p id="intro" class="note">b</p>
+		<p id="note" class="note">c</p>
+		<div id="div-note" class="note">d</div>
+		<p id="order" class="late late2">e</p>
+	</body></html>`)
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"plain", "color: red;"},
+		{"intro", "color: green; margin: 0;"},   // an ID beats classes and tags
+		{"note", "color: orange; margin: 0;"},   // a tag and a class beat the class
+		{"div-note", "color: blue; margin: 0;"}, // p rules don't match a div
+		{"order", "color: black;"},              // equal specificity: the later rule wins
+	}
+	for _, tt := range tests {
+		if got := styles[tt.id]; got != tt.want {
+			t.Errorf("style of #%s = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestPrepareEmailHTMLKeepsInlineStyles(t *testing.T) {
+	styles, _ := styleByID(t, `<html><head><style>
+		.note { color: blue; padding: 4px }
+		.loud { color: red !important; }
+		.quiet { color: red; }
+	</style></head><body>
+		<p id="inline" class="note" style="color: purple">a</p>
+		<p id="important" class="loud" style="color: purple">b</p>
+		<p id="both" class="quiet" style="color: purple !important">c</p>
+	</body></html>`)
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"inline", "color: purple; padding: 4px;"}, // existing inline styles win
+		{"important", "color: red !important;"},    // unless the stylesheet says !important
+		{"both", "color: purple !important;"},
+	}
+	for _, tt := range tests {
+		if got := styles[tt.id]; got != tt.want {
+			t.Errorf("style of #%s = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestPrepareEmailHTMLKeepsRulesItCannotInline(t *testing.T) {
+	_, rendered := styleByID(t, `<html><head><style>
+		p { color: red }
+		@media (max-width: 600px) { p { color: black } }
+		a:hover { color: red }
+	</style></head><body><p>a</p></body></html>`)
+
+	for _, want := range []string{"@media (max-width: 600px) { p { color: black } }", "a:hover { color: red }"} {
+		if !strings.Contains(rendered.HTML, want) {
+			t.Errorf("rendered HTML lost %q:\n%s", want, rendered.HTML)
+		}
+	}
+	if strings.Contains(rendered.HTML, "p { color: red }") {
+		t.Errorf("rendered HTML kept an inlined rule in <style>:\n%s", rendered.HTML)
+	}
+	if !hasIssue(rendered.Issues, "non-inlinable CSS selector") {
+		t.Errorf("issues = %+v, want the non-inlinable selector flagged", rendered.Issues)
+	}
+}
+
+func TestPrepareEmailHTMLAddsVMLBackgroundImage(t *testing.T) {
+	src := "https://cdn.example.com/it's (1).png"
+	rendered := PrepareEmailHTML(`<html><body><table><tr><td style="background-image: ` +
+		html.EscapeString(cssURL(src)) + `; height: 200px; width: 300px">x</td></tr></table></body></html>`)
+
+	want := `<v:fill type="frame" src="https://cdn.example.com/it's (1).png" color="#ffffff" />`
+	if !strings.Contains(rendered.HTML, want) {
+		t.Errorf("rendered HTML has no %s:\n%s", want, rendered.HTML)
+	}
+	if !strings.Contains(rendered.HTML, `style="width:300px;height:200px;"`) {
+		t.Errorf("VML fallback doesn't carry the cell's size:\n%s", rendered.HTML)
+	}
+	if !hasIssue(rendered.Issues, "CSS background image") {
+		t.Errorf("issues = %+v, want the background image flagged", rendered.Issues)
+	}
+}
+
+func TestPrepareEmailHTMLAddsGradientFallbacks(t *testing.T) {
+	styles, rendered := styleByID(t, `<html><body><table><tr>
+		<td id="cell" style="background: linear-gradient(135deg, #ff0000, #0000ff); height: 100px">x</td>
+	</tr></table></body></html>`)
+
+	if got, want := styles["cell"], "background-color: #ff0000; background: linear-gradient(135deg, #ff0000, #0000ff); height: 100px;"; got != want {
+		t.Errorf("style = %q, want %q", got, want)
+	}
+	for _, want := range []string{`bgcolor="#ff0000"`, `<v:fill type="gradient" color="#ff0000" color2="#0000ff" angle="135" />`} {
+		if !strings.Contains(rendered.HTML, want) {
+			t.Errorf("rendered HTML has no %s:\n%s", want, rendered.HTML)
+		}
+	}
+}
+
+func TestPrepareEmailHTMLFlagsUnsupportedConstructs(t *testing.T) {
+	styles, rendered := styleByID(t, `<html><head><link rel="stylesheet" href="https://example.com/a.css"></head><body>
+		<div id="flex" style="display: flex; padding: 1.5rem; height: 50vh">a</div>
+		<p style="color: var(--brand)">b</p>
+		<script>alert(1)</script>
+		<script>alert(2)</script>
+	</body></html>`)
+
+	if got, want := styles["flex"], "display: flex; padding: 24px; height: 50vh"; got != want {
+		t.Errorf("style = %q, want %q", got, want)
+	}
+	counts := make(map[string]int)
+	for _, issue := range rendered.Issues {
+		counts[issue.Construct] = issue.Count
+	}
+	want := map[string]int{
+		`<link rel="stylesheet">`: 1,
+		"display:flex/grid":       1,
+		"viewport units":          1,
+		"CSS custom properties":   1,
+		"<script>":                2,
+	}
+	for construct, count := range want {
+		if counts[construct] != count {
+			t.Errorf("issue %q counted %d times, want %d (issues %+v)", construct, counts[construct], count, rendered.Issues)
+		}
+	}
+}
+
+func TestCSSUnescape(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"https://cdn.example.com/cake.png", "https://cdn.example.com/cake.png"},
+		{`https://x.test/a\27 b`, "https://x.test/a'b"},
+		{`a\22 \29 \3b b`, `a");b`},
+		{`a\5c b\a c`, "a\\b\nc"},
+		{`\1F382 cake`, "🎂cake"},
+		{`a\"b`, `a"b`},
+		{`a\0 b`, "a\uFFFDb"},
+		{`trailing\`, `trailing\`},
+	}
+	for _, tt := range tests {
+		if got := cssUnescape(tt.value); got != tt.want {
+			t.Errorf("cssUnescape(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	// Whatever cssURL writes decodes back to the source
+	for _, src := range []string{"https://x.test/a'b", `https://x.test/a");color:red;("`, "https://x.test/ä ö"} {
+		url := cssURL(src)
+		if got := cssUnescape(url[len("url('") : len(url)-len("')")]); got != src {
+			t.Errorf("cssUnescape(cssURL(%q)) = %q", src, got)
+		}
+	}
+}
+
+func hasIssue(issues []CompatibilityIssue, construct string) bool {
+	for _, issue := range issues {
+		if issue.Construct == construct {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package temporal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ImageProxy swaps external image URLs for self-hosted, cached copies. CachedURL must not do
+// any I/O: it reports false for an image without a copy yet, and fetches it in the background.
+type ImageProxy interface {
+	CachedURL(sourceURL string) (string, bool)
+}
+
+// imageProxy is used by the render pipeline when set; nil leaves image URLs untouched
+var imageProxy ImageProxy
+
+var imgSrcPattern = regexp.MustCompile(`(?i)(<img\b[^>]*?\bsrc\s*=\s*["'])([^"']+)(["'])`)
+
+// SetImageProxy sets the proxy used to rewrite card images during rendering
+func SetImageProxy(proxy ImageProxy) {
+	imageProxy = proxy
+}
+
+// rewriteImageURLs replaces external URLs in <img src> and CSS url() values with
+// proxied copies. Rendering never waits on a fetch: images without a copy yet keep their
+// original URL until the proxy has fetched them.
+func rewriteImageURLs(rawHTML string) string {
+	if imageProxy == nil {
+		return rawHTML
+	}
+
+	// rewrite returns the proxied copy of an unescaped source URL, if there is one
+	rewritten := make(map[string]string)
+	rewrite := func(source string) (string, bool) {
+		source = strings.TrimSpace(source)
+		if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+			return "", false
+		}
+		if proxied, ok := rewritten[source]; ok {
+			return proxied, proxied != ""
+		}
+
+		proxied, ok := imageProxy.CachedURL(source)
+		if !ok {
+			fmt.Printf("ℹ️ [rewriteImageURLs] No proxied copy of %s yet, keeping the original URL\n", source)
+			proxied = ""
+		}
+		rewritten[source] = proxied
+		return proxied, ok
+	}
+
+	rawHTML = imgSrcPattern.ReplaceAllStringFunc(rawHTML, func(match string) string {
+		parts := imgSrcPattern.FindStringSubmatch(match)
+		proxied, ok := rewrite(html.UnescapeString(parts[2]))
+		if !ok {
+			return match
+		}
+		return parts[1] + html.EscapeString(proxied) + parts[3]
+	})
+
+	rawHTML = cssURLPattern.ReplaceAllStringFunc(rawHTML, func(match string) string {
+		parts := cssURLPattern.FindStringSubmatch(match)
+		proxied, ok := rewrite(cssURLSource(parts[1]))
+		if !ok {
+			return match
+		}
+		return cssURL(proxied)
+	})
+
+	return rawHTML
+}
+
+// cssURLSource decodes the argument of a url() value found in raw HTML. In a style attribute
+// its quotes and characters are HTML-escaped, around the CSS escapes cssURL writes.
+func cssURLSource(raw string) string {
+	return cssUnescape(strings.Trim(html.UnescapeString(raw), `'"`))
+}
@@ -0,0 +1,63 @@
+package temporal
+
+import (
+	"strings"
+	"testing"
+)
+
+// fakeImageProxy has a proxied copy of every image except the ones under /missing/
+type fakeImageProxy struct{}
+
+func (fakeImageProxy) CachedURL(source string) (string, bool) {
+	if strings.Contains(source, "/missing/") {
+		return "", false
+	}
+	return "https://cdn.example.com/assets/" + strings.TrimPrefix(source, "https://images.example.com/"), true
+}
+
+func TestRewriteImageURLs(t *testing.T) {
+	SetImageProxy(fakeImageProxy{})
+	defer SetImageProxy(nil)
+
+	background := Component{Type: ComponentImage, Attributes: map[string]string{
+		"src":    "https://images.example.com/it's.png",
+		"height": "200px",
+	}}
+
+	tests := []struct {
+		name    string
+		rawHTML string
+		want    string
+	}{
+		{
+			"img src",
+			`<img src="https://images.example.com/cake.png?w=1&amp;h=2">`,
+			`<img src="https://cdn.example.com/assets/cake.png?w=1&amp;h=2">`,
+		},
+		{
+			"img src without a copy yet",
+			`<img src="https://images.example.com/missing/cake.png">`,
+			`<img src="https://images.example.com/missing/cake.png">`,
+		},
+		{
+			"relative img src",
+			`<img src="/assets/cake.png">`,
+			`<img src="/assets/cake.png">`,
+		},
+		{
+			"escaped background url",
+			compileImage(background, 600),
+			strings.Replace(compileImage(background, 600), "url(&#39;https://images.example.com/it\\27 s.png&#39;)", "url('https://cdn.example.com/assets/it\\27 s.png')", 1),
+		},
+		{
+			"style block url",
+			`<style>td { background: url("https://images.example.com/bg.png") }</style>`,
+			`<style>td { background: url('https://cdn.example.com/assets/bg.png') }</style>`,
+		},
+	}
+	for _, tt := range tests {
+		if got := rewriteImageURLs(tt.rawHTML); got != tt.want {
+			t.Errorf("%s: rewriteImageURLs() =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
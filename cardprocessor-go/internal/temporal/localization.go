@@ -0,0 +1,49 @@
+package temporal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cardprocessor-go/internal/i18n"
+)
+
+// ParseLocalizedMessages parses and validates the per-language custom messages stored in
+// birthday settings, e.g. {"es": "¡Feliz cumpleaños!", "fr": "Joyeux anniversaire !"}.
+// Keys are normalized to loaded language codes; an empty string yields no messages.
+func ParseLocalizedMessages(raw string) (map[string]string, error) {
+	if strings.TrimSpace(raw) == "" || raw == "null" {
+		return nil, nil
+	}
+
+	var parsed map[string]string
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("localized messages must be a JSON object of language to message: %w", err)
+	}
+
+	messages := make(map[string]string, len(parsed))
+	for key, message := range parsed {
+		lang := i18n.NormalizeLanguage(key)
+		if lang == "" {
+			return nil, fmt.Errorf("unsupported language %q", key)
+		}
+		if strings.TrimSpace(message) != "" {
+			messages[lang] = message
+		}
+	}
+	return messages, nil
+}
+
+// LocalizedMessage returns the tenant message for lang from the raw localized messages,
+// or fallback when there is no variant for that language
+func LocalizedMessage(raw string, lang, fallback string) string {
+	messages, err := ParseLocalizedMessages(raw)
+	if err != nil {
+		fmt.Printf("⚠️ [LocalizedMessage] Ignoring invalid localized messages: %v\n", err)
+		return fallback
+	}
+	if message, ok := messages[i18n.NormalizeLanguage(lang)]; ok {
+		return message
+	}
+	return fallback
+}
@@ -0,0 +1,153 @@
+package temporal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/workflow"
+)
+
+// SignalCancelPromotion tells a card workflow waiting to send its split promotional email not
+// to send it, e.g. because the contact unsubscribed in the meantime
+const SignalCancelPromotion = "cancel_promotion"
+
+// DefaultPromotionDelay is the wait between a birthday card and its split promotional email
+// when the tenant hasn't set one
+const DefaultPromotionDelay = 30 * time.Second
+
+// maxPromotionDelay is the longest duration a tenant may wait before the promotional email
+const maxPromotionDelay = 7 * 24 * time.Hour
+
+// promotionDelayNextDayPrefix starts a "next day at HH:MM" promotion delay
+const promotionDelayNextDayPrefix = "next day at "
+
+// PromotionDelay is how long a card workflow waits before sending the split promotional email:
+// either a fixed duration, or until a time of day on the day after the card was sent
+type PromotionDelay struct {
+	Duration  time.Duration
+	NextDayAt string // HH:MM local time; set instead of Duration
+}
+
+// ParsePromotionDelay parses a promotion delay setting: a Go duration such as "30s", "4h" or
+// "1h30m", or "next day at HH:MM". An empty setting is the 30 second default.
+func ParsePromotionDelay(raw string) (PromotionDelay, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return PromotionDelay{Duration: DefaultPromotionDelay}, nil
+	}
+
+	if lower := strings.ToLower(raw); strings.HasPrefix(lower, promotionDelayNextDayPrefix) {
+		at := strings.TrimSpace(raw[len(promotionDelayNextDayPrefix):])
+		parsed, err := time.Parse("15:04", at)
+		if err != nil {
+			return PromotionDelay{}, fmt.Errorf("promotion delay %q must be \"next day at HH:MM\" with a 24-hour time", raw)
+		}
+		return PromotionDelay{NextDayAt: parsed.Format("15:04")}, nil
+	}
+
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		return PromotionDelay{}, fmt.Errorf("promotion delay %q must be a duration like \"4h\" or \"next day at HH:MM\"", raw)
+	}
+	if duration < 0 || duration > maxPromotionDelay {
+		return PromotionDelay{}, fmt.Errorf("promotion delay must be between 0s and %.0fh", maxPromotionDelay.Hours())
+	}
+	return PromotionDelay{Duration: duration}, nil
+}
+
+// Until returns how long to wait from now. A "next day" delay is resolved in loc, so the
+// promotion goes out at that wall-clock time the day after now there.
+func (d PromotionDelay) Until(now time.Time, loc *time.Location) time.Duration {
+	if d.NextDayAt == "" {
+		return d.Duration
+	}
+
+	at, _ := time.Parse("15:04", d.NextDayAt)
+	local := now.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day()+1, at.Hour(), at.Minute(), 0, 0, loc)
+	return next.Sub(now)
+}
+
+// String is the setting the delay was parsed from
+func (d PromotionDelay) String() string {
+	if d.NextDayAt != "" {
+		return promotionDelayNextDayPrefix + d.NextDayAt
+	}
+	return d.Duration.String()
+}
+
+// waitForPromotionDelay waits out the card's promotion delay on a durable timer, so the wait
+// survives worker restarts however long it is. It reports false when a cancel_promotion signal
+// arrives first, and returns the error when the workflow itself is canceled.
+func waitForPromotionDelay(ctx workflow.Context, input BirthdayTestWorkflowInput) (bool, error) {
+	logger := workflow.GetLogger(ctx)
+
+	delay, err := ParsePromotionDelay(input.PromotionDelay)
+	if err != nil {
+		logger.Warn("Invalid promotion delay, using the default", "promotionDelay", input.PromotionDelay, "error", err)
+		delay = PromotionDelay{Duration: DefaultPromotionDelay}
+	}
+	wait := delay.Duration
+	if delay.NextDayAt != "" {
+		if wait, err = nextDayPromotionWait(ctx, input, delay); err != nil {
+			return false, err
+		}
+	}
+	logger.Info("⏳ [SPLIT FLOW] Waiting before sending promotional email...", "promotionDelay", delay.String(), "wait", wait)
+
+	timerCtx, cancelTimer := workflow.WithCancel(ctx)
+	timer := workflow.NewTimer(timerCtx, wait)
+
+	canceled := false
+	var timerErr error
+	selector := workflow.NewSelector(ctx)
+	selector.AddFuture(timer, func(f workflow.Future) {
+		timerErr = f.Get(timerCtx, nil)
+	})
+	selector.AddReceive(workflow.GetSignalChannel(ctx, SignalCancelPromotion), func(c workflow.ReceiveChannel, more bool) {
+		var reason string
+		c.Receive(ctx, &reason)
+		logger.Info("🚫 [SPLIT FLOW] Promotional email canceled", "reason", reason)
+		canceled = true
+		cancelTimer()
+	})
+	selector.Select(ctx)
+
+	if canceled {
+		return false, nil
+	}
+	return timerErr == nil, timerErr
+}
+
+// PromotionSendTimeInput is what ResolvePromotionSendTime needs to place a "next day at HH:MM"
+// promotion delay
+type PromotionSendTimeInput struct {
+	PromotionDelay string    `json:"promotionDelay"`
+	Timezone       string    `json:"timezone"`
+	SentAt         time.Time `json:"sentAt"` // when the birthday card went out
+}
+
+// ResolvePromotionSendTime returns when a split promotional email with a "next day" delay goes
+// out, in the tenant's timezone or UTC when it's unknown. It runs as an activity because the
+// timezone database is read from the worker's system, which may change between a workflow's
+// run and its replay.
+func ResolvePromotionSendTime(ctx context.Context, input PromotionSendTimeInput) (time.Time, error) {
+	logger := activity.GetLogger(ctx)
+
+	delay, err := ParsePromotionDelay(input.PromotionDelay)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc := time.UTC
+	if input.Timezone != "" {
+		if tz, err := time.LoadLocation(input.Timezone); err == nil {
+			loc = tz
+		} else {
+			logger.Warn("Unknown timezone for promotion delay, using UTC", "timezone", input.Timezone, "error", err)
+		}
+	}
+	return input.SentAt.Add(delay.Until(input.SentAt, loc)), nil
+}
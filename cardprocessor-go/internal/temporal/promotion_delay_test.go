@@ -0,0 +1,47 @@
+package temporal
+
+import (
+	"testing"
+	"time"
+
+	"go.temporal.io/sdk/testsuite"
+)
+
+func TestResolvePromotionSendTime(t *testing.T) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivity(ResolvePromotionSendTime)
+
+	tests := []struct {
+		name     string
+		timezone string
+		sentAt   string
+		want     string
+	}{
+		// 23:30 in Berlin; the clocks go forward that night
+		{"next day across DST", "Europe/Berlin", "2026-03-28T22:30:00Z", "2026-03-29T07:00:00Z"},
+		// Already the 19th in Tokyo
+		{"ahead of UTC", "Asia/Tokyo", "2026-10-18T16:00:00Z", "2026-10-20T00:00:00Z"},
+		{"no timezone", "", "2026-10-18T16:00:00Z", "2026-10-19T09:00:00Z"},
+		{"unknown timezone", "Mars/Olympus_Mons", "2026-10-18T16:00:00Z", "2026-10-19T09:00:00Z"},
+	}
+	for _, tt := range tests {
+		sentAt, _ := time.Parse(time.RFC3339, tt.sentAt)
+		val, err := env.ExecuteActivity(ResolvePromotionSendTime, PromotionSendTimeInput{
+			PromotionDelay: "next day at 09:00",
+			Timezone:       tt.timezone,
+			SentAt:         sentAt,
+		})
+		if err != nil {
+			t.Errorf("%s: ResolvePromotionSendTime failed: %v", tt.name, err)
+			continue
+		}
+		var got time.Time
+		if err := val.Get(&got); err != nil {
+			t.Fatalf("%s: failed to read result: %v", tt.name, err)
+		}
+		if want, _ := time.Parse(time.RFC3339, tt.want); !got.Equal(want) {
+			t.Errorf("%s: send time = %s, want %s", tt.name, got.UTC().Format(time.RFC3339), tt.want)
+		}
+	}
+}
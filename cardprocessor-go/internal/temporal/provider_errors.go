@@ -0,0 +1,242 @@
+package temporal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Application error types of a failed provider send. Rejected, auth and not configured errors
+// are not retried; unavailable and network errors are, like ErrTypeRateLimited.
+const (
+	ErrTypeProviderRejected      = "ProviderRejected"      // 400, 422 and other 4xx: the email itself is invalid
+	ErrTypeProviderAuth          = "ProviderAuth"          // 401, 403: bad or revoked API key
+	ErrTypeProviderNotConfigured = "ProviderNotConfigured" // no API key, or the provider isn't implemented
+	ErrTypeProviderUnavailable   = "ProviderUnavailable"   // 408, 5xx
+	ErrTypeProviderNetwork       = "ProviderNetwork"       // the request never got an answer
+)
+
+// maxProviderErrorBody is how much of a provider's error response is kept as its message
+const maxProviderErrorBody = 1024
+
+// ProviderErrorDetails are the details of a provider send error
+type ProviderErrorDetails struct {
+	Provider   string `json:"provider"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// providerError builds the application error of the given type, retryable or not by type
+func providerError(errType string, details ProviderErrorDetails, cause error) error {
+	message := fmt.Sprintf("%s: %s", details.Provider, details.Message)
+	if details.StatusCode != 0 {
+		message = fmt.Sprintf("%s returned %d: %s", details.Provider, details.StatusCode, details.Message)
+	}
+
+	switch errType {
+	case ErrTypeProviderRejected, ErrTypeProviderAuth, ErrTypeProviderNotConfigured:
+		return temporal.NewNonRetryableApplicationError(message, errType, cause, details)
+	default:
+		return temporal.NewApplicationErrorWithCause(message, errType, cause, details)
+	}
+}
+
+// providerStatusError classifies a provider's non-2xx response. The body's message, or the
+// start of the body, becomes the error message.
+func providerStatusError(provider string, resp *http.Response) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return providerRateLimited(provider, resp)
+	}
+
+	details := ProviderErrorDetails{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Message:    providerErrorMessage(resp.Body),
+	}
+	if details.Message == "" {
+		details.Message = http.StatusText(resp.StatusCode)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return providerError(ErrTypeProviderAuth, details, nil)
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode >= 500:
+		return providerError(ErrTypeProviderUnavailable, details, nil)
+	default:
+		return providerError(ErrTypeProviderRejected, details, nil)
+	}
+}
+
+// providerErrorMessage reads the "message" of a JSON error body, or the start of the body
+func providerErrorMessage(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, maxProviderErrorBody))
+
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(data, &parsed) == nil && parsed.Message != "" {
+		return parsed.Message
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// providerNetworkError wraps a request that never got a response. Cancellation of the activity
+// is passed through as it is.
+func providerNetworkError(ctx context.Context, provider string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return providerError(ErrTypeProviderNetwork, ProviderErrorDetails{Provider: provider, Message: err.Error()}, err)
+}
+
+// providerNotConfigured is the error of a provider that can't be used
+func providerNotConfigured(provider, message string) error {
+	return providerError(ErrTypeProviderNotConfigured, ProviderErrorDetails{Provider: provider, Message: message}, nil)
+}
+
+// sendErrorRank orders provider errors by which should decide the send's outcome when every
+// provider failed: anything worth retrying first, then what the email or account got wrong
+func sendErrorRank(err error) int {
+	var appErr *temporal.ApplicationError
+	if !errors.As(err, &appErr) {
+		return 2
+	}
+	switch appErr.Type() {
+	case ErrTypeRateLimited:
+		return 0
+	case ErrTypeProviderUnavailable, ErrTypeProviderNetwork:
+		return 1
+	case ErrTypeProviderRejected:
+		return 3
+	case ErrTypeProviderAuth:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// SendFailure is the final classification of a send that failed, as recorded on email_sends
+type SendFailure struct {
+	Type      string `json:"type"`
+	Retryable bool   `json:"retryable"` // the error was retryable, but retries ran out
+	Provider  string `json:"provider,omitempty"`
+	Message   string `json:"message"`
+}
+
+// String is the error_message recorded for the failure
+func (f SendFailure) String() string {
+	if f.Retryable {
+		return fmt.Sprintf("%s (retryable, retries exhausted): %s", f.Type, f.Message)
+	}
+	return fmt.Sprintf("%s (non-retryable): %s", f.Type, f.Message)
+}
+
+// classifySendError turns the error of a send activity into its final classification
+func classifySendError(err error) SendFailure {
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) {
+		failure := SendFailure{
+			Type:      appErr.Type(),
+			Retryable: !appErr.NonRetryable(),
+			Message:   appErr.Message(),
+		}
+		if !appErr.HasDetails() {
+			return failure
+		}
+		switch appErr.Type() {
+		case ErrTypeRateLimited:
+			var details RateLimitDetails
+			if appErr.Details(&details) == nil && details.Scope == rateLimitScopeProvider {
+				failure.Provider = details.Key
+			}
+		case ErrTypeProviderRejected, ErrTypeProviderAuth, ErrTypeProviderNotConfigured,
+			ErrTypeProviderUnavailable, ErrTypeProviderNetwork:
+			var details ProviderErrorDetails
+			if appErr.Details(&details) == nil {
+				failure.Provider = details.Provider
+			}
+		}
+		return failure
+	}
+
+	var timeoutErr *temporal.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		return SendFailure{Type: "Timeout", Retryable: true, Message: timeoutErr.Error()}
+	}
+	return SendFailure{Type: "Unknown", Retryable: true, Message: err.Error()}
+}
+
+// RecordFailedSendInput is a send that failed for good, to be recorded on email_sends
+type RecordFailedSendInput struct {
+	Content     EmailContent `json:"content"`
+	TenantID    string       `json:"tenantId"`
+	EmailType   string       `json:"emailType"`
+	ContactID   string       `json:"contactId,omitempty"`
+	PromotionID string       `json:"promotionId,omitempty"`
+	Failure     SendFailure  `json:"failure"`
+}
+
+// RecordFailedSend records a failed send on email_sends with its classification as the error
+// message
+func RecordFailedSend(ctx context.Context, input RecordFailedSendInput) error {
+	logger := activity.GetLogger(ctx)
+	logger.Info("📝 Recording failed send", "tenantId", input.TenantID, "emailType", input.EmailType,
+		"errorType", input.Failure.Type)
+
+	emailCtx := &EmailContext{
+		TenantID:  input.TenantID,
+		EmailType: input.EmailType,
+		Metadata: map[string]interface{}{
+			"recipientEmail": input.Content.To,
+			"errorType":      input.Failure.Type,
+		},
+	}
+	if input.ContactID != "" {
+		emailCtx.ContactID = &input.ContactID
+	}
+	if input.PromotionID != "" {
+		emailCtx.PromotionID = &input.PromotionID
+	}
+
+	if input.Failure.Provider != "" {
+		emailCtx.Metadata["provider"] = input.Failure.Provider
+	}
+	return recordOutgoingEmail(ctx, input.Content, EmailSendResult{
+		Success:  false,
+		Provider: emailSendsProvider(input.Failure.Provider),
+		Error:    input.Failure.String(),
+	}, emailCtx)
+}
+
+// emailSendsProvider maps a provider name to one email_sends accepts. Failures that never
+// reached a provider, and providers it doesn't know, are recorded as "other".
+func emailSendsProvider(provider string) string {
+	switch provider {
+	case "resend", "sendgrid", "mailgun":
+		return provider
+	default:
+		return "other"
+	}
+}
+
+// recordSendFailure classifies a send activity's final error and records it on email_sends.
+// Tracking errors don't fail the workflow. Workflows started before changeRecordFailedSend only
+// classify the error.
+func recordSendFailure(ctx workflow.Context, input RecordFailedSendInput, err error) SendFailure {
+	input.Failure = classifySendError(err)
+	if workflow.GetVersion(ctx, changeRecordFailedSend, workflow.DefaultVersion, versionRecordFailedSend) == workflow.DefaultVersion {
+		return input.Failure
+	}
+	if recordErr := workflow.ExecuteActivity(ctx, RecordFailedSend, input).Get(ctx, nil); recordErr != nil {
+		workflow.GetLogger(ctx).Warn("Failed to record failed send", "error", recordErr)
+	}
+	return input.Failure
+}
@@ -0,0 +1,43 @@
+package temporal
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEmailSendsProvider(t *testing.T) {
+	// The values check_email_sends_provider (migration 021) allows
+	allowed := map[string]bool{"resend": true, "sendgrid": true, "mailgun": true, "other": true}
+
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{"resend", "resend"},
+		{"sendgrid", "sendgrid"},
+		{"mailgun", "mailgun"},
+		{"", "other"},
+		{"postmark", "other"},
+		{"none", "other"},
+	}
+	for _, tt := range tests {
+		got := emailSendsProvider(tt.provider)
+		if got != tt.want {
+			t.Errorf("emailSendsProvider(%q) = %q, want %q", tt.provider, got, tt.want)
+		}
+		if !allowed[got] {
+			t.Errorf("emailSendsProvider(%q) = %q, which email_sends doesn't accept", tt.provider, got)
+		}
+	}
+}
+
+func TestFailureWithoutProviderIsRecordedAsOther(t *testing.T) {
+	// A send that fails before reaching a provider has no provider on its failure
+	failure := classifySendError(errors.New("template rendering failed"))
+	if failure.Provider != "" {
+		t.Fatalf("failure.Provider = %q, want none", failure.Provider)
+	}
+	if got := emailSendsProvider(failure.Provider); got != "other" {
+		t.Errorf("failure without a provider recorded as %q, want \"other\"", got)
+	}
+}
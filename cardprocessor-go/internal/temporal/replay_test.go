@@ -0,0 +1,31 @@
+package temporal
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/worker"
+)
+
+// replayHistoryDir holds recorded workflow histories, captured with cmd/capture-history
+const replayHistoryDir = "testdata/replay"
+
+// TestReplayWorkflowHistories replays every recorded history against the current workflow
+// code. A failure means a change to a workflow would break the running workflows that
+// recorded such a history; wrap the change in workflow.GetVersion (see versions.go).
+func TestReplayWorkflowHistories(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(replayHistoryDir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files, "no workflow histories in %s", replayHistoryDir)
+
+	for _, file := range files {
+		file := file
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".json"), func(t *testing.T) {
+			replayer := worker.NewWorkflowReplayer()
+			registerWorkflows(replayer)
+			require.NoError(t, replayer.ReplayWorkflowHistoryFromJSONFile(nil, file))
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package temporal
+
+import (
+	"context"
+	"time"
+
+	"cardprocessor-go/internal/config"
+	"cardprocessor-go/internal/models"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RetentionPurgeScheduleID is the ID of the Temporal Schedule that runs the retention purge, so
+// only one schedule runs per namespace
+const RetentionPurgeScheduleID = "retention-purge"
+
+// RetentionPurgeWorkflowID starts the ID of every scheduled purge run; the schedule appends the
+// run's scheduled time. It was the whole ID of the cron workflow the schedule replaced.
+const RetentionPurgeWorkflowID = "retention-purge"
+
+// retentionPurgeBatchSize is how many rows each purge statement touches, keeping locks short
+const retentionPurgeBatchSize = 1000
+
+// PurgeTenantEmailDataInput is one tenant's purge within a retention purge run
+type PurgeTenantEmailDataInput struct {
+	Policy models.RetentionPolicy `json:"policy"`
+	RunID  string                 `json:"runId"`
+	RunAt  time.Time              `json:"runAt"` // cutoffs are measured back from here
+}
+
+// RetentionPurgeReport is what a retention purge run removed across all tenants
+type RetentionPurgeReport struct {
+	RunID                  string                     `json:"runId"`
+	RunAt                  time.Time                  `json:"runAt"`
+	Tenants                []models.RetentionPurgeRun `json:"tenants"`
+	FailedTenants          []string                   `json:"failedTenants,omitempty"`
+	ContentDeleted         int                        `json:"contentDeleted"`
+	ContentTruncated       int                        `json:"contentTruncated"`
+	WebhookPayloadsCleared int                        `json:"webhookPayloadsCleared"`
+	EventsCompacted        int                        `json:"eventsCompacted"`
+}
+
+// RetentionPurgeWorkflow applies every tenant's retention policy: old email content is deleted
+// or truncated, old raw webhook payloads are cleared and old email events are compacted into
+// daily counts. It runs on the RETENTION_PURGE_CRON schedule. A tenant whose purge fails is
+// reported and retried on the next run; the others still go ahead.
+func RetentionPurgeWorkflow(ctx workflow.Context) (RetentionPurgeReport, error) {
+	logger := workflow.GetLogger(ctx)
+	report := RetentionPurgeReport{
+		RunID: workflow.GetInfo(ctx).WorkflowExecution.RunID,
+		RunAt: workflow.Now(ctx).UTC(),
+	}
+	logger.Info("🧹 Starting retention purge workflow", "runId", report.RunID)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 1 * time.Hour,
+		HeartbeatTimeout:    2 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    30 * time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumAttempts:    3,
+		},
+	})
+
+	var policies []models.RetentionPolicy
+	if err := workflow.ExecuteActivity(ctx, GetRetentionPolicies).Get(ctx, &policies); err != nil {
+		logger.Error("Failed to get retention policies", "error", err)
+		return report, err
+	}
+
+	for _, policy := range policies {
+		if policy.Empty() {
+			continue
+		}
+
+		var run models.RetentionPurgeRun
+		err := workflow.ExecuteActivity(ctx, PurgeTenantEmailData, PurgeTenantEmailDataInput{
+			Policy: policy,
+			RunID:  report.RunID,
+			RunAt:  report.RunAt,
+		}).Get(ctx, &run)
+		if err != nil {
+			logger.Error("Failed to purge tenant email data", "tenantId", policy.TenantID, "error", err)
+			report.FailedTenants = append(report.FailedTenants, policy.TenantID)
+			continue
+		}
+
+		report.Tenants = append(report.Tenants, run)
+		report.ContentDeleted += run.ContentDeleted
+		report.ContentTruncated += run.ContentTruncated
+		report.WebhookPayloadsCleared += run.WebhookPayloadsCleared
+		report.EventsCompacted += run.EventsCompacted
+	}
+
+	logger.Info("✅ Retention purge workflow completed",
+		"tenants", len(report.Tenants),
+		"failedTenants", len(report.FailedTenants),
+		"contentDeleted", report.ContentDeleted,
+		"contentTruncated", report.ContentTruncated,
+		"webhookPayloadsCleared", report.WebhookPayloadsCleared,
+		"eventsCompacted", report.EventsCompacted)
+
+	return report, nil
+}
+
+// RetentionDefaults is the retention policy configured for tenants without their own settings
+func RetentionDefaults(cfg *config.Config) models.RetentionPolicy {
+	return models.RetentionPolicy{
+		ContentDays:     cfg.RetentionContentDays,
+		ContentAction:   models.RetentionActionDelete,
+		WebhookDataDays: cfg.RetentionWebhookDataDays,
+		EventDays:       cfg.RetentionEventDays,
+	}
+}
+
+// GetRetentionPolicies resolves the retention policy of every tenant
+func GetRetentionPolicies(ctx context.Context) ([]models.RetentionPolicy, error) {
+	return activityDeps.Repo.GetRetentionPolicies(ctx, RetentionDefaults(activityDeps.Config))
+}
+
+// PurgeTenantEmailData applies one tenant's retention policy in batches, heartbeating between
+// them, and records what it removed. Every step only touches rows past the cutoff, so a retried
+// attempt carries on where the failed one stopped.
+func PurgeTenantEmailData(ctx context.Context, input PurgeTenantEmailDataInput) (models.RetentionPurgeRun, error) {
+	logger := activity.GetLogger(ctx)
+	policy := input.Policy
+	run := models.RetentionPurgeRun{
+		TenantID: policy.TenantID,
+		RunID:    input.RunID,
+		RanAt:    input.RunAt,
+	}
+	logger.Info("🧹 Purging tenant email data", "tenantId", policy.TenantID,
+		"contentDays", policy.ContentDays, "contentAction", policy.ContentAction,
+		"webhookDataDays", policy.WebhookDataDays, "eventDays", policy.EventDays)
+
+	// purge runs a batch step until it comes back short
+	purge := func(step func() (int, error)) (int, error) {
+		total := 0
+		for {
+			n, err := step()
+			if err != nil {
+				return total, err
+			}
+			total += n
+			activity.RecordHeartbeat(ctx, run)
+			if n < retentionPurgeBatchSize {
+				return total, nil
+			}
+		}
+	}
+
+	if policy.ContentDays > 0 {
+		before := input.RunAt.AddDate(0, 0, -policy.ContentDays)
+		n, err := purge(func() (int, error) {
+			return activityDeps.Repo.PurgeEmailContent(ctx, policy.TenantID, before, policy.ContentAction, retentionPurgeBatchSize)
+		})
+		if policy.ContentAction == models.RetentionActionTruncate {
+			run.ContentTruncated = n
+		} else {
+			run.ContentDeleted = n
+		}
+		if err != nil {
+			logger.Error("❌ Failed to purge email content", "tenantId", policy.TenantID, "error", err)
+			return run, err
+		}
+	}
+
+	if policy.WebhookDataDays > 0 {
+		before := input.RunAt.AddDate(0, 0, -policy.WebhookDataDays)
+		n, err := purge(func() (int, error) {
+			return activityDeps.Repo.ClearWebhookPayloads(ctx, policy.TenantID, before, retentionPurgeBatchSize)
+		})
+		run.WebhookPayloadsCleared = n
+		if err != nil {
+			logger.Error("❌ Failed to clear webhook payloads", "tenantId", policy.TenantID, "error", err)
+			return run, err
+		}
+	}
+
+	if policy.EventDays > 0 {
+		before := input.RunAt.AddDate(0, 0, -policy.EventDays)
+		n, err := purge(func() (int, error) {
+			return activityDeps.Repo.CompactEmailEvents(ctx, policy.TenantID, before, retentionPurgeBatchSize)
+		})
+		run.EventsCompacted = n
+		if err != nil {
+			logger.Error("❌ Failed to compact email events", "tenantId", policy.TenantID, "error", err)
+			return run, err
+		}
+	}
+
+	if err := activityDeps.Repo.CreateRetentionPurgeRun(ctx, &run); err != nil {
+		logger.Error("❌ Failed to record retention purge run", "tenantId", policy.TenantID, "error", err)
+		return run, err
+	}
+
+	logger.Info("✅ Purged tenant email data", "tenantId", policy.TenantID,
+		"contentDeleted", run.ContentDeleted, "contentTruncated", run.ContentTruncated,
+		"webhookPayloadsCleared", run.WebhookPayloadsCleared, "eventsCompacted", run.EventsCompacted)
+
+	return run, nil
+}
@@ -0,0 +1,210 @@
+package temporal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cardprocessor-go/internal/ratelimit"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Application error types returned by the send activities when a limit holds an email back
+const (
+	// ErrTypeRateLimited is retryable: the details carry a RateLimitDetails saying how long to wait
+	ErrTypeRateLimited = "RateLimited"
+	// ErrTypeDailySendLimit is not retryable: the tenant has used up today's sends
+	ErrTypeDailySendLimit = "DailySendLimitReached"
+)
+
+// Rate limit scopes
+const (
+	rateLimitScopeProvider = "provider"
+	rateLimitScopeTenant   = "tenant"
+)
+
+// maxLimiterWait is the longest a send activity waits for a rate limit token itself. Longer
+// waits go back to the workflow, so the activity slot is free for other tenants meanwhile.
+const maxLimiterWait = 2 * time.Second
+
+// defaultRetryAfter is the wait after a provider's 429 without a usable Retry-After header
+const defaultRetryAfter = 5 * time.Second
+
+// maxRateLimitWaits is how many times a workflow waits out a rate limit before giving up on a send
+const maxRateLimitWaits = 20
+
+// emailProviders are tried in order of preference
+var emailProviders = []string{"resend", "sendgrid", "mailgun"}
+
+// RateLimitDetails says what held a send back and how long to wait before trying again
+type RateLimitDetails struct {
+	Scope      string        `json:"scope"` // provider, tenant
+	Key        string        `json:"key"`   // provider name or tenant ID
+	RetryAfter time.Duration `json:"retryAfter"`
+}
+
+// rateLimitedError is the retryable error for a send held back by a rate limit
+func rateLimitedError(scope, key string, retryAfter time.Duration) error {
+	return temporal.NewApplicationError(
+		fmt.Sprintf("%s %s is rate limited, retry after %s", scope, key, retryAfter),
+		ErrTypeRateLimited,
+		RateLimitDetails{Scope: scope, Key: key, RetryAfter: retryAfter},
+	)
+}
+
+// takeSendToken takes a token from key's bucket, waiting for one when the wait is short, and
+// returns a rate limited error when it is not
+func takeSendToken(ctx context.Context, limiter *ratelimit.Limiter, scope, key string) error {
+	for {
+		wait := limiter.Reserve(key)
+		if wait == 0 {
+			return nil
+		}
+		if wait > maxLimiterWait {
+			return rateLimitedError(scope, key, wait)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// checkDailySendLimit returns a non-retryable error once the tenant has sent its daily limit
+// since midnight UTC. Sends running at the same moment can overshoot the limit slightly.
+func checkDailySendLimit(ctx context.Context, tenantID string) error {
+	limit, err := activityDeps.Repo.GetDailySendLimit(ctx, tenantID, activityDeps.Config.DefaultDailySendLimit)
+	if err != nil {
+		return err
+	}
+	if limit.Limit == nil {
+		return nil
+	}
+
+	since := time.Now().UTC().Truncate(24 * time.Hour)
+	sent, err := activityDeps.Repo.CountEmailSendsSince(ctx, tenantID, since)
+	if err != nil {
+		return err
+	}
+	if sent >= *limit.Limit {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("daily send limit of %d reached (%s)", *limit.Limit, limit.Source),
+			ErrTypeDailySendLimit, nil)
+	}
+	return nil
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return defaultRetryAfter
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return defaultRetryAfter
+}
+
+// providerRateLimited handles a provider's 429: no email goes to the provider until it said to
+// come back, and the send is retried after that
+func providerRateLimited(provider string, resp *http.Response) error {
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	activityDeps.ProviderLimiter.Block(provider, time.Now().Add(retryAfter))
+	return rateLimitedError(rateLimitScopeProvider, provider, retryAfter)
+}
+
+// sendViaProviders sends the email within the tenant's daily limit and the tenant and provider
+// rate limits, trying each provider in turn. When every provider failed, the error that says
+// most about whether to retry is returned (see sendErrorRank).
+func sendViaProviders(ctx context.Context, content EmailContent, emailCtx *EmailContext) (EmailSendResult, error) {
+	logger := activity.GetLogger(ctx)
+
+	if err := checkDailySendLimit(ctx, emailCtx.TenantID); err != nil {
+		logger.Warn("🚦 Email held back by daily send limit", "tenantId", emailCtx.TenantID, "error", err)
+		return EmailSendResult{Success: false, Error: err.Error()}, err
+	}
+
+	if err := takeSendToken(ctx, activityDeps.TenantLimiter, rateLimitScopeTenant, emailCtx.TenantID); err != nil {
+		logger.Info("🚦 Tenant send rate limited", "tenantId", emailCtx.TenantID, "error", err)
+		return EmailSendResult{Success: false, Error: err.Error()}, err
+	}
+
+	var sendErr error
+	for _, provider := range emailProviders {
+		err := takeSendToken(ctx, activityDeps.ProviderLimiter, rateLimitScopeProvider, provider)
+		if err == nil {
+			var result EmailSendResult
+			result, err = sendEmailViaProvider(ctx, provider, content, emailCtx)
+			if err == nil && result.Success {
+				return result, nil
+			}
+			if err == nil {
+				err = providerError(ErrTypeProviderUnavailable, ProviderErrorDetails{Provider: provider, Message: result.Error}, nil)
+			}
+		}
+		logger.Warn("❌ Failed to send via provider", "provider", provider, "error", err)
+		if sendErr == nil || sendErrorRank(err) < sendErrorRank(sendErr) {
+			sendErr = err
+		}
+	}
+
+	// Nothing was sent, so the tenant keeps its token
+	activityDeps.TenantLimiter.Refund(emailCtx.TenantID)
+	return EmailSendResult{Success: false, Error: sendErr.Error()}, sendErr
+}
+
+// executeSendActivity runs a send activity, waiting out rate limits in the workflow. Temporal
+// would retry a rate limited send on the activity's own backoff, so those errors are excluded
+// from the activity's retries and retried here after the delay the limit asked for.
+func executeSendActivity(ctx workflow.Context, result interface{}, activityFn interface{}, args ...interface{}) error {
+	options := workflow.GetActivityOptions(ctx)
+	policy := temporal.RetryPolicy{}
+	if options.RetryPolicy != nil {
+		policy = *options.RetryPolicy
+	}
+	policy.NonRetryableErrorTypes = append(append([]string{}, policy.NonRetryableErrorTypes...), ErrTypeRateLimited)
+	options.RetryPolicy = &policy
+	ctx = workflow.WithActivityOptions(ctx, options)
+
+	for waits := 0; ; waits++ {
+		err := workflow.ExecuteActivity(ctx, activityFn, args...).Get(ctx, result)
+		retryAfter, limited := rateLimitDelay(err)
+		if !limited || waits >= maxRateLimitWaits {
+			return err
+		}
+
+		workflow.GetLogger(ctx).Info("🚦 Send rate limited, waiting", "retryAfter", retryAfter, "waits", waits+1)
+		if err := workflow.Sleep(ctx, retryAfter); err != nil {
+			return err
+		}
+	}
+}
+
+// rateLimitDelay reports whether an activity error is a rate limit, and how long it asked to wait
+func rateLimitDelay(err error) (time.Duration, bool) {
+	var appErr *temporal.ApplicationError
+	if !errors.As(err, &appErr) || appErr.Type() != ErrTypeRateLimited {
+		return 0, false
+	}
+
+	details := RateLimitDetails{RetryAfter: defaultRetryAfter}
+	if appErr.HasDetails() {
+		if err := appErr.Details(&details); err != nil {
+			details.RetryAfter = defaultRetryAfter
+		}
+	}
+	if details.RetryAfter < time.Second {
+		details.RetryAfter = time.Second
+	}
+	return details.RetryAfter, true
+}
@@ -0,0 +1,109 @@
+package temporal
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+
+	"cardprocessor-go/internal/i18n"
+)
+
+// maxSubjectVariants bounds how many subject lines a tenant can test at once
+const maxSubjectVariants = 5
+
+// SubjectVariant is one arm of a subject line A/B test
+type SubjectVariant struct {
+	ID        string `json:"id"`
+	Subject   string `json:"subject"`
+	Preheader string `json:"preheader,omitempty"`
+	Weight    int    `json:"weight,omitempty"` // relative share of sends; defaults to 1
+}
+
+var (
+	subjectSpacePattern       = regexp.MustCompile(`\s+`)
+	subjectPunctuationPattern = regexp.MustCompile(`\s+([,.!?])`)
+)
+
+// ParseSubjectVariants parses and validates the JSON variant list stored in birthday settings.
+// An empty string yields no variants.
+func ParseSubjectVariants(raw string) ([]SubjectVariant, error) {
+	if strings.TrimSpace(raw) == "" || raw == "null" {
+		return nil, nil
+	}
+
+	var variants []SubjectVariant
+	if err := json.Unmarshal([]byte(raw), &variants); err != nil {
+		return nil, fmt.Errorf("subject variants must be a JSON array: %w", err)
+	}
+	if len(variants) > maxSubjectVariants {
+		return nil, fmt.Errorf("at most %d subject variants are allowed", maxSubjectVariants)
+	}
+
+	seen := make(map[string]bool)
+	for i := range variants {
+		variant := &variants[i]
+		variant.ID = strings.TrimSpace(variant.ID)
+		if variant.ID == "" {
+			return nil, fmt.Errorf("subject variant %d has no id", i+1)
+		}
+		if len(variant.ID) > 50 {
+			return nil, fmt.Errorf("subject variant id %q is longer than 50 characters", variant.ID)
+		}
+		if seen[variant.ID] {
+			return nil, fmt.Errorf("duplicate subject variant id %q", variant.ID)
+		}
+		seen[variant.ID] = true
+
+		if strings.TrimSpace(variant.Subject) == "" {
+			return nil, fmt.Errorf("subject variant %q has no subject", variant.ID)
+		}
+		if variant.Weight < 0 {
+			return nil, fmt.Errorf("subject variant %q has a negative weight", variant.ID)
+		}
+		if variant.Weight == 0 {
+			variant.Weight = 1
+		}
+	}
+
+	return variants, nil
+}
+
+// ChooseSubjectVariant picks a variant with probability proportional to its weight.
+// The choice is a pure function of key, so workflows stay deterministic on replay.
+func ChooseSubjectVariant(variants []SubjectVariant, key string) (SubjectVariant, bool) {
+	total := 0
+	for _, variant := range variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return SubjectVariant{}, false
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	point := int(hash.Sum64() % uint64(total))
+
+	for _, variant := range variants {
+		if point < variant.Weight {
+			return variant, true
+		}
+		point -= variant.Weight
+	}
+	return variants[len(variants)-1], true
+}
+
+// RenderSubject fills merge tags in a subject or preheader template. Supported tags are
+// {{firstName}}, {{lastName}}, {{fullName}}, {{companyName}} and {{senderName}}.
+// Line breaks are removed and spacing left by empty tags is tidied up.
+func RenderSubject(subjectTemplate string, params TemplateParams) string {
+	subject := processPlaceholders(subjectTemplate, params)
+	subject = strings.ReplaceAll(subject, "{{fullName}}", i18n.Isolate(params.Language, params.RecipientName))
+	subject = strings.ReplaceAll(subject, "{{companyName}}", i18n.Isolate(params.Language, params.BrandName))
+	subject = strings.ReplaceAll(subject, "{{senderName}}", i18n.Isolate(params.Language, params.SenderName))
+
+	subject = subjectSpacePattern.ReplaceAllString(subject, " ")
+	subject = subjectPunctuationPattern.ReplaceAllString(subject, "$1")
+	return strings.TrimSpace(subject)
+}
@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"html/template"
+	"net/url"
 	"os"
 	"regexp"
 	"strings"
+
+	"cardprocessor-go/internal/i18n"
 )
 
 // BirthdayTemplateId represents the different template types
@@ -19,6 +22,20 @@ const (
 	TemplateCustom   BirthdayTemplateId = "custom"
 )
 
+// ParseTemplateId maps a stored template name to a BirthdayTemplateId, defaulting to TemplateDefault
+func ParseTemplateId(name string) BirthdayTemplateId {
+	switch strings.ToLower(name) {
+	case "confetti":
+		return TemplateConfetti
+	case "balloons":
+		return TemplateBalloons
+	case "custom":
+		return TemplateCustom
+	default:
+		return TemplateDefault
+	}
+}
+
 // TemplateParams represents the parameters for birthday template rendering
 type TemplateParams struct {
 	RecipientName        string                 `json:"recipientName"`
@@ -31,6 +48,8 @@ type TemplateParams struct {
 	PromotionTitle       string                 `json:"promotionTitle"`
 	PromotionDescription string                 `json:"promotionDescription"`
 	UnsubscribeToken     string                 `json:"unsubscribeToken"`
+	Preheader            string                 `json:"preheader"`
+	Language             string                 `json:"language"` // built-in strings fall back to English
 	IsTest               bool                   `json:"isTest"`
 }
 
@@ -66,7 +85,8 @@ func RenderBirthdayTemplate(templateId BirthdayTemplateId, params TemplateParams
 	return renderPredefinedTemplate(templateId, params)
 }
 
-// renderCustomTemplate renders a custom birthday template exactly matching server-node
+// renderCustomTemplate renders a custom birthday template. A component tree in
+// "layout" takes precedence; otherwise the title/message/imageUrl fields are used.
 func renderCustomTemplate(params TemplateParams) string {
 	customData := params.CustomThemeData
 	if customData == nil {
@@ -80,93 +100,52 @@ func renderCustomTemplate(params TemplateParams) string {
 		}
 	}
 
-	title := "Happy Birthday!"
+	if rawLayout, ok := customData["layout"]; ok && rawLayout != nil {
+		layout, err := ParseComponentLayout(rawLayout)
+		if err == nil {
+			var html string
+			html, err = CompileLayout(layout, params)
+			if err == nil {
+				return html
+			}
+		}
+		fmt.Printf("⚠️ [renderCustomTemplate] Invalid custom layout, falling back to field-based card: %v\n", err)
+	}
+
+	text := i18n.GetCardText(params.Language)
+	title := text.HappyBirthday
 	if customTitle, ok := customData["title"].(string); ok && customTitle != "" {
 		title = customTitle
 	} else if params.RecipientName != "" {
-		title = fmt.Sprintf("Happy Birthday, %s!", params.RecipientName)
+		title = fmt.Sprintf(text.HappyBirthdayName, i18n.Isolate(params.Language, params.RecipientName))
 	}
 
 	message := params.Message
 	if customMessage, ok := customData["message"].(string); ok && customMessage != "" {
 		message = customMessage
 	}
-	if message == "" {
-		message = "Wishing you a wonderful day!"
-	}
 
 	signature := ""
 	if customSignature, ok := customData["signature"].(string); ok {
 		signature = customSignature
 	}
 
-	fromMessage := params.SenderName
-	if fromMessage == "" {
-		fromMessage = "The Team"
+	header := map[string]string{
+		"height":        "200px",
+		"border-radius": "12px 12px 0 0",
+		"background":    "linear-gradient(135deg, #a8e6cf 0%, #dcedc1 100%)",
 	}
-
-	// Header image section - exactly matching server-node logic
-	headerImageSection := ""
 	if imageUrl, ok := customData["imageUrl"].(string); ok && imageUrl != "" {
-		headerImageSection = fmt.Sprintf(`
-			<div style="height: 200px; background-image: url('%s'); background-size: cover; background-position: center; border-radius: 12px 12px 0 0;">
-			</div>`, imageUrl)
-	} else {
-		headerImageSection = `
-			<div style="background: linear-gradient(135deg, #a8e6cf 0%, #dcedc1 100%); height: 200px; border-radius: 12px 12px 0 0;">
-			</div>`
+		header["src"] = imageUrl
 	}
 
-	// Conditionally render fromMessage section only if no signature
-	fromMessageSection := ""
-	if signature == "" {
-		fromMessageSection = fmt.Sprintf(`
-				<!-- 4. From Message -->
-				<div style="padding: 20px 30px 10px 30px; border-top: 1px solid #e2e8f0; text-align: center;">
-					<div style="font-size: 0.9rem; color: #718096;">
-						<p style="margin: 0; font-weight: 600; color: #4a5568;">%s</p>
-					</div>
-				</div>`, template.HTMLEscapeString(fromMessage))
-	}
-
-	// Always render unsubscribe section if token exists
-	unsubscribeSection := renderUnsubscribeSection(params)
-
-	return fmt.Sprintf(`<html>
-		<body style="font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 20px; background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);">
-			<div style="max-width: 600px; margin: 0 auto; background: white; border-radius: 12px; overflow: hidden; box-shadow: 0 20px 40px rgba(0,0,0,0.1);">
-				
-				<!-- 1. Header Image (standalone) -->
-				%s
-				
-				<!-- 2. Header Text (separate from image) -->
-				<div style="padding: 30px 30px 20px 30px; text-align: center; border-bottom: 1px solid #f0f0f0;">
-					<h1 style="color: #2d3748; font-size: 2.5rem; margin: 0; font-weight: bold;">%s</h1>
-				</div>
-				
-				<!-- 3. Content Area (message) -->
-				<div style="padding: 30px;">
-					<div style="font-size: 1.2rem; line-height: 1.6; color: #4a5568; text-align: center; margin-bottom: 20px;">%s</div>
-					%s
-					%s
-				</div>
-				
-				%s
-				%s
-			</div>
-		</body>
-	</html>`,
-		headerImageSection,
-		template.HTMLEscapeString(title),
-		sanitizeHTMLContent(message, params),
-		renderPromotionContent(params),
-		renderSignature(signature, params),
-		fromMessageSection,
-		unsubscribeSection,
-	)
+	return compileCardLayout(birthdayCardLayout(
+		"linear-gradient(135deg, #667eea 0%, #764ba2 100%)",
+		header, title, message, signature, params,
+	), params)
 }
 
-// renderPredefinedTemplate renders predefined birthday templates exactly matching server-node
+// renderPredefinedTemplate renders predefined birthday templates
 func renderPredefinedTemplate(templateId BirthdayTemplateId, params TemplateParams) string {
 	colors := themeColors[templateId]
 	if colors.Primary == "" {
@@ -179,12 +158,11 @@ func renderPredefinedTemplate(templateId BirthdayTemplateId, params TemplatePara
 	}
 
 	// Check if there's custom theme data with custom title/signature for this specific theme
-	headline := fmt.Sprintf("Happy Birthday%s!", func() string {
-		if params.RecipientName != "" {
-			return ", " + params.RecipientName
-		}
-		return ""
-	}())
+	text := i18n.GetCardText(params.Language)
+	headline := text.HappyBirthday
+	if params.RecipientName != "" {
+		headline = fmt.Sprintf(text.HappyBirthdayName, i18n.Isolate(params.Language, params.RecipientName))
+	}
 	signature := ""
 
 	if params.CustomThemeData != nil {
@@ -213,65 +191,88 @@ func renderPredefinedTemplate(templateId BirthdayTemplateId, params TemplatePara
 		}
 	}
 
-	message := params.Message
+	header := map[string]string{
+		"src":           headerImage,
+		"height":        "200px",
+		"border-radius": "12px 12px 0 0",
+	}
+
+	return compileCardLayout(birthdayCardLayout(
+		fmt.Sprintf("linear-gradient(135deg, %s 0%%, %s 100%%)", colors.Primary, colors.Secondary),
+		header, headline, params.Message, signature, params,
+	), params)
+}
+
+// birthdayCardLayout builds the standard card: header image (or personalized card image),
+// headline, message with promotion and signature, sender line (only without a signature),
+// and unsubscribe footer
+func birthdayCardLayout(pageBackground string, header map[string]string, headline, message, signature string, params TemplateParams) Component {
+	text := i18n.GetCardText(params.Language)
 	if message == "" {
-		message = "Wishing you a wonderful day!"
+		message = text.DefaultMessage
 	}
 
 	fromMessage := params.SenderName
 	if fromMessage == "" {
-		fromMessage = "The Team"
+		fromMessage = text.DefaultSender
+	}
+
+	// A personalized card image replaces the template header
+	if params.ImageUrl != "" {
+		header = map[string]string{
+			"src":           params.ImageUrl,
+			"alt":           headline,
+			"border-radius": "12px 12px 0 0",
+		}
+	}
+
+	content := []Component{
+		{Type: ComponentText, Content: message, Attributes: map[string]string{
+			"font-size": "19px", "line-height": "1.6", "color": "#4a5568", "text-align": "center", "padding": "0 0 20px 0",
+		}},
+		{Type: ComponentPromotion},
+	}
+	if signature != "" {
+		content = append(content, Component{Type: ComponentText, Content: signature, Attributes: map[string]string{
+			"font-size": "16px", "line-height": "1.5", "color": "#718096", "text-align": "center", "font-style": "italic", "padding": "20px 0 0 0",
+		}})
+	}
+
+	sections := []Component{
+		NewComponent(ComponentSection, map[string]string{"padding": "0"},
+			Component{Type: ComponentImage, Attributes: header},
+		),
+		NewComponent(ComponentSection, map[string]string{"padding": "30px 30px 20px 30px", "text-align": "center", "border-bottom": "1px solid #f0f0f0"},
+			Component{Type: ComponentText, Content: headline, Attributes: map[string]string{
+				"tag": "h1", "format": "plain", "color": "#2d3748", "font-size": "40px", "font-weight": "bold", "text-align": "center",
+			}},
+		),
+		NewComponent(ComponentSection, map[string]string{"padding": "30px"}, content...),
 	}
 
-	// Conditionally render fromMessage section only if no signature
-	fromMessageSection := ""
 	if signature == "" {
-		fromMessageSection = fmt.Sprintf(`
-				<!-- 4. From Message -->
-				<div style="padding: 20px 30px 10px 30px; border-top: 1px solid #e2e8f0; text-align: center;">
-					<div style="font-size: 0.9rem; color: #718096;">
-						<p style="margin: 0; font-weight: 600; color: #4a5568;">%s</p>
-					</div>
-				</div>`, template.HTMLEscapeString(fromMessage))
-	}
-
-	// Always render unsubscribe section if token exists
-	unsubscribeSection := renderUnsubscribeSection(params)
-
-	return fmt.Sprintf(`<html>
-		<body style="font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 20px; background: linear-gradient(135deg, %s 0%%, %s 100%%);">
-			<div style="max-width: 600px; margin: 0 auto; background: white; border-radius: 12px; overflow: hidden; box-shadow: 0 20px 40px rgba(0,0,0,0.1);">
-				
-				<!-- 1. Header Image (standalone) -->
-				<div style="height: 200px; background-image: url('%s'); background-size: cover; background-position: center; border-radius: 12px 12px 0 0;">
-				</div>
-				
-				<!-- 2. Header Text (separate from image) -->
-				<div style="padding: 30px 30px 20px 30px; text-align: center; border-bottom: 1px solid #f0f0f0;">
-					<h1 style="color: #2d3748; font-size: 2.5rem; margin: 0; font-weight: bold;">%s</h1>
-				</div>
-				
-				<!-- 3. Content Area (message) -->
-				<div style="padding: 30px;">
-					<div style="font-size: 1.2rem; line-height: 1.6; color: #4a5568; text-align: center; margin-bottom: 20px;">%s</div>
-					%s
-					%s
-				</div>
-				
-				%s
-				%s
-			</div>
-		</body>
-	</html>`,
-		colors.Primary, colors.Secondary,
-		headerImage,
-		template.HTMLEscapeString(headline),
-		sanitizeHTMLContent(message, params),
-		renderPromotionContent(params),
-		renderSignature(signature, params),
-		fromMessageSection,
-		unsubscribeSection,
-	)
+		sections = append(sections, NewComponent(ComponentSection, map[string]string{"padding": "20px 30px 10px 30px", "border-top": "1px solid #e2e8f0", "text-align": "center"},
+			Component{Type: ComponentText, Content: fromMessage, Attributes: map[string]string{
+				"tag": "p", "format": "plain", "font-size": "14px", "font-weight": "600", "color": "#4a5568", "text-align": "center",
+			}},
+		))
+	}
+
+	sections = append(sections, NewComponent(ComponentSection, map[string]string{"padding": "0"},
+		Component{Type: ComponentUnsubscribe},
+	))
+
+	return NewComponent(ComponentBody, map[string]string{"background": pageBackground}, sections...)
+}
+
+// compileCardLayout compiles a built-in layout; these are static and always valid
+func compileCardLayout(layout Component, params TemplateParams) string {
+	html, err := CompileLayout(layout, params)
+	if err != nil {
+		fmt.Printf("❌ [compileCardLayout] Built-in layout failed to compile: %v\n", err)
+		return fmt.Sprintf(`<html><body><p>%s</p></body></html>`, template.HTMLEscapeString(i18n.GetCardText(params.Language).HappyBirthday))
+	}
+	return html
 }
 
 // renderPromotionContent renders promotion content if available
@@ -290,12 +291,19 @@ func renderPromotionContent(params TemplateParams) string {
 		promotionDescription = fmt.Sprintf(`<p style="margin: 0 0 15px 0; color: #4a5568; font-size: 1rem; line-height: 1.5;">%s</p>`, sanitizeHTMLContent(params.PromotionDescription, params))
 	}
 
+	// The accent border sits on the side the text starts from
+	accentSide := "left"
+	if i18n.IsRTL(params.Language) {
+		accentSide = "right"
+	}
+
 	return fmt.Sprintf(`
-		<div style="margin: 30px 0; padding: 25px; background: linear-gradient(135deg, #f7fafc 0%%, #edf2f7 100%%); border-radius: 8px; border-left: 4px solid #667eea;">
+		<div style="margin: 30px 0; padding: 25px; background: linear-gradient(135deg, #f7fafc 0%%, #edf2f7 100%%); border-radius: 8px; border-%s: 4px solid #667eea;">
 			%s
 			%s
 			<div style="color: #2d3748; font-size: 1rem; line-height: 1.6;">%s</div>
 		</div>`,
+		accentSide,
 		promotionTitle,
 		promotionDescription,
 		sanitizeHTMLContent(params.PromotionContent, params),
@@ -320,26 +328,40 @@ func renderUnsubscribeSection(params TemplateParams) string {
 		return ""
 	}
 
-	// Use the main server's unsubscribe endpoint (APP_URL env var or default to localhost:3502)
-	baseUrl := os.Getenv("APP_URL")
-	if baseUrl == "" {
-		baseUrl = "http://localhost:3502"
-	}
-	unsubscribeUrl := fmt.Sprintf("%s/api/unsubscribe/birthday?token=%s", baseUrl, params.UnsubscribeToken)
+	unsubscribeUrl := unsubscribePageURL(params.UnsubscribeToken)
+	preferencesUrl := fmt.Sprintf("%s/api/preferences?token=%s", appBaseURL(), url.QueryEscape(params.UnsubscribeToken))
 
 	fmt.Printf("✅ [renderUnsubscribeSection] Generated unsubscribe URL: %s\n", unsubscribeUrl[:50]+"...")
 
+	text := i18n.GetCardText(params.Language)
+
 	return fmt.Sprintf(`
 		<div style="padding: 20px 30px; border-top: 1px solid #e2e8f0; text-align: center; background-color: #f7fafc;">
 			<p style="margin: 0; font-size: 0.8rem; color: #a0aec0; line-height: 1.4;">
-				Don't want to receive birthday cards? 
-				<a href="%s" style="color: #667eea; text-decoration: none; font-weight: 500;">Unsubscribe here</a>
+				%s 
+				<a href="%s" style="color: #667eea; text-decoration: none; font-weight: 500;">%s</a>
+				&middot;
+				<a href="%s" style="color: #667eea; text-decoration: none; font-weight: 500;">%s</a>
 			</p>
-		</div>`, unsubscribeUrl)
+		</div>`, template.HTMLEscapeString(text.UnsubscribePrompt), unsubscribeUrl, template.HTMLEscapeString(text.UnsubscribeLink),
+		preferencesUrl, template.HTMLEscapeString(text.ManagePreferences))
 }
 
-// processPlaceholders replaces placeholder tokens with actual customer data
+// processPlaceholders replaces placeholder tokens with actual customer data. Names are
+// wrapped in directional isolates when they could disturb the card language's text flow.
 func processPlaceholders(content string, params TemplateParams) string {
+	return replaceNamePlaceholders(content, params, func(name string) string {
+		return i18n.Isolate(params.Language, name)
+	})
+}
+
+// processURLPlaceholders replaces placeholder tokens in links, where isolate marks would
+// corrupt the URL
+func processURLPlaceholders(content string, params TemplateParams) string {
+	return replaceNamePlaceholders(content, params, func(name string) string { return name })
+}
+
+func replaceNamePlaceholders(content string, params TemplateParams, format func(string) string) string {
 	if content == "" {
 		return content
 	}
@@ -358,8 +380,8 @@ func processPlaceholders(content string, params TemplateParams) string {
 	}
 
 	// Replace placeholders
-	content = strings.ReplaceAll(content, "{{firstName}}", firstName)
-	content = strings.ReplaceAll(content, "{{lastName}}", lastName)
+	content = strings.ReplaceAll(content, "{{firstName}}", format(firstName))
+	content = strings.ReplaceAll(content, "{{lastName}}", format(lastName))
 
 	return content
 }
@@ -426,3 +448,18 @@ func ParseCustomThemeData(customThemeData interface{}) map[string]interface{} {
 		return nil
 	}
 }
+
+// appBaseURL is the public base URL of the contact-facing pages (APP_URL env var or default to
+// localhost:3502)
+func appBaseURL() string {
+	baseUrl := os.Getenv("APP_URL")
+	if baseUrl == "" {
+		baseUrl = "http://localhost:3502"
+	}
+	return strings.TrimRight(baseUrl, "/")
+}
+
+// unsubscribePageURL links to the main server's unsubscribe page for a token
+func unsubscribePageURL(token string) string {
+	return fmt.Sprintf("%s/api/unsubscribe/birthday?token=%s", appBaseURL(), url.QueryEscape(token))
+}
@@ -0,0 +1,89 @@
+package temporal
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// Workflow change IDs for workflow.GetVersion. A workflow that changes which commands it
+// issues (activities, timers, child workflows) must keep issuing the old ones for workflows
+// that started on the old code, or their replay fails with a nondeterminism error. Wrap the
+// change in GetVersion under a new change ID, keep the workflow.DefaultVersion branch until
+// no workflow started before the change is left running, and add a history of the new branch
+// to testdata/replay.
+const (
+	// changePromotionDelay replaced the split flow's fixed 30 second sleep with the tenant's
+	// promotion delay, which a cancel_promotion signal can call off
+	changePromotionDelay = "split-promotion-delay"
+	// changeRecordFailedSend records failed sends with the RecordFailedSend activity
+	changeRecordFailedSend = "record-failed-send"
+	// changeInvitationFailureStatus marks invitations failed with UpdateContactInvitationStatus
+	// when preparing or sending them fails
+	changeInvitationFailureStatus = "invitation-failure-status"
+	// changeBirthdayRunPages pages birthday runs through their contacts, passing the cards only
+	// contact IDs and continuing as new after each page
+	changeBirthdayRunPages = "birthday-run-pages"
+	// changePromotionSendTime resolves "next day at HH:MM" promotion delays in the
+	// ResolvePromotionSendTime activity instead of loading the timezone in the workflow
+	changePromotionSendTime = "promotion-send-time"
+)
+
+// Versions of each change; the workflow.DefaultVersion branch is the code before it
+const (
+	versionPromotionDelay          = 1
+	versionRecordFailedSend        = 1
+	versionInvitationFailureStatus = 1
+	versionBirthdayRunPages        = 1
+	versionPromotionSendTime       = 1
+)
+
+// legacyPromotionDelay is the split flow's delay before changePromotionDelay
+const legacyPromotionDelay = 30 * time.Second
+
+// waitBeforePromotion waits between the split flow's birthday card and its promotional email.
+// Workflows started before changePromotionDelay keep their fixed 30 second sleep and always
+// send the promotion.
+func waitBeforePromotion(ctx workflow.Context, input BirthdayTestWorkflowInput) (bool, error) {
+	if workflow.GetVersion(ctx, changePromotionDelay, workflow.DefaultVersion, versionPromotionDelay) == workflow.DefaultVersion {
+		workflow.GetLogger(ctx).Info("⏳ [SPLIT FLOW] Waiting 30 seconds before sending promotional email...")
+		if err := workflow.Sleep(ctx, legacyPromotionDelay); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return waitForPromotionDelay(ctx, input)
+}
+
+// nextDayPromotionWait returns how long to wait for a "next day at HH:MM" promotion delay,
+// from the send time ResolvePromotionSendTime works out in the card's timezone. Workflows
+// started before changePromotionSendTime started their timer in the same workflow task, so
+// this branch only runs when they replay, and replay doesn't compare timer durations.
+func nextDayPromotionWait(ctx workflow.Context, input BirthdayTestWorkflowInput, delay PromotionDelay) (time.Duration, error) {
+	now := workflow.Now(ctx)
+	if workflow.GetVersion(ctx, changePromotionSendTime, workflow.DefaultVersion, versionPromotionSendTime) == workflow.DefaultVersion {
+		return delay.Until(now, time.UTC), nil
+	}
+
+	var sendAt time.Time
+	err := workflow.ExecuteActivity(ctx, ResolvePromotionSendTime, PromotionSendTimeInput{
+		PromotionDelay: input.PromotionDelay,
+		Timezone:       input.Timezone,
+		SentAt:         now,
+	}).Get(ctx, &sendAt)
+	if temporal.IsCanceledError(err) {
+		return 0, err
+	}
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to resolve the promotion send time, using UTC", "timezone", input.Timezone, "error", err)
+		return delay.Until(now, time.UTC), nil
+	}
+	return sendAt.Sub(workflow.Now(ctx)), nil
+}
+
+// recordsInvitationFailures reports whether a failed invitation is marked failed. Workflows
+// started before changeInvitationFailureStatus only updated the status after a send.
+func recordsInvitationFailures(ctx workflow.Context) bool {
+	return workflow.GetVersion(ctx, changeInvitationFailureStatus, workflow.DefaultVersion, versionInvitationFailureStatus) != workflow.DefaultVersion
+}
@@ -14,6 +14,7 @@ func registerActivities(w worker.Worker) {
 	w.RegisterActivity(FetchPromotionData)
 	w.RegisterActivity(PreparePromotionalEmail)
 	w.RegisterActivity(SendPromotionalEmail)
+	w.RegisterActivity(ResolvePromotionSendTime)
 
 	// Register birthday invitation activities
 	w.RegisterActivity(PrepareBirthdayInvitationEmail)
@@ -23,13 +24,36 @@ func registerActivities(w worker.Worker) {
 
 	// Register unsubscribe token generation
 	w.RegisterActivity(GenerateBirthdayUnsubscribeToken)
+	// Register personalized card image rendering
+	w.RegisterActivity(GenerateBirthdayCardImage)
 	// Register outgoing email tracking
 	w.RegisterActivity(InsertOutgoingEmail)
+	w.RegisterActivity(RecordFailedSend)
 	// Register company name fetching
 	w.RegisterActivity(GetCompanyNameActivity)
-
+	// Register retention purge activities
+	w.RegisterActivity(GetRetentionPolicies)
+	w.RegisterActivity(PurgeTenantEmailData)
+	// Register scheduled birthday send activities
+	w.RegisterActivity(PrepareScheduledBirthdayCards)
+	w.RegisterActivity(ListBirthdayRecipients)
+	w.RegisterActivity(PrepareScheduledBirthdayCard)
+	w.RegisterActivity(RecordBirthdayRun)
 
 	// Register workflows
-	w.RegisterWorkflow(BirthdayTestWorkflow)
-	w.RegisterWorkflow(BirthdayInvitationWorkflow)
+	registerWorkflows(w)
+}
+
+// workflowRegistry is a worker or a workflow replayer
+type workflowRegistry interface {
+	RegisterWorkflow(w interface{})
+}
+
+// registerWorkflows registers all workflows with a worker, or with a replayer so replay tests
+// run every workflow the worker does
+func registerWorkflows(r workflowRegistry) {
+	r.RegisterWorkflow(BirthdayTestWorkflow)
+	r.RegisterWorkflow(BirthdayInvitationWorkflow)
+	r.RegisterWorkflow(RetentionPurgeWorkflow)
+	r.RegisterWorkflow(BirthdaySendWorkflow)
 }
@@ -1,6 +1,7 @@
 package temporal
 
 import (
+	"fmt"
 	"time"
 
 	"cardprocessor-go/internal/models"
@@ -24,7 +25,20 @@ type BirthdayTestWorkflowInput struct {
 	SenderName            string                 `json:"senderName"`
 	PromotionID           string                 `json:"promotionId"`
 	SplitPromotionalEmail bool                   `json:"splitPromotionalEmail"`
+	PersonalizedCardImage bool                   `json:"personalizedCardImage"`
+	ContactBirthday       string                 `json:"contactBirthday,omitempty"`
+	SubjectTemplate       string                 `json:"subjectTemplate,omitempty"`
+	PreheaderText         string                 `json:"preheaderText,omitempty"`
+	SubjectVariants       []SubjectVariant       `json:"subjectVariants,omitempty"`
+	SubjectVariant        string                 `json:"subjectVariant,omitempty"` // chosen variant ID, set by the workflow
+	Language              string                 `json:"language,omitempty"`       // resolved card language; empty means English
 	IsTest                bool                   `json:"isTest"`
+	PromotionDelay        string                 `json:"promotionDelay,omitempty"`   // wait before a split promotion, see ParsePromotionDelay; empty is 30 seconds
+	Timezone              string                 `json:"timezone,omitempty"`         // IANA timezone of a "next day at" promotion delay; empty is UTC
+	ActivityPolicies      *ActivityPolicies      `json:"activityPolicies,omitempty"` // nil uses DefaultActivityPolicies
+	// ScheduledCard is set on the cards of a birthday run, which only carry the contact, tenant
+	// and timezone; the rest is loaded from the tenant's birthday settings when the card starts
+	ScheduledCard bool `json:"scheduledCard,omitempty"`
 }
 
 // BirthdayTestWorkflowResult represents the result of birthday test workflow
@@ -34,31 +48,82 @@ type BirthdayTestWorkflowResult struct {
 	MessageID  string `json:"messageId,omitempty"`
 	Provider   string `json:"provider,omitempty"`
 	Error      string `json:"error,omitempty"`
+	ErrorType  string `json:"errorType,omitempty"` // Classification of a failed send, e.g. ProviderRejected
+	Skipped    bool   `json:"skipped,omitempty"`   // Not sent because the contact opted out or was erased
 	SentAt     string `json:"sentAt"`
+
+	// PromotionCanceled is set when a cancel_promotion signal called off the split promotional email
+	PromotionCanceled bool `json:"promotionCanceled,omitempty"`
 }
 
 // BirthdayInvitationWorkflowInput represents the input for birthday invitation workflow
 type BirthdayInvitationWorkflowInput struct {
-	ContactID        string `json:"contactId"`
-	ContactEmail     string `json:"contactEmail"`
-	ContactFirstName string `json:"contactFirstName"`
-	ContactLastName  string `json:"contactLastName"`
-	TenantID         string `json:"tenantId"`
-	TenantName       string `json:"tenantName"`
-	UserID           string `json:"userId"`
-	FromEmail        string `json:"fromEmail"`
-	BaseURL          string `json:"baseUrl"`
+	ContactID        string            `json:"contactId"`
+	ContactEmail     string            `json:"contactEmail"`
+	ContactFirstName string            `json:"contactFirstName"`
+	ContactLastName  string            `json:"contactLastName"`
+	TenantID         string            `json:"tenantId"`
+	TenantName       string            `json:"tenantName"`
+	UserID           string            `json:"userId"`
+	FromEmail        string            `json:"fromEmail"`
+	BaseURL          string            `json:"baseUrl"`
+	Language         string            `json:"language,omitempty"`
+	ActivityPolicies *ActivityPolicies `json:"activityPolicies,omitempty"` // nil uses DefaultActivityPolicies
 }
 
 // BirthdayInvitationWorkflowResult represents the result of birthday invitation workflow
 type BirthdayInvitationWorkflowResult struct {
-	ContactID       string `json:"contactId"`
-	Success         bool   `json:"success"`
-	MessageID       string `json:"messageId,omitempty"`
-	Provider        string `json:"provider,omitempty"`
-	Error           string `json:"error,omitempty"`
-	SentAt          string `json:"sentAt"`
-	InvitationToken string `json:"invitationToken,omitempty"`
+	ContactID    string `json:"contactId"`
+	Success      bool   `json:"success"`
+	MessageID    string `json:"messageId,omitempty"`
+	Provider     string `json:"provider,omitempty"`
+	Error        string `json:"error,omitempty"`
+	ErrorType    string `json:"errorType,omitempty"` // Classification of a failed send, e.g. ProviderRejected
+	SentAt       string `json:"sentAt"`
+	InvitationID string `json:"invitationId,omitempty"` // birthday_invitations row tracking the link
+}
+
+// QueryCurrentStep is the query type that reports which step a send workflow is on
+const QueryCurrentStep = "current_step"
+
+// Send workflow steps reported by the current_step query
+const (
+	StepStarting         = "starting"
+	StepLoadCard         = "load_card"
+	StepUnsubscribeToken = "unsubscribe_token"
+	StepInvitationToken  = "invitation_token"
+	StepCardImage        = "card_image"
+	StepFetchPromotion   = "fetch_promotion"
+	StepPrepareEmail     = "prepare_email"
+	StepSendEmail        = "send_email"
+	StepPromotionDelay   = "promotion_delay"
+	StepSendPromotion    = "send_promotion"
+	StepUpdateStatus     = "update_status"
+	StepCompleted        = "completed"
+	StepCanceled         = "canceled"
+)
+
+// trackStep registers the current_step query handler and returns the setter for the step
+func trackStep(ctx workflow.Context) func(string) {
+	step := StepStarting
+	err := workflow.SetQueryHandler(ctx, QueryCurrentStep, func() (string, error) {
+		return step, nil
+	})
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("Failed to register current step query", "error", err)
+	}
+	return func(next string) {
+		step = next
+	}
+}
+
+// cardEmailType is the email type a card is tracked under: test sends are kept apart from
+// real birthday cards
+func cardEmailType(input BirthdayTestWorkflowInput) string {
+	if input.IsTest {
+		return "test_card"
+	}
+	return "birthday_card"
 }
 
 // BirthdayTestWorkflow implements the birthday test card workflow
@@ -66,32 +131,61 @@ func BirthdayTestWorkflow(ctx workflow.Context, input BirthdayTestWorkflowInput)
 	logger := workflow.GetLogger(ctx)
 	userIDinfo := input.UserID
 	logger.Info("🎂 Starting birthday test workflow", "userId", input.UserID, "email", input.UserEmail, userIDinfo)
-
-	// Set activity options
-	activityOptions := workflow.ActivityOptions{
-		StartToCloseTimeout: 5 * time.Minute,
-		HeartbeatTimeout:    1 * time.Minute,
-		RetryPolicy: &temporal.RetryPolicy{
-			InitialInterval:    1 * time.Second,
-			MaximumInterval:    30 * time.Second,
-			BackoffCoefficient: 2.0,
-			MaximumAttempts:    3,
-		},
+	setStep := trackStep(ctx)
+
+	// Set activity options: preparing by default, with their own policies for sends and tracking
+	policies := input.ActivityPolicies.orDefault()
+	ctx = withActivityPolicy(ctx, policies.Prepare)
+	sendCtx := withActivityPolicy(ctx, policies.Send)
+	trackCtx := withActivityPolicy(ctx, policies.Tracking)
+
+	// Step 0: A birthday run's card only names the contact, so build the card here
+	if input.ScheduledCard {
+		setStep(StepLoadCard)
+		var scheduled ScheduledBirthdayCard
+		err := workflow.ExecuteActivity(ctx, PrepareScheduledBirthdayCard, PrepareScheduledBirthdayCardInput{
+			TenantID:  input.TenantID,
+			ContactID: input.UserID,
+			Timezone:  input.Timezone,
+		}).Get(ctx, &scheduled)
+		if temporal.IsCanceledError(err) {
+			setStep(StepCanceled)
+			return BirthdayTestWorkflowResult{}, err
+		}
+		if err != nil {
+			logger.Error("Failed to load scheduled birthday card", "contactId", input.UserID, "error", err)
+			return BirthdayTestWorkflowResult{
+				Success:    false,
+				WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
+				Error:      err.Error(),
+				SentAt:     workflow.Now(ctx).Format(time.RFC3339),
+			}, nil
+		}
+		if scheduled.Skipped {
+			setStep(StepCompleted)
+			return BirthdayTestWorkflowResult{
+				WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
+				Skipped:    true,
+				SentAt:     workflow.Now(ctx).Format(time.RFC3339),
+			}, nil
+		}
+		scheduled.Card.ActivityPolicies = input.ActivityPolicies
+		input = scheduled.Card
 	}
-	ctx = workflow.WithActivityOptions(ctx, activityOptions)
 
-	// Step 1: Generate unsubscribe token and store in database (if not a test)
+	// Step 1: Sign the unsubscribe token
+	setStep(StepUnsubscribeToken)
 	var unsubscribeTokenResult TokenResult
 	
-	// For test emails, don't try to store in database since UserID != ContactID
-	// This prevents foreign key constraint violations while still generating tokens
+	// For test emails UserID isn't a contact, so the token carries no contact ID and its
+	// link only shows a test notice
 	contactIDForToken := ""
 	if !input.IsTest {
 		contactIDForToken = input.UserID
 	}
 	
 	err := workflow.ExecuteActivity(ctx, GenerateBirthdayUnsubscribeToken, TokenInput{
-		ContactID: contactIDForToken, // Empty for test emails to skip database storage
+		ContactID: contactIDForToken, // Empty for test emails
 		TenantID:  input.TenantID,
 		Action:    "unsubscribe_birthday",
 		ExpiresIn: "never",
@@ -105,8 +199,7 @@ func BirthdayTestWorkflow(ctx workflow.Context, input BirthdayTestWorkflowInput)
 			"hasToken", unsubscribeTokenResult.Token != "",
 			"tokenLength", len(unsubscribeTokenResult.Token),
 			"success", unsubscribeTokenResult.Success,
-			"isTest", input.IsTest,
-			"storedInDB", !input.IsTest && contactIDForToken != "")
+			"isTest", input.IsTest)
 	}
 
 	// Step 2: Prepare enriched input with unsubscribe token
@@ -128,12 +221,48 @@ func BirthdayTestWorkflow(ctx workflow.Context, input BirthdayTestWorkflowInput)
 			return tokenToAdd
 		}())
 
+	// Step 2a: Pick the A/B subject variant for this send
+	if len(input.SubjectVariants) > 0 {
+		variantKey := workflow.GetInfo(ctx).WorkflowExecution.ID + "|" + input.UserEmail
+		if variant, ok := ChooseSubjectVariant(input.SubjectVariants, variantKey); ok {
+			enrichedInput.SubjectVariant = variant.ID
+			enrichedInput.SubjectTemplate = variant.Subject
+			if variant.Preheader != "" {
+				enrichedInput.PreheaderText = variant.Preheader
+			}
+			logger.Info("🔀 Assigned subject variant", "variant", variant.ID, "weight", variant.Weight)
+		}
+	}
+
+	// Step 2b: Render the personalized card image (cached per template version, contact and year)
+	if input.PersonalizedCardImage {
+		setStep(StepCardImage)
+		var cardImageResult CardImageResult
+		err = workflow.ExecuteActivity(ctx, GenerateBirthdayCardImage, CardImageInput{
+			TenantID:        input.TenantID,
+			ContactID:       input.UserID,
+			Name:            cardImageName(input.UserFirstName, input.UserLastName),
+			Birthday:        input.ContactBirthday,
+			EmailTemplate:   input.EmailTemplate,
+			CustomThemeData: input.CustomThemeData,
+			Year:            workflow.Now(ctx).Year(),
+			Language:        input.Language,
+		}).Get(ctx, &cardImageResult)
+		if err != nil || cardImageResult.ImageURL == "" {
+			logger.Error("Failed to generate personalized card image, using template header", "error", err, "reason", cardImageResult.Error)
+		} else {
+			enrichedInput.CustomThemeData["cardImageUrl"] = cardImageResult.ImageURL
+			logger.Info("🖼️ Added personalized card image to CustomThemeData", "url", cardImageResult.ImageURL)
+		}
+	}
+
 	// Step 3: Fetch promotion data if promotion ID is provided
 	var promotion *models.Promotion
 	logger.Info("📊 [Debug] Workflow settings",
 		"splitPromotionalEmail", input.SplitPromotionalEmail,
 		"hasPromotionID", input.PromotionID != "")
 	if input.PromotionID != "" {
+		setStep(StepFetchPromotion)
 		err = workflow.ExecuteActivity(ctx, FetchPromotionData, FetchPromotionInput{
 			PromotionID: input.PromotionID,
 			TenantID:    input.TenantID,
@@ -156,8 +285,13 @@ func BirthdayTestWorkflow(ctx workflow.Context, input BirthdayTestWorkflowInput)
 		logger.Info("📧 [SPLIT FLOW] Email 1/2: Preparing birthday card WITHOUT promotion content")
 
 		// Prepare birthday card WITHOUT promotion (PrepareBirthdayTestEmail sets PromotionContent="")
+		setStep(StepPrepareEmail)
 		var emailContent EmailContent
 		err = workflow.ExecuteActivity(ctx, PrepareBirthdayTestEmail, enrichedInput).Get(ctx, &emailContent)
+		if temporal.IsCanceledError(err) {
+			setStep(StepCanceled)
+			return BirthdayTestWorkflowResult{}, err
+		}
 		if err != nil {
 			logger.Error("Failed to prepare birthday test email", "error", err)
 			return BirthdayTestWorkflowResult{
@@ -169,50 +303,91 @@ func BirthdayTestWorkflow(ctx workflow.Context, input BirthdayTestWorkflowInput)
 		}
 
 		// Send birthday email first
+		setStep(StepSendEmail)
 		var sendResult EmailSendResult
-		err = workflow.ExecuteActivity(ctx, SendBirthdayTestEmail, emailContent, input.TenantID, "test_card").Get(ctx, &sendResult)
+		err = executeSendActivity(sendCtx, &sendResult, SendBirthdayTestEmail, emailContent, input.TenantID, cardEmailType(input))
+		if temporal.IsCanceledError(err) {
+			setStep(StepCanceled)
+			return BirthdayTestWorkflowResult{}, err
+		}
 		if err != nil {
-			logger.Error("Failed to send birthday test email", "error", err)
+			failure := recordSendFailure(trackCtx, RecordFailedSendInput{
+				Content:   emailContent,
+				TenantID:  input.TenantID,
+				EmailType: cardEmailType(input),
+				ContactID: cardContactID(input),
+			}, err)
+			logger.Error("Failed to send birthday test email", "error", err, "errorType", failure.Type)
 			return BirthdayTestWorkflowResult{
 				Success:    false,
 				WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
-				Error:      err.Error(),
+				Error:      failure.String(),
+				ErrorType:  failure.Type,
 				SentAt:     time.Now().Format(time.RFC3339),
 			}, nil
 		}
 
 		logger.Info("✅ [SPLIT FLOW] Email 1/2: Birthday card sent successfully (NO promotion included)")
-		logger.Info("⏳ [SPLIT FLOW] Waiting 30 seconds before sending promotional email...")
-
-		// Wait 30 seconds between emails for better deliverability
-		workflow.Sleep(ctx, 30*time.Second)
-
-		logger.Info("📧 [SPLIT FLOW] Email 2/2: Preparing promotional email (promotion content ONLY)")
-		// Prepare and send promotional email separately
-		var promoEmailContent EmailContent
-		err = workflow.ExecuteActivity(ctx, PreparePromotionalEmail, PreparePromotionalEmailInput{
-			ToEmail:          input.UserEmail,
-			FromEmail:        input.FromEmail,
-			Promotion:        promotion,
-			BusinessName:     input.TenantName,
-			UnsubscribeToken: unsubscribeTokenResult.Token,
-		}).Get(ctx, &promoEmailContent)
+
+		// Wait between emails for better deliverability, as long as the tenant's promotion
+		// delay says. Canceling the workflow here stops the promotional email; the birthday card
+		// has already gone out. A cancel_promotion signal stops only the promotional email.
+		setStep(StepPromotionDelay)
+		sendPromotion, err := waitBeforePromotion(ctx, input)
 		if err != nil {
-			logger.Warn("Failed to prepare promotional email (birthday was sent)", "error", err)
-			// Don't fail the workflow - birthday email was sent successfully
-		} else {
-			var promoSendResult EmailSendResult
-			err = workflow.ExecuteActivity(ctx, SendPromotionalEmail, promoEmailContent, input.TenantID, input.PromotionID).Get(ctx, &promoSendResult)
+			logger.Info("🛑 [SPLIT FLOW] Workflow canceled during the delay - promotional email not sent")
+			setStep(StepCanceled)
+			return BirthdayTestWorkflowResult{}, err
+		}
+
+		if sendPromotion {
+			logger.Info("📧 [SPLIT FLOW] Email 2/2: Preparing promotional email (promotion content ONLY)")
+			setStep(StepSendPromotion)
+			// Prepare and send promotional email separately
+			var promoEmailContent EmailContent
+			err = workflow.ExecuteActivity(ctx, PreparePromotionalEmail, PreparePromotionalEmailInput{
+				ToEmail:          input.UserEmail,
+				FromEmail:        input.FromEmail,
+				Promotion:        promotion,
+				BusinessName:     input.TenantName,
+				UnsubscribeToken: unsubscribeTokenResult.Token,
+				Language:         input.Language,
+				TenantID:         input.TenantID,
+				ContactID:        contactIDForToken,
+			}).Get(ctx, &promoEmailContent)
 			if err != nil {
-				logger.Warn("Failed to send promotional email (birthday was sent)", "error", err)
+				logger.Warn("Failed to prepare promotional email (birthday was sent)", "error", err)
 				// Don't fail the workflow - birthday email was sent successfully
 			} else {
-				logger.Info("✅ [SPLIT FLOW] Email 2/2: Promotional email sent successfully", "messageId", promoSendResult.MessageID)
+				var promoSendResult EmailSendResult
+				err = executeSendActivity(sendCtx, &promoSendResult, SendPromotionalEmail, promoEmailContent, input.TenantID, input.PromotionID)
+				if temporal.IsCanceledError(err) {
+					setStep(StepCanceled)
+					return BirthdayTestWorkflowResult{}, err
+				}
+				if err != nil {
+					failure := recordSendFailure(trackCtx, RecordFailedSendInput{
+						Content:     promoEmailContent,
+						TenantID:    input.TenantID,
+						EmailType:   "promotional",
+						ContactID:   cardContactID(input),
+						PromotionID: input.PromotionID,
+					}, err)
+					logger.Warn("Failed to send promotional email (birthday was sent)", "error", err, "errorType", failure.Type)
+					// Don't fail the workflow - birthday email was sent successfully
+				} else if promoSendResult.Skipped {
+					logger.Info("🚫 [SPLIT FLOW] Email 2/2: Promotional email skipped", "reason", promoSendResult.Error)
+				} else {
+					logger.Info("✅ [SPLIT FLOW] Email 2/2: Promotional email sent successfully", "messageId", promoSendResult.MessageID)
+				}
 			}
+		} else {
+			logger.Info("🚫 [SPLIT FLOW] Email 2/2: Promotional email not sent - canceled during the delay")
 		}
 
 		// Update status with birthday email send result
-		err = workflow.ExecuteActivity(ctx, UpdateBirthdayTestStatus, UpdateStatusInput{
+		setStep(StepUpdateStatus)
+		err = workflow.ExecuteActivity(trackCtx, UpdateBirthdayTestStatus, UpdateStatusInput{
 			UserID:    input.UserID,
 			TenantID:  input.TenantID,
 			Success:   sendResult.Success,
@@ -225,13 +400,17 @@ func BirthdayTestWorkflow(ctx workflow.Context, input BirthdayTestWorkflowInput)
 		}
 
 		logger.Info("✅ [SPLIT FLOW] Birthday test workflow completed - TWO separate emails sent", "success", sendResult.Success)
+		setStep(StepCompleted)
 		return BirthdayTestWorkflowResult{
 			Success:    sendResult.Success,
 			WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
 			MessageID:  sendResult.MessageID,
 			Provider:   sendResult.Provider,
 			Error:      sendResult.Error,
+			Skipped:    sendResult.Skipped,
 			SentAt:     time.Now().Format(time.RFC3339),
+
+			PromotionCanceled: !sendPromotion,
 		}, nil
 	}
 
@@ -242,11 +421,16 @@ func BirthdayTestWorkflow(ctx workflow.Context, input BirthdayTestWorkflowInput)
 		logger.Info("Including promotion in birthday test email", "promotionId", promotion.ID, "title", promotion.Title)
 	}
 
+	setStep(StepPrepareEmail)
 	var emailContent EmailContent
 	err = workflow.ExecuteActivity(ctx, PrepareBirthdayTestEmailWithPromotion, PrepareBirthdayTestEmailInput{
 		WorkflowInput: enrichedInput,
 		Promotion:     promotion,
 	}).Get(ctx, &emailContent)
+	if temporal.IsCanceledError(err) {
+		setStep(StepCanceled)
+		return BirthdayTestWorkflowResult{}, err
+	}
 	if err != nil {
 		logger.Error("Failed to prepare birthday test email", "error", err)
 		return BirthdayTestWorkflowResult{
@@ -258,20 +442,33 @@ func BirthdayTestWorkflow(ctx workflow.Context, input BirthdayTestWorkflowInput)
 	}
 
 	// Send birthday test email
+	setStep(StepSendEmail)
 	var sendResult EmailSendResult
-	err = workflow.ExecuteActivity(ctx, SendBirthdayTestEmail, emailContent, input.TenantID, "test_card").Get(ctx, &sendResult)
+	err = executeSendActivity(sendCtx, &sendResult, SendBirthdayTestEmail, emailContent, input.TenantID, cardEmailType(input))
+	if temporal.IsCanceledError(err) {
+		setStep(StepCanceled)
+		return BirthdayTestWorkflowResult{}, err
+	}
 	if err != nil {
-		logger.Error("Failed to send birthday test email", "error", err)
+		failure := recordSendFailure(trackCtx, RecordFailedSendInput{
+			Content:   emailContent,
+			TenantID:  input.TenantID,
+			EmailType: cardEmailType(input),
+			ContactID: cardContactID(input),
+		}, err)
+		logger.Error("Failed to send birthday test email", "error", err, "errorType", failure.Type)
 		return BirthdayTestWorkflowResult{
 			Success:    false,
 			WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
-			Error:      err.Error(),
+			Error:      failure.String(),
+			ErrorType:  failure.Type,
 			SentAt:     time.Now().Format(time.RFC3339),
 		}, nil
 	}
 
 	// Update tracking status
-	err = workflow.ExecuteActivity(ctx, UpdateBirthdayTestStatus, UpdateStatusInput{
+	setStep(StepUpdateStatus)
+	err = workflow.ExecuteActivity(trackCtx, UpdateBirthdayTestStatus, UpdateStatusInput{
 		UserID:    input.UserID,
 		TenantID:  input.TenantID,
 		Success:   sendResult.Success,
@@ -285,12 +482,14 @@ func BirthdayTestWorkflow(ctx workflow.Context, input BirthdayTestWorkflowInput)
 	}
 
 	logger.Info("✅ Birthday test workflow completed", "success", sendResult.Success)
+	setStep(StepCompleted)
 	return BirthdayTestWorkflowResult{
 		Success:    sendResult.Success,
 		WorkflowID: workflow.GetInfo(ctx).WorkflowExecution.ID,
 		MessageID:  sendResult.MessageID,
 		Provider:   sendResult.Provider,
 		Error:      sendResult.Error,
+		Skipped:    sendResult.Skipped,
 		SentAt:     time.Now().Format(time.RFC3339),
 	}, nil
 }
@@ -299,38 +498,53 @@ func BirthdayTestWorkflow(ctx workflow.Context, input BirthdayTestWorkflowInput)
 func BirthdayInvitationWorkflow(ctx workflow.Context, input BirthdayInvitationWorkflowInput) (BirthdayInvitationWorkflowResult, error) {
 	logger := workflow.GetLogger(ctx)
 	logger.Info("🎂 Starting birthday invitation workflow", "contactId", input.ContactID, "email", input.ContactEmail)
+	setStep := trackStep(ctx)
 
-	// Set activity options
-	activityOptions := workflow.ActivityOptions{
-		StartToCloseTimeout: 5 * time.Minute,
-		HeartbeatTimeout:    1 * time.Minute,
-		RetryPolicy: &temporal.RetryPolicy{
-			InitialInterval:    1 * time.Second,
-			MaximumInterval:    30 * time.Second,
-			BackoffCoefficient: 2.0,
-			MaximumAttempts:    3,
-		},
-	}
-	ctx = workflow.WithActivityOptions(ctx, activityOptions)
+	// Set activity options: preparing by default, with their own policies for sends and tracking
+	policies := input.ActivityPolicies.orDefault()
+	ctx = withActivityPolicy(ctx, policies.Prepare)
+	sendCtx := withActivityPolicy(ctx, policies.Send)
+	trackCtx := withActivityPolicy(ctx, policies.Tracking)
 
-	// Step 1: Generate invitation token
+	// Step 1: Generate and store the signed invitation token
+	setStep(StepInvitationToken)
 	var tokenResult TokenResult
 	err := workflow.ExecuteActivity(ctx, GenerateBirthdayInvitationToken, TokenInput{
 		ContactID: input.ContactID,
+		TenantID:  input.TenantID,
 		Action:    "update_birthday",
-		ExpiresIn: "30d",
+		ExpiresIn: fmt.Sprintf("%dd", invitationExpiryDays),
 	}).Get(ctx, &tokenResult)
+	if temporal.IsCanceledError(err) {
+		setStep(StepCanceled)
+		return BirthdayInvitationWorkflowResult{}, err
+	}
 	if err != nil {
 		logger.Error("Failed to generate invitation token", "error", err)
 		return BirthdayInvitationWorkflowResult{
 			ContactID: input.ContactID,
 			Success:   false,
 			Error:     err.Error(),
-			SentAt:    time.Now().Format(time.RFC3339),
+			SentAt:    workflow.Now(ctx).Format(time.RFC3339),
 		}, nil
 	}
 
+	// recordStatus marks the invitation sent or failed; tracking errors don't fail the workflow
+	recordStatus := func(sent bool) {
+		err := workflow.ExecuteActivity(trackCtx, UpdateContactInvitationStatus, UpdateStatusInput{
+			ContactID:      input.ContactID,
+			TenantID:       input.TenantID,
+			InvitationSent: sent,
+			InvitationID:   tokenResult.TokenID,
+			SentAt:         workflow.Now(ctx).Format(time.RFC3339),
+		}).Get(ctx, nil)
+		if err != nil {
+			logger.Warn("Failed to update contact invitation status", "error", err)
+		}
+	}
+
 	// Step 2: Prepare invitation email content
+	setStep(StepPrepareEmail)
 	var emailContent EmailContent
 	err = workflow.ExecuteActivity(ctx, PrepareBirthdayInvitationEmail, PrepareEmailInput{
 		ContactID:        input.ContactID,
@@ -340,60 +554,83 @@ func BirthdayInvitationWorkflow(ctx workflow.Context, input BirthdayInvitationWo
 		TenantName:       input.TenantName,
 		InvitationToken:  tokenResult.Token,
 		BaseURL:          input.BaseURL,
+		Language:         input.Language,
 	}).Get(ctx, &emailContent)
+	if temporal.IsCanceledError(err) {
+		setStep(StepCanceled)
+		return BirthdayInvitationWorkflowResult{}, err
+	}
 	if err != nil {
 		logger.Error("Failed to prepare invitation email", "error", err)
+		if recordsInvitationFailures(ctx) {
+			recordStatus(false)
+		}
 		return BirthdayInvitationWorkflowResult{
-			ContactID: input.ContactID,
-			Success:   false,
-			Error:     err.Error(),
-			SentAt:    time.Now().Format(time.RFC3339),
+			ContactID:    input.ContactID,
+			Success:      false,
+			Error:        err.Error(),
+			SentAt:       workflow.Now(ctx).Format(time.RFC3339),
+			InvitationID: tokenResult.TokenID,
 		}, nil
 	}
 
 	// Step 3: Send invitation email
+	setStep(StepSendEmail)
 	var sendResult EmailSendResult
-	err = workflow.ExecuteActivity(ctx, SendBirthdayInvitationEmail, SendEmailInput{
-		To:              input.ContactEmail,
-		From:            input.FromEmail,
-		Subject:         emailContent.Subject,
-		HTMLContent:     emailContent.HTMLContent,
-		TextContent:     emailContent.TextContent,
-		TenantID:        input.TenantID,
-		ContactID:       input.ContactID,
-		InvitationToken: tokenResult.Token,
-	}).Get(ctx, &sendResult)
+	err = executeSendActivity(sendCtx, &sendResult, SendBirthdayInvitationEmail, SendEmailInput{
+		To:           input.ContactEmail,
+		From:         input.FromEmail,
+		Subject:      emailContent.Subject,
+		HTMLContent:  emailContent.HTMLContent,
+		TextContent:  emailContent.TextContent,
+		TenantID:     input.TenantID,
+		ContactID:    input.ContactID,
+		InvitationID: tokenResult.TokenID,
+	})
+	if temporal.IsCanceledError(err) {
+		setStep(StepCanceled)
+		return BirthdayInvitationWorkflowResult{}, err
+	}
 	if err != nil {
-		logger.Error("Failed to send invitation email", "error", err)
-		return BirthdayInvitationWorkflowResult{
+		failure := recordSendFailure(trackCtx, RecordFailedSendInput{
+			Content: EmailContent{
+				Subject:     emailContent.Subject,
+				HTMLContent: emailContent.HTMLContent,
+				TextContent: emailContent.TextContent,
+				To:          input.ContactEmail,
+				From:        input.FromEmail,
+			},
+			TenantID:  input.TenantID,
+			EmailType: "invitation",
 			ContactID: input.ContactID,
-			Success:   false,
-			Error:     err.Error(),
-			SentAt:    time.Now().Format(time.RFC3339),
+		}, err)
+		logger.Error("Failed to send invitation email", "error", err, "errorType", failure.Type)
+		if recordsInvitationFailures(ctx) {
+			recordStatus(false)
+		}
+		return BirthdayInvitationWorkflowResult{
+			ContactID:    input.ContactID,
+			Success:      false,
+			Error:        failure.String(),
+			ErrorType:    failure.Type,
+			SentAt:       workflow.Now(ctx).Format(time.RFC3339),
+			InvitationID: tokenResult.TokenID,
 		}, nil
 	}
 
-	// Step 4: Update contact invitation status
-	err = workflow.ExecuteActivity(ctx, UpdateContactInvitationStatus, UpdateStatusInput{
-		ContactID:       input.ContactID,
-		TenantID:        input.TenantID,
-		InvitationSent:  true,
-		InvitationToken: tokenResult.Token,
-		SentAt:          time.Now().Format(time.RFC3339),
-	}).Get(ctx, nil)
-	if err != nil {
-		logger.Warn("Failed to update contact invitation status", "error", err)
-		// Don't fail the workflow for tracking errors
-	}
+	// Step 4: Record the invitation as sent
+	setStep(StepUpdateStatus)
+	recordStatus(sendResult.Success)
 
 	logger.Info("✅ Birthday invitation workflow completed", "success", sendResult.Success)
+	setStep(StepCompleted)
 	return BirthdayInvitationWorkflowResult{
-		ContactID:       input.ContactID,
-		Success:         sendResult.Success,
-		MessageID:       sendResult.MessageID,
-		Provider:        sendResult.Provider,
-		Error:           sendResult.Error,
-		SentAt:          time.Now().Format(time.RFC3339),
-		InvitationToken: tokenResult.Token,
+		ContactID:    input.ContactID,
+		Success:      sendResult.Success,
+		MessageID:    sendResult.MessageID,
+		Provider:     sendResult.Provider,
+		Error:        sendResult.Error,
+		SentAt:       workflow.Now(ctx).Format(time.RFC3339),
+		InvitationID: tokenResult.TokenID,
 	}, nil
 }
@@ -0,0 +1,95 @@
+package tokens
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned when verifying a token
+var (
+	ErrMalformed = errors.New("malformed token")
+	ErrSignature = errors.New("invalid token signature")
+	ErrExpired   = errors.New("token expired")
+)
+
+// invitationPurpose is mixed into the signature so an invitation token can't be replayed
+// as any other kind of signed token
+const invitationPurpose = "birthday_invitation"
+
+// nonceBytes is the amount of randomness in each token
+const nonceBytes = 16
+
+// Invitation is the verified content of a birthday invitation token
+type Invitation struct {
+	Nonce     string
+	ExpiresAt time.Time
+}
+
+// NewInvitation issues a birthday invitation token of the form
+// "<nonce>.<expiry>.<signature>": a random nonce, the expiry as base-36 Unix seconds and an
+// HMAC-SHA256 over both. The token is unguessable on its own; the database keeps only its
+// hash (see Hash) to make it single-use.
+func NewInvitation(secret string, expiresAt time.Time) (string, error) {
+	if secret == "" {
+		return "", errors.New("token secret is not configured")
+	}
+
+	nonce := make([]byte, nonceBytes)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate token nonce: %w", err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(nonce) + "." + strconv.FormatInt(expiresAt.Unix(), 36)
+	return payload + "." + sign(secret, invitationPurpose, payload), nil
+}
+
+// VerifyInvitation checks an invitation token's signature and expiry. It doesn't tell whether
+// the token has been issued or used; look its Hash up for that.
+func VerifyInvitation(secret, token string, now time.Time) (*Invitation, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	nonce, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(nonce) != nonceBytes {
+		return nil, ErrMalformed
+	}
+	expiry, err := strconv.ParseInt(parts[1], 36, 64)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if secret == "" || !hmac.Equal([]byte(parts[2]), []byte(sign(secret, invitationPurpose, payload))) {
+		return nil, ErrSignature
+	}
+
+	expiresAt := time.Unix(expiry, 0)
+	if !now.Before(expiresAt) {
+		return nil, ErrExpired
+	}
+
+	return &Invitation{Nonce: parts[0], ExpiresAt: expiresAt}, nil
+}
+
+// Hash returns the hex SHA-256 of a token, which is what gets stored in the database
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// sign returns the base64url HMAC-SHA256 of purpose and payload
+func sign(secret, purpose, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose + ":" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
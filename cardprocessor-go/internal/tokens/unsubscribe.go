@@ -0,0 +1,152 @@
+package tokens
+
+import (
+	"crypto/hmac"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unsubscribe scopes: what a signed unsubscribe token opts the contact out of
+const (
+	ScopeBirthday   = "birthday"
+	ScopePromotions = "promotions"
+	ScopeAll        = "all"
+)
+
+// unsubscribePurpose is mixed into the signature so an unsubscribe token can't be replayed
+// as any other kind of signed token
+const unsubscribePurpose = "unsubscribe"
+
+// unsubscribeVersion prefixes signed unsubscribe tokens. Legacy tokens are 64 hex characters,
+// so the prefix tells the two formats apart.
+const unsubscribeVersion = "u1"
+
+// Unsubscribe is the verified content of a signed unsubscribe token. An empty ContactID
+// means the token was issued for a test send.
+type Unsubscribe struct {
+	KeyID     string
+	TenantID  string
+	ContactID string
+	Scope     string
+	IssuedAt  time.Time
+}
+
+// Keyring holds the HMAC keys for unsubscribe tokens. The first key signs new tokens and
+// every key verifies, so a key can be rotated out once mail signed with it has aged out.
+type Keyring struct {
+	signingKeyID string
+	keys         map[string][]byte
+}
+
+// ParseKeyring reads a keyring from a comma-separated list of "<id>:<secret>" pairs, e.g.
+// "2026b:new-secret,2026a:old-secret". At least one key is required.
+func ParseKeyring(spec string) (*Keyring, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New("unsubscribe token keys are not configured")
+	}
+
+	keyring := &Keyring{keys: make(map[string][]byte)}
+	for _, entry := range strings.Split(spec, ",") {
+		id, secret, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || !validKeyID(id) || secret == "" {
+			return nil, fmt.Errorf("invalid unsubscribe token key %q, expected <id>:<secret>", id)
+		}
+		if _, exists := keyring.keys[id]; exists {
+			return nil, fmt.Errorf("duplicate unsubscribe token key %q", id)
+		}
+		if keyring.signingKeyID == "" {
+			keyring.signingKeyID = id
+		}
+		keyring.keys[id] = []byte(secret)
+	}
+	return keyring, nil
+}
+
+// NewUnsubscribe issues a signed unsubscribe token of the form
+// "u1.<key id>.<claims>.<signature>", where claims is the base64url tenant, contact, scope and
+// base-36 issue time. It is verified without a database lookup (see VerifyUnsubscribe).
+func (k *Keyring) NewUnsubscribe(tenantID, contactID, scope string, issuedAt time.Time) (string, error) {
+	if !ValidScope(scope) {
+		return "", fmt.Errorf("invalid unsubscribe scope %q", scope)
+	}
+	if tenantID == "" {
+		return "", errors.New("unsubscribe token needs a tenant")
+	}
+
+	claims := strings.Join([]string{tenantID, contactID, scope, strconv.FormatInt(issuedAt.Unix(), 36)}, "\n")
+	payload := unsubscribeVersion + "." + k.signingKeyID + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	return payload + "." + sign(string(k.keys[k.signingKeyID]), unsubscribePurpose, payload), nil
+}
+
+// VerifyUnsubscribe checks a signed unsubscribe token's signature and age. A maxAge of zero
+// accepts tokens of any age.
+func (k *Keyring) VerifyUnsubscribe(token string, maxAge time.Duration, now time.Time) (*Unsubscribe, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || parts[0] != unsubscribeVersion {
+		return nil, ErrMalformed
+	}
+
+	secret, ok := k.keys[parts[1]]
+	if !ok {
+		return nil, ErrSignature
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(sign(string(secret), unsubscribePurpose, payload))) {
+		return nil, ErrSignature
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	claims := strings.Split(string(raw), "\n")
+	if len(claims) != 4 || claims[0] == "" || !ValidScope(claims[2]) {
+		return nil, ErrMalformed
+	}
+	issued, err := strconv.ParseInt(claims[3], 36, 64)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	issuedAt := time.Unix(issued, 0)
+	if maxAge > 0 && now.Sub(issuedAt) > maxAge {
+		return nil, ErrExpired
+	}
+
+	return &Unsubscribe{
+		KeyID:     parts[1],
+		TenantID:  claims[0],
+		ContactID: claims[1],
+		Scope:     claims[2],
+		IssuedAt:  issuedAt,
+	}, nil
+}
+
+// IsUnsubscribe reports whether token is in the signed unsubscribe format rather than a
+// legacy database token
+func IsUnsubscribe(token string) bool {
+	return strings.HasPrefix(token, unsubscribeVersion+".")
+}
+
+// ValidScope reports whether scope is one of the unsubscribe scopes
+func ValidScope(scope string) bool {
+	return scope == ScopeBirthday || scope == ScopePromotions || scope == ScopeAll
+}
+
+// validKeyID accepts key IDs that can't collide with the token's separators
+func validKeyID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
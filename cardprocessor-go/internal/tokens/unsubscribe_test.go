@@ -0,0 +1,186 @@
+package tokens
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var issuedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func mustKeyring(t *testing.T, spec string) *Keyring {
+	t.Helper()
+	keyring, err := ParseKeyring(spec)
+	if err != nil {
+		t.Fatalf("ParseKeyring(%q): %v", spec, err)
+	}
+	return keyring
+}
+
+func mustToken(t *testing.T, keyring *Keyring, scope string) string {
+	t.Helper()
+	token, err := keyring.NewUnsubscribe("tenant-1", "contact-1", scope, issuedAt)
+	if err != nil {
+		t.Fatalf("NewUnsubscribe: %v", err)
+	}
+	return token
+}
+
+// signedClaims signs raw claims with a key, for tokens NewUnsubscribe refuses to issue
+func signedClaims(keyID, secret, claims string) string {
+	payload := unsubscribeVersion + "." + keyID + "." + base64.RawURLEncoding.EncodeToString([]byte(claims))
+	return payload + "." + sign(secret, unsubscribePurpose, payload)
+}
+
+func TestParseKeyring(t *testing.T) {
+	tests := []struct {
+		spec        string
+		wantErr     bool
+		wantSigning string
+	}{
+		{"", true, ""},
+		{"  ", true, ""},
+		{"2026a:secret", false, "2026a"},
+		{" 2026b:new , 2026a:old ", false, "2026b"},
+		{"secret", true, ""},
+		{"2026a:", true, ""},
+		{":secret", true, ""},
+		{"key.1:secret", true, ""},
+		{"2026a:one,2026a:two", true, ""},
+	}
+	for _, tt := range tests {
+		keyring, err := ParseKeyring(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseKeyring(%q) error = %v, want error %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if err == nil && keyring.signingKeyID != tt.wantSigning {
+			t.Errorf("ParseKeyring(%q) signs with %q, want %q", tt.spec, keyring.signingKeyID, tt.wantSigning)
+		}
+	}
+}
+
+func TestVerifyUnsubscribe(t *testing.T) {
+	keyring := mustKeyring(t, "2026a:secret-a")
+	token := mustToken(t, keyring, ScopePromotions)
+
+	got, err := keyring.VerifyUnsubscribe(token, 24*time.Hour, issuedAt.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("VerifyUnsubscribe: %v", err)
+	}
+	want := Unsubscribe{KeyID: "2026a", TenantID: "tenant-1", ContactID: "contact-1", Scope: ScopePromotions, IssuedAt: issuedAt}
+	if !got.IssuedAt.Equal(want.IssuedAt) {
+		t.Errorf("IssuedAt = %v, want %v", got.IssuedAt, want.IssuedAt)
+	}
+	got.IssuedAt = want.IssuedAt
+	if *got != want {
+		t.Errorf("VerifyUnsubscribe = %+v, want %+v", *got, want)
+	}
+	if !IsUnsubscribe(token) {
+		t.Errorf("IsUnsubscribe(%q) = false", token)
+	}
+}
+
+func TestVerifyUnsubscribeRejects(t *testing.T) {
+	keyring := mustKeyring(t, "2026a:secret-a")
+	token := mustToken(t, keyring, ScopeBirthday)
+	parts := strings.Split(token, ".")
+
+	// Flip one character of the claims, keeping the original signature
+	claims := []byte(parts[2])
+	if claims[0] == 'A' {
+		claims[0] = 'B'
+	} else {
+		claims[0] = 'A'
+	}
+	tampered := strings.Join([]string{parts[0], parts[1], string(claims), parts[3]}, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"tampered claims", tampered, ErrSignature},
+		{"tampered signature", token[:len(token)-1] + "x", ErrSignature},
+		{"other key", mustToken(t, mustKeyring(t, "2026a:other-secret"), ScopeBirthday), ErrSignature},
+		{"unknown key ID", signedClaims("2025z", "secret-a", "tenant-1\ncontact-1\nbirthday\n0"), ErrSignature},
+		{"wrong scope", signedClaims("2026a", "secret-a", "tenant-1\ncontact-1\nnewsletter\n0"), ErrMalformed},
+		{"no tenant", signedClaims("2026a", "secret-a", "\ncontact-1\nbirthday\n0"), ErrMalformed},
+		{"bad issue time", signedClaims("2026a", "secret-a", "tenant-1\ncontact-1\nbirthday\n!"), ErrMalformed},
+		{"legacy token", strings.Repeat("ab", 32), ErrMalformed},
+		{"wrong version", "u2" + strings.TrimPrefix(token, unsubscribeVersion), ErrMalformed},
+		{"missing signature", strings.Join(parts[:3], "."), ErrMalformed},
+		{"empty", "", ErrMalformed},
+	}
+	for _, tt := range tests {
+		if _, err := keyring.VerifyUnsubscribe(tt.token, 0, issuedAt); !errors.Is(err, tt.want) {
+			t.Errorf("%s: VerifyUnsubscribe error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyUnsubscribeExpiry(t *testing.T) {
+	keyring := mustKeyring(t, "2026a:secret-a")
+	token := mustToken(t, keyring, ScopeAll)
+	maxAge := 365 * 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		now    time.Time
+		want   error
+	}{
+		{"fresh", maxAge, issuedAt.Add(time.Minute), nil},
+		{"at max age", maxAge, issuedAt.Add(maxAge), nil},
+		{"expired", maxAge, issuedAt.Add(maxAge + time.Second), ErrExpired},
+		{"no max age", 0, issuedAt.Add(10 * maxAge), nil},
+	}
+	for _, tt := range tests {
+		if _, err := keyring.VerifyUnsubscribe(token, tt.maxAge, tt.now); !errors.Is(err, tt.want) {
+			t.Errorf("%s: VerifyUnsubscribe error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestVerifyUnsubscribeAfterRotation(t *testing.T) {
+	oldKeyring := mustKeyring(t, "2026a:secret-a")
+	oldToken := mustToken(t, oldKeyring, ScopeBirthday)
+
+	// The new key signs, the old one still verifies
+	rotated := mustKeyring(t, "2026b:secret-b,2026a:secret-a")
+	newToken := mustToken(t, rotated, ScopeBirthday)
+	if !strings.HasPrefix(newToken, unsubscribeVersion+".2026b.") {
+		t.Errorf("rotated keyring signed %q, want key 2026b", newToken)
+	}
+	for _, token := range []string{oldToken, newToken} {
+		if _, err := rotated.VerifyUnsubscribe(token, 0, issuedAt); err != nil {
+			t.Errorf("rotated keyring rejected %q: %v", token, err)
+		}
+	}
+
+	// Once the old key is retired its tokens no longer verify
+	retired := mustKeyring(t, "2026b:secret-b")
+	if _, err := retired.VerifyUnsubscribe(oldToken, 0, issuedAt); !errors.Is(err, ErrSignature) {
+		t.Errorf("retired key: VerifyUnsubscribe error = %v, want %v", err, ErrSignature)
+	}
+	if _, err := retired.VerifyUnsubscribe(newToken, 0, issuedAt); err != nil {
+		t.Errorf("retired keyring rejected %q: %v", newToken, err)
+	}
+
+	// The old keyring doesn't know the new key
+	if _, err := oldKeyring.VerifyUnsubscribe(newToken, 0, issuedAt); !errors.Is(err, ErrSignature) {
+		t.Errorf("old keyring: VerifyUnsubscribe error = %v, want %v", err, ErrSignature)
+	}
+}
+
+func TestNewUnsubscribeRejectsInvalidInput(t *testing.T) {
+	keyring := mustKeyring(t, "2026a:secret-a")
+	if _, err := keyring.NewUnsubscribe("tenant-1", "contact-1", "newsletter", issuedAt); err == nil {
+		t.Error("NewUnsubscribe accepted an unknown scope")
+	}
+	if _, err := keyring.NewUnsubscribe("", "contact-1", ScopeBirthday, issuedAt); err == nil {
+		t.Error("NewUnsubscribe accepted an empty tenant")
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"strings"
 	"syscall"
 
+	"cardprocessor-go/internal/assets"
 	"cardprocessor-go/internal/config"
 	"cardprocessor-go/internal/database"
+	"cardprocessor-go/internal/i18n"
 	"cardprocessor-go/internal/repository"
 	"cardprocessor-go/internal/router"
 	"cardprocessor-go/internal/temporal"
@@ -26,6 +28,42 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Unsubscribe links are signed with their own keys, parsed once here
+	if err := cfg.LoadUnsubscribeKeyring(); err != nil {
+		log.Fatalf("Invalid UNSUBSCRIBE_TOKEN_KEYS: %v", err)
+	}
+	if cfg.UnsubscribeKeyring == nil {
+		log.Printf("⚠️  WARNING: UNSUBSCRIBE_TOKEN_KEYS is not set - birthday unsubscribe links fall back to stored legacy tokens, and promotion and signed links can't be issued or verified until keys are configured")
+	}
+
+	if cfg.InvitationTokenSecret == "" {
+		log.Println("⚠️ INVITATION_TOKEN_SECRET is not set: birthday invitations will fail until it is")
+	}
+
+	// Load locale overrides and report incomplete translations
+	if cfg.LocalesDir != "" {
+		if err := i18n.LoadCatalog(cfg.LocalesDir); err != nil {
+			log.Printf("⚠️ Failed to load locales from %s: %v", cfg.LocalesDir, err)
+			log.Println("Continuing with embedded locales...")
+		}
+	}
+	for _, locale := range i18n.CheckCompleteness() {
+		if locale.Complete() && len(locale.Unknown) == 0 {
+			continue
+		}
+		log.Printf("⚠️ Locale %s has translation issues", locale.Language)
+		if len(locale.Missing) > 0 {
+			log.Printf("   └─ Missing keys: %s", strings.Join(locale.Missing, ", "))
+		}
+		for key, forms := range locale.MissingForms {
+			log.Printf("   └─ Missing plural forms for %s: %s", key, strings.Join(forms, ", "))
+		}
+		if len(locale.Unknown) > 0 {
+			log.Printf("   └─ Unknown keys: %s", strings.Join(locale.Unknown, ", "))
+		}
+	}
+	log.Printf("✅ Locales loaded: %s", strings.Join(i18n.Languages(), ", "))
+
 	// Initialize database
 	db, err := database.NewConnection(cfg)
 	if err != nil {
@@ -36,6 +74,22 @@ func main() {
 	// Initialize repository
 	repo := repository.NewRepository(db)
 
+	// Initialize image asset storage
+	assetService, err := assets.NewService(cfg, repo)
+	if err != nil {
+		log.Printf("⚠️ Failed to initialize asset storage: %v", err)
+		log.Println("Continuing without self-hosted images...")
+		assetService = nil
+	} else {
+		log.Printf("✅ Asset storage initialized (%s)", cfg.AssetStorage)
+		if cfg.AssetProxyEnabled {
+			temporal.SetImageProxy(assetService)
+		}
+		if cfg.CardImageEnabled {
+			temporal.SetCardImageRenderer(assetService)
+		}
+	}
+
 	// Initialize Temporal worker if enabled
 	var temporalClient *temporal.TemporalClient
 	if cfg.TemporalWorkerEnabled {
@@ -55,13 +109,27 @@ func main() {
 				}
 			}()
 			log.Println("✅ Temporal worker started")
+
+			// Bring the retention purge schedule in line with RETENTION_PURGE_CRON
+			if err := temporalClient.SyncRetentionPurgeSchedule(context.Background()); err != nil {
+				log.Printf("⚠️ Failed to sync retention purge schedule: %v", err)
+			} else if cfg.RetentionPurgeCron == "" {
+				log.Println("ℹ️ Retention purge is disabled")
+			}
+
+			// Bring the tenants' birthday schedules in line with their settings
+			if schedules, err := repo.GetBirthdaySchedules(context.Background()); err != nil {
+				log.Printf("⚠️ Failed to load birthday schedules: %v", err)
+			} else if err := temporalClient.ReconcileBirthdaySchedules(context.Background(), schedules); err != nil {
+				log.Printf("⚠️ Failed to reconcile birthday schedules: %v", err)
+			}
 		}
 	} else {
 		log.Println("ℹ️ Temporal worker is disabled")
 	}
 
 	// Initialize and start server
-	apiRouter := router.SetupRouter(cfg, repo, temporalClient)
+	apiRouter := router.SetupRouter(cfg, repo, temporalClient, assetService)
 
 	// Initialize and start separate webhook server on its own port
 	webhookRouter := router.SetupWebhookRouter(cfg, repo)
@@ -0,0 +1,37 @@
+-- Migration: Add image asset tables for self-hosted card images
+-- Uploaded images and proxied copies of external images are resized to email-safe
+-- widths and stored under content-hash keys by the card processor
+
+CREATE TABLE IF NOT EXISTS "image_assets" (
+  "id" varchar PRIMARY KEY DEFAULT gen_random_uuid(),
+  "tenant_id" varchar REFERENCES "tenants"("id") ON DELETE CASCADE,
+  "source_url" text,
+  "original_filename" text,
+  "original_content_type" text NOT NULL,
+  "original_width" integer NOT NULL,
+  "original_height" integer NOT NULL,
+  "created_at" timestamp DEFAULT now()
+);
+
+COMMENT ON COLUMN image_assets.tenant_id IS 'Owning tenant for uploads; NULL for proxied copies of external images shared across tenants';
+COMMENT ON COLUMN image_assets.source_url IS 'External URL this asset was proxied from; NULL for direct uploads';
+
+CREATE INDEX IF NOT EXISTS "image_assets_tenant_id_idx" ON "image_assets"("tenant_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "image_assets_source_url_idx" ON "image_assets"("source_url") WHERE "source_url" IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS "image_asset_variants" (
+  "id" varchar PRIMARY KEY DEFAULT gen_random_uuid(),
+  "asset_id" varchar NOT NULL REFERENCES "image_assets"("id") ON DELETE CASCADE,
+  "width" integer NOT NULL,
+  "height" integer NOT NULL,
+  "content_type" text NOT NULL,
+  "content_hash" varchar(64) NOT NULL,
+  "storage_key" text NOT NULL,
+  "size_bytes" integer NOT NULL,
+  "created_at" timestamp DEFAULT now()
+);
+
+COMMENT ON COLUMN image_asset_variants.content_hash IS 'SHA-256 of the encoded variant; also used as the public file name';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "image_asset_variants_asset_width_idx" ON "image_asset_variants"("asset_id", "width");
+CREATE INDEX IF NOT EXISTS "image_asset_variants_content_hash_idx" ON "image_asset_variants"("content_hash");
@@ -0,0 +1,7 @@
+-- Migration: Add personalized_card_image column to birthday_settings table
+-- This enables a server-rendered card image with the contact's name and age in place of the template header
+
+ALTER TABLE birthday_settings 
+ADD COLUMN IF NOT EXISTS personalized_card_image BOOLEAN DEFAULT false;
+
+COMMENT ON COLUMN birthday_settings.personalized_card_image IS 'When enabled, birthday cards show a rendered image with the contact''s name and age composited over the template background and tenant logo';
@@ -0,0 +1,19 @@
+-- Migration: Add subject line templates, preheader text and A/B subject variants
+-- Birthday emails can use a tenant-defined subject (with merge tags) and preheader,
+-- optionally split across weighted variants whose results are tracked per send
+
+ALTER TABLE birthday_settings 
+ADD COLUMN IF NOT EXISTS subject_template TEXT,
+ADD COLUMN IF NOT EXISTS preheader_text TEXT,
+ADD COLUMN IF NOT EXISTS subject_variants TEXT;
+
+COMMENT ON COLUMN birthday_settings.subject_template IS 'Subject line for birthday emails; supports merge tags such as {{firstName}} and {{companyName}}';
+COMMENT ON COLUMN birthday_settings.preheader_text IS 'Inbox preview text shown after the subject line';
+COMMENT ON COLUMN birthday_settings.subject_variants IS 'JSON array of A/B subject variants: [{"id","subject","preheader","weight"}]';
+
+ALTER TABLE email_sends 
+ADD COLUMN IF NOT EXISTS subject_variant TEXT;
+
+COMMENT ON COLUMN email_sends.subject_variant IS 'ID of the A/B subject variant used for this send, if any';
+
+CREATE INDEX IF NOT EXISTS idx_email_sends_subject_variant ON email_sends(tenant_id, email_type, subject_variant) WHERE subject_variant IS NOT NULL;
@@ -0,0 +1,15 @@
+-- Migration: Add preferred contact language and localized birthday messages
+-- Birthday cards are rendered in the contact's preferred language, falling back to the
+-- tenant's default language and then English
+
+ALTER TABLE email_contacts 
+ADD COLUMN IF NOT EXISTS preferred_language TEXT;
+
+COMMENT ON COLUMN email_contacts.preferred_language IS 'Preferred language for emails (ISO 639-1 code, e.g. en, es, fr, de)';
+
+ALTER TABLE birthday_settings 
+ADD COLUMN IF NOT EXISTS default_language TEXT NOT NULL DEFAULT 'en',
+ADD COLUMN IF NOT EXISTS localized_messages TEXT;
+
+COMMENT ON COLUMN birthday_settings.default_language IS 'Language used for contacts without a supported preferred language';
+COMMENT ON COLUMN birthday_settings.localized_messages IS 'JSON object of per-language custom messages: {"es": "...", "fr": "..."}';
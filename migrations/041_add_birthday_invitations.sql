@@ -0,0 +1,23 @@
+-- Migration: Add birthday invitations
+-- Each invitation email carries a signed, expiring token; only its SHA-256 is stored here and
+-- it can be used once. The row also tracks the invitation's progress for the contact
+-- (pending → sent → opened → completed).
+
+CREATE TABLE IF NOT EXISTS "birthday_invitations" (
+  "id" varchar PRIMARY KEY DEFAULT gen_random_uuid(),
+  "tenant_id" varchar NOT NULL REFERENCES "tenants"("id") ON DELETE CASCADE,
+  "contact_id" varchar NOT NULL REFERENCES "email_contacts"("id") ON DELETE CASCADE,
+  "token_hash" varchar(64) NOT NULL UNIQUE,
+  "status" text NOT NULL DEFAULT 'pending',
+  "expires_at" timestamp NOT NULL,
+  "sent_at" timestamp,
+  "opened_at" timestamp,
+  "completed_at" timestamp,
+  "created_at" timestamp DEFAULT now()
+);
+
+COMMENT ON COLUMN birthday_invitations.token_hash IS 'Hex SHA-256 of the invitation token; the token itself is never stored';
+COMMENT ON COLUMN birthday_invitations.status IS 'pending, sent, failed, opened or completed';
+COMMENT ON COLUMN birthday_invitations.completed_at IS 'When the contact submitted their birthday; the token cannot be used again';
+
+CREATE INDEX IF NOT EXISTS "birthday_invitations_contact_idx" ON "birthday_invitations"("tenant_id", "contact_id", "created_at" DESC);
@@ -0,0 +1,33 @@
+-- Migration: Add subscription topics
+-- Contacts can opt out of each kind of email separately instead of all-or-nothing. Birthday
+-- cards keep using email_contacts.birthday_email_enabled; the other topics (birthday_promotion,
+-- anniversaries, invitations) are stored here, and a missing row means subscribed.
+-- Every change to any topic is appended to contact_subscription_events.
+
+CREATE TABLE IF NOT EXISTS "contact_subscriptions" (
+  "id" varchar PRIMARY KEY DEFAULT gen_random_uuid(),
+  "tenant_id" varchar NOT NULL REFERENCES "tenants"("id") ON DELETE CASCADE,
+  "contact_id" varchar NOT NULL REFERENCES "email_contacts"("id") ON DELETE CASCADE,
+  "topic" text NOT NULL,
+  "subscribed" boolean NOT NULL DEFAULT true,
+  "updated_at" timestamp DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "contact_subscriptions_contact_topic_idx" ON "contact_subscriptions"("contact_id", "topic");
+
+COMMENT ON COLUMN contact_subscriptions.topic IS 'birthday_promotion, anniversaries or invitations; birthday_card lives in email_contacts.birthday_email_enabled';
+
+CREATE TABLE IF NOT EXISTS "contact_subscription_events" (
+  "id" varchar PRIMARY KEY DEFAULT gen_random_uuid(),
+  "tenant_id" varchar NOT NULL REFERENCES "tenants"("id") ON DELETE CASCADE,
+  "contact_id" varchar NOT NULL REFERENCES "email_contacts"("id") ON DELETE CASCADE,
+  "topic" text NOT NULL,
+  "subscribed" boolean NOT NULL,
+  "source" text NOT NULL,
+  "reason" text,
+  "created_at" timestamp DEFAULT now()
+);
+
+COMMENT ON COLUMN contact_subscription_events.source IS 'Where the change came from: unsubscribe_page, one_click, mailto or api';
+
+CREATE INDEX IF NOT EXISTS "contact_subscription_events_contact_idx" ON "contact_subscription_events"("tenant_id", "contact_id", "created_at" DESC);
@@ -0,0 +1,50 @@
+-- Migration: Add consent audit log
+-- email_contacts only holds the current consent state, so every change to it is also appended
+-- to consent_events with the evidence for it: where it came from, who made it, the request's
+-- IP address and user agent and, for opt-ins, the consent wording shown. The log is the record
+-- for GDPR/CASL audits, so it has no foreign keys (it must outlive the contact row) and rows
+-- can't be changed or deleted unless the session sets app.consent_events_erasure = 'on'.
+
+CREATE TABLE IF NOT EXISTS "consent_events" (
+  "id" varchar PRIMARY KEY DEFAULT gen_random_uuid(),
+  "tenant_id" varchar NOT NULL,
+  "contact_id" varchar NOT NULL,
+  "email" text NOT NULL,
+  "topic" text NOT NULL,
+  "action" text NOT NULL,
+  "source" text NOT NULL,
+  "reason" text,
+  "consent_text" text,
+  "ip_address" text,
+  "user_agent" text,
+  "actor_user_id" varchar,
+  "occurred_at" timestamp NOT NULL DEFAULT now()
+);
+
+ALTER TABLE consent_events
+ADD CONSTRAINT check_consent_events_action
+CHECK (action IN ('granted', 'withdrawn'));
+
+COMMENT ON TABLE consent_events IS 'Append-only consent audit log; rows outlive the contact';
+COMMENT ON COLUMN consent_events.email IS 'The contact''s address when the change was made';
+COMMENT ON COLUMN consent_events.topic IS 'A subscription topic (birthday_card, birthday_promotion, anniversaries, invitations) or marketing';
+COMMENT ON COLUMN consent_events.source IS 'Where the change came from: unsubscribe_page, one_click, mailto, preference_center, birthday_invitation, api, ...';
+COMMENT ON COLUMN consent_events.actor_user_id IS 'The staff user who made the change; NULL when the contact made it';
+
+CREATE INDEX IF NOT EXISTS "consent_events_contact_idx" ON "consent_events"("tenant_id", "contact_id", "occurred_at");
+
+-- Refuse updates and deletes so the log can't be rewritten after the fact
+CREATE OR REPLACE FUNCTION prevent_consent_events_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF current_setting('app.consent_events_erasure', true) = 'on' THEN
+        RETURN COALESCE(NEW, OLD);
+    END IF;
+    RAISE EXCEPTION 'consent_events is append-only';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER prevent_consent_events_change
+    BEFORE UPDATE OR DELETE ON consent_events
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_consent_events_change();
@@ -0,0 +1,27 @@
+-- Migration: Add contact erasures
+-- A GDPR erasure deletes the contact and their email content and pseudonymises the sends and
+-- events kept for aggregate reporting. contact_erasures records each erasure with the counts
+-- it touched and a SHA-256 hash of the lowercased address, which the send activities check so
+-- the address is never mailed again, without keeping the address itself.
+
+CREATE TABLE IF NOT EXISTS "contact_erasures" (
+  "id" varchar PRIMARY KEY DEFAULT gen_random_uuid(),
+  "tenant_id" varchar NOT NULL REFERENCES "tenants"("id") ON DELETE CASCADE,
+  "contact_id" varchar NOT NULL,
+  "email_hash" varchar(64) NOT NULL,
+  "reason" text,
+  "requested_by_user_id" varchar,
+  "emails_sent" integer NOT NULL DEFAULT 0,
+  "emails_opened" integer NOT NULL DEFAULT 0,
+  "email_sends_pseudonymised" integer NOT NULL DEFAULT 0,
+  "email_contents_deleted" integer NOT NULL DEFAULT 0,
+  "email_events_scrubbed" integer NOT NULL DEFAULT 0,
+  "activities_deleted" integer NOT NULL DEFAULT 0,
+  "unsubscribe_tokens_deleted" integer NOT NULL DEFAULT 0,
+  "erased_at" timestamp NOT NULL DEFAULT now()
+);
+
+COMMENT ON COLUMN contact_erasures.contact_id IS 'ID of the deleted contact; consent_events rows keep it';
+COMMENT ON COLUMN contact_erasures.email_hash IS 'Hex SHA-256 of the trimmed, lowercased address; suppresses future sends';
+
+CREATE INDEX IF NOT EXISTS "contact_erasures_email_hash_idx" ON "contact_erasures"("tenant_id", "email_hash");
@@ -0,0 +1,48 @@
+-- Migration: Add email retention
+-- Card content and raw webhook payloads were kept forever. A scheduled purge workflow now
+-- applies each tenant's retention settings (or the RETENTION_* defaults): email content older
+-- than content_days is deleted or truncated, email_activity.webhook_data older than
+-- webhook_data_days is cleared, and email_events older than event_days are compacted into
+-- email_event_daily_stats. A NULL setting falls back to the default; 0 keeps data forever.
+
+CREATE TABLE IF NOT EXISTS "email_retention_settings" (
+  "tenant_id" varchar PRIMARY KEY REFERENCES "tenants"("id") ON DELETE CASCADE,
+  "content_days" integer,
+  "content_action" text NOT NULL DEFAULT 'delete',
+  "webhook_data_days" integer,
+  "event_days" integer,
+  "created_at" timestamp DEFAULT now(),
+  "updated_at" timestamp DEFAULT now()
+);
+
+ALTER TABLE email_retention_settings
+ADD CONSTRAINT check_email_retention_settings_content_action
+CHECK (content_action IN ('delete', 'truncate'));
+
+CREATE TABLE IF NOT EXISTS "email_event_daily_stats" (
+  "id" varchar PRIMARY KEY DEFAULT gen_random_uuid(),
+  "tenant_id" varchar NOT NULL REFERENCES "tenants"("id") ON DELETE CASCADE,
+  "day" date NOT NULL,
+  "email_type" text NOT NULL,
+  "event_type" text NOT NULL,
+  "event_count" integer NOT NULL DEFAULT 0
+);
+
+COMMENT ON TABLE email_event_daily_stats IS 'Daily counts of email_events rows removed by the retention purge';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "email_event_daily_stats_day_idx" ON "email_event_daily_stats"("tenant_id", "day", "email_type", "event_type");
+
+CREATE TABLE IF NOT EXISTS "retention_purge_runs" (
+  "id" varchar PRIMARY KEY DEFAULT gen_random_uuid(),
+  "tenant_id" varchar NOT NULL REFERENCES "tenants"("id") ON DELETE CASCADE,
+  "run_id" text NOT NULL,
+  "content_deleted" integer NOT NULL DEFAULT 0,
+  "content_truncated" integer NOT NULL DEFAULT 0,
+  "webhook_payloads_cleared" integer NOT NULL DEFAULT 0,
+  "events_compacted" integer NOT NULL DEFAULT 0,
+  "ran_at" timestamp NOT NULL DEFAULT now()
+);
+
+COMMENT ON COLUMN retention_purge_runs.run_id IS 'Temporal run ID of the RetentionPurgeWorkflow run';
+
+CREATE INDEX IF NOT EXISTS "retention_purge_runs_tenant_idx" ON "retention_purge_runs"("tenant_id", "ran_at" DESC);
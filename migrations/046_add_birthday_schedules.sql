@@ -0,0 +1,25 @@
+-- Migration: Add per-tenant birthday send schedules
+-- A tenant can have its birthday cards sent by a Temporal Schedule instead of the app's fixed
+-- daily check. The schedule runs BirthdaySendWorkflow at send_hour in the tenant's timezone;
+-- days_ahead sends cards that many days before the birthday. schedule_id is set while the
+-- tenant has a schedule, and the Go service reconciles Temporal against these rows at startup.
+
+ALTER TABLE birthday_settings
+ADD COLUMN IF NOT EXISTS schedule_id text,
+ADD COLUMN IF NOT EXISTS send_hour integer NOT NULL DEFAULT 9,
+ADD COLUMN IF NOT EXISTS timezone text NOT NULL DEFAULT 'UTC',
+ADD COLUMN IF NOT EXISTS days_ahead integer NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS schedule_paused boolean NOT NULL DEFAULT false;
+
+ALTER TABLE birthday_settings
+ADD CONSTRAINT check_birthday_settings_send_hour
+CHECK (send_hour BETWEEN 0 AND 23);
+
+ALTER TABLE birthday_settings
+ADD CONSTRAINT check_birthday_settings_days_ahead
+CHECK (days_ahead BETWEEN 0 AND 30);
+
+COMMENT ON COLUMN birthday_settings.schedule_id IS 'Temporal Schedule ID sending this tenant''s birthday cards; NULL when the tenant has no schedule';
+COMMENT ON COLUMN birthday_settings.send_hour IS 'Hour of the day (0-23) in timezone when scheduled birthday cards are sent';
+COMMENT ON COLUMN birthday_settings.timezone IS 'IANA timezone for the send hour and for deciding whose birthday it is';
+COMMENT ON COLUMN birthday_settings.days_ahead IS 'Days before the birthday that scheduled cards are sent; 0 sends on the day';
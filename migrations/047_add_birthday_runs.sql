@@ -0,0 +1,36 @@
+-- Migration: Add birthday runs
+-- BirthdaySendWorkflow now sends a tenant's birthday cards as a batch with a bounded number
+-- of card workflows at a time and can be paused and resumed. When a run ends it writes one
+-- summary row here; while it is still going its progress is read from the workflow itself.
+
+CREATE TABLE IF NOT EXISTS "birthday_runs" (
+  "id" varchar PRIMARY KEY DEFAULT gen_random_uuid(),
+  "tenant_id" varchar NOT NULL REFERENCES "tenants"("id") ON DELETE CASCADE,
+  "workflow_id" text NOT NULL,
+  "run_id" text NOT NULL,
+  "trigger" text NOT NULL DEFAULT 'schedule',
+  "status" text NOT NULL,
+  "birthday_date" date,
+  "total_contacts" integer NOT NULL DEFAULT 0,
+  "processed_count" integer NOT NULL DEFAULT 0,
+  "sent_count" integer NOT NULL DEFAULT 0,
+  "skipped_count" integer NOT NULL DEFAULT 0,
+  "already_sent_count" integer NOT NULL DEFAULT 0,
+  "failed_count" integer NOT NULL DEFAULT 0,
+  "started_at" timestamp NOT NULL,
+  "completed_at" timestamp
+);
+
+ALTER TABLE birthday_runs
+ADD CONSTRAINT check_birthday_runs_trigger
+CHECK (trigger IN ('schedule', 'manual'));
+
+ALTER TABLE birthday_runs
+ADD CONSTRAINT check_birthday_runs_status
+CHECK (status IN ('completed', 'canceled', 'failed'));
+
+COMMENT ON COLUMN birthday_runs.workflow_id IS 'Temporal workflow ID of the BirthdaySendWorkflow run';
+COMMENT ON COLUMN birthday_runs.already_sent_count IS 'Cards skipped because an earlier run had already started them';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "birthday_runs_workflow_idx" ON "birthday_runs"("workflow_id");
+CREATE INDEX IF NOT EXISTS "birthday_runs_tenant_idx" ON "birthday_runs"("tenant_id", "started_at" DESC);
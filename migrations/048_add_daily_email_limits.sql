@@ -0,0 +1,16 @@
+-- Migration: Add daily email limits
+-- The Go send activities now cap how many emails a tenant sends per UTC day. The cap comes from
+-- an active tenant_limits override, else the tenant's subscription plan, else the Free plan,
+-- else DEFAULT_DAILY_SEND_LIMIT. NULL on a plan means no daily cap, like monthly_email_limit.
+
+ALTER TABLE subscription_plans
+ADD COLUMN IF NOT EXISTS daily_email_limit integer;
+
+ALTER TABLE tenant_limits
+ADD COLUMN IF NOT EXISTS daily_email_limit integer;
+
+COMMENT ON COLUMN subscription_plans.daily_email_limit IS 'Emails a tenant on this plan may send per UTC day; NULL is unlimited';
+COMMENT ON COLUMN tenant_limits.daily_email_limit IS 'Overrides the plan''s daily email limit; NULL uses the plan limit';
+
+-- Daily send counts look up a tenant's sends since midnight
+CREATE INDEX IF NOT EXISTS idx_email_sends_tenant_created_at ON email_sends(tenant_id, created_at);
@@ -0,0 +1,10 @@
+-- Migration: Add birthday promotion delay
+-- With split promotional emails, BirthdayTestWorkflow used to wait a fixed 30 seconds between
+-- the birthday card and the promotion. The wait is now a durable timer set per tenant: either
+-- a duration ("30s", "4h") or "next day at HH:MM" in the tenant's birthday schedule timezone.
+-- NULL keeps the 30 second wait.
+
+ALTER TABLE birthday_settings
+ADD COLUMN IF NOT EXISTS promotion_delay text;
+
+COMMENT ON COLUMN birthday_settings.promotion_delay IS 'Wait before a split promotional email: a duration such as "4h" or "next day at HH:MM"; NULL is 30 seconds';
@@ -1,4 +1,4 @@
-import type { Express } from "express";
+import express, { type Express } from "express";
 import { createServer, type Server } from "http";
 import path from "path";
 import { fileURLToPath } from "url";
@@ -246,13 +246,21 @@ export async function registerRoutes(app: Express): Promise<Server> {
     try {
       console.log('🔗 [Unsubscribe Proxy] Forwarding POST request to cardprocessor-go:5004');
 
+      // Repeat array fields such as the topic checkboxes instead of joining them with commas
+      const form = new URLSearchParams();
+      for (const [key, value] of Object.entries(req.body ?? {})) {
+        for (const item of Array.isArray(value) ? value : [value]) {
+          form.append(key, String(item));
+        }
+      }
+
       // Forward request to cardprocessor-go server
       const response = await fetch('http://localhost:5004/api/unsubscribe/birthday', {
         method: 'POST',
         headers: {
           'Content-Type': 'application/x-www-form-urlencoded',
         },
-        body: new URLSearchParams(req.body).toString()
+        body: form.toString()
       });
 
       if (!response.ok) {
@@ -271,6 +279,32 @@ export async function registerRoutes(app: Express): Promise<Server> {
     }
   });
 
+  // RFC 8058 one-click unsubscribe - proxy to cardprocessor-go main server (port 5004)
+  // Mailbox providers POST "List-Unsubscribe=One-Click" here (urlencoded or multipart) with no
+  // user interaction; the response is a bare status code.
+  app.post("/api/unsubscribe/one-click", express.raw({ type: 'multipart/form-data', limit: '10kb' }), async (req: any, res) => {
+    try {
+      const queryParams = new URLSearchParams(req.query as any).toString();
+      const url = `http://localhost:5004/api/unsubscribe/one-click${queryParams ? '?' + queryParams : ''}`;
+
+      const isMultipart = Buffer.isBuffer(req.body);
+      const response = await fetch(url, {
+        method: 'POST',
+        headers: {
+          'Content-Type': isMultipart ? req.get('content-type') : 'application/x-www-form-urlencoded',
+          'User-Agent': req.get('user-agent') || 'birthday-service-proxy',
+          'X-Forwarded-For': req.ip,
+        },
+        body: isMultipart ? req.body : new URLSearchParams(req.body).toString(),
+      });
+
+      res.status(response.status).end();
+    } catch (error) {
+      console.error('❌ [One-Click Unsubscribe Proxy] Failed to communicate with cardprocessor-go:', error);
+      res.status(500).end();
+    }
+  });
+
   // Resubscribe endpoint - proxy to cardprocessor-go main server (port 5004)
   app.get("/api/resubscribe/birthday", async (req: any, res) => {
     try {
@@ -298,6 +332,81 @@ export async function registerRoutes(app: Express): Promise<Server> {
     }
   });
 
+  // Contact preference center - proxy to cardprocessor-go main server (port 5004)
+  // Public (token-authenticated). Language headers and cookies are forwarded so the page
+  // language negotiation and the remembered language choice keep working behind the proxy.
+  const proxyPreferenceCenter = async (req: any, res: any) => {
+    try {
+      console.log(`🔗 [Preferences Proxy] Forwarding ${req.method} request to cardprocessor-go:5004`);
+
+      const queryParams = new URLSearchParams(req.query as any).toString();
+      const url = `http://localhost:5004/api/preferences${queryParams ? '?' + queryParams : ''}`;
+
+      const headers: Record<string, string> = {
+        'User-Agent': req.get('user-agent') || 'birthday-service-proxy',
+        'X-Forwarded-For': req.ip,
+      };
+      if (req.get('accept-language')) headers['Accept-Language'] = req.get('accept-language');
+      if (req.get('cookie')) headers['Cookie'] = req.get('cookie');
+      if (req.method === 'POST') headers['Content-Type'] = 'application/x-www-form-urlencoded';
+
+      const response = await fetch(url, {
+        method: req.method,
+        headers,
+        body: req.method === 'POST' ? new URLSearchParams(req.body).toString() : undefined,
+      });
+
+      const setCookie = response.headers.get('set-cookie');
+      if (setCookie) res.set('Set-Cookie', setCookie);
+      res.set('Vary', 'Accept-Language, Cookie');
+
+      const html = await response.text();
+      res.status(response.status).type('text/html').send(html);
+    } catch (error) {
+      console.error('❌ [Preferences Proxy] Failed to communicate with cardprocessor-go:', error);
+      res.status(500).send('<html><body><h1>Service Temporarily Unavailable</h1><p>Unable to load your preferences. Please try again later.</p></body></html>');
+    }
+  };
+  app.get("/api/preferences", proxyPreferenceCenter);
+  app.post("/api/preferences", proxyPreferenceCenter);
+
+  // Birthday invitation page - proxy to cardprocessor-go main server (port 5004)
+  // Public (authenticated by the single-use invitation token in the emailed link)
+  const proxyBirthdayInvitation = async (req: any, res: any) => {
+    try {
+      console.log(`🔗 [Birthday Invitation Proxy] Forwarding ${req.method} request to cardprocessor-go:5004`);
+
+      const queryParams = new URLSearchParams(req.query as any).toString();
+      const url = `http://localhost:5004/api/birthday-update${queryParams ? '?' + queryParams : ''}`;
+
+      const headers: Record<string, string> = {
+        'User-Agent': req.get('user-agent') || 'birthday-service-proxy',
+        'X-Forwarded-For': req.ip,
+      };
+      if (req.get('accept-language')) headers['Accept-Language'] = req.get('accept-language');
+      if (req.get('cookie')) headers['Cookie'] = req.get('cookie');
+      if (req.method === 'POST') headers['Content-Type'] = 'application/x-www-form-urlencoded';
+
+      const response = await fetch(url, {
+        method: req.method,
+        headers,
+        body: req.method === 'POST' ? new URLSearchParams(req.body).toString() : undefined,
+      });
+
+      const setCookie = response.headers.get('set-cookie');
+      if (setCookie) res.set('Set-Cookie', setCookie);
+      res.set('Vary', 'Accept-Language, Cookie');
+
+      const html = await response.text();
+      res.status(response.status).type('text/html').send(html);
+    } catch (error) {
+      console.error('❌ [Birthday Invitation Proxy] Failed to communicate with cardprocessor-go:', error);
+      res.status(500).send('<html><body><h1>Service Temporarily Unavailable</h1><p>Unable to load this page. Please try again later.</p></body></html>');
+    }
+  };
+  app.get("/api/birthday-update", proxyBirthdayInvitation);
+  app.post("/api/birthday-update", proxyBirthdayInvitation);
+
   // Email tracking endpoints - DISABLED (legacy server-node service no longer exists)
   // Email tracking is now handled directly in the database via email_sends, email_events, and email_content tables
   // Data is tracked automatically by cardprocessor-go when emails are sent
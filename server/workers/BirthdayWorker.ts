@@ -204,6 +204,12 @@ export class BirthdayWorker extends EventEmitter {
         return;
       }
 
+      // Tenants with a Temporal Schedule get their cards from the Go card processor instead
+      if (settings.scheduleId) {
+        console.log(`🎂 [BirthdayWorker] Birthday emails for tenant ${tenantId} are sent by schedule ${settings.scheduleId}`);
+        return;
+      }
+
       // Create birthday jobs for each contact
       for (const contact of contacts) {
         const jobId = this.createBirthdayJob(contact, settings);
@@ -1,5 +1,5 @@
 import { sql } from "drizzle-orm";
-import { pgTable, text, varchar, timestamp, boolean, decimal, integer, uuid, uniqueIndex, index, jsonb } from "drizzle-orm/pg-core";
+import { pgTable, text, varchar, timestamp, boolean, decimal, integer, uuid, uniqueIndex, index, jsonb, date } from "drizzle-orm/pg-core";
 import { relations } from "drizzle-orm";
 import { createInsertSchema } from "drizzle-zod";
 import { z } from "zod";
@@ -180,6 +180,7 @@ export const subscriptionPlans = pgTable("subscription_plans", {
   isActive: boolean("is_active").default(true),
   sortOrder: integer("sort_order").default(0),
   monthlyEmailLimit: integer("monthly_email_limit").default(100), // Default to Free plan limit
+  dailyEmailLimit: integer("daily_email_limit"), // Sends per UTC day, enforced by the Go worker; null = unlimited
   allowUsersManagement: boolean("allow_users_management").default(false), // Whether plan allows managing users
   allowRolesManagement: boolean("allow_roles_management").default(false), // Whether plan allows managing roles/permissions
   createdAt: timestamp("created_at").defaultNow(),
@@ -218,6 +219,7 @@ export const tenantLimits = pgTable("tenant_limits", {
   maxUsers: integer("max_users"), // NULL means use subscription plan limit
   maxStorageGb: integer("max_storage_gb"), // NULL means use subscription plan limit
   monthlyEmailLimit: integer("monthly_email_limit"), // NULL means use subscription plan limit
+  dailyEmailLimit: integer("daily_email_limit"), // NULL means use subscription plan limit
   customLimits: text("custom_limits").default('{}'), // JSON for future extensibility
   overrideReason: text("override_reason"), // Why this tenant has custom limits
   createdBy: varchar("created_by").references(() => betterAuthUser.id, { onDelete: 'set null' }),
@@ -239,6 +241,7 @@ export const emailSends = pgTable("email_sends", {
   senderName: text("sender_name"),
   subject: text("subject").notNull(),
   emailType: text("email_type").notNull(), // 'birthday_card', 'test_card', 'promotional', 'newsletter', 'invitation', 'appointment_reminder'
+  subjectVariant: text("subject_variant"), // A/B subject variant ID used for this send
 
   // Provider details
   provider: text("provider").notNull(), // 'resend', 'sendgrid', 'mailgun'
@@ -361,6 +364,7 @@ export const emailContacts = pgTable("email_contacts", {
   birthdayEmailEnabled: boolean("birthday_email_enabled").default(false), // Whether user wants birthday emails
   birthdayUnsubscribeReason: text("birthday_unsubscribe_reason"), // Reason for unsubscribing from birthday emails
   birthdayUnsubscribedAt: timestamp("birthday_unsubscribed_at"), // Timestamp when unsubscribed from birthday emails
+  preferredLanguage: text("preferred_language"), // ISO 639-1 code used for birthday cards (e.g. 'es')
   // Consent tracking fields
   consentGiven: boolean("consent_given").notNull().default(false),
   consentDate: timestamp("consent_date"),
@@ -567,7 +571,7 @@ export const emailActivity = pgTable("email_activity", {
   contactId: varchar("contact_id").notNull().references(() => emailContacts.id, { onDelete: 'cascade' }),
   campaignId: varchar("campaign_id").references(() => campaigns.id, { onDelete: 'set null' }),
   newsletterId: varchar("newsletter_id").references(() => newsletters.id, { onDelete: 'set null' }),
-  activityType: text("activity_type").notNull(), // 'sent', 'delivered', 'opened', 'clicked', 'bounced', 'complained', 'unsubscribed'
+  activityType: text("activity_type").notNull(), // 'sent', 'delivered', 'opened', 'clicked', 'bounced', 'complained', 'unsubscribed', 'preferences_updated', 'birthday_invitation_completed'
   activityData: text("activity_data"), // JSON string with additional event data
   userAgent: text("user_agent"),
   ipAddress: text("ip_address"),
@@ -1494,7 +1498,7 @@ export const createEmailActivitySchema = z.object({
   contactId: z.string().uuid(),
   campaignId: z.string().uuid().optional(),
   newsletterId: z.string().uuid().optional(),
-  activityType: z.enum(['sent', 'delivered', 'opened', 'clicked', 'bounced', 'complained', 'unsubscribed']),
+  activityType: z.enum(['sent', 'delivered', 'opened', 'clicked', 'bounced', 'complained', 'unsubscribed', 'preferences_updated', 'birthday_invitation_completed']),
   activityData: z.string().optional(),
   userAgent: z.string().optional(),
   ipAddress: z.string().optional(),
@@ -1844,8 +1848,20 @@ export const birthdaySettings = pgTable("birthday_settings", {
   customThemeData: text("custom_theme_data"), // JSON data for custom theme
   promotionId: varchar("promotion_id").references(() => promotions.id, { onDelete: 'set null' }), // Optional promotion to include in birthday emails
   splitPromotionalEmail: boolean("split_promotional_email").default(false), // Send promotion as separate email for better deliverability
+  promotionDelay: text("promotion_delay"), // Wait before the split promotion: a duration like "4h" or "next day at HH:MM" (schedule timezone); null is 30s
+  personalizedCardImage: boolean("personalized_card_image").default(false), // Render a card image with the contact's name and age
+  subjectTemplate: text("subject_template"), // Subject line with merge tags, e.g. "Happy Birthday {{firstName}}!"
+  preheaderText: text("preheader_text"), // Inbox preview text
+  subjectVariants: text("subject_variants"), // JSON array of weighted A/B subject variants
+  defaultLanguage: text("default_language").notNull().default('en'), // Language for contacts without a preferred language
+  localizedMessages: text("localized_messages"), // JSON object of per-language custom messages
   disabledHolidays: text("disabled_holidays").array(), // Array of disabled holiday IDs (e.g., ['valentine', 'stpatrick'])
   senderName: text("sender_name").default(''), // Sender name for birthday emails
+  scheduleId: text("schedule_id"), // Temporal Schedule sending this tenant's cards; null when unscheduled
+  sendHour: integer("send_hour").notNull().default(9), // Hour (0-23) in timezone that scheduled cards go out
+  timezone: text("timezone").notNull().default('UTC'), // IANA timezone for the schedule
+  daysAhead: integer("days_ahead").notNull().default(0), // Send scheduled cards this many days before the birthday
+  schedulePaused: boolean("schedule_paused").notNull().default(false),
   createdAt: timestamp("created_at").defaultNow(),
   updatedAt: timestamp("updated_at").defaultNow(),
 });
@@ -1906,6 +1922,181 @@ export const birthdayUnsubscribeTokens = pgTable("birthday_unsubscribe_tokens",
   usedAt: timestamp("used_at"),
 });
 
+// Birthday invitations: single-use signed links asking a contact for their birthday
+export const birthdayInvitations = pgTable("birthday_invitations", {
+  id: varchar("id").primaryKey().default(sql`gen_random_uuid()`),
+  tenantId: varchar("tenant_id").notNull().references(() => tenants.id, { onDelete: 'cascade' }),
+  contactId: varchar("contact_id").notNull().references(() => emailContacts.id, { onDelete: 'cascade' }),
+  tokenHash: varchar("token_hash", { length: 64 }).notNull().unique(), // SHA-256 of the token; the token itself is never stored
+  status: text("status").notNull().default('pending'), // pending, sent, failed, opened, completed
+  expiresAt: timestamp("expires_at").notNull(),
+  sentAt: timestamp("sent_at"),
+  openedAt: timestamp("opened_at"),
+  completedAt: timestamp("completed_at"), // Set when the contact submits their birthday; the token is then spent
+  createdAt: timestamp("created_at").defaultNow(),
+});
+
+// Per-contact subscription topics (birthday_promotion, anniversaries, invitations). Birthday cards
+// stay in emailContacts.birthdayEmailEnabled; a missing row means subscribed.
+export const contactSubscriptions = pgTable("contact_subscriptions", {
+  id: varchar("id").primaryKey().default(sql`gen_random_uuid()`),
+  tenantId: varchar("tenant_id").notNull().references(() => tenants.id, { onDelete: 'cascade' }),
+  contactId: varchar("contact_id").notNull().references(() => emailContacts.id, { onDelete: 'cascade' }),
+  topic: text("topic").notNull(),
+  subscribed: boolean("subscribed").notNull().default(true),
+  updatedAt: timestamp("updated_at").defaultNow(),
+}, (table) => ({
+  contactTopicIdx: uniqueIndex("contact_subscriptions_contact_topic_idx").on(table.contactId, table.topic),
+}));
+
+// History of subscription topic changes, including birthday cards
+export const contactSubscriptionEvents = pgTable("contact_subscription_events", {
+  id: varchar("id").primaryKey().default(sql`gen_random_uuid()`),
+  tenantId: varchar("tenant_id").notNull().references(() => tenants.id, { onDelete: 'cascade' }),
+  contactId: varchar("contact_id").notNull().references(() => emailContacts.id, { onDelete: 'cascade' }),
+  topic: text("topic").notNull(), // birthday_card, birthday_promotion, anniversaries, invitations
+  subscribed: boolean("subscribed").notNull(),
+  source: text("source").notNull(), // unsubscribe_page, one_click, mailto, api
+  reason: text("reason"),
+  createdAt: timestamp("created_at").defaultNow(),
+}, (table) => ({
+  contactIdx: index("contact_subscription_events_contact_idx").on(table.tenantId, table.contactId, table.createdAt),
+}));
+
+// Append-only consent audit log (GDPR/CASL). No foreign keys so it outlives the contact;
+// a trigger rejects updates and deletes (see migrations/043_add_consent_events.sql).
+export const consentEvents = pgTable("consent_events", {
+  id: varchar("id").primaryKey().default(sql`gen_random_uuid()`),
+  tenantId: varchar("tenant_id").notNull(),
+  contactId: varchar("contact_id").notNull(),
+  email: text("email").notNull(), // Address at the time of the change
+  topic: text("topic").notNull(), // A subscription topic or marketing
+  action: text("action").notNull(), // granted, withdrawn
+  source: text("source").notNull(), // unsubscribe_page, one_click, mailto, preference_center, birthday_invitation, api, ...
+  reason: text("reason"),
+  consentText: text("consent_text"), // Consent wording shown, for opt-ins
+  ipAddress: text("ip_address"),
+  userAgent: text("user_agent"),
+  actorUserId: varchar("actor_user_id"), // Staff user; NULL when the contact made the change
+  occurredAt: timestamp("occurred_at").notNull().defaultNow(),
+}, (table) => ({
+  contactIdx: index("consent_events_contact_idx").on(table.tenantId, table.contactId, table.occurredAt),
+}));
+
+// GDPR erasures: counts of what each erasure removed and a SHA-256 hash of the address, which
+// suppresses future sends without keeping the address itself
+export const contactErasures = pgTable("contact_erasures", {
+  id: varchar("id").primaryKey().default(sql`gen_random_uuid()`),
+  tenantId: varchar("tenant_id").notNull().references(() => tenants.id, { onDelete: 'cascade' }),
+  contactId: varchar("contact_id").notNull(), // ID of the deleted contact
+  emailHash: varchar("email_hash", { length: 64 }).notNull(), // Hex SHA-256 of the trimmed, lowercased address
+  reason: text("reason"),
+  requestedByUserId: varchar("requested_by_user_id"),
+  emailsSent: integer("emails_sent").notNull().default(0),
+  emailsOpened: integer("emails_opened").notNull().default(0),
+  emailSendsPseudonymised: integer("email_sends_pseudonymised").notNull().default(0),
+  emailContentsDeleted: integer("email_contents_deleted").notNull().default(0),
+  emailEventsScrubbed: integer("email_events_scrubbed").notNull().default(0),
+  activitiesDeleted: integer("activities_deleted").notNull().default(0),
+  unsubscribeTokensDeleted: integer("unsubscribe_tokens_deleted").notNull().default(0),
+  erasedAt: timestamp("erased_at").notNull().defaultNow(),
+}, (table) => ({
+  emailHashIdx: index("contact_erasures_email_hash_idx").on(table.tenantId, table.emailHash),
+}));
+
+// Per-tenant retention for stored email data, applied by the Go RetentionPurgeWorkflow.
+// NULL falls back to the RETENTION_* defaults; 0 keeps data forever.
+export const emailRetentionSettings = pgTable("email_retention_settings", {
+  tenantId: varchar("tenant_id").primaryKey().references(() => tenants.id, { onDelete: 'cascade' }),
+  contentDays: integer("content_days"), // Days to keep email_content
+  contentAction: text("content_action").notNull().default('delete'), // delete, truncate
+  webhookDataDays: integer("webhook_data_days"), // Days to keep email_activity.webhook_data
+  eventDays: integer("event_days"), // Days before email_events are compacted into daily stats
+  createdAt: timestamp("created_at").defaultNow(),
+  updatedAt: timestamp("updated_at").defaultNow(),
+});
+
+// Daily counts of the email_events rows compacted by the retention purge
+export const emailEventDailyStats = pgTable("email_event_daily_stats", {
+  id: varchar("id").primaryKey().default(sql`gen_random_uuid()`),
+  tenantId: varchar("tenant_id").notNull().references(() => tenants.id, { onDelete: 'cascade' }),
+  day: date("day").notNull(),
+  emailType: text("email_type").notNull(),
+  eventType: text("event_type").notNull(),
+  eventCount: integer("event_count").notNull().default(0),
+}, (table) => ({
+  dayIdx: uniqueIndex("email_event_daily_stats_day_idx").on(table.tenantId, table.day, table.emailType, table.eventType),
+}));
+
+// What each retention purge run removed, per tenant
+export const retentionPurgeRuns = pgTable("retention_purge_runs", {
+  id: varchar("id").primaryKey().default(sql`gen_random_uuid()`),
+  tenantId: varchar("tenant_id").notNull().references(() => tenants.id, { onDelete: 'cascade' }),
+  runId: text("run_id").notNull(), // Temporal run ID
+  contentDeleted: integer("content_deleted").notNull().default(0),
+  contentTruncated: integer("content_truncated").notNull().default(0),
+  webhookPayloadsCleared: integer("webhook_payloads_cleared").notNull().default(0),
+  eventsCompacted: integer("events_compacted").notNull().default(0),
+  ranAt: timestamp("ran_at").notNull().defaultNow(),
+}, (table) => ({
+  tenantIdx: index("retention_purge_runs_tenant_idx").on(table.tenantId, table.ranAt),
+}));
+
+// Summary of each finished BirthdaySendWorkflow batch run
+export const birthdayRuns = pgTable("birthday_runs", {
+  id: varchar("id").primaryKey().default(sql`gen_random_uuid()`),
+  tenantId: varchar("tenant_id").notNull().references(() => tenants.id, { onDelete: 'cascade' }),
+  workflowId: text("workflow_id").notNull(), // Temporal workflow ID
+  runId: text("run_id").notNull(), // Temporal run ID
+  trigger: text("trigger").notNull().default('schedule'), // schedule, manual
+  status: text("status").notNull(), // completed, canceled, failed
+  birthdayDate: date("birthday_date"),
+  totalContacts: integer("total_contacts").notNull().default(0),
+  processedCount: integer("processed_count").notNull().default(0),
+  sentCount: integer("sent_count").notNull().default(0),
+  skippedCount: integer("skipped_count").notNull().default(0), // Suppressed or unsubscribed contacts
+  alreadySentCount: integer("already_sent_count").notNull().default(0), // Cards an earlier run already started
+  failedCount: integer("failed_count").notNull().default(0),
+  startedAt: timestamp("started_at").notNull(),
+  completedAt: timestamp("completed_at"),
+}, (table) => ({
+  workflowIdx: uniqueIndex("birthday_runs_workflow_idx").on(table.workflowId),
+  tenantIdx: index("birthday_runs_tenant_idx").on(table.tenantId, table.startedAt),
+}));
+
+// Self-hosted image assets (uploads and proxied copies of external card images)
+export const imageAssets = pgTable("image_assets", {
+  id: varchar("id").primaryKey().default(sql`gen_random_uuid()`),
+  tenantId: varchar("tenant_id").references(() => tenants.id, { onDelete: 'cascade' }), // NULL for shared proxied images
+  sourceUrl: text("source_url"), // External URL this asset was proxied from; NULL for uploads
+  originalFilename: text("original_filename"),
+  originalContentType: text("original_content_type").notNull(),
+  originalWidth: integer("original_width").notNull(),
+  originalHeight: integer("original_height").notNull(),
+  createdAt: timestamp("created_at").defaultNow(),
+}, (table) => ({
+  tenantIdIdx: index("image_assets_tenant_id_idx").on(table.tenantId),
+  sourceUrlIdx: uniqueIndex("image_assets_source_url_idx").on(table.sourceUrl).where(sql`${table.sourceUrl} IS NOT NULL`),
+}));
+
+export const imageAssetVariants = pgTable("image_asset_variants", {
+  id: varchar("id").primaryKey().default(sql`gen_random_uuid()`),
+  assetId: varchar("asset_id").notNull().references(() => imageAssets.id, { onDelete: 'cascade' }),
+  width: integer("width").notNull(),
+  height: integer("height").notNull(),
+  contentType: text("content_type").notNull(),
+  contentHash: varchar("content_hash", { length: 64 }).notNull(), // SHA-256, also the public file name
+  storageKey: text("storage_key").notNull(),
+  sizeBytes: integer("size_bytes").notNull(),
+  createdAt: timestamp("created_at").defaultNow(),
+}, (table) => ({
+  assetWidthIdx: uniqueIndex("image_asset_variants_asset_width_idx").on(table.assetId, table.width),
+  contentHashIdx: index("image_asset_variants_content_hash_idx").on(table.contentHash),
+}));
+
+export type ImageAsset = typeof imageAssets.$inferSelect;
+export type ImageAssetVariant = typeof imageAssetVariants.$inferSelect;
+
 // Extended types for bounced emails with relations
 export interface BouncedEmailWithDetails extends BouncedEmail {
   sourceTenant?: Tenant;